@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -26,6 +28,8 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/ellipsis"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 	grpcruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/grpc"
 )
 
@@ -82,6 +86,17 @@ func AddInstanceCommands(
 				}
 				return strings.Join(g.pg.Tags, ",")
 			})
+			cols.MustAddColumn(columns.Attributes{
+				Name:         "Paused",
+				Visible:      true,
+				EllipsisType: ellipsis.End,
+				Order:        40,
+			}, func(g *GadgetInfo) any {
+				if g.pg == nil {
+					return ""
+				}
+				return strconv.FormatBool(g.pg.Paused)
+			})
 			cols.MustAddColumn(columns.Attributes{
 				Name:         "Gadget",
 				Visible:      true,
@@ -91,7 +106,8 @@ func AddInstanceCommands(
 				if g.pg == nil {
 					return ""
 				}
-				return g.pg.GadgetConfig.ImageName
+				images := append([]string{g.pg.GadgetConfig.ImageName}, g.pg.GadgetConfig.AdditionalImageNames...)
+				return strings.Join(images, ",")
 			})
 
 			formatter := textcolumns.NewFormatter(cols.GetColumnMap())
@@ -140,4 +156,136 @@ func AddInstanceCommands(
 	}
 	AddFlags(deleteCmd, runtimeParams, nil, runtime)
 	rootCmd.AddCommand(deleteCmd)
+
+	pauseCmd := &cobra.Command{
+		Use:          "pause",
+		Short:        "Pause one or more gadget instances, keeping their eBPF programs loaded and their buffered events",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return forEachGadgetInstance(runtime, runtimeParams, args, "pause", func(id string) error {
+				return runtime.PauseGadgetInstance(context.Background(), runtimeParams, id)
+			})
+		},
+	}
+	AddFlags(pauseCmd, runtimeParams, nil, runtime)
+	rootCmd.AddCommand(pauseCmd)
+
+	resumeCmd := &cobra.Command{
+		Use:          "resume",
+		Short:        "Resume one or more paused gadget instances",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return forEachGadgetInstance(runtime, runtimeParams, args, "resume", func(id string) error {
+				return runtime.ResumeGadgetInstance(context.Background(), runtimeParams, id)
+			})
+		},
+	}
+	AddFlags(resumeCmd, runtimeParams, nil, runtime)
+	rootCmd.AddCommand(resumeCmd)
+
+	updateCmd := &cobra.Command{
+		Use:   "update INSTANCE PARAM=VALUE...",
+		Short: "Update params of a running gadget instance",
+		Long: `Update params of a running gadget instance without restarting it.
+
+Only params that operators declare as live-updatable can be changed:
+` + strings.Join(liveUpdateParamNames(), ", ") + `
+
+Params can be given by their name (e.g. filter) or their full key (e.g. operator.filter.filter).`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paramValues, err := parseLiveUpdateParams(args[1:])
+			if err != nil {
+				return err
+			}
+			return forEachGadgetInstance(runtime, runtimeParams, args[:1], "update", func(id string) error {
+				return runtime.UpdateGadgetInstance(context.Background(), runtimeParams, id, paramValues)
+			})
+		},
+	}
+	AddFlags(updateCmd, runtimeParams, nil, runtime)
+	rootCmd.AddCommand(updateCmd)
+}
+
+// forEachGadgetInstance looks up the given gadget instances by ID or name and
+// calls fn for each of them
+func forEachGadgetInstance(
+	runtime *grpcruntime.Runtime,
+	runtimeParams *params.Params,
+	idOrNames []string,
+	action string,
+	fn func(id string) error,
+) error {
+	instances, ambiguous, notfound, err := findGadgetInstances(runtime, runtimeParams, idOrNames)
+	if err != nil {
+		return fmt.Errorf("getting gadget instances: %w", err)
+	}
+	if len(ambiguous) > 0 {
+		fmt.Fprintf(os.Stderr, "ambiguous names/ids: %s\n", strings.Join(ambiguous, ", "))
+	}
+	if len(notfound) > 0 {
+		fmt.Fprintf(os.Stderr, "not found names/ids: %s\n", strings.Join(notfound, ", "))
+	}
+	failed := false
+	for _, instance := range instances {
+		if err := fn(instance.Id); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to %s gadget instance %q: %v\n", action, instance.Id, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s\n", instance.Id)
+	}
+	if failed || len(ambiguous) > 0 || len(notfound) > 0 {
+		return fmt.Errorf("failed to %s all gadget instances", action)
+	}
+	return nil
+}
+
+// liveUpdateParams returns the params of all data operators that can be
+// updated while a gadget is running, indexed by their name and full key
+func liveUpdateParams() map[string]string {
+	res := make(map[string]string)
+	for _, op := range operators.GetDataOperators() {
+		for _, p := range op.InstanceParams() {
+			if !slices.Contains(p.Tags, api.TagLiveUpdate) {
+				continue
+			}
+			key := fmt.Sprintf("operator.%s.%s", op.Name(), p.Key)
+			res[p.Key] = key
+			res[key] = key
+		}
+	}
+	return res
+}
+
+func liveUpdateParamNames() []string {
+	var names []string
+	for name := range liveUpdateParams() {
+		if !strings.HasPrefix(name, "operator.") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func parseLiveUpdateParams(args []string) (api.ParamValues, error) {
+	keys := liveUpdateParams()
+	paramValues := make(api.ParamValues)
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid param %q: expected PARAM=VALUE", arg)
+		}
+		key, ok := keys[name]
+		if !ok {
+			return nil, fmt.Errorf("param %q cannot be updated; supported params: %s",
+				name, strings.Join(liveUpdateParamNames(), ", "))
+		}
+		paramValues[key] = value
+	}
+	return paramValues, nil
 }
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"slices"
 	"strings"
@@ -35,29 +36,41 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/aggregate"
 	clioperator "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/cli"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/combiner"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/dedup"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/file"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/generate_networkpolicy"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/join"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/limiter"
 	ocihandler "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/oci-handler"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otel-logs"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otel-metrics"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otel-traces"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ratelimit"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/record"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sink"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sort"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/syslog"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
 	grpcruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/grpc"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/replay"
 )
 
 type CommandMode string
 
 const (
-	CommandModeRun    CommandMode = "run GADGET"
+	CommandModeRun    CommandMode = "run GADGET [GADGET...]"
 	CommandModeAttach CommandMode = "attach GADGET_INSTANCE"
+	CommandModeReplay CommandMode = "replay FILE"
 )
 
 var commandModesDescriptions = map[CommandMode]string{
-	CommandModeRun:    "Run a gadget",
+	CommandModeRun:    "Run one or more gadgets",
 	CommandModeAttach: "Attach to a running gadget",
+	CommandModeReplay: "Replay a gadget run recorded using --record",
 }
 
 func findGadgetInstances(runtime *grpcruntime.Runtime, runtimeParams *params.Params, idOrNames []string) (instances []*api.GadgetInstance, ambiguous []string, notfound []string, retErr error) {
@@ -99,9 +112,17 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 
 	ociParams := apihelpers.ToParamDescs(ocihandler.OciHandler.InstanceParams()).ToParams()
 
+	dataOperators := operators.GetDataOperators()
+	if commandMode == CommandModeReplay {
+		// operators that ran before recording must not run again
+		maps.DeleteFunc(dataOperators, func(_ string, op operators.DataOperator) bool {
+			return replay.SkipDataOperator(op)
+		})
+	}
+
 	// Add operator global flags
 	opGlobalParams := make(map[string]*params.Params)
-	for _, op := range operators.GetDataOperators() {
+	for _, op := range dataOperators {
 		opGlobalParams[op.Name()] = apihelpers.ToParamDescs(op.GlobalParams()).ToParams()
 	}
 
@@ -165,7 +186,7 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 		}
 
 		ops := make([]operators.DataOperator, 0)
-		for _, op := range operators.GetDataOperators() {
+		for _, op := range dataOperators {
 			// Initialize operator
 			err := op.Init(opGlobalParams[op.Name()])
 			if err != nil {
@@ -175,9 +196,13 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 			ops = append(ops, op)
 		}
 		ops = append(ops, clioperator.CLIOperator, combiner.CombinerOperator, generate_networkpolicy.GNPOperator)
+		if commandMode == CommandModeReplay {
+			ops = slices.DeleteFunc(ops, replay.SkipDataOperator)
+		}
 		initializedOperators = true
 
 		imageName := actualArgs[0]
+		additionalImages := additionalImageArgs(commandMode, actualArgs)
 		if grpcrt, ok := runtime.(*grpcruntime.Runtime); ok && commandMode == CommandModeAttach {
 			instances, ambiguous, notfound, err := findGadgetInstances(grpcrt, runtimeParams, []string{imageName})
 			if err != nil {
@@ -213,6 +238,7 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 		gadgetCtx := gadgetcontext.New(
 			context.Background(),
 			imageName,
+			gadgetcontext.WithAdditionalImages(additionalImages...),
 			gadgetcontext.WithDataOperators(ops...),
 			gadgetcontext.WithUseInstance(commandMode == CommandModeAttach),
 		)
@@ -282,7 +308,7 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 		ctx := fe.GetContext()
 
 		ops := make([]operators.DataOperator, 0)
-		for _, op := range operators.GetDataOperators() {
+		for _, op := range dataOperators {
 			if !initializedOperators {
 				// initialize operators if not yet done in PreRun (e.g. when -f was specified)
 				err := op.Init(opGlobalParams[op.Name()])
@@ -294,6 +320,9 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 			ops = append(ops, op)
 		}
 		ops = append(ops, clioperator.CLIOperator, combiner.CombinerOperator, generate_networkpolicy.GNPOperator)
+		if commandMode == CommandModeReplay {
+			ops = slices.DeleteFunc(ops, replay.SkipDataOperator)
+		}
 
 		timeoutDuration := time.Duration(timeoutSeconds) * time.Second
 
@@ -301,6 +330,7 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 		if len(args) > 0 {
 			image = args[0]
 		}
+		additionalImages := additionalImageArgs(commandMode, args)
 
 		paramValueMap := make(map[string]string)
 
@@ -347,6 +377,7 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 
 			spec := specs[0]
 			image = spec.Image
+			additionalImages = nil
 			runtimeParams.Set("id", spec.ID)
 			runtimeParams.Set("name", spec.Name)
 			runtimeParams.Set("tags", strings.Join(spec.Tags, ","))
@@ -363,6 +394,7 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 		gadgetCtx := gadgetcontext.New(
 			ctx,
 			image,
+			gadgetcontext.WithAdditionalImages(additionalImages...),
 			gadgetcontext.WithDataOperators(ops...),
 			gadgetcontext.WithTimeout(timeoutDuration),
 			gadgetcontext.WithUseInstance(commandMode == CommandModeAttach),
@@ -414,7 +446,7 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 		"Number of seconds that the gadget will run for, 0 to run indefinitely",
 	)
 
-	if commandMode != CommandModeAttach {
+	if commandMode == CommandModeRun {
 		AddOCIFlags(cmd, ociParams, skipParams, runtime)
 		cmd.PersistentFlags().StringVarP(&inFile, "file", "f", "", "path or remote URL (prefixed with http:// or https://) to a gadget runtime manifest file")
 	}
@@ -429,6 +461,15 @@ func NewRunCommand(rootCmd *cobra.Command, runtime runtime.Runtime, hiddenColumn
 	return cmd
 }
 
+// additionalImageArgs returns the images to run together with the first one;
+// this is only supported when running gadgets
+func additionalImageArgs(commandMode CommandMode, args []string) []string {
+	if commandMode != CommandModeRun || len(args) < 2 {
+		return nil
+	}
+	return args[1:]
+}
+
 func runInstanceSpecsDetached(
 	ctx context.Context,
 	runtime runtime.Runtime,
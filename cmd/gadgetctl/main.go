@@ -29,6 +29,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/environment"
 	grpcruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/grpc"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/replay"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/experimental"
 )
 
@@ -107,6 +108,7 @@ func main() {
 	rootCmd.AddCommand(common.NewSyncCommand(runtime))
 	rootCmd.AddCommand(common.NewRunCommand(rootCmd, runtime, hiddenColumnTags, common.CommandModeRun))
 	rootCmd.AddCommand(common.NewRunCommand(rootCmd, runtime, hiddenColumnTags, common.CommandModeAttach))
+	rootCmd.AddCommand(common.NewRunCommand(rootCmd, replay.New(), hiddenColumnTags, common.CommandModeReplay))
 	rootCmd.AddCommand(common.NewConfigCmd(runtime, rootFlags))
 	rootCmd.AddCommand(image.NewImageCmd(runtime, imgCommands))
 
@@ -1,4 +1,4 @@
-// Copyright 2023-2024 The Inspektor Gadget authors
+// Copyright 2023-2025 The Inspektor Gadget authors
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -32,8 +32,10 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
 	gadgetservice "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/auth"
 	instancemanager "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/instance-manager"
 	filestore "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/store/file-store"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/oci"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
 	gadgettls "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/tls"
 )
@@ -52,6 +54,7 @@ func newDaemonCommand(runtime runtime.Runtime) *cobra.Command {
 	var serverKey string
 	var serverCert string
 	var clientCA string
+	var authConfig string
 
 	daemonCmd.PersistentFlags().StringVarP(
 		&group,
@@ -93,6 +96,12 @@ func newDaemonCommand(runtime runtime.Runtime) *cobra.Command {
 		"",
 		"Path to CA certificate for client validation")
 
+	daemonCmd.PersistentFlags().StringVar(
+		&authConfig,
+		"auth-config",
+		"",
+		"Path to a file configuring client authentication (TLS client certificates or tokens) and authorization")
+
 	service := gadgetservice.NewService(log.StandardLogger())
 
 	for _, params := range service.GetOperatorMap() {
@@ -163,6 +172,11 @@ All these options should be set at the same time to enable TLS connection`,
 				Certificates: []tls.Certificate{cert},
 				ClientCAs:    ca,
 			}
+			if authConfig != "" {
+				// Clients can also authenticate using tokens; unauthenticated
+				// clients are rejected by the auth config
+				tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			}
 
 			options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
 
@@ -171,6 +185,16 @@ All these options should be set at the same time to enable TLS connection`,
 			log.Warnf("no TLS configuration provided, communication between daemon and CLI will not be encrypted")
 		}
 
+		if authConfig != "" {
+			a, err := auth.Load(authConfig)
+			if err != nil {
+				return fmt.Errorf("loading auth config: %w", err)
+			}
+			a.NormalizeImage = oci.NormalizeImageName
+			service.SetAuth(a)
+			log.Infof("authentication and authorization enabled using %q", authConfig)
+		}
+
 		mgr, err := instancemanager.New(runtime)
 		if err != nil {
 			return fmt.Errorf("initializing manager: %w", err)
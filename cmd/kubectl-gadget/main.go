@@ -32,6 +32,7 @@ import (
 	igconfig "github.com/inspektor-gadget/inspektor-gadget/pkg/config"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	grpcruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/grpc"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/replay"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/experimental"
 )
 
@@ -159,6 +160,7 @@ func main() {
 	rootCmd.AddCommand(common.NewSyncCommand(grpcRuntime))
 	rootCmd.AddCommand(common.NewRunCommand(rootCmd, grpcRuntime, hiddenColumnTags, common.CommandModeRun))
 	rootCmd.AddCommand(common.NewRunCommand(rootCmd, grpcRuntime, hiddenColumnTags, common.CommandModeAttach))
+	rootCmd.AddCommand(common.NewRunCommand(rootCmd, replay.New(), hiddenColumnTags, common.CommandModeReplay))
 	rootCmd.AddCommand(common.NewConfigCmd(grpcRuntime, rootFlags))
 	rootCmd.AddCommand(img.NewImageCmd(grpcRuntime, imgCommands))
 
@@ -0,0 +1,213 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cgrouphandler handles how cgroup programs (cgroup_skb, cgroup/connect4, cgroup/sysctl,
+// cgroup/dev, etc.) are attached to containers and cgroups. The programs are attached to the
+// cgroup v2 of the containers, hence they also apply to all processes in nested cgroups.
+package cgrouphandler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/cgroups"
+)
+
+type attachment struct {
+	path string
+
+	// link is nil until the program is set using AttachProg()
+	link link.Link
+
+	// users keeps track of the users' pid that have called Attach(). This can happen when
+	// several containers share the same cgroup. In this case we want to attach the program
+	// once.
+	users map[uint32]struct{}
+}
+
+type Handler struct {
+	prog       *ebpf.Program
+	attachType ebpf.AttachType
+
+	// key: cgroup path including the mountpoint
+	// value: attachment
+	attachments map[string]*attachment
+
+	// mu protects attachments from concurrent access
+	// AttachContainer and DetachContainer can be called in parallel
+	mu sync.Mutex
+}
+
+// IsCgroupProgram returns whether programs of the given type are attached to cgroups
+func IsCgroupProgram(typ ebpf.ProgramType) bool {
+	switch typ {
+	case ebpf.CGroupSKB, ebpf.CGroupSock, ebpf.CGroupSockAddr, ebpf.CGroupSockopt,
+		ebpf.CGroupSysctl, ebpf.CGroupDevice:
+		return true
+	}
+	return false
+}
+
+func NewHandler(attachType ebpf.AttachType) *Handler {
+	return &Handler{
+		attachType:  attachType,
+		attachments: make(map[string]*attachment),
+	}
+}
+
+func (t *Handler) attach(a *attachment) error {
+	l, err := link.AttachCgroup(link.CgroupOptions{
+		Path:    a.path,
+		Attach:  t.attachType,
+		Program: t.prog,
+	})
+	if err != nil {
+		return fmt.Errorf("attaching program to cgroup %q: %w", a.path, err)
+	}
+	a.link = l
+	return nil
+}
+
+func (t *Handler) closeAttachment(a *attachment) {
+	if a.link != nil {
+		a.link.Close()
+		a.link = nil
+	}
+}
+
+// AttachProg sets the program and attaches it to all cgroups registered so far
+func (t *Handler) AttachProg(prog *ebpf.Program) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prog = prog
+	for _, a := range t.attachments {
+		if a.link != nil {
+			continue
+		}
+		if err := t.attach(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addAttachment registers a cgroup and attaches the program to it if it's already set. It must be
+// called with mu held.
+func (t *Handler) addAttachment(path string, pid uint32) error {
+	if a, ok := t.attachments[path]; ok {
+		a.users[pid] = struct{}{}
+		return nil
+	}
+
+	a := &attachment{
+		path:  path,
+		users: map[uint32]struct{}{pid: {}},
+	}
+	if t.prog != nil {
+		if err := t.attach(a); err != nil {
+			return err
+		}
+	}
+	t.attachments[path] = a
+	return nil
+}
+
+// containerCgroupPath returns the cgroup v2 path of the container including the mountpoint. It's
+// usually set by the cgroup enricher of the container collection, otherwise it's read from
+// /proc.
+func containerCgroupPath(container *containercollection.Container) (string, error) {
+	if container.CgroupPath != "" {
+		return container.CgroupPath, nil
+	}
+
+	pid := container.ContainerPid()
+	_, cgroupPathV2, err := cgroups.GetCgroupPaths(int(pid))
+	if err != nil {
+		return "", fmt.Errorf("getting cgroup paths of pid %d: %w", pid, err)
+	}
+	if cgroupPathV2 == "" {
+		return "", fmt.Errorf("pid %d is not in a cgroup v2", pid)
+	}
+	return cgroups.CgroupPathV2AddMountpoint(cgroupPathV2)
+}
+
+func (t *Handler) AttachContainer(container *containercollection.Container) error {
+	path, err := containerCgroupPath(container)
+	if err != nil {
+		return fmt.Errorf("attaching container %s: %w", container.Runtime.ContainerName, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.addAttachment(path, container.ContainerPid()); err != nil {
+		return fmt.Errorf("attaching container %s: %w", container.Runtime.ContainerName, err)
+	}
+	return nil
+}
+
+func (t *Handler) DetachContainer(container *containercollection.Container) error {
+	pid := container.ContainerPid()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for path, a := range t.attachments {
+		if _, ok := a.users[pid]; ok {
+			delete(a.users, pid)
+			if len(a.users) == 0 {
+				t.closeAttachment(a)
+				delete(t.attachments, path)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("pid %d is not attached", pid)
+}
+
+// AttachCgroup attaches the program to the given cgroup v2 path, including the mountpoint. See
+// AttachContainer() if you want to attach to a container.
+func (t *Handler) AttachCgroup(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.addAttachment(path, 0)
+}
+
+func (t *Handler) DetachCgroup(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if a, ok := t.attachments[path]; ok {
+		t.closeAttachment(a)
+		delete(t.attachments, path)
+		return nil
+	}
+	return fmt.Errorf("cgroup %q is not attached", path)
+}
+
+func (t *Handler) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for path, a := range t.attachments {
+		t.closeAttachment(a)
+		delete(t.attachments, path)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgrouphandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	utilstest "github.com/inspektor-gadget/inspektor-gadget/internal/test"
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/cgroups"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func newCgroupSKBProg(t *testing.T) *ebpf.Program {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:       ebpf.CGroupSKB,
+		AttachType: ebpf.AttachCGroupInetEgress,
+		License:    "GPL",
+		Instructions: asm.Instructions{
+			asm.Mov.Imm(asm.R0, 1), // allow
+			asm.Return(),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { prog.Close() })
+	return prog
+}
+
+// newCgroup creates a temporary cgroup v2 and returns its path including the mountpoint
+func newCgroup(t *testing.T) string {
+	root, err := cgroups.CgroupPathV2AddMountpoint("/")
+	if err != nil {
+		t.Skipf("cgroup v2 not available: %s", err)
+	}
+	path, err := os.MkdirTemp(root, "ig-cgrouphandler-")
+	if err != nil {
+		t.Skipf("creating cgroup: %s", err)
+	}
+	t.Cleanup(func() { os.Remove(path) })
+	return path
+}
+
+// attachedPrograms returns the number of egress programs attached to the cgroup
+func attachedPrograms(t *testing.T, path string) int {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	res, err := link.QueryPrograms(link.QueryOptions{
+		Target: int(f.Fd()),
+		Attach: ebpf.AttachCGroupInetEgress,
+	})
+	require.NoError(t, err)
+	return len(res.Programs)
+}
+
+func newContainer(pid uint32, cgroupPath string) *containercollection.Container {
+	return &containercollection.Container{
+		Runtime: containercollection.RuntimeMetadata{
+			BasicRuntimeMetadata: types.BasicRuntimeMetadata{
+				ContainerPID:  pid,
+				ContainerName: filepath.Base(cgroupPath),
+			},
+		},
+		CgroupPath: cgroupPath,
+	}
+}
+
+func TestIsCgroupProgram(t *testing.T) {
+	assert.True(t, IsCgroupProgram(ebpf.CGroupSKB))
+	assert.True(t, IsCgroupProgram(ebpf.CGroupSockAddr))
+	assert.True(t, IsCgroupProgram(ebpf.CGroupSysctl))
+	assert.True(t, IsCgroupProgram(ebpf.CGroupDevice))
+	assert.False(t, IsCgroupProgram(ebpf.SchedCLS))
+	assert.False(t, IsCgroupProgram(ebpf.Kprobe))
+}
+
+func TestAttachContainer(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	path := newCgroup(t)
+	handler := NewHandler(ebpf.AttachCGroupInetEgress)
+	defer handler.Close()
+
+	// Two containers sharing the same cgroup
+	c1 := newContainer(1001, path)
+	c2 := newContainer(1002, path)
+
+	// Containers can be attached before the program is set
+	require.NoError(t, handler.AttachContainer(c1))
+	require.NoError(t, handler.AttachContainer(c2))
+	assert.Equal(t, 0, attachedPrograms(t, path))
+
+	require.NoError(t, handler.AttachProg(newCgroupSKBProg(t)))
+	assert.Equal(t, 1, attachedPrograms(t, path))
+
+	// The program is kept until the last container is detached
+	require.NoError(t, handler.DetachContainer(c1))
+	assert.Equal(t, 1, attachedPrograms(t, path))
+	require.NoError(t, handler.DetachContainer(c2))
+	assert.Equal(t, 0, attachedPrograms(t, path))
+
+	require.Error(t, handler.DetachContainer(c1))
+}
+
+func TestAttachCgroup(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	path := newCgroup(t)
+	handler := NewHandler(ebpf.AttachCGroupInetEgress)
+
+	require.NoError(t, handler.AttachProg(newCgroupSKBProg(t)))
+	require.NoError(t, handler.AttachCgroup(path))
+	assert.Equal(t, 1, attachedPrograms(t, path))
+
+	require.NoError(t, handler.DetachCgroup(path))
+	assert.Equal(t, 0, attachedPrograms(t, path))
+	require.Error(t, handler.DetachCgroup(path))
+
+	// Closing the handler detaches the program
+	require.NoError(t, handler.AttachCgroup(path))
+	assert.Equal(t, 1, attachedPrograms(t, path))
+	handler.Close()
+	assert.Equal(t, 0, attachedPrograms(t, path))
+
+	require.Error(t, handler.AttachCgroup(filepath.Join(path, "nonexistent")))
+}
@@ -22,11 +22,13 @@
 package containercollection
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 
 	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
 )
@@ -86,6 +88,9 @@ type ContainerCollection struct {
 	// kubeconfigPath is the path to the kubeconfig file, or empty for in-cluster config.
 	// Some options like WithPodInformer will use it.
 	kubeconfigPath string
+
+	// ownerReferences caches the owner references of pods
+	ownerReferences *ownerReferenceCache
 }
 
 // ContainerCollectionOption are options to pass to
@@ -102,6 +107,10 @@ func (cc *ContainerCollection) Initialize(options ...ContainerCollectionOption)
 		panic("Initialize already called")
 	}
 
+	cc.ownerReferences = newOwnerReferenceCache(func() (dynamic.Interface, error) {
+		return newDynamicClient(cc.kubeconfigPath)
+	})
+
 	// Call functional options. This might fetch initial containers.
 	for _, o := range options {
 		err := o(cc)
@@ -160,6 +169,13 @@ func (cc *ContainerCollection) RemoveContainer(id string) {
 	// present.
 	cc.containers.Delete(id)
 
+	// Without a pod informer, nothing else would drop the cached owner
+	// reference once the pod is gone
+	if cc.ownerReferences != nil && container.K8s.PodName != "" &&
+		len(cc.LookupPIDByPod(container.K8s.Namespace, container.K8s.PodName)) == 0 {
+		cc.ownerReferences.podDeleted(ownerReferenceKey(container.K8s.Namespace, container.K8s.PodName))
+	}
+
 	// Make this operation atomic, as RemoveContainer() could be called concurrently, which could result in
 	// dirty map contents
 	cc.mu.Lock()
@@ -349,7 +365,7 @@ func (cc *ContainerCollection) LookupOwnerReferenceByMntns(mntns uint64) *metav1
 	cc.containers.Range(func(key, value interface{}) bool {
 		c := value.(*Container)
 		if mntns == c.Mntns {
-			ownerRef, err = c.GetOwnerReference(cc.kubeconfigPath)
+			ownerRef, err = cc.getOwnerReference(c)
 			if err != nil {
 				log.Warnf("Failed to get owner reference of %s/%s/%s: %s",
 					c.K8s.Namespace, c.K8s.PodName, c.K8s.ContainerName, err)
@@ -362,6 +378,18 @@ func (cc *ContainerCollection) LookupOwnerReferenceByMntns(mntns uint64) *metav1
 	return ownerRef
 }
 
+// getOwnerReference returns the owner reference of the container, using the
+// cache of the collection if it has been initialized
+func (cc *ContainerCollection) getOwnerReference(c *Container) (*metav1.OwnerReference, error) {
+	if cc.ownerReferences == nil {
+		return c.GetOwnerReference(cc.kubeconfigPath)
+	}
+	if err := cc.ownerReferences.enrich(c); err != nil {
+		return nil, fmt.Errorf("enriching owner reference: %w", err)
+	}
+	return c.K8s.ownerReference, nil
+}
+
 // GetContainersBySelector returns a slice of containers that match
 // the selector or an empty slice if there are not matches
 func (cc *ContainerCollection) GetContainersBySelector(
@@ -21,6 +21,7 @@ import (
 
 	"github.com/moby/moby/pkg/stringid"
 	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
 
@@ -65,6 +66,10 @@ type Container struct {
 	// It is used to avoid re-serializing the map[string]string for every event.
 	podLabelsAsString string
 
+	// podAnnotations are the annotations of the pod. They are not part of
+	// K8sMetadata to avoid sending them around with every container.
+	podAnnotations map[string]string
+
 	// We keep an open file descriptor of the containers mount and net namespaces to be sure the
 	// kernel doesn't reuse the inode id before we get rid of this container. This logic avoids
 	// a race condition when the ns inode id is reused by a new container and we erroneously
@@ -245,6 +250,21 @@ func (c *Container) K8sPodLabelsAsString() string {
 	return c.podLabelsAsString
 }
 
+func (c *Container) K8sPodAnnotations() map[string]string {
+	return c.podAnnotations
+}
+
+func (c *Container) SetPodAnnotations(podAnnotations map[string]string) {
+	c.podAnnotations = make(map[string]string, len(podAnnotations))
+	for k, v := range podAnnotations {
+		// This one can be huge and isn't useful to identify workloads
+		if k == corev1.LastAppliedConfigAnnotation {
+			continue
+		}
+		c.podAnnotations[k] = v
+	}
+}
+
 func (c *Container) SetPodLabels(podLabels map[string]string) {
 	if len(podLabels) == 0 {
 		// IsEnriched relies on c.K8s.PodLabels == nil to know if it
@@ -213,11 +213,13 @@ func (k *K8sClient) GetRunningContainers(pod *v1.Pod) []Container {
 					PodName:       pod.GetName(),
 					ContainerName: s.Name,
 				},
+				PodUID: string(pod.GetUID()),
 			},
 		}
 
 		// PodLabels must be set through the SetPodLabels method
 		containerDef.SetPodLabels(pod.Labels)
+		containerDef.SetPodAnnotations(pod.Annotations)
 		containers = append(containers, containerDef)
 	}
 
@@ -31,6 +31,7 @@ import (
 	"golang.org/x/sys/unix"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -306,6 +307,7 @@ func withPodInformer(nodeName string, fallbackMode bool) ContainerCollectionOpti
 					if !ok {
 						return
 					}
+					cc.ownerReferences.podDeleted(key)
 					if containerIDs, ok := containerIDsByKey[key]; ok {
 						for containerID := range containerIDs {
 							cc.RemoveContainer(containerID)
@@ -316,6 +318,7 @@ func withPodInformer(nodeName string, fallbackMode bool) ContainerCollectionOpti
 					if !ok {
 						return
 					}
+					cc.ownerReferences.podUpdated(pod)
 					key, _ := cache.MetaNamespaceKeyFunc(pod)
 					containerIDs, ok := containerIDsByKey[key]
 					if !ok {
@@ -465,6 +468,17 @@ func getExpectedOwnerReference(ownerReferences []metav1.OwnerReference) *metav1.
 func getOwnerReferences(dynamicClient dynamic.Interface,
 	resNamespace, resKind, resGroupVersion, resName string,
 ) ([]metav1.OwnerReference, error) {
+	res, err := getResource(dynamicClient, resNamespace, resKind, resGroupVersion, resName)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.GetOwnerReferences(), nil
+}
+
+func getResource(dynamicClient dynamic.Interface,
+	resNamespace, resKind, resGroupVersion, resName string,
+) (*unstructured.Unstructured, error) {
 	gv, err := schema.ParseGroupVersion(resGroupVersion)
 	if err != nil {
 		return nil, fmt.Errorf("parsing %s/%s groupVersion %s: %w",
@@ -486,7 +500,7 @@ func getOwnerReferences(dynamicClient dynamic.Interface,
 			resKind, resGroupVersion, resNamespace, resName, err)
 	}
 
-	return res.GetOwnerReferences(), nil
+	return res, nil
 }
 
 func getPodByCgroups(clientset *kubernetes.Clientset, nodeName string, container *Container) (*corev1.Pod, error) {
@@ -586,6 +600,7 @@ func WithKubernetesEnrichment(nodeName string) ContainerCollectionOption {
 				container.K8s.PodName = pod.Name
 				container.K8s.PodUID = string(pod.UID)
 				container.SetPodLabels(pod.Labels)
+				container.SetPodAnnotations(pod.Annotations)
 
 				// drop pause containers
 				if container.K8s.PodName != "" && container.K8s.ContainerName == "" {
@@ -594,7 +609,7 @@ func WithKubernetesEnrichment(nodeName string) ContainerCollectionOption {
 			}
 
 			if container.K8s.ownerReference == nil {
-				_, err = container.GetOwnerReference(cc.kubeconfigPath)
+				_, err = cc.getOwnerReference(container)
 				if err != nil {
 					log.Errorf("kubernetes enricher: failed to get owner reference for container %s: %s", container.Runtime.ContainerID, err)
 					// Don't drop the container. We just have problems getting the owner reference, but still want to trace the container.
@@ -0,0 +1,199 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/k8sutil"
+)
+
+type ownerReferenceEntry struct {
+	podUID string
+
+	// podOwners are the UIDs of the owner references of the pod itself; they
+	// are used to detect when a pod gets adopted or orphaned
+	podOwners []k8stypes.UID
+
+	// ownerReference is the highest owner reference of the pod, nil if the
+	// pod isn't managed by any of the expected controllers
+	ownerReference *metav1.OwnerReference
+
+	podAnnotations map[string]string
+}
+
+// ownerReferenceCache caches the owner references of pods, so that
+// containers of the same pod don't walk the owner chain (e.g. Pod ->
+// ReplicaSet -> Deployment) on the API server again. As the pod has to be
+// fetched anyway, its annotations are kept as well. Entries are invalidated
+// by the pod informer when a pod is deleted or its owner references change.
+type ownerReferenceCache struct {
+	mu sync.Mutex
+
+	// Keys:   "namespace/podname"
+	entries map[string]*ownerReferenceEntry
+
+	newClient func() (dynamic.Interface, error)
+	client    dynamic.Interface
+}
+
+func newOwnerReferenceCache(newClient func() (dynamic.Interface, error)) *ownerReferenceCache {
+	return &ownerReferenceCache{
+		entries:   make(map[string]*ownerReferenceEntry),
+		newClient: newClient,
+	}
+}
+
+func newDynamicClient(kubeconfigPath string) (dynamic.Interface, error) {
+	kubeconfig, err := k8sutil.NewKubeConfig(kubeconfigPath, "container-collection/ownerReferenceCache")
+	if err != nil {
+		return nil, fmt.Errorf("getting Kubernetes config: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("getting dynamic Kubernetes client: %w", err)
+	}
+	return dynamicClient, nil
+}
+
+func ownerReferenceKey(namespace, podName string) string {
+	return namespace + "/" + podName
+}
+
+func ownerUIDs(ownerReferences []metav1.OwnerReference) []k8stypes.UID {
+	uids := make([]k8stypes.UID, 0, len(ownerReferences))
+	for _, ref := range ownerReferences {
+		uids = append(uids, ref.UID)
+	}
+	return uids
+}
+
+func (c *ownerReferenceCache) getClient() (dynamic.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		client, err := c.newClient()
+		if err != nil {
+			return nil, err
+		}
+		c.client = client
+	}
+	return c.client, nil
+}
+
+// enrich sets the owner reference and, if they are unknown, the pod
+// annotations of the container, either from the cache or by walking the owner
+// chain of its pod.
+func (c *ownerReferenceCache) enrich(container *Container) error {
+	if container.K8s.ownerReference != nil {
+		return nil
+	}
+
+	key := ownerReferenceKey(container.K8s.Namespace, container.K8s.PodName)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok || (entry.podUID != "" && container.K8s.PodUID != "" && entry.podUID != container.K8s.PodUID) {
+		var err error
+		entry, err = c.fetch(container)
+		if err != nil {
+			return fmt.Errorf("getting %s owner reference: %w", key, err)
+		}
+
+		c.mu.Lock()
+		c.entries[key] = entry
+		c.mu.Unlock()
+	}
+
+	container.K8s.ownerReference = entry.ownerReference
+	if container.podAnnotations == nil {
+		container.SetPodAnnotations(entry.podAnnotations)
+	}
+	return nil
+}
+
+func (c *ownerReferenceCache) fetch(container *Container) (*ownerReferenceEntry, error) {
+	client, err := c.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := getResource(client, container.K8s.Namespace, "pods", "v1", container.K8s.PodName)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &ownerReferenceEntry{
+		podUID:         string(pod.GetUID()),
+		podOwners:      ownerUIDs(pod.GetOwnerReferences()),
+		podAnnotations: pod.GetAnnotations(),
+	}
+
+	// ownerReferenceEnrichment would fetch the pod again if it doesn't have
+	// any owner references
+	if len(pod.GetOwnerReferences()) > 0 {
+		if err := ownerReferenceEnrichment(client, container, pod.GetOwnerReferences()); err != nil {
+			return nil, err
+		}
+		entry.ownerReference = container.K8s.ownerReference
+	}
+
+	return entry, nil
+}
+
+// podUpdated drops the cache entry of the pod if it has been replaced or its
+// owner references changed.
+func (c *ownerReferenceCache) podUpdated(pod *corev1.Pod) {
+	key, err := cache.MetaNamespaceKeyFunc(pod)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+
+	if entry.podUID != "" && entry.podUID != string(pod.UID) {
+		delete(c.entries, key)
+		return
+	}
+
+	if !slices.Equal(entry.podOwners, ownerUIDs(pod.OwnerReferences)) {
+		delete(c.entries, key)
+	}
+}
+
+// podDeleted drops the cache entry of the pod identified by key
+// ("namespace/podname").
+func (c *ownerReferenceCache) podDeleted(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func newObject(apiVersion, kind, name string, uid k8stypes.UID, owner *metav1.OwnerReference) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetUID(uid)
+	if owner != nil {
+		obj.SetOwnerReferences([]metav1.OwnerReference{*owner})
+	}
+	return obj
+}
+
+func controllerRef(apiVersion, kind, name string, uid k8stypes.UID) *metav1.OwnerReference {
+	controller := true
+	return &metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		UID:        uid,
+		Controller: &controller,
+	}
+}
+
+func newPodContainer(id, podName string, podUID k8stypes.UID) *Container {
+	return &Container{
+		Runtime: RuntimeMetadata{
+			BasicRuntimeMetadata: types.BasicRuntimeMetadata{ContainerID: id},
+		},
+		K8s: K8sMetadata{
+			BasicK8sMetadata: types.BasicK8sMetadata{
+				Namespace: "default",
+				PodName:   podName,
+			},
+			PodUID: string(podUID),
+		},
+	}
+}
+
+func TestOwnerReferenceCache(t *testing.T) {
+	deploymentRef := controllerRef("apps/v1", "Deployment", "web", "deploy-uid")
+	replicaSetRef := controllerRef("apps/v1", "ReplicaSet", "web-5d4f8", "rs-uid")
+
+	pod := newObject("v1", "Pod", "web-5d4f8-abcde", "pod-uid", replicaSetRef)
+	pod.SetAnnotations(map[string]string{
+		"team":                             "payments",
+		corev1.LastAppliedConfigAnnotation: "{}",
+	})
+	bare := newObject("v1", "Pod", "bare", "bare-uid", nil)
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		pod,
+		bare,
+		newObject("apps/v1", "ReplicaSet", "web-5d4f8", "rs-uid", deploymentRef),
+		newObject("apps/v1", "Deployment", "web", "deploy-uid", nil),
+	)
+	c := newOwnerReferenceCache(func() (dynamic.Interface, error) {
+		return client, nil
+	})
+
+	// The owner chain is walked up to the Deployment
+	web := newPodContainer("web", "web-5d4f8-abcde", "pod-uid")
+	require.NoError(t, c.enrich(web))
+	require.Equal(t, &types.K8sOwnerReference{Kind: "Deployment", Name: "web"}, web.K8sOwnerReference())
+	require.Equal(t, map[string]string{"team": "payments"}, web.K8sPodAnnotations())
+	require.Len(t, client.Actions(), 3)
+
+	// Other containers of the same pod are served from the cache
+	sidecar := newPodContainer("sidecar", "web-5d4f8-abcde", "pod-uid")
+	require.NoError(t, c.enrich(sidecar))
+	require.Equal(t, &types.K8sOwnerReference{Kind: "Deployment", Name: "web"}, sidecar.K8sOwnerReference())
+	require.Equal(t, map[string]string{"team": "payments"}, sidecar.K8sPodAnnotations())
+	require.Len(t, client.Actions(), 3)
+
+	// Pods without owner are cached as well
+	require.NoError(t, c.enrich(newPodContainer("bare", "bare", "bare-uid")))
+	require.NoError(t, c.enrich(newPodContainer("bare2", "bare", "bare-uid")))
+	require.Len(t, client.Actions(), 4)
+
+	// Updates not touching the owner references keep the entry
+	updated := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "web-5d4f8-abcde",
+			UID:             "pod-uid",
+			OwnerReferences: []metav1.OwnerReference{*replicaSetRef},
+		},
+	}
+	c.podUpdated(updated)
+	require.Contains(t, c.entries, "default/web-5d4f8-abcde")
+
+	// Orphaned pods are looked up again
+	updated.OwnerReferences = nil
+	c.podUpdated(updated)
+	require.NotContains(t, c.entries, "default/web-5d4f8-abcde")
+
+	// A new pod with the same name isn't served from the cache
+	require.NoError(t, c.enrich(newPodContainer("bare3", "bare", "other-uid")))
+	require.Len(t, client.Actions(), 5)
+
+	c.podDeleted("default/bare")
+	require.NotContains(t, c.entries, "default/bare")
+}
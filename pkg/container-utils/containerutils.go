@@ -181,3 +181,27 @@ func GetIfacePeers(pid int) ([]*net.Interface, error) {
 
 	return ifacesHost, err
 }
+
+// GetIPs returns the IP addresses of the network namespace pid is running in,
+// excluding loopback and link-local addresses.
+func GetIPs(pid int) ([]net.IP, error) {
+	var ips []net.IP
+
+	err := nsenter.NetnsEnter(pid, func() error {
+		addrs, err := netlink.AddrList(nil, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("getting addresses: %w", err)
+		}
+
+		for _, addr := range addrs {
+			if addr.IP.IsLoopback() || addr.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			ips = append(ips, addr.IP)
+		}
+
+		return nil
+	})
+
+	return ips, err
+}
@@ -98,6 +98,10 @@ type FieldAccessor interface {
 	// Type returns the underlying type of the field
 	Type() api.Kind
 
+	// ArrayDims returns the dimensions of the field if it is a multidimensional array or an array of structs;
+	// the elements of an array of structs are its SubFields
+	ArrayDims() []uint32
+
 	// Flags returns the flags of the field
 	Flags() uint32
 
@@ -208,6 +212,10 @@ func (a *fieldAccessor) Type() api.Kind {
 	return a.f.Kind
 }
 
+func (a *fieldAccessor) ArrayDims() []uint32 {
+	return a.f.ArrayDims
+}
+
 func (a *fieldAccessor) Get(d Data) []byte {
 	if FieldFlagEmpty.In(a.f.Flags) {
 		return nil
@@ -440,7 +448,7 @@ func (a *fieldAccessor) Uint8(data Data) (uint8, error) {
 	if len(val) != 1 {
 		return 0, invalidFieldLengthErr(len(val), 1)
 	}
-	return val[0], nil
+	return uint8(a.bits(uint64(val[0]))), nil
 }
 
 func (a *fieldAccessor) Uint16(data Data) (uint16, error) {
@@ -448,7 +456,7 @@ func (a *fieldAccessor) Uint16(data Data) (uint16, error) {
 	if len(val) != 2 {
 		return 0, invalidFieldLengthErr(len(val), 2)
 	}
-	return a.ds.byteOrder.Uint16(val), nil
+	return uint16(a.bits(uint64(a.ds.byteOrder.Uint16(val)))), nil
 }
 
 func (a *fieldAccessor) Uint32(data Data) (uint32, error) {
@@ -456,7 +464,7 @@ func (a *fieldAccessor) Uint32(data Data) (uint32, error) {
 	if len(val) != 4 {
 		return 0, invalidFieldLengthErr(len(val), 4)
 	}
-	return a.ds.byteOrder.Uint32(val), nil
+	return uint32(a.bits(uint64(a.ds.byteOrder.Uint32(val)))), nil
 }
 
 func (a *fieldAccessor) Uint64(data Data) (uint64, error) {
@@ -464,7 +472,7 @@ func (a *fieldAccessor) Uint64(data Data) (uint64, error) {
 	if len(val) != 8 {
 		return 0, invalidFieldLengthErr(len(val), 8)
 	}
-	return a.ds.byteOrder.Uint64(val), nil
+	return a.bits(a.ds.byteOrder.Uint64(val)), nil
 }
 
 func (a *fieldAccessor) Int8(data Data) (int8, error) {
@@ -472,7 +480,7 @@ func (a *fieldAccessor) Int8(data Data) (int8, error) {
 	if len(val) != 1 {
 		return 0, invalidFieldLengthErr(len(val), 1)
 	}
-	return int8(val[0]), nil
+	return int8(a.signedBits(int64(int8(val[0])))), nil
 }
 
 func (a *fieldAccessor) Int16(data Data) (int16, error) {
@@ -480,7 +488,7 @@ func (a *fieldAccessor) Int16(data Data) (int16, error) {
 	if len(val) != 2 {
 		return 0, invalidFieldLengthErr(len(val), 2)
 	}
-	return int16(a.ds.byteOrder.Uint16(val)), nil
+	return int16(a.signedBits(int64(int16(a.ds.byteOrder.Uint16(val))))), nil
 }
 
 func (a *fieldAccessor) Int32(data Data) (int32, error) {
@@ -488,7 +496,7 @@ func (a *fieldAccessor) Int32(data Data) (int32, error) {
 	if len(val) != 4 {
 		return 0, invalidFieldLengthErr(len(val), 4)
 	}
-	return int32(a.ds.byteOrder.Uint32(val)), nil
+	return int32(a.signedBits(int64(int32(a.ds.byteOrder.Uint32(val))))), nil
 }
 
 func (a *fieldAccessor) Int64(data Data) (int64, error) {
@@ -496,7 +504,7 @@ func (a *fieldAccessor) Int64(data Data) (int64, error) {
 	if len(val) != 8 {
 		return 0, invalidFieldLengthErr(len(val), 8)
 	}
-	return int64(a.ds.byteOrder.Uint64(val)), nil
+	return a.signedBits(int64(a.ds.byteOrder.Uint64(val))), nil
 }
 
 func (a *fieldAccessor) Float32(data Data) (float32, error) {
@@ -597,7 +605,7 @@ func (a *fieldAccessor) Bool(data Data) (bool, error) {
 	if len(val) != 1 {
 		return false, invalidFieldLengthErr(len(val), 1)
 	}
-	return val[0] == 1, nil
+	return a.bits(uint64(val[0])) == 1, nil
 }
 
 func (a *fieldAccessor) PutUint8(data Data, val uint8) error {
@@ -605,7 +613,7 @@ func (a *fieldAccessor) PutUint8(data Data, val uint8) error {
 	if len(b) != 1 {
 		return invalidFieldLengthErr(len(b), 1)
 	}
-	b[0] = val
+	b[0] = uint8(a.mergeBits(uint64(b[0]), uint64(val)))
 	return nil
 }
 
@@ -614,7 +622,7 @@ func (a *fieldAccessor) PutUint16(data Data, val uint16) error {
 	if len(b) != 2 {
 		return invalidFieldLengthErr(len(b), 2)
 	}
-	a.ds.byteOrder.PutUint16(b, val)
+	a.ds.byteOrder.PutUint16(b, uint16(a.mergeBits(uint64(a.ds.byteOrder.Uint16(b)), uint64(val))))
 	return nil
 }
 
@@ -623,7 +631,7 @@ func (a *fieldAccessor) PutUint32(data Data, val uint32) error {
 	if len(b) != 4 {
 		return invalidFieldLengthErr(len(b), 4)
 	}
-	a.ds.byteOrder.PutUint32(b, val)
+	a.ds.byteOrder.PutUint32(b, uint32(a.mergeBits(uint64(a.ds.byteOrder.Uint32(b)), uint64(val))))
 	return nil
 }
 
@@ -632,7 +640,7 @@ func (a *fieldAccessor) PutUint64(data Data, val uint64) error {
 	if len(b) != 8 {
 		return invalidFieldLengthErr(len(b), 8)
 	}
-	a.ds.byteOrder.PutUint64(a.Get(data), val)
+	a.ds.byteOrder.PutUint64(b, a.mergeBits(a.ds.byteOrder.Uint64(b), val))
 	return nil
 }
 
@@ -641,7 +649,7 @@ func (a *fieldAccessor) PutInt8(data Data, val int8) error {
 	if len(b) != 1 {
 		return invalidFieldLengthErr(len(b), 1)
 	}
-	b[0] = uint8(val)
+	b[0] = uint8(a.mergeBits(uint64(b[0]), uint64(val)))
 	return nil
 }
 
@@ -650,7 +658,7 @@ func (a *fieldAccessor) PutInt16(data Data, val int16) error {
 	if len(b) != 2 {
 		return invalidFieldLengthErr(len(b), 2)
 	}
-	a.ds.byteOrder.PutUint16(b, uint16(val))
+	a.ds.byteOrder.PutUint16(b, uint16(a.mergeBits(uint64(a.ds.byteOrder.Uint16(b)), uint64(val))))
 	return nil
 }
 
@@ -659,7 +667,7 @@ func (a *fieldAccessor) PutInt32(data Data, val int32) error {
 	if len(b) != 4 {
 		return invalidFieldLengthErr(len(b), 4)
 	}
-	a.ds.byteOrder.PutUint32(b, uint32(val))
+	a.ds.byteOrder.PutUint32(b, uint32(a.mergeBits(uint64(a.ds.byteOrder.Uint32(b)), uint64(val))))
 	return nil
 }
 
@@ -668,7 +676,7 @@ func (a *fieldAccessor) PutInt64(data Data, val int64) error {
 	if len(b) != 8 {
 		return invalidFieldLengthErr(len(b), 8)
 	}
-	a.ds.byteOrder.PutUint64(b, uint64(val))
+	a.ds.byteOrder.PutUint64(b, a.mergeBits(a.ds.byteOrder.Uint64(b), uint64(val)))
 	return nil
 }
 
@@ -694,10 +702,47 @@ func (a *fieldAccessor) PutBool(data Data, val bool) error {
 		return invalidFieldLengthErr(len(b), 1)
 	}
 
+	var v uint64
 	if val {
-		b[0] = 1
-	} else {
-		b[0] = 0
+		v = 1
 	}
+	b[0] = uint8(a.mergeBits(uint64(b[0]), v))
 	return nil
 }
+
+// bitShift returns the number of bits the value of a bitfield is shifted inside its integer
+func (a *fieldAccessor) bitShift() uint32 {
+	// On big endian, bit offsets start at the most significant bit
+	if a.ds.byteOrder.Uint16([]byte{0, 1}) == 1 {
+		return a.f.Size*8 - a.f.BitOffset - a.f.BitSize
+	}
+	return a.f.BitOffset
+}
+
+// bits extracts the value of a bitfield from the integer v holding it; v is returned unchanged if
+// the field is not a bitfield
+func (a *fieldAccessor) bits(v uint64) uint64 {
+	if a.f.BitSize == 0 {
+		return v
+	}
+	return (v >> a.bitShift()) & (uint64(1)<<a.f.BitSize - 1)
+}
+
+// signedBits is like bits, but sign-extends the value of the bitfield
+func (a *fieldAccessor) signedBits(v int64) int64 {
+	if a.f.BitSize == 0 {
+		return v
+	}
+	return v << (64 - a.bitShift() - a.f.BitSize) >> (64 - a.f.BitSize)
+}
+
+// mergeBits returns the integer old holding a bitfield with its value replaced by val; val is
+// returned unchanged if the field is not a bitfield
+func (a *fieldAccessor) mergeBits(old uint64, val uint64) uint64 {
+	if a.f.BitSize == 0 {
+		return val
+	}
+	shift := a.bitShift()
+	mask := (uint64(1)<<a.f.BitSize - 1) << shift
+	return old&^mask | (val<<shift)&mask
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
@@ -188,6 +189,38 @@ func (ds *dataSource) Columns() (*columns.Columns[DataTuple], error) {
 			continue
 		}
 
+		if toString := arrayToStringFunc(&fieldAccessor{ds: ds, f: f}); toString != nil {
+			err := cols.AddColumn(*df.Attributes, func(d *DataTuple) any {
+				if d.data == nil {
+					return ""
+				}
+				return toString(d.data)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("creating columns: %w", err)
+			}
+			continue
+		}
+
+		// Bitfields need to be read using the accessor to get shifted and masked
+		if f.BitSize > 0 && f.ReflectType() != nil {
+			acc := &fieldAccessor{
+				ds: ds,
+				f:  f,
+			}
+			zero := reflect.Zero(f.ReflectType()).Interface()
+			err := cols.AddColumn(*df.Attributes, func(d *DataTuple) any {
+				if d.data == nil {
+					return zero
+				}
+				return bitfieldValue(acc, d.data)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("creating columns: %w", err)
+			}
+			continue
+		}
+
 		if f.ReflectType() == nil {
 			df.Type = reflect.TypeOf([]byte{})
 
@@ -236,6 +269,89 @@ func (ds *dataSource) Columns() (*columns.Columns[DataTuple], error) {
 	return cols, nil
 }
 
+// bitfieldValue returns the value of a bitfield using the type of its kind
+func bitfieldValue(acc *fieldAccessor, data Data) any {
+	var v any
+	switch acc.f.Kind {
+	case api.Kind_Int8:
+		v, _ = acc.Int8(data)
+	case api.Kind_Int16:
+		v, _ = acc.Int16(data)
+	case api.Kind_Int32:
+		v, _ = acc.Int32(data)
+	case api.Kind_Int64:
+		v, _ = acc.Int64(data)
+	case api.Kind_Uint8:
+		v, _ = acc.Uint8(data)
+	case api.Kind_Uint16:
+		v, _ = acc.Uint16(data)
+	case api.Kind_Uint32:
+		v, _ = acc.Uint32(data)
+	case api.Kind_Uint64:
+		v, _ = acc.Uint64(data)
+	case api.Kind_Bool:
+		v, _ = acc.Bool(data)
+	}
+	return v
+}
+
+func toStrings[T any](vals []T, err error) []string {
+	res := make([]string, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, fmt.Sprint(v))
+	}
+	return res
+}
+
+// nestArray formats the elements of a (multidimensional) array like "[[1 2] [3 4]]"
+func nestArray(vals []string, dims []uint32) string {
+	if len(dims) < 2 {
+		return "[" + strings.Join(vals, " ") + "]"
+	}
+	n := len(vals) / int(dims[0])
+	if n == 0 {
+		return "[]"
+	}
+	parts := make([]string, 0, dims[0])
+	for i := 0; i+n <= len(vals); i += n {
+		parts = append(parts, nestArray(vals[i:i+n], dims[1:]))
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// arrayToStringFunc returns a function that formats the elements of an array of numbers or nil if
+// the field is not such an array
+func arrayToStringFunc(acc *fieldAccessor) func(Data) string {
+	var toString func(Data) []string
+	switch acc.f.Kind {
+	default:
+		return nil
+	case api.ArrayOf(api.Kind_Int8):
+		toString = func(d Data) []string { return toStrings(acc.Int8Array(d)) }
+	case api.ArrayOf(api.Kind_Int16):
+		toString = func(d Data) []string { return toStrings(acc.Int16Array(d)) }
+	case api.ArrayOf(api.Kind_Int32):
+		toString = func(d Data) []string { return toStrings(acc.Int32Array(d)) }
+	case api.ArrayOf(api.Kind_Int64):
+		toString = func(d Data) []string { return toStrings(acc.Int64Array(d)) }
+	case api.ArrayOf(api.Kind_Uint8):
+		toString = func(d Data) []string { return toStrings(acc.Uint8Array(d)) }
+	case api.ArrayOf(api.Kind_Uint16):
+		toString = func(d Data) []string { return toStrings(acc.Uint16Array(d)) }
+	case api.ArrayOf(api.Kind_Uint32):
+		toString = func(d Data) []string { return toStrings(acc.Uint32Array(d)) }
+	case api.ArrayOf(api.Kind_Uint64):
+		toString = func(d Data) []string { return toStrings(acc.Uint64Array(d)) }
+	case api.ArrayOf(api.Kind_Float32):
+		toString = func(d Data) []string { return toStrings(acc.Float32Array(d)) }
+	case api.ArrayOf(api.Kind_Float64):
+		toString = func(d Data) []string { return toStrings(acc.Float64Array(d)) }
+	}
+	return func(d Data) string {
+		return nestArray(toString(d), acc.f.ArrayDims)
+	}
+}
+
 var defaultFieldAnnotations = map[string]string{
 	metadatav1.ColumnsWidthAnnotation:     "16",
 	metadatav1.ColumnsEllipsisAnnotation:  string(metadatav1.EllipsisEnd),
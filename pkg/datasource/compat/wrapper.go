@@ -16,6 +16,7 @@ package compat
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/environment"
@@ -54,6 +55,10 @@ type EventWrapperBase struct {
 	podLabelsAccessor            datasource.FieldAccessor
 	ownerKindAccessor            datasource.FieldAccessor
 	ownerNameAccessor            datasource.FieldAccessor
+	podAnnotationsAccessor       datasource.FieldAccessor
+
+	k8s            datasource.FieldAccessor
+	podAnnotations []string
 }
 
 type (
@@ -143,6 +148,7 @@ func WrapAccessors(source datasource.DataSource, mntnsidAccessor datasource.Fiel
 	if err != nil {
 		return nil, err
 	}
+	ev.k8s = k8s
 
 	ev.nodeAccessor, err = k8s.AddSubField("node",
 		api.Kind_String,
@@ -330,6 +336,39 @@ func WrapAccessors(source datasource.DataSource, mntnsidAccessor datasource.Fiel
 	return ev, nil
 }
 
+// AddPodAnnotations adds the k8s.podAnnotations field, holding the given pod
+// annotations as comma-separated key=value pairs
+func (ev *EventWrapperBase) AddPodAnnotations(annotations []string) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	acc, err := ev.k8s.AddSubField(
+		"podAnnotations",
+		api.Kind_String,
+		datasource.WithTags("kubernetes"),
+		datasource.WithFlags(datasource.FieldFlagHidden),
+		datasource.WithOrder(-25),
+	)
+	if err != nil {
+		return err
+	}
+	ev.podAnnotationsAccessor = acc
+	ev.podAnnotations = annotations
+	return nil
+}
+
+func (ev *EventWrapperBase) podAnnotationsString(container types.Container) string {
+	annotations := container.K8sPodAnnotations()
+	kvPairs := make([]string, 0, len(ev.podAnnotations))
+	for _, k := range ev.podAnnotations {
+		if v, ok := annotations[k]; ok {
+			kvPairs = append(kvPairs, k+"="+v)
+		}
+	}
+	return strings.Join(kvPairs, ",")
+}
+
 type EventWrapper struct {
 	*EventWrapperBase
 	Data datasource.Data
@@ -387,6 +426,9 @@ func (ev *EventWrapper) SetPodMetadata(container types.Container) {
 		if ev.podLabelsAccessor.IsRequested() {
 			ev.podLabelsAccessor.PutString(ev.Data, container.K8sPodLabelsAsString())
 		}
+		if ev.podAnnotationsAccessor != nil && ev.podAnnotationsAccessor.IsRequested() {
+			ev.podAnnotationsAccessor.PutString(ev.Data, ev.podAnnotationsString(container))
+		}
 	}
 	rt := container.RuntimeMetadata()
 	if rt != nil {
@@ -25,6 +25,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -42,6 +43,17 @@ func (d *dataElement) payload() [][]byte {
 	return d.Payload
 }
 
+// CopyData returns a deep copy of data. Data sources can reuse the memory of packets after they
+// have been emitted, so subscribers have to copy the data they keep after their callback returns.
+func CopyData(data Data) Data {
+	payload := data.payload()
+	d := &dataElement{Payload: make([][]byte, len(payload))}
+	for i, p := range payload {
+		d.Payload[i] = slices.Clone(p)
+	}
+	return d
+}
+
 type data api.GadgetData
 
 func (d *data) private() {}
@@ -170,10 +182,15 @@ type dataSource struct {
 
 	requestedFields map[string]bool
 
-	subscriptions []*subscription
+	// subscriptions is replaced as a whole when subscribing, so subscribers can
+	// be added while data is being emitted
+	subscriptions atomic.Pointer[[]*subscription]
 
 	referenced bool
 
+	// lostData counts data that has been lost or dropped before being emitted
+	lostData atomic.Uint64
+
 	byteOrder binary.ByteOrder
 	lock      sync.RWMutex
 
@@ -401,6 +418,16 @@ func (ds *dataSource) AddStaticFields(size uint32, fields []StaticField) (FieldA
 				nf.Annotations[k] = v
 			}
 		}
+		if s, ok := f.(BitfieldField); ok && s.FieldBitSize() > 0 {
+			nf.BitOffset = s.FieldBitOffset()
+			nf.BitSize = s.FieldBitSize()
+			if nf.Size > 8 || nf.BitOffset+nf.BitSize > nf.Size*8 {
+				return nil, fmt.Errorf("bitfield %q exceeds size of field (bit offset %d, bit size %d, size %d)", nf.Name, nf.BitOffset, nf.BitSize, nf.Size)
+			}
+		}
+		if s, ok := f.(ArrayField); ok {
+			nf.ArrayDims = slices.Clone(s.FieldArrayDims())
+		}
 		if s, ok := f.(ParentedField); ok {
 			parent := s.FieldParent()
 			if parent >= 0 {
@@ -649,18 +676,29 @@ func (ds *dataSource) SubscribePacket(fn PacketFunc, priority int) error {
 	return nil
 }
 
+// addSubscription adds s to the subscriptions; ds.lock must be held
 func (ds *dataSource) addSubscription(s *subscription) {
-	ds.subscriptions = append(ds.subscriptions, s)
-	sort.SliceStable(ds.subscriptions, func(i, j int) bool {
-		return ds.subscriptions[i].priority < ds.subscriptions[j].priority
+	var subscriptions []*subscription
+	if old := ds.subscriptions.Load(); old != nil {
+		subscriptions = slices.Clone(*old)
+	}
+	subscriptions = append(subscriptions, s)
+	sort.SliceStable(subscriptions, func(i, j int) bool {
+		return subscriptions[i].priority < subscriptions[j].priority
 	})
+	ds.subscriptions.Store(&subscriptions)
 }
 
 func (ds *dataSource) EmitAndRelease(p Packet) error {
 	defer ds.Release(p)
 
+	subscriptions := ds.subscriptions.Load()
+	if subscriptions == nil {
+		return nil
+	}
+
 	var err error
-	for _, s := range ds.subscriptions {
+	for _, s := range *subscriptions {
 		err = s.fn(ds, p)
 		if errors.Is(err, ErrDiscard) {
 			return nil
@@ -676,7 +714,11 @@ func (ds *dataSource) Release(p Packet) {
 }
 
 func (ds *dataSource) ReportLostData(ctr uint64) {
-	// TODO
+	ds.lostData.Add(ctr)
+}
+
+func (ds *dataSource) LostDataCount() uint64 {
+	return ds.lostData.Load()
 }
 
 func (ds *dataSource) IsRequestedField(fieldName string) bool {
@@ -752,6 +794,10 @@ func (ds *dataSource) CopyFieldsTo(out DataSource) error {
 		}
 	}
 
+	// Copied fields keep their payload index, so make sure new packets have
+	// room for them and fields added later don't reuse their indexes
+	outDs.payloadCount = max(outDs.payloadCount, ds.payloadCount)
+
 	return nil
 }
 
@@ -145,12 +145,16 @@ type DataSource interface {
 	// ReportLostData reports a number of lost data cases
 	ReportLostData(lostSampleCount uint64)
 
+	// LostDataCount returns the total number of lost data cases reported so far
+	LostDataCount() uint64
+
 	// Dump dumps the content of Packet to a writer for debugging purposes
 	Dump(Packet, io.Writer)
 
 	// Subscribe makes sure that events emitted from this DataSource are passed to DataFunc; subscribers will be
 	// sorted by priority and handed over data in that order (lower numbers = earlier). Subscriptions to
-	// DataSources should only happen in the initialization phase. Data sent to dataFn has to be consumed synchronously
+	// DataSources should usually happen in the initialization phase; operators subscribing later on, e.g. when their
+	// params are updated, only get data emitted after subscribing. Data sent to dataFn has to be consumed synchronously
 	// and must not be accessed after returning. If the data source type is TypeArray, the dataFn will be called for each
 	// data element in the array. For TypeSingle, it will be called once. If you want to receive the entire Packet
 	// (PacketSingle, PacketArray, etc), use SubscribePacket instead.
@@ -402,6 +402,112 @@ func TestDataSourceStaticFieldsStrings(t *testing.T) {
 	}
 }
 
+func TestDataSourceStaticFieldsBitfields(t *testing.T) {
+	t.Parallel()
+
+	ds, err := New(TypeSingle, "event")
+	require.NoError(t, err)
+
+	// struct { u32 a:3; s32 b:5; u32 c:24; u8 d:1; }
+	fieldsAcc, err := ds.AddStaticFields(8, []StaticField{
+		&dummyField{name: "a", size: 4, kind: api.Kind_Uint32, bitOffset: 0, bitSize: 3},
+		&dummyField{name: "b", size: 4, kind: api.Kind_Int32, bitOffset: 3, bitSize: 5},
+		&dummyField{name: "c", size: 4, kind: api.Kind_Uint32, bitOffset: 8, bitSize: 24},
+		&dummyField{name: "d", size: 1, offset: 4, kind: api.Kind_Bool, bitOffset: 0, bitSize: 1},
+	})
+	require.NoError(t, err)
+
+	d, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	defer ds.Release(d)
+
+	buf := make([]byte, 8)
+	ds.ByteOrder().PutUint32(buf, 5|0x1d<<3|0xabcdef<<8)
+	buf[4] = 1
+	require.NoError(t, fieldsAcc.Set(d, buf))
+
+	a, err := ds.GetField("a").Uint32(d)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), a)
+
+	// 0x1d is -3 in 5 bits
+	b, err := ds.GetField("b").Int32(d)
+	require.NoError(t, err)
+	assert.Equal(t, int32(-3), b)
+
+	c, err := ds.GetField("c").Uint32(d)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0xabcdef), c)
+
+	dv, err := ds.GetField("d").Bool(d)
+	require.NoError(t, err)
+	assert.True(t, dv)
+
+	// Writing a bitfield must keep the others untouched
+	require.NoError(t, ds.GetField("b").PutInt32(d, 7))
+	b, err = ds.GetField("b").Int32(d)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), b)
+	a, err = ds.GetField("a").Uint32(d)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), a)
+	c, err = ds.GetField("c").Uint32(d)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0xabcdef), c)
+
+	require.NoError(t, ds.GetField("d").PutBool(d, false))
+	dv, err = ds.GetField("d").Bool(d)
+	require.NoError(t, err)
+	assert.False(t, dv)
+
+	// Bitfields exceeding their integer are rejected
+	_, err = ds.AddStaticFields(4, []StaticField{
+		&dummyField{name: "e", size: 4, kind: api.Kind_Uint32, bitOffset: 30, bitSize: 3},
+	})
+	require.Error(t, err)
+}
+
+func TestDataSourceStaticFieldsArrayDims(t *testing.T) {
+	t.Parallel()
+
+	ds, err := New(TypeSingle, "event")
+	require.NoError(t, err)
+
+	_, err = ds.AddStaticFields(24, []StaticField{
+		&dummyField{name: "matrix", size: 24, kind: api.ArrayOf(api.Kind_Uint32), arrayDims: []uint32{2, 3}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint32{2, 3}, ds.GetField("matrix").ArrayDims())
+}
+
+func TestCopyData(t *testing.T) {
+	t.Parallel()
+
+	ds, err := New(TypeSingle, "event")
+	require.NoError(t, err)
+	f, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+
+	d, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+
+	buf := []byte("nginx")
+	require.NoError(t, f.Set(d, buf))
+	cp := CopyData(d)
+
+	// Changing the memory referenced by the packet must not change the copy
+	copy(buf, "curl!")
+	comm, err := f.String(cp)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", comm)
+
+	require.NoError(t, f.PutString(d, "cat"))
+	comm, err = f.String(cp)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", comm)
+}
+
 // TODO(Jose): Repeat this for all the types
 func TestDataSourceSubscribePriorities(t *testing.T) {
 	t.Parallel()
@@ -519,6 +625,18 @@ func TestDataSourceNewPacket(t *testing.T) {
 	ds.Release(dataArray)
 }
 
+func TestDataSourceReportLostData(t *testing.T) {
+	t.Parallel()
+
+	ds, err := New(TypeSingle, "event")
+	require.NoError(t, err)
+	require.Zero(t, ds.LostDataCount())
+
+	ds.ReportLostData(3)
+	ds.ReportLostData(2)
+	require.Equal(t, uint64(5), ds.LostDataCount())
+}
+
 func TestDataSourcePacketArray(t *testing.T) {
 	t.Parallel()
 
@@ -770,10 +888,13 @@ func TestSiblingsRoot(t *testing.T) {
 }
 
 type dummyField struct {
-	name   string
-	size   uint32
-	offset uint32
-	kind   api.Kind
+	name      string
+	size      uint32
+	offset    uint32
+	kind      api.Kind
+	bitOffset uint32
+	bitSize   uint32
+	arrayDims []uint32
 }
 
 func (d *dummyField) FieldName() string {
@@ -792,6 +913,18 @@ func (d *dummyField) FieldType() api.Kind {
 	return d.kind
 }
 
+func (d *dummyField) FieldBitOffset() uint32 {
+	return d.bitOffset
+}
+
+func (d *dummyField) FieldBitSize() uint32 {
+	return d.bitSize
+}
+
+func (d *dummyField) FieldArrayDims() []uint32 {
+	return d.arrayDims
+}
+
 func randBytes(n int) []byte {
 	ret := make([]byte, n)
 	rand.Read(ret)
@@ -17,9 +17,12 @@ package expr
 import (
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/expr-lang/expr"
 	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
 	"github.com/expr-lang/expr/vm"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
@@ -195,6 +198,98 @@ func getBuiltInExpressions() []expr.Option {
 	}
 }
 
+// fieldCollector gathers all identifiers and member accesses of an expression, so that they can be checked
+// against the fields of a DataSource
+type fieldCollector struct {
+	identifiers []string
+	members     []*ast.MemberNode
+	callees     map[string]struct{}
+	variables   map[string]struct{}
+}
+
+func (fc *fieldCollector) Visit(node *ast.Node) {
+	switch nx := (*node).(type) {
+	case *ast.IdentifierNode:
+		fc.identifiers = append(fc.identifiers, nx.Value)
+	case *ast.MemberNode:
+		fc.members = append(fc.members, nx)
+	case *ast.CallNode:
+		if cn, ok := nx.Callee.(*ast.IdentifierNode); ok {
+			fc.callees[cn.Value] = struct{}{}
+		}
+	case *ast.VariableDeclaratorNode:
+		fc.variables[nx.Name] = struct{}{}
+	}
+}
+
+// memberPath returns the dotted path of a member access like "k8s.podName"
+func memberPath(node ast.Node) (string, bool) {
+	switch nx := node.(type) {
+	case *ast.IdentifierNode:
+		return nx.Value, true
+	case *ast.MemberNode:
+		if nx.Method {
+			return "", false
+		}
+		pn, ok := nx.Property.(*ast.StringNode)
+		if !ok {
+			return "", false
+		}
+		parent, ok := memberPath(nx.Node)
+		if !ok {
+			return "", false
+		}
+		return parent + "." + pn.Value, true
+	}
+	return "", false
+}
+
+// UnknownFields returns the names of all fields that are referenced in expression but don't exist in ds
+func UnknownFields(ds datasource.DataSource, expression string) ([]string, error) {
+	tree, err := parser.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("parsing expression: %w", err)
+	}
+
+	fc := &fieldCollector{
+		callees:   make(map[string]struct{}),
+		variables: make(map[string]struct{}),
+	}
+	ast.Walk(&tree.Node, fc)
+
+	unknown := make([]string, 0)
+	for _, name := range fc.identifiers {
+		if strings.HasPrefix(name, "$") {
+			continue
+		}
+		if _, ok := fc.callees[name]; ok {
+			continue
+		}
+		if _, ok := fc.variables[name]; ok {
+			continue
+		}
+		if ds.GetField(name) == nil && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	for _, m := range fc.members {
+		path, ok := memberPath(m)
+		if !ok {
+			continue
+		}
+		parentPath, _ := memberPath(m.Node)
+		parent := ds.GetField(parentPath)
+		if parent == nil || len(parent.SubFields()) == 0 {
+			// either unknown itself (and already reported) or not a field that has subfields
+			continue
+		}
+		if ds.GetField(path) == nil && !slices.Contains(unknown, path) {
+			unknown = append(unknown, path)
+		}
+	}
+	return unknown, nil
+}
+
 func CompileStringProgram(ds datasource.DataSource, expression string) (*vm.Program, error) {
 	dsp := dsPatcher{
 		ds: ds,
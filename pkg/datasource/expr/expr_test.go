@@ -386,3 +386,58 @@ func TestExpressionFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestUnknownFields(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "filter")
+	require.NoError(t, err)
+	_, err = ds.AddField("stringValue", api.Kind_String)
+	require.NoError(t, err)
+	parent, err := ds.AddField("parent", api.Kind_Invalid)
+	require.NoError(t, err)
+	_, err = parent.AddSubField("child", api.Kind_Int64)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		expression string
+		unknown    []string
+		error      bool
+	}{
+		{
+			name:       "all known",
+			expression: "stringValue == 'abc' && parent.child > 1",
+			unknown:    []string{},
+		},
+		{
+			name:       "unknown field",
+			expression: "foo == 1 || stringValue == 'abc' || bar > foo",
+			unknown:    []string{"foo", "bar"},
+		},
+		{
+			name:       "unknown subfield",
+			expression: "parent.other == 1",
+			unknown:    []string{"parent.other"},
+		},
+		{
+			name:       "functions and variables",
+			expression: "let x = upper(stringValue); x == 'ABC' && abs(parent.child) > 0",
+			unknown:    []string{},
+		},
+		{
+			name:       "invalid expression",
+			expression: "stringValue ==",
+			error:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			unknown, err := UnknownFields(ds, tc.expression)
+			if tc.error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.unknown, unknown)
+		})
+	}
+}
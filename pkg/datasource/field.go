@@ -92,6 +92,18 @@ type ParentedField interface {
 	FieldParent() int
 }
 
+type BitfieldField interface {
+	// FieldBitOffset should return the offset in bits of the value inside the integer at FieldOffset
+	FieldBitOffset() uint32
+	// FieldBitSize should return the number of bits of the value, 0 if the field is not a bitfield
+	FieldBitSize() uint32
+}
+
+type ArrayField interface {
+	// FieldArrayDims should return the dimensions of a multidimensional array or an array of structs
+	FieldArrayDims() []uint32
+}
+
 type FieldOption func(*field)
 
 func WithSameParentAs(otherField FieldAccessor) FieldOption {
@@ -0,0 +1,307 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package arrow converts data of a data source to Apache Arrow records and
+// writes them as Arrow IPC or Parquet files. Fields are mapped to columns by
+// their kind; fields with sub-fields become structs and arrays of numbers
+// become lists.
+package arrow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+// SnapshotColumn is added as first column for data sources of type
+// datasource.TypeArray; it holds the time the array was emitted, so
+// consecutive snapshots can be told apart.
+const SnapshotColumn = "snapshot"
+
+type Formatter struct {
+	ds        datasource.DataSource
+	mem       memory.Allocator
+	schema    *arrow.Schema
+	builder   *array.RecordBuilder
+	appenders []func(data datasource.Data)
+	snapshot  *array.TimestampBuilder
+	batchSize int
+}
+
+// New returns a formatter that converts data of the given data source to
+// Arrow records. All referenced fields are included, even hidden ones.
+func New(ds datasource.DataSource, options ...Option) (*Formatter, error) {
+	f := &Formatter{
+		ds:  ds,
+		mem: memory.DefaultAllocator,
+	}
+	for _, o := range options {
+		o(f)
+	}
+
+	var fields []arrow.Field
+	if ds.Type() == datasource.TypeArray {
+		fields = append(fields, arrow.Field{
+			Name: SnapshotColumn,
+			Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"},
+		})
+	}
+	accessors := fieldAccessors(ds.Accessors(true))
+	for _, acc := range accessors {
+		field, err := arrowField(acc)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("data source %q has no fields to export", ds.Name())
+	}
+	f.schema = arrow.NewSchema(fields, nil)
+	f.builder = array.NewRecordBuilder(f.mem, f.schema)
+
+	builders := f.builder.Fields()
+	if ds.Type() == datasource.TypeArray {
+		f.snapshot = builders[0].(*array.TimestampBuilder)
+		builders = builders[1:]
+	}
+	for i, acc := range accessors {
+		f.appenders = append(f.appenders, newAppender(builders[i], acc))
+	}
+	return f, nil
+}
+
+// fieldAccessors returns the accessors that are exported, skipping
+// unreferenced fields and fields without data.
+func fieldAccessors(accessors []datasource.FieldAccessor) []datasource.FieldAccessor {
+	res := make([]datasource.FieldAccessor, 0, len(accessors))
+	for _, acc := range accessors {
+		if datasource.FieldFlagUnreferenced.In(acc.Flags()) {
+			continue
+		}
+		if len(fieldAccessors(acc.SubFields())) == 0 && acc.Type() == api.Kind_Invalid {
+			continue
+		}
+		res = append(res, acc)
+	}
+	return res
+}
+
+func arrowType(kind api.Kind) (arrow.DataType, bool) {
+	switch kind {
+	case api.Kind_Bool:
+		return arrow.FixedWidthTypes.Boolean, true
+	case api.Kind_Int8:
+		return arrow.PrimitiveTypes.Int8, true
+	case api.Kind_Int16:
+		return arrow.PrimitiveTypes.Int16, true
+	case api.Kind_Int32:
+		return arrow.PrimitiveTypes.Int32, true
+	case api.Kind_Int64:
+		return arrow.PrimitiveTypes.Int64, true
+	case api.Kind_Uint8:
+		return arrow.PrimitiveTypes.Uint8, true
+	case api.Kind_Uint16:
+		return arrow.PrimitiveTypes.Uint16, true
+	case api.Kind_Uint32:
+		return arrow.PrimitiveTypes.Uint32, true
+	case api.Kind_Uint64:
+		return arrow.PrimitiveTypes.Uint64, true
+	case api.Kind_Float32:
+		return arrow.PrimitiveTypes.Float32, true
+	case api.Kind_Float64:
+		return arrow.PrimitiveTypes.Float64, true
+	case api.Kind_String, api.Kind_CString:
+		return arrow.BinaryTypes.String, true
+	case api.Kind_Bytes:
+		return arrow.BinaryTypes.Binary, true
+	}
+	return nil, false
+}
+
+func arrowField(acc datasource.FieldAccessor) (arrow.Field, error) {
+	field := arrow.Field{Name: acc.Name()}
+
+	if subFields := fieldAccessors(acc.SubFields()); len(subFields) > 0 {
+		children := make([]arrow.Field, 0, len(subFields))
+		for _, sub := range subFields {
+			child, err := arrowField(sub)
+			if err != nil {
+				return arrow.Field{}, err
+			}
+			children = append(children, child)
+		}
+		field.Type = arrow.StructOf(children...)
+		return field, nil
+	}
+
+	if acc.Type()&api.KindFlagArray != 0 {
+		elem, ok := arrowType(acc.Type() &^ api.KindFlagArray)
+		if !ok || !(arrow.IsInteger(elem.ID()) || arrow.IsFloating(elem.ID())) {
+			// Arrays of other kinds are exported as raw bytes
+			field.Type = arrow.BinaryTypes.Binary
+			return field, nil
+		}
+		field.Type = arrow.ListOfNonNullable(elem)
+		return field, nil
+	}
+
+	typ, ok := arrowType(acc.Type())
+	if !ok {
+		return arrow.Field{}, fmt.Errorf("field %q: unsupported kind %s", acc.FullName(), acc.Type())
+	}
+	field.Type = typ
+	return field, nil
+}
+
+type appender[T any] interface {
+	Append(T)
+}
+
+func scalar[T any, B appender[T]](b array.Builder, get func(datasource.Data) (T, error)) func(datasource.Data) {
+	vb := b.(B)
+	return func(data datasource.Data) {
+		v, _ := get(data)
+		vb.Append(v)
+	}
+}
+
+func list[T any, B interface{ AppendValues([]T, []bool) }](b array.Builder, get func(datasource.Data) ([]T, error)) func(datasource.Data) {
+	lb := b.(*array.ListBuilder)
+	vb := lb.ValueBuilder().(B)
+	return func(data datasource.Data) {
+		v, _ := get(data)
+		lb.Append(true)
+		vb.AppendValues(v, nil)
+	}
+}
+
+func raw(b array.Builder, acc datasource.FieldAccessor) func(datasource.Data) {
+	bb := b.(*array.BinaryBuilder)
+	return func(data datasource.Data) {
+		bb.Append(acc.Get(data))
+	}
+}
+
+// newAppender returns a function that appends the value of the given field to
+// the builder; b must have been created from the type returned by arrowField.
+func newAppender(b array.Builder, acc datasource.FieldAccessor) func(datasource.Data) {
+	if subFields := fieldAccessors(acc.SubFields()); len(subFields) > 0 {
+		sb := b.(*array.StructBuilder)
+		children := make([]func(datasource.Data), 0, len(subFields))
+		for i, sub := range subFields {
+			children = append(children, newAppender(sb.FieldBuilder(i), sub))
+		}
+		return func(data datasource.Data) {
+			sb.Append(true)
+			for _, child := range children {
+				child(data)
+			}
+		}
+	}
+
+	switch acc.Type() {
+	case api.Kind_Bool:
+		return scalar[bool, *array.BooleanBuilder](b, acc.Bool)
+	case api.Kind_Int8:
+		return scalar[int8, *array.Int8Builder](b, acc.Int8)
+	case api.Kind_Int16:
+		return scalar[int16, *array.Int16Builder](b, acc.Int16)
+	case api.Kind_Int32:
+		return scalar[int32, *array.Int32Builder](b, acc.Int32)
+	case api.Kind_Int64:
+		return scalar[int64, *array.Int64Builder](b, acc.Int64)
+	case api.Kind_Uint8:
+		return scalar[uint8, *array.Uint8Builder](b, acc.Uint8)
+	case api.Kind_Uint16:
+		return scalar[uint16, *array.Uint16Builder](b, acc.Uint16)
+	case api.Kind_Uint32:
+		return scalar[uint32, *array.Uint32Builder](b, acc.Uint32)
+	case api.Kind_Uint64:
+		return scalar[uint64, *array.Uint64Builder](b, acc.Uint64)
+	case api.Kind_Float32:
+		return scalar[float32, *array.Float32Builder](b, acc.Float32)
+	case api.Kind_Float64:
+		return scalar[float64, *array.Float64Builder](b, acc.Float64)
+	case api.Kind_String, api.Kind_CString:
+		return scalar[string, *array.StringBuilder](b, acc.String)
+	case api.ArrayOf(api.Kind_Int8):
+		return list[int8, *array.Int8Builder](b, acc.Int8Array)
+	case api.ArrayOf(api.Kind_Int16):
+		return list[int16, *array.Int16Builder](b, acc.Int16Array)
+	case api.ArrayOf(api.Kind_Int32):
+		return list[int32, *array.Int32Builder](b, acc.Int32Array)
+	case api.ArrayOf(api.Kind_Int64):
+		return list[int64, *array.Int64Builder](b, acc.Int64Array)
+	case api.ArrayOf(api.Kind_Uint8):
+		return list[uint8, *array.Uint8Builder](b, acc.Uint8Array)
+	case api.ArrayOf(api.Kind_Uint16):
+		return list[uint16, *array.Uint16Builder](b, acc.Uint16Array)
+	case api.ArrayOf(api.Kind_Uint32):
+		return list[uint32, *array.Uint32Builder](b, acc.Uint32Array)
+	case api.ArrayOf(api.Kind_Uint64):
+		return list[uint64, *array.Uint64Builder](b, acc.Uint64Array)
+	case api.ArrayOf(api.Kind_Float32):
+		return list[float32, *array.Float32Builder](b, acc.Float32Array)
+	case api.ArrayOf(api.Kind_Float64):
+		return list[float64, *array.Float64Builder](b, acc.Float64Array)
+	}
+	return raw(b, acc)
+}
+
+// Schema returns the Arrow schema of the records
+func (f *Formatter) Schema() *arrow.Schema {
+	return f.schema
+}
+
+// Append adds a row for the given data to the current record
+func (f *Formatter) Append(data datasource.Data) {
+	for _, fn := range f.appenders {
+		fn(data)
+	}
+}
+
+// AppendArray adds a row for each element of the array to the current record;
+// all rows get the given time as snapshot.
+func (f *Formatter) AppendArray(dataArray datasource.DataArray, snapshot time.Time) {
+	ts := arrow.Timestamp(snapshot.UnixNano())
+	for i := 0; i < dataArray.Len(); i++ {
+		if f.snapshot != nil {
+			f.snapshot.Append(ts)
+		}
+		f.Append(dataArray.Get(i))
+	}
+}
+
+// Len returns the number of rows in the current record
+func (f *Formatter) Len() int {
+	return f.builder.Field(0).Len()
+}
+
+// NewRecord returns the rows appended so far as a record and starts a new
+// one. The caller is responsible for releasing the record.
+func (f *Formatter) NewRecord() arrow.Record {
+	return f.builder.NewRecord()
+}
+
+// Release frees the memory held by the formatter
+func (f *Formatter) Release() {
+	f.builder.Release()
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type testDS struct {
+	ds      datasource.DataSource
+	comm    datasource.FieldAccessor
+	pid     datasource.FieldAccessor
+	k8s     datasource.FieldAccessor
+	podName datasource.FieldAccessor
+	ok      datasource.FieldAccessor
+	args    datasource.FieldAccessor
+	hidden  datasource.FieldAccessor
+}
+
+func newTestDS(t *testing.T, dsType datasource.Type) *testDS {
+	ds, err := datasource.New(dsType, "test")
+	require.NoError(t, err)
+
+	d := &testDS{ds: ds}
+	d.comm, err = ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	d.pid, err = ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	d.k8s, err = ds.AddField("k8s", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+	require.NoError(t, err)
+	d.podName, err = d.k8s.AddSubField("podName", api.Kind_String)
+	require.NoError(t, err)
+	d.ok, err = ds.AddField("ok", api.Kind_Bool)
+	require.NoError(t, err)
+	d.args, err = ds.AddField("args", api.ArrayOf(api.Kind_Int32))
+	require.NoError(t, err)
+	d.hidden, err = ds.AddField("hidden", api.Kind_Int64, datasource.WithFlags(datasource.FieldFlagHidden))
+	require.NoError(t, err)
+	return d
+}
+
+func (d *testDS) fill(t *testing.T, data datasource.Data, i int) {
+	require.NoError(t, d.comm.PutString(data, "comm"+string(rune('a'+i))))
+	require.NoError(t, d.pid.PutUint32(data, uint32(i)))
+	require.NoError(t, d.podName.PutString(data, "pod"))
+	require.NoError(t, d.ok.PutBool(data, i%2 == 0))
+	args := make([]byte, 8)
+	d.ds.ByteOrder().PutUint32(args, uint32(i))
+	d.ds.ByteOrder().PutUint32(args[4:], math.MaxUint32)
+	require.NoError(t, d.args.Set(data, args))
+	require.NoError(t, d.hidden.PutInt64(data, -int64(i)))
+}
+
+func TestSchema(t *testing.T) {
+	d := newTestDS(t, datasource.TypeArray)
+	f, err := New(d.ds)
+	require.NoError(t, err)
+	defer f.Release()
+
+	expected := arrow.NewSchema([]arrow.Field{
+		{Name: SnapshotColumn, Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}},
+		{Name: "comm", Type: arrow.BinaryTypes.String},
+		{Name: "pid", Type: arrow.PrimitiveTypes.Uint32},
+		{Name: "k8s", Type: arrow.StructOf(arrow.Field{Name: "podName", Type: arrow.BinaryTypes.String})},
+		{Name: "ok", Type: arrow.FixedWidthTypes.Boolean},
+		{Name: "args", Type: arrow.ListOfNonNullable(arrow.PrimitiveTypes.Int32)},
+		{Name: "hidden", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	assert.True(t, expected.Equal(f.Schema()), "got schema %s", f.Schema())
+}
+
+func readTable(t *testing.T, format Format, b []byte) arrow.Table {
+	switch format {
+	case FormatArrow:
+		r, err := ipc.NewFileReader(bytes.NewReader(b))
+		require.NoError(t, err)
+		defer r.Close()
+		var recs []arrow.Record
+		for i := 0; i < r.NumRecords(); i++ {
+			rec, err := r.Record(i)
+			require.NoError(t, err)
+			rec.Retain()
+			recs = append(recs, rec)
+		}
+		return array.NewTableFromRecords(r.Schema(), recs)
+	default:
+		pr, err := file.NewParquetReader(bytes.NewReader(b))
+		require.NoError(t, err)
+		fr, err := pqarrow.NewFileReader(pr, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+		require.NoError(t, err)
+		tbl, err := fr.ReadTable(context.Background())
+		require.NoError(t, err)
+		return tbl
+	}
+}
+
+// column returns all chunks of the i-th column of the table as a single array
+func column(t *testing.T, tbl arrow.Table, i int) arrow.Array {
+	arr, err := array.Concatenate(tbl.Column(i).Data().Chunks(), memory.DefaultAllocator)
+	require.NoError(t, err)
+	t.Cleanup(arr.Release)
+	return arr
+}
+
+func TestWriter(t *testing.T) {
+	for name, format := range map[string]Format{"arrow": FormatArrow, "parquet": FormatParquet} {
+		t.Run(name, func(t *testing.T) {
+			d := newTestDS(t, datasource.TypeSingle)
+
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, d.ds, format, WithBatchSize(2))
+			require.NoError(t, err)
+
+			for i := 0; i < 5; i++ {
+				data, err := d.ds.NewPacketSingle()
+				require.NoError(t, err)
+				d.fill(t, data, i)
+				require.NoError(t, w.Write(data))
+			}
+			require.NoError(t, w.Close())
+			require.Error(t, w.Write(nil))
+
+			tbl := readTable(t, format, buf.Bytes())
+			defer tbl.Release()
+			require.EqualValues(t, 5, tbl.NumRows())
+			require.EqualValues(t, 6, tbl.NumCols())
+
+			assert.Equal(t, "commc", column(t, tbl, 0).(*array.String).Value(2))
+			assert.Equal(t, uint32(4), column(t, tbl, 1).(*array.Uint32).Value(4))
+			podName := column(t, tbl, 2).(*array.Struct).Field(0).(*array.String)
+			assert.Equal(t, "pod", podName.Value(1))
+			assert.Equal(t, false, column(t, tbl, 3).(*array.Boolean).Value(1))
+			args := column(t, tbl, 4).(*array.List)
+			start, end := args.ValueOffsets(3)
+			assert.Equal(t, []int32{3, -1}, args.ListValues().(*array.Int32).Int32Values()[start:end])
+			assert.Equal(t, int64(-2), column(t, tbl, 5).(*array.Int64).Value(2))
+		})
+	}
+}
+
+func TestWriterArray(t *testing.T) {
+	d := newTestDS(t, datasource.TypeArray)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, d.ds, FormatParquet)
+	require.NoError(t, err)
+
+	snapshot := time.Now()
+	for n := 0; n < 2; n++ {
+		arr, err := d.ds.NewPacketArray()
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			data := arr.New()
+			d.fill(t, data, i)
+			arr.Append(data)
+		}
+		w.formatter.AppendArray(arr, snapshot.Add(time.Duration(n)*time.Second))
+	}
+	require.NoError(t, w.Close())
+
+	tbl := readTable(t, FormatParquet, buf.Bytes())
+	defer tbl.Release()
+	require.EqualValues(t, 6, tbl.NumRows())
+
+	snapshots := column(t, tbl, 0).(*array.Timestamp)
+	assert.Equal(t, snapshots.Value(0), snapshots.Value(2))
+	assert.Equal(t, arrow.Timestamp(snapshot.Add(time.Second).UnixNano()), snapshots.Value(3))
+}
@@ -0,0 +1,36 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+type Option func(*Formatter)
+
+// WithAllocator sets the allocator used for building records
+func WithAllocator(mem memory.Allocator) Option {
+	return func(formatter *Formatter) {
+		formatter.mem = mem
+	}
+}
+
+// WithBatchSize sets the number of rows a Writer collects before writing
+// them as a record; defaults to DefaultBatchSize.
+func WithBatchSize(size int) Option {
+	return func(formatter *Formatter) {
+		formatter.batchSize = size
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+)
+
+type Format int
+
+const (
+	// FormatArrow writes files in the Arrow IPC file format (also known as
+	// Feather V2)
+	FormatArrow Format = iota
+	// FormatParquet writes Parquet files; each record becomes a row group
+	FormatParquet
+)
+
+// DefaultBatchSize is the default number of rows that are collected before
+// they are written as a record
+const DefaultBatchSize = 64 * 1024
+
+type recordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// Writer writes data of a data source to an Arrow IPC or Parquet file. Rows are
+// collected and written in batches; the file is only complete after Close has
+// been called.
+type Writer struct {
+	mu        sync.Mutex
+	formatter *Formatter
+	w         recordWriter
+	batchSize int
+}
+
+// NewWriter returns a writer that writes data of the given data source to w
+// in the given format.
+func NewWriter(w io.Writer, ds datasource.DataSource, format Format, options ...Option) (*Writer, error) {
+	f, err := New(ds, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var rw recordWriter
+	switch format {
+	case FormatArrow:
+		rw, err = ipc.NewFileWriter(w, ipc.WithSchema(f.Schema()), ipc.WithAllocator(f.mem))
+	case FormatParquet:
+		props := parquet.NewWriterProperties(
+			parquet.WithCompression(compress.Codecs.Snappy),
+			parquet.WithAllocator(f.mem),
+		)
+		rw, err = pqarrow.NewFileWriter(f.Schema(), w, props, pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+	default:
+		err = fmt.Errorf("unknown format %d", format)
+	}
+	if err != nil {
+		f.Release()
+		return nil, fmt.Errorf("creating writer: %w", err)
+	}
+
+	batchSize := f.batchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &Writer{
+		formatter: f,
+		w:         rw,
+		batchSize: batchSize,
+	}, nil
+}
+
+func (w *Writer) flush() error {
+	if w.formatter.Len() == 0 {
+		return nil
+	}
+	rec := w.formatter.NewRecord()
+	defer rec.Release()
+	return w.w.Write(rec)
+}
+
+func (w *Writer) flushIfFull() error {
+	if w.formatter.Len() < w.batchSize {
+		return nil
+	}
+	return w.flush()
+}
+
+// Write adds a row for the given data
+func (w *Writer) Write(data datasource.Data) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.w == nil {
+		return errors.New("writer closed")
+	}
+	w.formatter.Append(data)
+	return w.flushIfFull()
+}
+
+// WriteArray adds a row for each element of the array, using the current time
+// as snapshot
+func (w *Writer) WriteArray(dataArray datasource.DataArray) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.w == nil {
+		return errors.New("writer closed")
+	}
+	w.formatter.AppendArray(dataArray, time.Now())
+	return w.flushIfFull()
+}
+
+// Close writes the remaining rows and the footer of the file. It doesn't close
+// the underlying writer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.w == nil {
+		return nil
+	}
+	err := errors.Join(w.flush(), w.w.Close())
+	w.formatter.Release()
+	w.w = nil
+	return err
+}
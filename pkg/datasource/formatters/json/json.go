@@ -129,7 +129,7 @@ func (f *Formatter) init() error {
 	f.fns = append(f.fns, func(e *encodeState, data datasource.Data) {
 		e.Write(opener)
 	})
-	subFieldFuncs, _ := f.addSubFields(nil, "", indent)
+	subFieldFuncs, _ := f.addSubFields(nil, "", indent, false)
 	f.fns = append(f.fns, subFieldFuncs...)
 	f.fns = append(f.fns, func(e *encodeState, data datasource.Data) {
 		e.Write(closer)
@@ -139,42 +139,87 @@ func (f *Formatter) init() error {
 
 func writeIntArrFn[T constraints.Integer](
 	toArrayFn func(datasource.Data) ([]T, error),
-	fieldSep []byte,
+	f *Formatter,
+	dims []uint32,
 	newIndent string,
 ) func(e *encodeState, data datasource.Data) {
-	return func(e *encodeState, data datasource.Data) {
-		vals, _ := toArrayFn(data)
-		for i, v := range vals {
-			if i > 0 {
-				e.Write(fieldSep)
-			}
-			e.WriteString(newIndent)
-			b := strconv.AppendInt(e.scratch[:0], int64(v), 10)
-			e.Write(b)
-		}
-	}
+	return writeArrFn(toArrayFn, func(e *encodeState, v T) {
+		b := strconv.AppendInt(e.scratch[:0], int64(v), 10)
+		e.Write(b)
+	}, f, dims, newIndent)
 }
 
 func writeFloatArrFn[T constraints.Float](
 	toArrayFn func(datasource.Data) ([]T, error),
-	fieldSep []byte,
+	f *Formatter,
+	dims []uint32,
 	newIndent string,
 ) func(e *encodeState, data datasource.Data) {
-	var f T
-	fsize := unsafe.Sizeof(f)
-	return func(e *encodeState, data datasource.Data) {
-		vals, _ := toArrayFn(data)
-		for i, v := range vals {
+	var v T
+	fsize := unsafe.Sizeof(v)
+	return writeArrFn(toArrayFn, func(e *encodeState, v T) {
+		floatEncoder(fsize*8).writeFloat(e, float64(v))
+	}, f, dims, newIndent)
+}
+
+// writeArrFn returns a function writing the elements of an array; elements of multidimensional
+// arrays are nested into arrays according to dims
+func writeArrFn[T any](
+	toArrayFn func(datasource.Data) ([]T, error),
+	writeVal func(e *encodeState, v T),
+	f *Formatter,
+	dims []uint32,
+	newIndent string,
+) func(e *encodeState, data datasource.Data) {
+	// indentation and closers of the nested arrays by depth
+	indents := []string{newIndent}
+	closers := [][]byte{closerArray}
+	for depth := 1; depth < len(dims); depth++ {
+		indent := ""
+		if f.pretty {
+			indent = indents[depth-1] + f.indent
+			closers[depth-1] = append([]byte("\n"+indents[depth-1]), closerArray...)
+		}
+		indents = append(indents, indent)
+		closers = append(closers, closerArray)
+	}
+
+	var write func(e *encodeState, vals []T, depth int)
+	write = func(e *encodeState, vals []T, depth int) {
+		if depth >= len(dims)-1 {
+			for i, v := range vals {
+				if i > 0 {
+					e.Write(f.fieldSep)
+				}
+				e.WriteString(indents[depth])
+				writeVal(e, v)
+			}
+			return
+		}
+		n := len(vals) / int(dims[depth])
+		if n == 0 {
+			return
+		}
+		for i := 0; i+n <= len(vals); i += n {
 			if i > 0 {
-				e.Write(fieldSep)
+				e.Write(f.fieldSep)
 			}
-			e.WriteString(newIndent)
-			floatEncoder(fsize*8).writeFloat(e, float64(v))
+			e.WriteString(indents[depth])
+			e.Write(f.openerArray)
+			write(e, vals[i:i+n], depth+1)
+			e.Write(closers[depth])
 		}
 	}
+
+	return func(e *encodeState, data datasource.Data) {
+		vals, _ := toArrayFn(data)
+		write(e, vals, 0)
+	}
 }
 
-func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix string, indent string) (fns []func(*encodeState, datasource.Data), fieldCounter int) {
+// addSubFields returns the functions to write the given fields; if inArray is set, the fields are
+// the elements of an array of structs and are written in order without their names
+func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix string, indent string, inArray bool) (fns []func(*encodeState, datasource.Data), fieldCounter int) {
 	if accessors == nil {
 		accessors = f.ds.Accessors(true)
 	}
@@ -182,9 +227,11 @@ func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix st
 	ctr := -1
 
 	// sort lexicographically
-	slices.SortFunc(accessors, func(i datasource.FieldAccessor, j datasource.FieldAccessor) int {
-		return strings.Compare(i.Name(), j.Name())
-	})
+	if !inArray {
+		slices.SortFunc(accessors, func(i datasource.FieldAccessor, j datasource.FieldAccessor) int {
+			return strings.Compare(i.Name(), j.Name())
+		})
+	}
 
 	for _, acc := range accessors {
 		accessor := acc
@@ -204,7 +251,7 @@ func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix st
 		var subFieldCount int
 		subFields := accessor.SubFields()
 		if len(subFields) > 0 {
-			subFieldFuncs, subFieldCount = f.addSubFields(subFields, fullFieldName+".", indent+f.indent)
+			subFieldFuncs, subFieldCount = f.addSubFields(subFields, fullFieldName+".", indent+f.indent, len(accessor.ArrayDims()) > 0)
 			fieldCounter += subFieldCount
 		}
 
@@ -236,6 +283,12 @@ func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix st
 		if f.pretty {
 			fieldName = append(append([]byte(indent), fieldName...), ' ')
 		}
+		if inArray {
+			fieldName = []byte{}
+			if f.pretty {
+				fieldName = []byte(indent)
+			}
+		}
 		if ctr > 0 {
 			fns = append(fns, func(e *encodeState, data datasource.Data) {
 				e.Write(f.fieldSep)
@@ -249,6 +302,19 @@ func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix st
 			closerArray = append([]byte("\n"+indent), closerArray...)
 		}
 
+		// Field is an array of structs
+		if len(subFields) > 0 && len(accessor.ArrayDims()) > 0 {
+			fns = append(fns, func(e *encodeState, data datasource.Data) {
+				e.Write(fieldName)
+				e.Write(f.openerArray)
+			})
+			fns = append(fns, subFieldFuncs...)
+			fns = append(fns, func(e *encodeState, data datasource.Data) {
+				e.Write(closerArray)
+			})
+			continue
+		}
+
 		// Field has subfields
 		if len(subFields) > 0 {
 			fns = append(fns, func(e *encodeState, data datasource.Data) {
@@ -270,27 +336,28 @@ func (f *Formatter) addSubFields(accessors []datasource.FieldAccessor, prefix st
 			if f.pretty {
 				newIndent = indent + f.indent
 			}
+			dims := accessor.ArrayDims()
 			switch accessor.Type() {
 			case api.ArrayOf(api.Kind_Int8):
-				fn = writeIntArrFn(accessor.Int8Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Int8Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Int16):
-				fn = writeIntArrFn(accessor.Int16Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Int16Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Int32):
-				fn = writeIntArrFn(accessor.Int32Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Int32Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Int64):
-				fn = writeIntArrFn(accessor.Int64Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Int64Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Uint8):
-				fn = writeIntArrFn(accessor.Uint8Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Uint8Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Uint16):
-				fn = writeIntArrFn(accessor.Uint16Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Uint16Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Uint32):
-				fn = writeIntArrFn(accessor.Uint32Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Uint32Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Uint64):
-				fn = writeIntArrFn(accessor.Uint64Array, f.fieldSep, newIndent)
+				fn = writeIntArrFn(accessor.Uint64Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Float32):
-				fn = writeFloatArrFn(accessor.Float32Array, f.fieldSep, newIndent)
+				fn = writeFloatArrFn(accessor.Float32Array, f, dims, newIndent)
 			case api.ArrayOf(api.Kind_Float64):
-				fn = writeFloatArrFn(accessor.Float64Array, f.fieldSep, newIndent)
+				fn = writeFloatArrFn(accessor.Float64Array, f, dims, newIndent)
 			default:
 				fn = func(e *encodeState, data datasource.Data) {
 					e.Write(fieldName)
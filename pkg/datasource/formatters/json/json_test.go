@@ -0,0 +1,123 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type staticField struct {
+	name      string
+	size      uint32
+	offset    uint32
+	kind      api.Kind
+	parent    int
+	bitOffset uint32
+	bitSize   uint32
+	arrayDims []uint32
+}
+
+func (f *staticField) FieldName() string        { return f.name }
+func (f *staticField) FieldSize() uint32        { return f.size }
+func (f *staticField) FieldOffset() uint32      { return f.offset }
+func (f *staticField) FieldType() api.Kind      { return f.kind }
+func (f *staticField) FieldParent() int         { return f.parent }
+func (f *staticField) FieldBitOffset() uint32   { return f.bitOffset }
+func (f *staticField) FieldBitSize() uint32     { return f.bitSize }
+func (f *staticField) FieldArrayDims() []uint32 { return f.arrayDims }
+
+// newArrayDataSource returns a data source holding
+//
+//	struct {
+//		u32 matrix[2][3];
+//		struct { u16 x; } arr[2];
+//		u8 flags:3;
+//	}
+func newArrayDataSource(t *testing.T) (datasource.DataSource, datasource.Data) {
+	ds, err := datasource.New(datasource.TypeSingle, "event")
+	require.NoError(t, err)
+
+	acc, err := ds.AddStaticFields(29, []datasource.StaticField{
+		&staticField{name: "matrix", size: 24, kind: api.ArrayOf(api.Kind_Uint32), parent: -1, arrayDims: []uint32{2, 3}},
+		&staticField{name: "arr", size: 4, offset: 24, kind: api.Kind_Bytes, parent: -1, arrayDims: []uint32{2}},
+		&staticField{name: "0", size: 2, offset: 24, kind: api.Kind_Bytes, parent: 1},
+		&staticField{name: "x", size: 2, offset: 24, kind: api.Kind_Uint16, parent: 2},
+		&staticField{name: "1", size: 2, offset: 26, kind: api.Kind_Bytes, parent: 1},
+		&staticField{name: "x", size: 2, offset: 26, kind: api.Kind_Uint16, parent: 4},
+		&staticField{name: "flags", size: 1, offset: 28, kind: api.Kind_Uint8, parent: -1, bitOffset: 2, bitSize: 3},
+	})
+	require.NoError(t, err)
+
+	d, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+
+	buf := make([]byte, 29)
+	for i := range 6 {
+		ds.ByteOrder().PutUint32(buf[i*4:], uint32(i+1))
+	}
+	ds.ByteOrder().PutUint16(buf[24:], 7)
+	ds.ByteOrder().PutUint16(buf[26:], 8)
+	buf[28] = 0xff
+	require.NoError(t, acc.Set(d, buf))
+	return ds, d
+}
+
+func TestArrays(t *testing.T) {
+	ds, d := newArrayDataSource(t)
+
+	f, err := New(ds)
+	require.NoError(t, err)
+	assert.Equal(t, `{"arr":[{"x":7},{"x":8}],"flags":7,"matrix":[[1,2,3],[4,5,6]]}`, string(f.Marshal(d)))
+
+	f, err = New(ds, WithFields([]string{"arr.1.x", "matrix"}))
+	require.NoError(t, err)
+	assert.Equal(t, `{"arr":[{"x":8}],"matrix":[[1,2,3],[4,5,6]]}`, string(f.Marshal(d)))
+}
+
+func TestArraysPretty(t *testing.T) {
+	ds, d := newArrayDataSource(t)
+
+	f, err := New(ds, WithPretty(true, "  "))
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "arr": [
+    {
+      "x": 7
+    },
+    {
+      "x": 8
+    }
+  ],
+  "flags": 7,
+  "matrix": [
+    [
+      1,
+      2,
+      3
+    ],
+    [
+      4,
+      5,
+      6
+    ]
+  ]
+}`, string(f.Marshal(d)))
+}
@@ -0,0 +1,33 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+type Option func(*Formatter)
+
+// WithFields specifies which fields to export and in which order. If fields is
+// nil, the fields visible by default will be used.
+func WithFields(fields []string) Option {
+	return func(formatter *Formatter) {
+		formatter.fields = fields
+	}
+}
+
+// WithPrefixColumns adds columns in front of the fields of the data source;
+// their values are passed to Format and FormatArray.
+func WithPrefixColumns(names ...string) Option {
+	return func(formatter *Formatter) {
+		formatter.prefix = names
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package table formats data of a data source as CSV, TSV or Markdown tables.
+// Values are taken unpadded from the same columns used by the columns output,
+// so annotations like columns.hex or columns.precision are honored.
+package table
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+)
+
+type Style int
+
+const (
+	StyleCSV Style = iota
+	StyleTSV
+	StyleMarkdown
+)
+
+var tsvReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"\t", `\t`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+type Formatter struct {
+	ds      datasource.DataSource
+	style   Style
+	fields  []string
+	prefix  []string
+	names   []string
+	getters []func(*datasource.DataTuple) string
+
+	buf bytes.Buffer
+	csv *csv.Writer
+}
+
+// New returns a formatter for the given data source. Unless fields are given
+// using WithFields, the fields visible by default are used.
+func New(ds datasource.DataSource, style Style, options ...Option) (*Formatter, error) {
+	f := &Formatter{
+		ds:    ds,
+		style: style,
+	}
+	for _, o := range options {
+		o(f)
+	}
+
+	p, err := ds.Parser()
+	if err != nil {
+		return nil, fmt.Errorf("getting parser: %w", err)
+	}
+	cols, ok := p.GetColumns().(columns.ColumnMap[datasource.DataTuple])
+	if !ok {
+		return nil, fmt.Errorf("invalid columns: expected columns.ColumnMap[datasource.DataTuple], got %T", p.GetColumns())
+	}
+
+	fields := f.fields
+	if fields == nil {
+		fields = p.GetDefaultColumns()
+	}
+
+	aliases := make(map[string]*columns.Column[datasource.DataTuple])
+	for _, c := range cols {
+		if c.Alias != "" {
+			aliases[strings.ToLower(c.Alias)] = c
+		}
+	}
+
+	for _, field := range fields {
+		col, ok := cols.GetColumn(field)
+		if !ok {
+			col, ok = aliases[strings.ToLower(field)]
+			if !ok {
+				return nil, fmt.Errorf("field %q not found", field)
+			}
+		}
+		f.names = append(f.names, col.Name)
+		f.getters = append(f.getters, columns.GetFieldAsStringExt[datasource.DataTuple](col, 'f', col.Precision, col.Hex))
+	}
+
+	if style == StyleCSV {
+		f.csv = csv.NewWriter(&f.buf)
+	}
+
+	return f, nil
+}
+
+func (f *Formatter) formatRow(values []string) string {
+	switch f.style {
+	case StyleCSV:
+		f.buf.Reset()
+		f.csv.Write(values)
+		f.csv.Flush()
+		return strings.TrimSuffix(f.buf.String(), "\n")
+	case StyleTSV:
+		for i, v := range values {
+			values[i] = tsvReplacer.Replace(v)
+		}
+		return strings.Join(values, "\t")
+	default:
+		for i, v := range values {
+			values[i] = markdownReplacer.Replace(v)
+		}
+		return "| " + strings.Join(values, " | ") + " |"
+	}
+}
+
+// FormatHeader returns the header of the table; in case of Markdown, this
+// includes the delimiter row.
+func (f *Formatter) FormatHeader() string {
+	names := append(append([]string{}, f.prefix...), f.names...)
+	header := f.formatRow(names)
+	if f.style != StyleMarkdown {
+		return header
+	}
+	delimiters := make([]string, len(names))
+	for i := range delimiters {
+		delimiters[i] = "---"
+	}
+	return header + "\n" + "| " + strings.Join(delimiters, " | ") + " |"
+}
+
+// Format returns a row for the given data. prefixValues are the values for the
+// columns added with WithPrefixColumns.
+func (f *Formatter) Format(data datasource.Data, prefixValues ...string) string {
+	tuple := datasource.NewDataTuple(f.ds, data)
+	values := make([]string, 0, len(prefixValues)+len(f.getters))
+	values = append(values, prefixValues...)
+	for _, get := range f.getters {
+		values = append(values, get(tuple))
+	}
+	return f.formatRow(values)
+}
+
+// FormatArray returns one row for each element of the array, separated by
+// newlines.
+func (f *Formatter) FormatArray(dataArray datasource.DataArray, prefixValues ...string) string {
+	rows := make([]string, 0, dataArray.Len())
+	for i := 0; i < dataArray.Len(); i++ {
+		rows = append(rows, f.Format(dataArray.Get(i), prefixValues...))
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type testDS struct {
+	ds     datasource.DataSource
+	comm   datasource.FieldAccessor
+	args   datasource.FieldAccessor
+	pid    datasource.FieldAccessor
+	secret datasource.FieldAccessor
+}
+
+func newTestDS(t *testing.T, dsType datasource.Type) *testDS {
+	ds, err := datasource.New(dsType, "test")
+	require.NoError(t, err)
+
+	d := &testDS{ds: ds}
+	d.comm, err = ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	d.pid, err = ds.AddField("pid", api.Kind_Uint32)
+	require.NoError(t, err)
+	d.args, err = ds.AddField("args", api.Kind_String)
+	require.NoError(t, err)
+	d.secret, err = ds.AddField("secret", api.Kind_String,
+		datasource.WithAnnotations(map[string]string{"columns.hidden": "true"}))
+	require.NoError(t, err)
+	return d
+}
+
+func (d *testDS) newData(t *testing.T, comm, args string, pid uint32) datasource.Data {
+	data, err := d.ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, d.comm.PutString(data, comm))
+	require.NoError(t, d.args.PutString(data, args))
+	require.NoError(t, d.pid.PutUint32(data, pid))
+	require.NoError(t, d.secret.PutString(data, "hunter2"))
+	return data
+}
+
+func TestFormatter(t *testing.T) {
+	tests := []struct {
+		name           string
+		style          Style
+		options        []Option
+		expectedHeader string
+		expectedRow    string
+	}{
+		{
+			name:           "csv",
+			style:          StyleCSV,
+			expectedHeader: "comm,pid,args",
+			expectedRow:    `cat,42,"a,b ""c"""`,
+		},
+		{
+			name:           "tsv",
+			style:          StyleTSV,
+			expectedHeader: "comm\tpid\targs",
+			expectedRow:    "cat\t42\ta,b \"c\"",
+		},
+		{
+			name:           "markdown",
+			style:          StyleMarkdown,
+			expectedHeader: "| comm | pid | args |\n| --- | --- | --- |",
+			expectedRow:    `| cat | 42 | a,b "c" |`,
+		},
+		{
+			name:           "fields",
+			style:          StyleCSV,
+			options:        []Option{WithFields([]string{"secret", "PID"})},
+			expectedHeader: "secret,pid",
+			expectedRow:    "hunter2,42",
+		},
+		{
+			name:           "prefix columns",
+			style:          StyleTSV,
+			options:        []Option{WithFields([]string{"comm"}), WithPrefixColumns("snapshot")},
+			expectedHeader: "snapshot\tcomm",
+			expectedRow:    "prefix\tcat",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := newTestDS(t, datasource.TypeSingle)
+			f, err := New(d.ds, test.style, test.options...)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedHeader, f.FormatHeader())
+
+			var prefix []string
+			if len(f.prefix) > 0 {
+				prefix = []string{"prefix"}
+			}
+			assert.Equal(t, test.expectedRow, f.Format(d.newData(t, "cat", `a,b "c"`, 42), prefix...))
+		})
+	}
+}
+
+func TestFormatterEscaping(t *testing.T) {
+	value := "a\tb|c\\d\ne"
+	tests := []struct {
+		style    Style
+		expected string
+	}{
+		{style: StyleCSV, expected: "\"a\tb|c\\d\ne\""},
+		{style: StyleTSV, expected: `a\tb|c\\d\ne`},
+		{style: StyleMarkdown, expected: `| a` + "\t" + `b\|c\\d<br>e |`},
+	}
+
+	for _, test := range tests {
+		d := newTestDS(t, datasource.TypeSingle)
+		f, err := New(d.ds, test.style, WithFields([]string{"args"}))
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, f.Format(d.newData(t, "", value, 0)))
+	}
+}
+
+func TestFormatterArray(t *testing.T) {
+	d := newTestDS(t, datasource.TypeArray)
+	f, err := New(d.ds, StyleCSV, WithFields([]string{"comm", "pid"}), WithPrefixColumns("snapshot"))
+	require.NoError(t, err)
+
+	arr, err := d.ds.NewPacketArray()
+	require.NoError(t, err)
+	for i, comm := range []string{"bash", "curl"} {
+		data := arr.New()
+		require.NoError(t, d.comm.PutString(data, comm))
+		require.NoError(t, d.pid.PutUint32(data, uint32(i+1)))
+		arr.Append(data)
+	}
+
+	assert.Equal(t, "snapshot,comm,pid", f.FormatHeader())
+	assert.Equal(t, "t1,bash,1\nt1,curl,2", f.FormatArray(arr, "t1"))
+}
+
+func TestFormatterUnknownField(t *testing.T) {
+	d := newTestDS(t, datasource.TypeSingle)
+	_, err := New(d.ds, StyleCSV, WithFields([]string{"nope"}))
+	require.Error(t, err)
+}
+
+type staticField struct {
+	name      string
+	size      uint32
+	offset    uint32
+	kind      api.Kind
+	bitOffset uint32
+	bitSize   uint32
+	arrayDims []uint32
+}
+
+func (f *staticField) FieldName() string        { return f.name }
+func (f *staticField) FieldSize() uint32        { return f.size }
+func (f *staticField) FieldOffset() uint32      { return f.offset }
+func (f *staticField) FieldType() api.Kind      { return f.kind }
+func (f *staticField) FieldBitOffset() uint32   { return f.bitOffset }
+func (f *staticField) FieldBitSize() uint32     { return f.bitSize }
+func (f *staticField) FieldArrayDims() []uint32 { return f.arrayDims }
+
+func TestFormatterBitfieldsAndArrays(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "test")
+	require.NoError(t, err)
+
+	acc, err := ds.AddStaticFields(14, []datasource.StaticField{
+		&staticField{name: "matrix", size: 12, kind: api.ArrayOf(api.Kind_Uint16), arrayDims: []uint32{2, 3}},
+		&staticField{name: "list", size: 2, offset: 12, kind: api.ArrayOf(api.Kind_Int8)},
+		&staticField{name: "lo", size: 2, offset: 12, kind: api.Kind_Uint16, bitOffset: 0, bitSize: 4},
+		&staticField{name: "hi", size: 2, offset: 12, kind: api.Kind_Int16, bitOffset: 12, bitSize: 4},
+	})
+	require.NoError(t, err)
+
+	f, err := New(ds, StyleCSV, WithFields([]string{"matrix", "list", "lo", "hi"}))
+	require.NoError(t, err)
+
+	data, err := ds.NewPacketSingle()
+	require.NoError(t, err)
+	buf := make([]byte, 14)
+	for i := range 6 {
+		ds.ByteOrder().PutUint16(buf[i*2:], uint16(i+1))
+	}
+	ds.ByteOrder().PutUint16(buf[12:], 0xf00a)
+	require.NoError(t, acc.Set(data, buf))
+
+	list := "[10 -16]"
+	if ds.ByteOrder().Uint16([]byte{0, 1}) == 1 {
+		list = "[-16 10]"
+	}
+	assert.Equal(t, "matrix,list,lo,hi", f.FormatHeader())
+	assert.Equal(t, "[[1 2 3] [4 5 6]],"+list+",10,-1", f.Format(data))
+}
@@ -55,6 +55,31 @@ func AsInt64(f FieldAccessor) (func(Data) int64, error) {
 	}
 }
 
+func AsUint64Func[T constraints.Unsigned](extract func(Data) (T, error)) func(Data) uint64 {
+	return func(data Data) uint64 {
+		v, err := extract(data)
+		if err != nil {
+			return 0
+		}
+		return uint64(v)
+	}
+}
+
+func AsUint64(f FieldAccessor) (func(Data) uint64, error) {
+	switch f.Type() {
+	default:
+		return nil, fmt.Errorf("invalid field type for AsUint64: %s", f.Type())
+	case api.Kind_Uint8:
+		return AsUint64Func(f.Uint8), nil
+	case api.Kind_Uint16:
+		return AsUint64Func(f.Uint16), nil
+	case api.Kind_Uint32:
+		return AsUint64Func(f.Uint32), nil
+	case api.Kind_Uint64:
+		return AsUint64Func(f.Uint64), nil
+	}
+}
+
 func AsFloat64Func[T constraints.Float](extract func(Data) (T, error)) func(Data) float64 {
 	return func(data Data) float64 {
 		v, err := extract(data)
@@ -65,14 +65,23 @@ type GadgetContext struct {
 
 	lock           sync.Mutex
 	dataSources    map[string]datasource.DataSource
+	dsNamespaces   map[string]string
 	dataOperators  []operators.DataOperator
 	localOperators []operators.DataOperatorInstance
 	vars           map[string]any
 	params         []*api.Param
 	loaded         bool
 	imageName      string
-	metadata       []byte
-	orasTarget     oras.ReadOnlyTarget
+	// additionalImages are run together with imageName
+	additionalImages []string
+	metadata         []byte
+	orasTarget       oras.ReadOnlyTarget
+
+	// lifecycleLock serializes pausing, resuming and updating params with
+	// starting and stopping the operators
+	lifecycleLock sync.Mutex
+	started       bool
+	paused        bool
 }
 
 func New(
@@ -87,9 +96,10 @@ func New(
 		args:   []string{},
 		logger: logger.DefaultLogger(),
 
-		imageName:   imageName,
-		dataSources: make(map[string]datasource.DataSource),
-		vars:        make(map[string]any),
+		imageName:    imageName,
+		dataSources:  make(map[string]datasource.DataSource),
+		dsNamespaces: make(map[string]string),
+		vars:         make(map[string]any),
 	}
 	for _, option := range options {
 		option(gadgetContext)
@@ -145,6 +155,10 @@ func (c *GadgetContext) ImageName() string {
 	return c.imageName
 }
 
+func (c *GadgetContext) ImageNames() []string {
+	return append([]string{c.imageName}, c.additionalImages...)
+}
+
 func (c *GadgetContext) DataOperators() []operators.DataOperator {
 	return slices.Clone(c.dataOperators)
 }
@@ -167,28 +181,49 @@ func (c *GadgetContext) UseInstance() bool {
 }
 
 func (c *GadgetContext) RegisterDataSource(t datasource.Type, name string) (datasource.DataSource, error) {
+	cfg, _ := c.GetVar("config")
+	return c.registerDataSource(t, name, "", cfg)
+}
+
+// registerDataSource registers a data source, prefixing its name with
+// namespace, if set; its configuration is looked up by name in cfg
+func (c *GadgetContext) registerDataSource(t datasource.Type, name, namespace string, cfg any) (datasource.DataSource, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	fullName := name
+	if namespace != "" {
+		fullName = namespace + "." + name
+	}
+
 	options := make([]datasource.DataSourceOption, 0)
-	if cfg, ok := c.GetVar("config"); ok {
-		if v, ok := cfg.(*viper.Viper); ok {
-			sub := v.Sub("datasources." + name)
-			if sub != nil {
-				options = append(options, datasource.WithConfig(sub))
-			}
+	if v, ok := cfg.(*viper.Viper); ok {
+		sub := v.Sub("datasources." + name)
+		if sub != nil {
+			options = append(options, datasource.WithConfig(sub))
 		}
 	}
 
-	ds, err := datasource.New(t, name, options...)
+	ds, err := datasource.New(t, fullName, options...)
 	if err != nil {
 		return nil, fmt.Errorf("creating DataSource: %w", err)
 	}
 
-	c.dataSources[name] = ds
+	c.dataSources[fullName] = ds
+	if namespace != "" {
+		c.dsNamespaces[fullName] = namespace
+	}
 	return ds, nil
 }
 
+// dataSourceNamespaces returns the namespaces of all data sources registered
+// by images run together in this context
+func (c *GadgetContext) dataSourceNamespaces() map[string]string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return maps.Clone(c.dsNamespaces)
+}
+
 func (c *GadgetContext) getDataSources(all bool) map[string]datasource.DataSource {
 	c.lock.Lock()
 	defer c.lock.Unlock()
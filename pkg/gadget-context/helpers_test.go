@@ -52,6 +52,10 @@ func (s *fakeOperator) InstanceParams() api.Params {
 			Key:          "foo",
 			DefaultValue: "567",
 		},
+		{
+			Key:  "bar",
+			Tags: []string{api.TagLiveUpdate},
+		},
 	}
 }
 
@@ -94,3 +98,20 @@ func (s *fakeOperator) PostStop(operators.GadgetContext) error {
 func (s *fakeOperator) Close(operators.GadgetContext) error {
 	return s.runMethod("close")
 }
+
+func (s *fakeOperator) Pause(operators.GadgetContext) error {
+	return s.runMethod("pause")
+}
+
+func (s *fakeOperator) Resume(operators.GadgetContext) error {
+	return s.runMethod("resume")
+}
+
+func (s *fakeOperator) UpdateParams(_ operators.GadgetContext, paramValues api.ParamValues) error {
+	for k, v := range paramValues {
+		if err := s.runMethod("update_" + k + "=" + v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetcontext
+
+import (
+	"bytes"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// imageContext is the view of a GadgetContext that the image operators of one
+// of several images run together get. Data sources registered through it are
+// prefixed with the namespace of the image, so that images can use the same
+// names for their data sources. The configuration of the image (its metadata)
+// is kept separate from the one of other images; other variables are shared
+// with the whole context, so that operators can still hand over things like
+// the mount namespace filter map to all images.
+type imageContext struct {
+	*GadgetContext
+
+	imageName string
+	namespace string
+
+	lock sync.Mutex
+	vars map[string]any
+}
+
+// ImageContext returns a view of the context for imageName; it is used when
+// running several images in the same context.
+func (c *GadgetContext) ImageContext(imageName string, namespace string) operators.GadgetContext {
+	return &imageContext{
+		GadgetContext: c,
+		imageName:     imageName,
+		namespace:     namespace,
+		vars:          make(map[string]any),
+	}
+}
+
+func (c *imageContext) ImageName() string {
+	return c.imageName
+}
+
+func (c *imageContext) RegisterDataSource(t datasource.Type, name string) (datasource.DataSource, error) {
+	cfg, _ := c.GetVar("config")
+	return c.GadgetContext.registerDataSource(t, name, c.namespace, cfg)
+}
+
+// GetDataSources returns the data sources of the image by the name it used to
+// register them, as well as the data sources of the context that don't belong
+// to any image.
+func (c *imageContext) GetDataSources() map[string]datasource.DataSource {
+	namespaces := c.GadgetContext.dataSourceNamespaces()
+
+	ret := c.GadgetContext.GetDataSources()
+	own := make(map[string]datasource.DataSource)
+	for name, ds := range ret {
+		namespace, ok := namespaces[name]
+		if !ok {
+			continue
+		}
+		delete(ret, name)
+		if namespace == c.namespace {
+			own[strings.TrimPrefix(name, namespace+".")] = ds
+		}
+	}
+
+	// Data sources of the image hide the ones of the context with the same name
+	maps.Copy(ret, own)
+	return ret
+}
+
+// isImageVar returns whether a variable is stored for the image (local) and/or
+// for the whole context (shared); maps are stored in both, with the image
+// seeing its own ones first
+func isImageVar(varName string) (local bool, shared bool) {
+	switch {
+	case varName == "config":
+		return true, false
+	case strings.HasPrefix(varName, operators.MapPrefix):
+		return true, true
+	}
+	return false, true
+}
+
+func (c *imageContext) SetVar(varName string, value any) {
+	local, shared := isImageVar(varName)
+	if local {
+		c.lock.Lock()
+		c.vars[varName] = value
+		c.lock.Unlock()
+	}
+	if shared {
+		c.GadgetContext.SetVar(varName, value)
+	}
+}
+
+func (c *imageContext) GetVar(varName string) (any, bool) {
+	local, shared := isImageVar(varName)
+	if local {
+		c.lock.Lock()
+		res, ok := c.vars[varName]
+		c.lock.Unlock()
+		if ok || !shared {
+			return res, ok
+		}
+	}
+	return c.GadgetContext.GetVar(varName)
+}
+
+// SetMetadata stores the metadata of the image as its configuration
+func (c *imageContext) SetMetadata(m []byte) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(bytes.NewReader(m))
+	if err != nil {
+		c.Logger().Warnf("unmarshalling metadata of %q: %v", c.imageName, err)
+		return
+	}
+	c.Logger().Debugf("loaded metadata of %q as config", c.imageName)
+	c.SetVar("config", v)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetcontext
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+func TestImageContext(t *testing.T) {
+	ctx := New(t.Context(), "trace_exec", WithAdditionalImages("trace_tcp"))
+	require.Equal(t, []string{"trace_exec", "trace_tcp"}, ctx.ImageNames())
+
+	execCtx := ctx.ImageContext("trace_exec", "trace_exec")
+	tcpCtx := ctx.ImageContext("trace_tcp", "trace_tcp")
+	assert.Equal(t, "trace_exec", execCtx.ImageName())
+	assert.Equal(t, "trace_tcp", tcpCtx.ImageName())
+
+	execCtx.SetMetadata([]byte(`
+datasources:
+  events:
+    annotations:
+      foo: exec
+`))
+	tcpCtx.SetMetadata([]byte(`
+datasources:
+  events:
+    annotations:
+      foo: tcp
+`))
+
+	// Both images use the same name for their data sources
+	execDs, err := execCtx.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	tcpDs, err := tcpCtx.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	otherDs, err := ctx.RegisterDataSource(datasource.TypeSingle, "other")
+	require.NoError(t, err)
+
+	assert.Equal(t, "trace_exec.events", execDs.Name())
+	assert.Equal(t, "exec", execDs.Annotations()["foo"])
+	assert.Equal(t, "trace_tcp.events", tcpDs.Name())
+	assert.Equal(t, "tcp", tcpDs.Annotations()["foo"])
+
+	assert.Equal(t, map[string]datasource.DataSource{
+		"trace_exec.events": execDs,
+		"trace_tcp.events":  tcpDs,
+		"other":             otherDs,
+	}, ctx.GetDataSources())
+	assert.Equal(t, map[string]datasource.DataSource{
+		"events": execDs,
+		"other":  otherDs,
+	}, execCtx.GetDataSources())
+	assert.Equal(t, map[string]datasource.DataSource{
+		"events": tcpDs,
+		"other":  otherDs,
+	}, tcpCtx.GetDataSources())
+
+	// The config is kept per image
+	_, ok := ctx.GetVar("config")
+	assert.False(t, ok)
+	cfg, ok := execCtx.GetVar("config")
+	require.True(t, ok)
+	assert.Equal(t, "exec", cfg.(*viper.Viper).GetString("datasources.events.annotations.foo"))
+
+	// Maps are kept per image and shared with the context
+	execCtx.SetVar(operators.MapPrefix+"map", "exec")
+	tcpCtx.SetVar(operators.MapPrefix+"map", "tcp")
+	v, _ := execCtx.GetVar(operators.MapPrefix + "map")
+	assert.Equal(t, "exec", v)
+	v, _ = ctx.GetVar(operators.MapPrefix + "map")
+	assert.Equal(t, "tcp", v)
+
+	// Other variables are shared
+	ctx.SetVar("shared", 1)
+	v, _ = tcpCtx.GetVar("shared")
+	assert.Equal(t, 1, v)
+	execCtx.SetVar("shared", 2)
+	v, _ = ctx.GetVar("shared")
+	assert.Equal(t, 2, v)
+}
@@ -87,3 +87,18 @@ func WithName(name string) Option {
 		gadgetCtx.name = name
 	}
 }
+
+// WithAdditionalImages adds images to be run together with the main image in
+// the same context
+func WithAdditionalImages(images ...string) Option {
+	return func(gadgetCtx *GadgetContext) {
+		gadgetCtx.additionalImages = append(gadgetCtx.additionalImages, images...)
+	}
+}
+
+// WithPaused makes the gadget pause its operators right after starting them
+func WithPaused(val bool) Option {
+	return func(gadgetCtx *GadgetContext) {
+		gadgetCtx.paused = val
+	}
+}
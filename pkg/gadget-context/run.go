@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 
 	"github.com/spf13/viper"
@@ -242,13 +243,26 @@ func (c *GadgetContext) Run(paramValues api.ParamValues) error {
 		return fmt.Errorf("pre-starting operators: %w", err)
 	}
 
+	c.lifecycleLock.Lock()
 	if err := c.start(); err != nil {
+		c.lifecycleLock.Unlock()
 		return fmt.Errorf("starting operators: %w", err)
 	}
+	c.started = true
+	if c.paused {
+		if err := c.pauseOperators(); err != nil {
+			c.Logger().Warnf("pausing operators: %v", err)
+		}
+	}
+	c.lifecycleLock.Unlock()
 
 	c.Logger().Debugf("running...")
 	WaitForTimeoutOrDone(c)
 
+	c.lifecycleLock.Lock()
+	c.started = false
+	c.lifecycleLock.Unlock()
+
 	var errs []error
 
 	if err := c.preStop(); err != nil {
@@ -261,9 +275,135 @@ func (c *GadgetContext) Run(paramValues api.ParamValues) error {
 		errs = append(errs, fmt.Errorf("post-stopping operators: %w", err))
 	}
 
+	c.reportLostData()
+
+	return errors.Join(errs...)
+}
+
+func (c *GadgetContext) pauseOperators() error {
+	var errs []error
+
+	// Pause in reverse order
+	for i := len(c.localOperators) - 1; i >= 0; i-- {
+		opInst := c.localOperators[i]
+		if pauser, ok := opInst.(operators.Pauser); ok {
+			c.Logger().Debugf("pausing op %q", opInst.Name())
+			if err := pauser.Pause(c); err != nil {
+				errs = append(errs, fmt.Errorf("pausing operator %q: %w", opInst.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *GadgetContext) resumeOperators() error {
+	var errs []error
+
+	for _, opInst := range c.localOperators {
+		if pauser, ok := opInst.(operators.Pauser); ok {
+			c.Logger().Debugf("resuming op %q", opInst.Name())
+			if err := pauser.Resume(c); err != nil {
+				errs = append(errs, fmt.Errorf("resuming operator %q: %w", opInst.Name(), err))
+			}
+		}
+	}
 	return errors.Join(errs...)
 }
 
+// Pause pauses all operators that support it, like detaching eBPF programs
+// while keeping them loaded. If the gadget hasn't been started yet, the
+// operators will be paused right after starting them.
+func (c *GadgetContext) Pause() error {
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	if c.paused {
+		return nil
+	}
+	c.paused = true
+	if !c.started {
+		return nil
+	}
+	return c.pauseOperators()
+}
+
+// Resume resumes all operators paused by Pause
+func (c *GadgetContext) Resume() error {
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	if !c.paused {
+		return nil
+	}
+	c.paused = false
+	if !c.started {
+		return nil
+	}
+	return c.resumeOperators()
+}
+
+// Paused returns whether the gadget has been paused
+func (c *GadgetContext) Paused() bool {
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+	return c.paused
+}
+
+// UpdateParams hands over new values for params to the operators of a running
+// gadget. Only params tagged with api.TagLiveUpdate can be updated. All values
+// are validated before any operator gets to see them.
+func (c *GadgetContext) UpdateParams(paramValues api.ParamValues) error {
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	if !c.started {
+		return errors.New("gadget is not running")
+	}
+
+	params := c.Params()
+	for key, value := range paramValues {
+		idx := slices.IndexFunc(params, func(p *api.Param) bool {
+			return p.Prefix+p.Key == key
+		})
+		if idx < 0 {
+			return fmt.Errorf("unknown param %q", key)
+		}
+		p := params[idx]
+		if !slices.Contains(p.Tags, api.TagLiveUpdate) {
+			return fmt.Errorf("param %q cannot be updated while the gadget is running", key)
+		}
+		if err := apihelpers.Validate(api.Params{p}, api.ParamValues{p.Key: value}); err != nil {
+			return fmt.Errorf("validating param %q: %w", key, err)
+		}
+	}
+
+	for _, opInst := range c.localOperators {
+		opParamValues := paramValues.ExtractPrefixedValues(fmt.Sprintf("operator.%s", opInst.Name()))
+		if len(opParamValues) == 0 {
+			continue
+		}
+		updater, ok := opInst.(operators.ParamUpdater)
+		if !ok {
+			return fmt.Errorf("operator %q doesn't support updating params", opInst.Name())
+		}
+		c.Logger().Debugf("updating params of op %q", opInst.Name())
+		if err := updater.UpdateParams(c, opParamValues); err != nil {
+			return fmt.Errorf("updating params of operator %q: %w", opInst.Name(), err)
+		}
+	}
+	return nil
+}
+
+// reportLostData logs the number of events that have been lost or dropped for
+// each data source during the run
+func (c *GadgetContext) reportLostData() {
+	for name, ds := range c.GetAllDataSources() {
+		if lost := ds.LostDataCount(); lost > 0 {
+			c.Logger().Warnf("data source %q: %d events lost or dropped", name, lost)
+		}
+	}
+}
+
 var udCtrRunningGadgets, _ = metrics.Int64UpDownCounter("ig_gadgets_running",
 	metric.WithDescription("Number of running gadgets"),
 	metric.WithUnit("{instance}"),
@@ -21,7 +21,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
 )
 
 func TestRun(t *testing.T) {
@@ -312,3 +314,101 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+// runUntilStarted runs gadgetCtx in the background and returns once all
+// operators have been started; the returned function stops the gadget and
+// returns the result of Run
+func runUntilStarted(t *testing.T, gadgetCtx *GadgetContext) func() error {
+	started := make(chan struct{})
+	gadgetCtx.dataOperators = append(gadgetCtx.dataOperators, simple.New("started",
+		simple.WithPriority(1000),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			close(started)
+			return nil
+		}),
+	))
+
+	done := make(chan error)
+	go func() {
+		done <- gadgetCtx.Run(api.ParamValues{})
+	}()
+
+	select {
+	case <-started:
+	case err := <-done:
+		t.Fatalf("gadget stopped before being started: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for gadget to start")
+	}
+
+	return func() error {
+		gadgetCtx.Cancel()
+		return <-done
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	calls := []string{}
+	op1 := &fakeOperator{name: "op1", priority: 1, called: &calls}
+	op2 := &fakeOperator{name: "op2", priority: 2, called: &calls}
+
+	// Pausing before starting pauses the operators right after starting them
+	gadgetCtx := New(context.Background(), "", WithDataOperators(op1, op2), WithPaused(true))
+	stop := runUntilStarted(t, gadgetCtx)
+
+	require.True(t, gadgetCtx.Paused())
+	require.NoError(t, gadgetCtx.Pause())
+	require.NoError(t, gadgetCtx.Resume())
+	require.NoError(t, gadgetCtx.Resume())
+	require.False(t, gadgetCtx.Paused())
+	require.NoError(t, gadgetCtx.Pause())
+	require.NoError(t, stop())
+
+	require.Equal(t, []string{
+		"op1_prestart",
+		"op2_prestart",
+		"op1_start",
+		"op2_start",
+
+		// WithPaused
+		"op2_pause",
+		"op1_pause",
+
+		// Resume; the second call is a no-op
+		"op1_resume",
+		"op2_resume",
+
+		// Pause in inverse order
+		"op2_pause",
+		"op1_pause",
+
+		"op2_stop",
+		"op1_stop",
+		"op2_poststop",
+		"op1_poststop",
+		"op2_close",
+		"op1_close",
+	}, calls)
+}
+
+func TestUpdateParams(t *testing.T) {
+	calls := []string{}
+	op1 := &fakeOperator{name: "op1", priority: 1, called: &calls}
+	op2 := &fakeOperator{name: "op2", priority: 2, called: &calls, fails: []string{"update_bar=fail"}}
+
+	gadgetCtx := New(context.Background(), "", WithDataOperators(op1, op2))
+	require.Error(t, gadgetCtx.UpdateParams(api.ParamValues{"operator.op1.bar": "abc"}), "not running")
+
+	stop := runUntilStarted(t, gadgetCtx)
+
+	require.NoError(t, gadgetCtx.UpdateParams(api.ParamValues{"operator.op1.bar": "abc"}))
+	require.Error(t, gadgetCtx.UpdateParams(api.ParamValues{"operator.op1.foo": "123"}), "not live-updatable")
+	require.Error(t, gadgetCtx.UpdateParams(api.ParamValues{"operator.op1.baz": "123"}), "unknown param")
+	require.Error(t, gadgetCtx.UpdateParams(api.ParamValues{"operator.op2.bar": "fail"}), "operator failure")
+	require.NoError(t, stop())
+
+	require.Error(t, gadgetCtx.UpdateParams(api.ParamValues{"operator.op1.bar": "def"}), "stopped")
+
+	require.Contains(t, calls, "op1_update_bar=abc")
+	require.NotContains(t, calls, "op1_update_bar=def")
+}
@@ -85,6 +85,58 @@ func TestGetStringValuesPerDataSource(t *testing.T) {
 	}
 }
 
+func TestGetListValuesPerDataSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    map[string][]string
+		expectedErr bool
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: map[string][]string{},
+		},
+		{
+			name:     "valid without datasource",
+			input:    "comm, pid",
+			expected: map[string][]string{"": {"comm", "pid"}},
+		},
+		{
+			name:     "valid with multiple datasources",
+			input:    "open:comm,fname;exec:pid",
+			expected: map[string][]string{"open": {"comm", "fname"}, "exec": {"pid"}},
+		},
+		{
+			name:     "valid with namespaced datasource",
+			input:    "trace_open.open:comm",
+			expected: map[string][]string{"trace_open.open": {"comm"}},
+		},
+		{
+			name:     "valid with empty elements",
+			input:    "open:comm,,;exec:",
+			expected: map[string][]string{"open": {"comm"}},
+		},
+		{
+			name:        "invalid mixing datasource and no datasource",
+			input:       "comm;exec:pid",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := GetListValuesPerDataSource(test.input)
+			if test.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.expected, got)
+		})
+	}
+}
+
 func TestGetIntValuesPerDataSource(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -123,6 +123,36 @@ func GetStringValuesPerDataSource(s string) (map[string]string, error) {
 	return res, nil
 }
 
+// GetListValuesPerDataSource will separate a string and extract per-datasource lists of values. It expects a string
+// like `datasource1:value1,value2;datasource2:value3` or `value1,value2` (datasource is optional - this will lead to
+// an empty key). Empty values are ignored.
+func GetListValuesPerDataSource(s string) (map[string][]string, error) {
+	res := make(map[string][]string)
+	for _, entry := range strings.Split(s, ";") {
+		dsName, list, found := strings.Cut(entry, ":")
+		if !found {
+			list = dsName
+			dsName = ""
+		}
+		values := make([]string, 0)
+		for _, v := range strings.Split(list, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) > 0 {
+			res[dsName] = values
+		}
+	}
+	// Check edge cases
+	if _, ok := res[""]; ok {
+		if len(res) > 1 {
+			return nil, fmt.Errorf("mixed values with and without specifying data source")
+		}
+	}
+	return res, nil
+}
+
 // GetIntValuesPerDataSource works like GetStringValuesPerDataSource, but will return int values instead
 func GetIntValuesPerDataSource(s string) (map[string]int, error) {
 	var err error
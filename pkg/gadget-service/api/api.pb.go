@@ -131,6 +131,9 @@ type GadgetRunRequest struct {
 	Args []string `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
 	// used to inform the server about the expected protocol version
 	Version uint32 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	// additionalImageNames are run together with imageName in the same gadget
+	// context; their data sources are prefixed with the name of the image
+	AdditionalImageNames []string `protobuf:"bytes,5,rep,name=additionalImageNames,proto3" json:"additionalImageNames,omitempty"`
 	// sets the requested log level (see pkg/logger/logger.go)
 	LogLevel uint32 `protobuf:"varint,12,opt,name=logLevel,proto3" json:"logLevel,omitempty"`
 	// time that a gadget should run; use 0, if the gadget should run until it's being
@@ -198,6 +201,13 @@ func (x *GadgetRunRequest) GetVersion() uint32 {
 	return 0
 }
 
+func (x *GadgetRunRequest) GetAdditionalImageNames() []string {
+	if x != nil {
+		return x.AdditionalImageNames
+	}
+	return nil
+}
+
 func (x *GadgetRunRequest) GetLogLevel() uint32 {
 	if x != nil {
 		return x.LogLevel
@@ -1189,7 +1199,18 @@ type Field struct {
 	Parent uint32 `protobuf:"varint,11,opt,name=parent,proto3" json:"parent,omitempty"`
 	// order determines the default position of this field when
 	// ordering multiple fields
-	Order         int32 `protobuf:"varint,12,opt,name=order,proto3" json:"order,omitempty"`
+	Order int32 `protobuf:"varint,12,opt,name=order,proto3" json:"order,omitempty"`
+	// bitOffset is the offset in bits of the value of a bitfield
+	// inside the integer of size bytes at offs; only used if
+	// bitSize is set
+	BitOffset uint32 `protobuf:"varint,13,opt,name=bitOffset,proto3" json:"bitOffset,omitempty"`
+	// bitSize is the number of bits of a bitfield; zero if the
+	// field is not a bitfield
+	BitSize uint32 `protobuf:"varint,14,opt,name=bitSize,proto3" json:"bitSize,omitempty"`
+	// arrayDims holds the dimensions of multidimensional arrays
+	// and arrays of structs, outermost first; for arrays of
+	// structs the sub fields hold the elements
+	ArrayDims     []uint32 `protobuf:"varint,15,rep,packed,name=arrayDims,proto3" json:"arrayDims,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1308,6 +1329,27 @@ func (x *Field) GetOrder() int32 {
 	return 0
 }
 
+func (x *Field) GetBitOffset() uint32 {
+	if x != nil {
+		return x.BitOffset
+	}
+	return 0
+}
+
+func (x *Field) GetBitSize() uint32 {
+	if x != nil {
+		return x.BitSize
+	}
+	return 0
+}
+
+func (x *Field) GetArrayDims() []uint32 {
+	if x != nil {
+		return x.ArrayDims
+	}
+	return nil
+}
+
 type GetGadgetInfoRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// params are the gadget's parameters
@@ -1318,8 +1360,10 @@ type GetGadgetInfoRequest struct {
 	Flags   uint32 `protobuf:"varint,4,opt,name=flags,proto3" json:"flags,omitempty"`
 	// can be used to include more info for debugging (e.g., in ig image inspect)
 	RequestExtraInfo bool `protobuf:"varint,5,opt,name=requestExtraInfo,proto3" json:"requestExtraInfo,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// see GadgetRunRequest.additionalImageNames
+	AdditionalImageNames []string `protobuf:"bytes,6,rep,name=additionalImageNames,proto3" json:"additionalImageNames,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *GetGadgetInfoRequest) Reset() {
@@ -1387,6 +1431,13 @@ func (x *GetGadgetInfoRequest) GetRequestExtraInfo() bool {
 	return false
 }
 
+func (x *GetGadgetInfoRequest) GetAdditionalImageNames() []string {
+	if x != nil {
+		return x.AdditionalImageNames
+	}
+	return nil
+}
+
 type GetGadgetInfoResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	GadgetInfo    *GadgetInfo            `protobuf:"bytes,1,opt,name=gadgetInfo,proto3" json:"gadgetInfo,omitempty"`
@@ -1584,7 +1635,10 @@ type GadgetInstance struct {
 	// name is a (non-unique) string assigned to a gadget, set by the client
 	Name string `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
 	// nodes is a list of nodes the gadget should run on; if empty, all nodes will run the gadget
-	Nodes         []string `protobuf:"bytes,5,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Nodes []string `protobuf:"bytes,5,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	// paused is set if the eBPF programs of the instance are detached; the instance keeps its ID, configuration and
+	// event buffer while paused
+	Paused        bool `protobuf:"varint,7,opt,name=paused,proto3" json:"paused,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1661,6 +1715,13 @@ func (x *GadgetInstance) GetNodes() []string {
 	return nil
 }
 
+func (x *GadgetInstance) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
 type ListGadgetInstanceResponse struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	GadgetInstances []*GadgetInstance      `protobuf:"bytes,1,rep,name=gadgetInstances,proto3" json:"gadgetInstances,omitempty"`
@@ -1749,6 +1810,60 @@ func (x *GadgetInstanceId) GetId() string {
 	return ""
 }
 
+type UpdateGadgetInstanceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// paramValues holds the new values for params of the instance; only params that are tagged as live-updatable can
+	// be changed
+	ParamValues   map[string]string `protobuf:"bytes,2,rep,name=paramValues,proto3" json:"paramValues,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateGadgetInstanceRequest) Reset() {
+	*x = UpdateGadgetInstanceRequest{}
+	mi := &file_api_api_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateGadgetInstanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateGadgetInstanceRequest) ProtoMessage() {}
+
+func (x *UpdateGadgetInstanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_api_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateGadgetInstanceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateGadgetInstanceRequest) Descriptor() ([]byte, []int) {
+	return file_api_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdateGadgetInstanceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateGadgetInstanceRequest) GetParamValues() map[string]string {
+	if x != nil {
+		return x.ParamValues
+	}
+	return nil
+}
+
 type StatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Result        int32                  `protobuf:"varint,1,opt,name=result,proto3" json:"result,omitempty"`
@@ -1759,7 +1874,7 @@ type StatusResponse struct {
 
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
-	mi := &file_api_api_proto_msgTypes[24]
+	mi := &file_api_api_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1771,7 +1886,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_api_proto_msgTypes[24]
+	mi := &file_api_api_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1784,7 +1899,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_api_api_proto_rawDescGZIP(), []int{24}
+	return file_api_api_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *StatusResponse) GetResult() int32 {
@@ -1805,12 +1920,13 @@ var File_api_api_proto protoreflect.FileDescriptor
 
 const file_api_api_proto_rawDesc = "" +
 	"\n" +
-	"\rapi/api.proto\x12\x03api\"\x9e\x02\n" +
+	"\rapi/api.proto\x12\x03api\"\xd2\x02\n" +
 	"\x10GadgetRunRequest\x12\x1c\n" +
 	"\timageName\x18\x01 \x01(\tR\timageName\x12H\n" +
 	"\vparamValues\x18\x02 \x03(\v2&.api.GadgetRunRequest.ParamValuesEntryR\vparamValues\x12\x12\n" +
 	"\x04args\x18\x03 \x03(\tR\x04args\x12\x18\n" +
-	"\aversion\x18\x04 \x01(\rR\aversion\x12\x1a\n" +
+	"\aversion\x18\x04 \x01(\rR\aversion\x122\n" +
+	"\x14additionalImageNames\x18\x05 \x03(\tR\x14additionalImageNames\x12\x1a\n" +
 	"\blogLevel\x18\f \x01(\rR\blogLevel\x12\x18\n" +
 	"\atimeout\x18\r \x01(\x03R\atimeout\x1a>\n" +
 	"\x10ParamValuesEntry\x12\x10\n" +
@@ -1896,7 +2012,7 @@ const file_api_api_proto_rawDesc = "" +
 	"\x05flags\x18\a \x01(\rR\x05flags\x1a>\n" +
 	"\x10AnnotationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8f\x03\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe5\x03\n" +
 	"\x05Field\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
 	"\bfullName\x18\x02 \x01(\tR\bfullName\x12\x14\n" +
@@ -1910,16 +2026,20 @@ const file_api_api_proto_rawDesc = "" +
 	"\vannotations\x18\n" +
 	" \x03(\v2\x1b.api.Field.AnnotationsEntryR\vannotations\x12\x16\n" +
 	"\x06parent\x18\v \x01(\rR\x06parent\x12\x14\n" +
-	"\x05order\x18\f \x01(\x05R\x05order\x1a>\n" +
+	"\x05order\x18\f \x01(\x05R\x05order\x12\x1c\n" +
+	"\tbitOffset\x18\r \x01(\rR\tbitOffset\x12\x18\n" +
+	"\abitSize\x18\x0e \x01(\rR\abitSize\x12\x1c\n" +
+	"\tarrayDims\x18\x0f \x03(\rR\tarrayDims\x1a>\n" +
 	"\x10AnnotationsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9e\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd2\x02\n" +
 	"\x14GetGadgetInfoRequest\x12L\n" +
 	"\vparamValues\x18\x01 \x03(\v2*.api.GetGadgetInfoRequest.ParamValuesEntryR\vparamValues\x12\x1c\n" +
 	"\timageName\x18\x02 \x01(\tR\timageName\x12\x18\n" +
 	"\aversion\x18\x03 \x01(\rR\aversion\x12\x14\n" +
 	"\x05flags\x18\x04 \x01(\rR\x05flags\x12*\n" +
-	"\x10requestExtraInfo\x18\x05 \x01(\bR\x10requestExtraInfo\x1a>\n" +
+	"\x10requestExtraInfo\x18\x05 \x01(\bR\x10requestExtraInfo\x122\n" +
+	"\x14additionalImageNames\x18\x06 \x03(\tR\x14additionalImageNames\x1a>\n" +
 	"\x10ParamValuesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"H\n" +
@@ -1933,18 +2053,25 @@ const file_api_api_proto_rawDesc = "" +
 	"\x1cCreateGadgetInstanceResponse\x12\x16\n" +
 	"\x06result\x18\x01 \x01(\x05R\x06result\x12;\n" +
 	"\x0egadgetInstance\x18\x02 \x01(\v2\x13.api.GadgetInstanceR\x0egadgetInstance\"\x1c\n" +
-	"\x1aListGadgetInstancesRequest\"\xbb\x01\n" +
+	"\x1aListGadgetInstancesRequest\"\xd3\x01\n" +
 	"\x0eGadgetInstance\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
 	"\fgadgetConfig\x18\x02 \x01(\v2\x15.api.GadgetRunRequestR\fgadgetConfig\x12\x12\n" +
 	"\x04tags\x18\x03 \x03(\tR\x04tags\x12 \n" +
 	"\vtimeCreated\x18\x04 \x01(\x03R\vtimeCreated\x12\x12\n" +
 	"\x04name\x18\x06 \x01(\tR\x04name\x12\x14\n" +
-	"\x05nodes\x18\x05 \x03(\tR\x05nodes\"[\n" +
+	"\x05nodes\x18\x05 \x03(\tR\x05nodes\x12\x16\n" +
+	"\x06paused\x18\a \x01(\bR\x06paused\"[\n" +
 	"\x1aListGadgetInstanceResponse\x12=\n" +
 	"\x0fgadgetInstances\x18\x01 \x03(\v2\x13.api.GadgetInstanceR\x0fgadgetInstances\"\"\n" +
 	"\x10GadgetInstanceId\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"B\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xc2\x01\n" +
+	"\x1bUpdateGadgetInstanceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12S\n" +
+	"\vparamValues\x18\x02 \x03(\v21.api.UpdateGadgetInstanceRequest.ParamValuesEntryR\vparamValues\x1a>\n" +
+	"\x10ParamValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"B\n" +
 	"\x0eStatusResponse\x12\x16\n" +
 	"\x06result\x18\x01 \x01(\x05R\x06result\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage*\xb5\x01\n" +
@@ -1973,12 +2100,15 @@ const file_api_api_proto_rawDesc = "" +
 	"\aGetInfo\x12\x10.api.InfoRequest\x1a\x11.api.InfoResponse\"\x002\x99\x01\n" +
 	"\rGadgetManager\x12H\n" +
 	"\rGetGadgetInfo\x12\x19.api.GetGadgetInfoRequest\x1a\x1a.api.GetGadgetInfoResponse\"\x00\x12>\n" +
-	"\tRunGadget\x12\x19.api.GadgetControlRequest\x1a\x10.api.GadgetEvent\"\x00(\x010\x012\xda\x02\n" +
+	"\tRunGadget\x12\x19.api.GadgetControlRequest\x1a\x10.api.GadgetEvent\"\x00(\x010\x012\xb6\x04\n" +
 	"\x15GadgetInstanceManager\x12]\n" +
 	"\x14CreateGadgetInstance\x12 .api.CreateGadgetInstanceRequest\x1a!.api.CreateGadgetInstanceResponse\"\x00\x12Y\n" +
 	"\x13ListGadgetInstances\x12\x1f.api.ListGadgetInstancesRequest\x1a\x1f.api.ListGadgetInstanceResponse\"\x00\x12A\n" +
 	"\x11GetGadgetInstance\x12\x15.api.GadgetInstanceId\x1a\x13.api.GadgetInstance\"\x00\x12D\n" +
-	"\x14RemoveGadgetInstance\x12\x15.api.GadgetInstanceId\x1a\x13.api.StatusResponse\"\x00BEZCgithub.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/apib\x06proto3"
+	"\x14RemoveGadgetInstance\x12\x15.api.GadgetInstanceId\x1a\x13.api.StatusResponse\"\x00\x12C\n" +
+	"\x13PauseGadgetInstance\x12\x15.api.GadgetInstanceId\x1a\x13.api.StatusResponse\"\x00\x12D\n" +
+	"\x14ResumeGadgetInstance\x12\x15.api.GadgetInstanceId\x1a\x13.api.StatusResponse\"\x00\x12O\n" +
+	"\x14UpdateGadgetInstance\x12 .api.UpdateGadgetInstanceRequest\x1a\x13.api.StatusResponse\"\x00BEZCgithub.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/apib\x06proto3"
 
 var (
 	file_api_api_proto_rawDescOnce sync.Once
@@ -1993,7 +2123,7 @@ func file_api_api_proto_rawDescGZIP() []byte {
 }
 
 var file_api_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_api_api_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
+var file_api_api_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_api_api_proto_goTypes = []any{
 	(Kind)(0),                            // 0: api.Kind
 	(*GadgetRunRequest)(nil),             // 1: api.GadgetRunRequest
@@ -2020,56 +2150,65 @@ var file_api_api_proto_goTypes = []any{
 	(*GadgetInstance)(nil),               // 22: api.GadgetInstance
 	(*ListGadgetInstanceResponse)(nil),   // 23: api.ListGadgetInstanceResponse
 	(*GadgetInstanceId)(nil),             // 24: api.GadgetInstanceId
-	(*StatusResponse)(nil),               // 25: api.StatusResponse
-	nil,                                  // 26: api.GadgetRunRequest.ParamValuesEntry
-	nil,                                  // 27: api.GadgetInfo.AnnotationsEntry
-	nil,                                  // 28: api.ExtraInfo.DataEntry
-	nil,                                  // 29: api.DataSource.AnnotationsEntry
-	nil,                                  // 30: api.Field.AnnotationsEntry
-	nil,                                  // 31: api.GetGadgetInfoRequest.ParamValuesEntry
+	(*UpdateGadgetInstanceRequest)(nil),  // 25: api.UpdateGadgetInstanceRequest
+	(*StatusResponse)(nil),               // 26: api.StatusResponse
+	nil,                                  // 27: api.GadgetRunRequest.ParamValuesEntry
+	nil,                                  // 28: api.GadgetInfo.AnnotationsEntry
+	nil,                                  // 29: api.ExtraInfo.DataEntry
+	nil,                                  // 30: api.DataSource.AnnotationsEntry
+	nil,                                  // 31: api.Field.AnnotationsEntry
+	nil,                                  // 32: api.GetGadgetInfoRequest.ParamValuesEntry
+	nil,                                  // 33: api.UpdateGadgetInstanceRequest.ParamValuesEntry
 }
 var file_api_api_proto_depIdxs = []int32{
-	26, // 0: api.GadgetRunRequest.paramValues:type_name -> api.GadgetRunRequest.ParamValuesEntry
+	27, // 0: api.GadgetRunRequest.paramValues:type_name -> api.GadgetRunRequest.ParamValuesEntry
 	1,  // 1: api.GadgetControlRequest.runRequest:type_name -> api.GadgetRunRequest
 	4,  // 2: api.GadgetControlRequest.stopRequest:type_name -> api.GadgetStopRequest
 	2,  // 3: api.GadgetControlRequest.attachRequest:type_name -> api.GadgetAttachRequest
 	8,  // 4: api.GadgetData.data:type_name -> api.DataElement
 	8,  // 5: api.GadgetDataArray.dataArray:type_name -> api.DataElement
 	15, // 6: api.GadgetInfo.dataSources:type_name -> api.DataSource
-	27, // 7: api.GadgetInfo.annotations:type_name -> api.GadgetInfo.AnnotationsEntry
+	28, // 7: api.GadgetInfo.annotations:type_name -> api.GadgetInfo.AnnotationsEntry
 	11, // 8: api.GadgetInfo.params:type_name -> api.Param
 	13, // 9: api.GadgetInfo.extraInfo:type_name -> api.ExtraInfo
-	28, // 10: api.ExtraInfo.data:type_name -> api.ExtraInfo.DataEntry
+	29, // 10: api.ExtraInfo.data:type_name -> api.ExtraInfo.DataEntry
 	16, // 11: api.DataSource.fields:type_name -> api.Field
-	29, // 12: api.DataSource.annotations:type_name -> api.DataSource.AnnotationsEntry
+	30, // 12: api.DataSource.annotations:type_name -> api.DataSource.AnnotationsEntry
 	0,  // 13: api.Field.kind:type_name -> api.Kind
-	30, // 14: api.Field.annotations:type_name -> api.Field.AnnotationsEntry
-	31, // 15: api.GetGadgetInfoRequest.paramValues:type_name -> api.GetGadgetInfoRequest.ParamValuesEntry
+	31, // 14: api.Field.annotations:type_name -> api.Field.AnnotationsEntry
+	32, // 15: api.GetGadgetInfoRequest.paramValues:type_name -> api.GetGadgetInfoRequest.ParamValuesEntry
 	12, // 16: api.GetGadgetInfoResponse.gadgetInfo:type_name -> api.GadgetInfo
 	22, // 17: api.CreateGadgetInstanceRequest.gadgetInstance:type_name -> api.GadgetInstance
 	22, // 18: api.CreateGadgetInstanceResponse.gadgetInstance:type_name -> api.GadgetInstance
 	1,  // 19: api.GadgetInstance.gadgetConfig:type_name -> api.GadgetRunRequest
 	22, // 20: api.ListGadgetInstanceResponse.gadgetInstances:type_name -> api.GadgetInstance
-	14, // 21: api.ExtraInfo.DataEntry.value:type_name -> api.GadgetInspectAddendum
-	6,  // 22: api.BuiltInGadgetManager.GetInfo:input_type -> api.InfoRequest
-	17, // 23: api.GadgetManager.GetGadgetInfo:input_type -> api.GetGadgetInfoRequest
-	5,  // 24: api.GadgetManager.RunGadget:input_type -> api.GadgetControlRequest
-	19, // 25: api.GadgetInstanceManager.CreateGadgetInstance:input_type -> api.CreateGadgetInstanceRequest
-	21, // 26: api.GadgetInstanceManager.ListGadgetInstances:input_type -> api.ListGadgetInstancesRequest
-	24, // 27: api.GadgetInstanceManager.GetGadgetInstance:input_type -> api.GadgetInstanceId
-	24, // 28: api.GadgetInstanceManager.RemoveGadgetInstance:input_type -> api.GadgetInstanceId
-	7,  // 29: api.BuiltInGadgetManager.GetInfo:output_type -> api.InfoResponse
-	18, // 30: api.GadgetManager.GetGadgetInfo:output_type -> api.GetGadgetInfoResponse
-	3,  // 31: api.GadgetManager.RunGadget:output_type -> api.GadgetEvent
-	20, // 32: api.GadgetInstanceManager.CreateGadgetInstance:output_type -> api.CreateGadgetInstanceResponse
-	23, // 33: api.GadgetInstanceManager.ListGadgetInstances:output_type -> api.ListGadgetInstanceResponse
-	22, // 34: api.GadgetInstanceManager.GetGadgetInstance:output_type -> api.GadgetInstance
-	25, // 35: api.GadgetInstanceManager.RemoveGadgetInstance:output_type -> api.StatusResponse
-	29, // [29:36] is the sub-list for method output_type
-	22, // [22:29] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	33, // 21: api.UpdateGadgetInstanceRequest.paramValues:type_name -> api.UpdateGadgetInstanceRequest.ParamValuesEntry
+	14, // 22: api.ExtraInfo.DataEntry.value:type_name -> api.GadgetInspectAddendum
+	6,  // 23: api.BuiltInGadgetManager.GetInfo:input_type -> api.InfoRequest
+	17, // 24: api.GadgetManager.GetGadgetInfo:input_type -> api.GetGadgetInfoRequest
+	5,  // 25: api.GadgetManager.RunGadget:input_type -> api.GadgetControlRequest
+	19, // 26: api.GadgetInstanceManager.CreateGadgetInstance:input_type -> api.CreateGadgetInstanceRequest
+	21, // 27: api.GadgetInstanceManager.ListGadgetInstances:input_type -> api.ListGadgetInstancesRequest
+	24, // 28: api.GadgetInstanceManager.GetGadgetInstance:input_type -> api.GadgetInstanceId
+	24, // 29: api.GadgetInstanceManager.RemoveGadgetInstance:input_type -> api.GadgetInstanceId
+	24, // 30: api.GadgetInstanceManager.PauseGadgetInstance:input_type -> api.GadgetInstanceId
+	24, // 31: api.GadgetInstanceManager.ResumeGadgetInstance:input_type -> api.GadgetInstanceId
+	25, // 32: api.GadgetInstanceManager.UpdateGadgetInstance:input_type -> api.UpdateGadgetInstanceRequest
+	7,  // 33: api.BuiltInGadgetManager.GetInfo:output_type -> api.InfoResponse
+	18, // 34: api.GadgetManager.GetGadgetInfo:output_type -> api.GetGadgetInfoResponse
+	3,  // 35: api.GadgetManager.RunGadget:output_type -> api.GadgetEvent
+	20, // 36: api.GadgetInstanceManager.CreateGadgetInstance:output_type -> api.CreateGadgetInstanceResponse
+	23, // 37: api.GadgetInstanceManager.ListGadgetInstances:output_type -> api.ListGadgetInstanceResponse
+	22, // 38: api.GadgetInstanceManager.GetGadgetInstance:output_type -> api.GadgetInstance
+	26, // 39: api.GadgetInstanceManager.RemoveGadgetInstance:output_type -> api.StatusResponse
+	26, // 40: api.GadgetInstanceManager.PauseGadgetInstance:output_type -> api.StatusResponse
+	26, // 41: api.GadgetInstanceManager.ResumeGadgetInstance:output_type -> api.StatusResponse
+	26, // 42: api.GadgetInstanceManager.UpdateGadgetInstance:output_type -> api.StatusResponse
+	33, // [33:43] is the sub-list for method output_type
+	23, // [23:33] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_api_api_proto_init() }
@@ -2088,7 +2227,7 @@ func file_api_api_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_api_proto_rawDesc), len(file_api_api_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   31,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   3,
 		},
@@ -256,6 +256,9 @@ type GadgetInstanceManagerClient interface {
 	ListGadgetInstances(ctx context.Context, in *ListGadgetInstancesRequest, opts ...grpc.CallOption) (*ListGadgetInstanceResponse, error)
 	GetGadgetInstance(ctx context.Context, in *GadgetInstanceId, opts ...grpc.CallOption) (*GadgetInstance, error)
 	RemoveGadgetInstance(ctx context.Context, in *GadgetInstanceId, opts ...grpc.CallOption) (*StatusResponse, error)
+	PauseGadgetInstance(ctx context.Context, in *GadgetInstanceId, opts ...grpc.CallOption) (*StatusResponse, error)
+	ResumeGadgetInstance(ctx context.Context, in *GadgetInstanceId, opts ...grpc.CallOption) (*StatusResponse, error)
+	UpdateGadgetInstance(ctx context.Context, in *UpdateGadgetInstanceRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 }
 
 type gadgetInstanceManagerClient struct {
@@ -302,6 +305,33 @@ func (c *gadgetInstanceManagerClient) RemoveGadgetInstance(ctx context.Context,
 	return out, nil
 }
 
+func (c *gadgetInstanceManagerClient) PauseGadgetInstance(ctx context.Context, in *GadgetInstanceId, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/api.GadgetInstanceManager/PauseGadgetInstance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gadgetInstanceManagerClient) ResumeGadgetInstance(ctx context.Context, in *GadgetInstanceId, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/api.GadgetInstanceManager/ResumeGadgetInstance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gadgetInstanceManagerClient) UpdateGadgetInstance(ctx context.Context, in *UpdateGadgetInstanceRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/api.GadgetInstanceManager/UpdateGadgetInstance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GadgetInstanceManagerServer is the server API for GadgetInstanceManager service.
 // All implementations must embed UnimplementedGadgetInstanceManagerServer
 // for forward compatibility
@@ -310,6 +340,9 @@ type GadgetInstanceManagerServer interface {
 	ListGadgetInstances(context.Context, *ListGadgetInstancesRequest) (*ListGadgetInstanceResponse, error)
 	GetGadgetInstance(context.Context, *GadgetInstanceId) (*GadgetInstance, error)
 	RemoveGadgetInstance(context.Context, *GadgetInstanceId) (*StatusResponse, error)
+	PauseGadgetInstance(context.Context, *GadgetInstanceId) (*StatusResponse, error)
+	ResumeGadgetInstance(context.Context, *GadgetInstanceId) (*StatusResponse, error)
+	UpdateGadgetInstance(context.Context, *UpdateGadgetInstanceRequest) (*StatusResponse, error)
 	mustEmbedUnimplementedGadgetInstanceManagerServer()
 }
 
@@ -329,6 +362,15 @@ func (UnimplementedGadgetInstanceManagerServer) GetGadgetInstance(context.Contex
 func (UnimplementedGadgetInstanceManagerServer) RemoveGadgetInstance(context.Context, *GadgetInstanceId) (*StatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveGadgetInstance not implemented")
 }
+func (UnimplementedGadgetInstanceManagerServer) PauseGadgetInstance(context.Context, *GadgetInstanceId) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseGadgetInstance not implemented")
+}
+func (UnimplementedGadgetInstanceManagerServer) ResumeGadgetInstance(context.Context, *GadgetInstanceId) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeGadgetInstance not implemented")
+}
+func (UnimplementedGadgetInstanceManagerServer) UpdateGadgetInstance(context.Context, *UpdateGadgetInstanceRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGadgetInstance not implemented")
+}
 func (UnimplementedGadgetInstanceManagerServer) mustEmbedUnimplementedGadgetInstanceManagerServer() {}
 
 // UnsafeGadgetInstanceManagerServer may be embedded to opt out of forward compatibility for this service.
@@ -414,6 +456,60 @@ func _GadgetInstanceManager_RemoveGadgetInstance_Handler(srv interface{}, ctx co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GadgetInstanceManager_PauseGadgetInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GadgetInstanceId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GadgetInstanceManagerServer).PauseGadgetInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.GadgetInstanceManager/PauseGadgetInstance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GadgetInstanceManagerServer).PauseGadgetInstance(ctx, req.(*GadgetInstanceId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GadgetInstanceManager_ResumeGadgetInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GadgetInstanceId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GadgetInstanceManagerServer).ResumeGadgetInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.GadgetInstanceManager/ResumeGadgetInstance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GadgetInstanceManagerServer).ResumeGadgetInstance(ctx, req.(*GadgetInstanceId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GadgetInstanceManager_UpdateGadgetInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGadgetInstanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GadgetInstanceManagerServer).UpdateGadgetInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.GadgetInstanceManager/UpdateGadgetInstance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GadgetInstanceManagerServer).UpdateGadgetInstance(ctx, req.(*UpdateGadgetInstanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _GadgetInstanceManager_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.GadgetInstanceManager",
 	HandlerType: (*GadgetInstanceManagerServer)(nil),
@@ -434,6 +530,18 @@ var _GadgetInstanceManager_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveGadgetInstance",
 			Handler:    _GadgetInstanceManager_RemoveGadgetInstance_Handler,
 		},
+		{
+			MethodName: "PauseGadgetInstance",
+			Handler:    _GadgetInstanceManager_PauseGadgetInstance_Handler,
+		},
+		{
+			MethodName: "ResumeGadgetInstance",
+			Handler:    _GadgetInstanceManager_ResumeGadgetInstance_Handler,
+		},
+		{
+			MethodName: "UpdateGadgetInstance",
+			Handler:    _GadgetInstanceManager_UpdateGadgetInstance_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/api.proto",
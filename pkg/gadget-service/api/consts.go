@@ -81,6 +81,11 @@ const (
 	TagSrcEbpf = "src:ebpf"
 )
 
+const (
+	// TagLiveUpdate marks instance params of operators that can be changed while a gadget instance is running
+	TagLiveUpdate = "live-update"
+)
+
 const (
 	FetchCountAnnotation    = "fetch-count"
 	FetchIntervalAnnotation = "fetch-interval"
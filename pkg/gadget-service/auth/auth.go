@@ -0,0 +1,370 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth implements authentication and authorization for the gadget
+// service. Clients are identified either by the common name of their (verified)
+// TLS client certificate or by a static bearer token. A policy then defines
+// which identities may run which gadget images and manage gadget instances.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+// Action is an operation on the gadget service that is subject to
+// authorization
+type Action string
+
+const (
+	// ActionRun allows running gadgets and getting information about them
+	ActionRun Action = "run"
+	// ActionAttach allows attaching to running gadget instances
+	ActionAttach Action = "attach"
+	// ActionCreateInstance allows creating (headless) gadget instances
+	ActionCreateInstance Action = "create-instance"
+	// ActionDeleteInstance allows deleting gadget instances
+	ActionDeleteInstance Action = "delete-instance"
+	// ActionUpdateInstance allows pausing, resuming and changing params of
+	// gadget instances
+	ActionUpdateInstance Action = "update-instance"
+	// ActionListInstances allows listing gadget instances and their details
+	ActionListInstances Action = "list-instances"
+)
+
+var actions = []Action{
+	ActionRun, ActionAttach, ActionCreateInstance, ActionDeleteInstance, ActionUpdateInstance, ActionListInstances,
+}
+
+const (
+	MethodTLS   = "tls"
+	MethodToken = "token"
+	MethodNone  = "none"
+
+	// AnonymousIdentity is the identity of unauthenticated clients, if
+	// those are allowed
+	AnonymousIdentity = "anonymous"
+
+	// Wildcard matches any identity, action or image in rules
+	Wildcard = "*"
+
+	// MetadataKey is the gRPC metadata key used to transport bearer tokens
+	MetadataKey = "authorization"
+
+	bearerPrefix = "Bearer "
+)
+
+// Identity is an authenticated client
+type Identity struct {
+	Name   string
+	Method string
+}
+
+func (i *Identity) String() string {
+	return fmt.Sprintf("%s:%s", i.Method, i.Name)
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx holding the given identity
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity stored in ctx, if any
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+type Token struct {
+	// Identity is the name of the identity authenticated by the token
+	Identity string `yaml:"identity"`
+	// Token is the token itself; mutually exclusive with TokenFile
+	Token string `yaml:"token"`
+	// TokenFile is a file containing the token
+	TokenFile string `yaml:"tokenFile"`
+}
+
+type Rule struct {
+	// Identities the rule applies to; Wildcard matches any identity
+	Identities []string `yaml:"identities"`
+	// Actions allowed by the rule; Wildcard allows all actions
+	Actions []Action `yaml:"actions"`
+	// Images the actions are allowed on, as patterns like
+	// "ghcr.io/inspektor-gadget/gadget/*"; an empty list or Wildcard allows
+	// all images
+	Images []string `yaml:"images"`
+}
+
+type Config struct {
+	// AllowAnonymous lets clients without token or client certificate connect
+	// using AnonymousIdentity
+	AllowAnonymous bool    `yaml:"allowAnonymous"`
+	Tokens         []Token `yaml:"tokens"`
+	Rules          []Rule  `yaml:"rules"`
+}
+
+// Auth authenticates clients and authorizes their requests
+type Auth struct {
+	allowAnonymous bool
+	// tokens maps the sha256 digest of a token to an identity
+	tokens map[[sha256.Size]byte]string
+	rules  []Rule
+
+	// NormalizeImage is used to get the canonical name of images before
+	// matching them against the rules
+	NormalizeImage func(string) (string, error)
+}
+
+// Load reads the configuration from a YAML file and creates a new Auth
+func Load(filename string) (*Auth, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading auth config: %w", err)
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("parsing auth config: %w", err)
+	}
+	return New(cfg)
+}
+
+func New(cfg *Config) (*Auth, error) {
+	a := &Auth{
+		allowAnonymous: cfg.AllowAnonymous,
+		tokens:         make(map[[sha256.Size]byte]string),
+	}
+
+	for i, t := range cfg.Tokens {
+		if t.Identity == "" {
+			return nil, fmt.Errorf("token %d: missing identity", i)
+		}
+		if t.Identity == AnonymousIdentity || t.Identity == Wildcard {
+			return nil, fmt.Errorf("token %d: reserved identity %q", i, t.Identity)
+		}
+		token := t.Token
+		if t.TokenFile != "" {
+			if token != "" {
+				return nil, fmt.Errorf("token %d: token and tokenFile are mutually exclusive", i)
+			}
+			b, err := os.ReadFile(t.TokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("token %d: reading token file: %w", i, err)
+			}
+			token = strings.TrimSpace(string(b))
+		}
+		if token == "" {
+			return nil, fmt.Errorf("token %d: empty token", i)
+		}
+		digest := sha256.Sum256([]byte(token))
+		if _, ok := a.tokens[digest]; ok {
+			return nil, fmt.Errorf("token %d: duplicate token", i)
+		}
+		a.tokens[digest] = t.Identity
+	}
+
+	for i, r := range cfg.Rules {
+		if len(r.Identities) == 0 {
+			return nil, fmt.Errorf("rule %d: missing identities", i)
+		}
+		if len(r.Actions) == 0 {
+			return nil, fmt.Errorf("rule %d: missing actions", i)
+		}
+		for _, action := range r.Actions {
+			if action != Wildcard && !slices.Contains(actions, action) {
+				return nil, fmt.Errorf("rule %d: unknown action %q", i, action)
+			}
+		}
+		for _, image := range r.Images {
+			if _, err := path.Match(image, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid image pattern %q: %w", i, image, err)
+			}
+		}
+	}
+	a.rules = cfg.Rules
+
+	return a, nil
+}
+
+// Authenticate returns the identity of the client of the given (incoming) gRPC
+// context. Bearer tokens have precedence over TLS client certificates.
+func (a *Auth) Authenticate(ctx context.Context) (*Identity, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataKey); len(values) > 0 {
+			token, ok := strings.CutPrefix(values[0], bearerPrefix)
+			if !ok {
+				return nil, errors.New("unsupported authorization scheme")
+			}
+			return a.authenticateToken(token)
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.AuthInfo != nil {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 &&
+			len(tlsInfo.State.VerifiedChains[0]) > 0 {
+			cn := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+			if cn == "" {
+				return nil, errors.New("client certificate without common name")
+			}
+			return &Identity{Name: cn, Method: MethodTLS}, nil
+		}
+	}
+
+	if a.allowAnonymous {
+		return &Identity{Name: AnonymousIdentity, Method: MethodNone}, nil
+	}
+	return nil, errors.New("no credentials provided")
+}
+
+func (a *Auth) authenticateToken(token string) (*Identity, error) {
+	digest := sha256.Sum256([]byte(token))
+	// Compare against all tokens in constant time to not leak information
+	// about valid tokens
+	var identity string
+	for d, name := range a.tokens {
+		if subtle.ConstantTimeCompare(d[:], digest[:]) == 1 {
+			identity = name
+		}
+	}
+	if identity == "" {
+		return nil, errors.New("invalid token")
+	}
+	return &Identity{Name: identity, Method: MethodToken}, nil
+}
+
+func matchImage(patterns []string, images []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if pattern == Wildcard {
+			return true
+		}
+		for _, image := range images {
+			if ok, _ := path.Match(pattern, image); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize checks whether the identity is allowed to perform the action on
+// the given image; image is ignored for actions that don't target an image.
+func (a *Auth) Authorize(id *Identity, action Action, image string) error {
+	images := []string{image}
+	if image != "" && a.NormalizeImage != nil {
+		if normalized, err := a.NormalizeImage(image); err == nil && normalized != image {
+			images = append(images, normalized)
+		}
+	}
+
+	for _, r := range a.rules {
+		if !slices.Contains(r.Identities, Wildcard) && !slices.Contains(r.Identities, id.Name) {
+			continue
+		}
+		if !slices.Contains(r.Actions, Wildcard) && !slices.Contains(r.Actions, action) {
+			continue
+		}
+		if action != ActionListInstances && !matchImage(r.Images, images) {
+			continue
+		}
+		return nil
+	}
+
+	if image != "" && action != ActionListInstances {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s %q", id, action, image)
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s", id, action)
+}
+
+// UnaryServerInterceptor authenticates clients of unary calls and stores their
+// identity in the context passed to the handler
+func (a *Auth) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id, err := a.Authenticate(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "authenticating: %v", err)
+		}
+		return handler(NewContext(ctx, id), req)
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor authenticates clients of streaming calls and stores
+// their identity in the context of the stream
+func (a *Auth) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id, err := a.Authenticate(ss.Context())
+		if err != nil {
+			return status.Errorf(codes.Unauthenticated, "authenticating: %v", err)
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: NewContext(ss.Context(), id)})
+	}
+}
+
+// TokenCredentials sends a bearer token with each request
+type TokenCredentials struct {
+	Token string
+	// RequireTLS makes gRPC refuse to send the token over connections without
+	// transport security
+	RequireTLS bool
+}
+
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{
+		MetadataKey: bearerPrefix + c.Token,
+	}, nil
+}
+
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return c.RequireTLS
+}
+
+// LoadTokenCredentials reads a token from a file
+func LoadTokenCredentials(filename string, requireTLS bool) (*TokenCredentials, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading token file: %w", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return nil, fmt.Errorf("token file %q is empty", filename)
+	}
+	return &TokenCredentials{Token: token, RequireTLS: requireTLS}, nil
+}
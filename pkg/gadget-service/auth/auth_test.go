@@ -0,0 +1,252 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func newTestAuth(t *testing.T) *Auth {
+	a, err := New(&Config{
+		Tokens: []Token{
+			{Identity: "alice", Token: "alice-secret"},
+			{Identity: "bob", Token: "bob-secret"},
+		},
+		Rules: []Rule{
+			{
+				Identities: []string{"alice"},
+				Actions:    []Action{Wildcard},
+			},
+			{
+				Identities: []string{"bob", "carol"},
+				Actions:    []Action{ActionRun, ActionListInstances},
+				Images:     []string{"ghcr.io/inspektor-gadget/gadget/trace_*"},
+			},
+			{
+				Identities: []string{Wildcard},
+				Actions:    []Action{ActionAttach},
+				Images:     []string{"ghcr.io/inspektor-gadget/gadget/top_file:*"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	a.NormalizeImage = func(image string) (string, error) {
+		if image == "trace_open" {
+			return "ghcr.io/inspektor-gadget/gadget/trace_open:latest", nil
+		}
+		return image, nil
+	}
+	return a
+}
+
+func tokenContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "Bearer "+token))
+}
+
+func tlsContext(cn string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}},
+			},
+		},
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newTestAuth(t)
+
+	id, err := a.Authenticate(tokenContext("bob-secret"))
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: "bob", Method: MethodToken}, id)
+
+	id, err = a.Authenticate(tlsContext("carol"))
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: "carol", Method: MethodTLS}, id)
+
+	// tokens have precedence over client certificates
+	ctx := metadata.NewIncomingContext(tlsContext("carol"), metadata.Pairs(MetadataKey, "Bearer alice-secret"))
+	id, err = a.Authenticate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", id.Name)
+
+	_, err = a.Authenticate(tokenContext("wrong"))
+	require.Error(t, err)
+
+	_, err = a.Authenticate(metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "Basic foo")))
+	require.Error(t, err)
+
+	_, err = a.Authenticate(tlsContext(""))
+	require.Error(t, err)
+
+	_, err = a.Authenticate(context.Background())
+	require.Error(t, err)
+
+	a.allowAnonymous = true
+	id, err = a.Authenticate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: AnonymousIdentity, Method: MethodNone}, id)
+}
+
+func TestAuthorize(t *testing.T) {
+	a := newTestAuth(t)
+
+	alice := &Identity{Name: "alice", Method: MethodToken}
+	bob := &Identity{Name: "bob", Method: MethodToken}
+	carol := &Identity{Name: "carol", Method: MethodTLS}
+	dave := &Identity{Name: "dave", Method: MethodTLS}
+
+	testCases := []struct {
+		id      *Identity
+		action  Action
+		image   string
+		allowed bool
+	}{
+		{alice, ActionRun, "myregistry.io/mygadget:v1", true},
+		{alice, ActionDeleteInstance, "trace_exec", true},
+		{alice, ActionUpdateInstance, "trace_exec", true},
+		{bob, ActionRun, "ghcr.io/inspektor-gadget/gadget/trace_exec:latest", true},
+		// the image name is normalized before matching
+		{bob, ActionRun, "trace_open", true},
+		{bob, ActionRun, "ghcr.io/inspektor-gadget/gadget/top_file:latest", false},
+		{bob, ActionCreateInstance, "ghcr.io/inspektor-gadget/gadget/trace_exec:latest", false},
+		{bob, ActionListInstances, "", true},
+		{carol, ActionRun, "ghcr.io/inspektor-gadget/gadget/trace_dns:latest", true},
+		{carol, ActionDeleteInstance, "ghcr.io/inspektor-gadget/gadget/trace_dns:latest", false},
+		{carol, ActionUpdateInstance, "ghcr.io/inspektor-gadget/gadget/trace_dns:latest", false},
+		{dave, ActionAttach, "ghcr.io/inspektor-gadget/gadget/top_file:latest", true},
+		{dave, ActionAttach, "ghcr.io/inspektor-gadget/gadget/trace_dns:latest", false},
+		{dave, ActionListInstances, "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.action)+"/"+tc.id.Name+"/"+tc.image, func(t *testing.T) {
+			err := a.Authorize(tc.id, tc.action, tc.image)
+			if tc.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	testCases := map[string]*Config{
+		"token without identity": {
+			Tokens: []Token{{Token: "foo"}},
+		},
+		"empty token": {
+			Tokens: []Token{{Identity: "foo"}},
+		},
+		"reserved identity": {
+			Tokens: []Token{{Identity: AnonymousIdentity, Token: "foo"}},
+		},
+		"duplicate token": {
+			Tokens: []Token{{Identity: "foo", Token: "foo"}, {Identity: "bar", Token: "foo"}},
+		},
+		"token and token file": {
+			Tokens: []Token{{Identity: "foo", Token: "foo", TokenFile: "/dev/null"}},
+		},
+		"missing token file": {
+			Tokens: []Token{{Identity: "foo", TokenFile: "/nonexistent"}},
+		},
+		"rule without identities": {
+			Rules: []Rule{{Actions: []Action{ActionRun}}},
+		},
+		"rule without actions": {
+			Rules: []Rule{{Identities: []string{"foo"}}},
+		},
+		"unknown action": {
+			Rules: []Rule{{Identities: []string{"foo"}, Actions: []Action{"foo"}}},
+		},
+		"invalid image pattern": {
+			Rules: []Rule{{Identities: []string{"foo"}, Actions: []Action{ActionRun}, Images: []string{"["}}},
+		},
+	}
+	for name, cfg := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0o600))
+
+	configFile := filepath.Join(dir, "auth.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+tokens:
+  - identity: ci
+    tokenFile: `+tokenFile+`
+rules:
+  - identities: [ci]
+    actions: [run]
+`), 0o600))
+
+	a, err := Load(configFile)
+	require.NoError(t, err)
+
+	id, err := a.Authenticate(tokenContext("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "ci", id.Name)
+	require.NoError(t, a.Authorize(id, ActionRun, "trace_open"))
+	require.Error(t, a.Authorize(id, ActionCreateInstance, "trace_open"))
+
+	creds, err := LoadTokenCredentials(tokenFile, true)
+	require.NoError(t, err)
+	assert.True(t, creds.RequireTransportSecurity())
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", md[MetadataKey])
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a := newTestAuth(t)
+	interceptor := a.UnaryServerInterceptor()
+
+	var id *Identity
+	handler := func(ctx context.Context, req any) (any, error) {
+		id, _ = FromContext(ctx)
+		return nil, nil
+	}
+
+	_, err := interceptor(tokenContext("alice-secret"), nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.NotNil(t, id)
+	assert.Equal(t, "alice", id.Name)
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
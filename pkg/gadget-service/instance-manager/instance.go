@@ -16,7 +16,9 @@ package instancemanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"sync"
 
 	log "github.com/sirupsen/logrus"
@@ -60,6 +62,12 @@ type GadgetInstance struct {
 	state                gadgetState
 	error                error
 	ready                chan struct{}
+
+	// gadgetCtx is set once the gadget is being run
+	gadgetCtx *gadgetcontext.GadgetContext
+	// paused is set while the gadget is paused; events that are still emitted
+	// in that state are dropped
+	paused bool
 }
 
 func (p *GadgetInstance) GadgetInfo() (*api.GadgetInfo, error) {
@@ -116,6 +124,57 @@ func (p *GadgetInstance) AddClient(client api.GadgetManager_RunGadgetServer) cha
 	return done
 }
 
+// Pause detaches the eBPF programs of the gadget without unloading them; the
+// buffered events are kept
+func (p *GadgetInstance) Pause() error {
+	p.mu.Lock()
+	p.paused = true
+	gadgetCtx := p.gadgetCtx
+	p.mu.Unlock()
+
+	if gadgetCtx == nil {
+		// Will be paused when being run
+		return nil
+	}
+	return gadgetCtx.Pause()
+}
+
+// Resume re-attaches the eBPF programs of a paused gadget
+func (p *GadgetInstance) Resume() error {
+	p.mu.Lock()
+	p.paused = false
+	gadgetCtx := p.gadgetCtx
+	p.mu.Unlock()
+
+	if gadgetCtx == nil {
+		return nil
+	}
+	return gadgetCtx.Resume()
+}
+
+// UpdateParams applies new values for live-updatable params to the running
+// gadget and stores them in its configuration
+func (p *GadgetInstance) UpdateParams(paramValues api.ParamValues) error {
+	p.mu.Lock()
+	gadgetCtx := p.gadgetCtx
+	p.mu.Unlock()
+
+	if gadgetCtx == nil {
+		return errors.New("gadget instance is not running")
+	}
+	if err := gadgetCtx.UpdateParams(paramValues); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.request.ParamValues == nil {
+		p.request.ParamValues = make(map[string]string)
+	}
+	maps.Copy(p.request.ParamValues, paramValues)
+	return nil
+}
+
 func (p *GadgetInstance) RemoveClients() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -164,6 +223,10 @@ func (p *GadgetInstance) Run(
 					}
 
 					p.mu.Lock()
+					if p.paused {
+						p.mu.Unlock()
+						return nil
+					}
 					p.eventBuffer[p.eventBufferOffs] = event
 					p.eventBufferOffs = (p.eventBufferOffs + 1) % len(p.eventBuffer)
 					if p.eventBufferOffs == 0 {
@@ -199,15 +262,20 @@ func (p *GadgetInstance) Run(
 	}
 	ops = append(ops, svc)
 
+	p.mu.Lock()
 	gadgetCtx := gadgetcontext.New(
 		ctx,
 		p.request.ImageName,
+		gadgetcontext.WithAdditionalImages(p.request.AdditionalImageNames...),
 		gadgetcontext.WithLogger(logger),
 		gadgetcontext.WithDataOperators(ops...),
 		gadgetcontext.WithAsRemoteCall(true),
 		gadgetcontext.WithName(p.name),
 		gadgetcontext.WithID(p.id),
+		gadgetcontext.WithPaused(p.paused),
 	)
+	p.gadgetCtx = gadgetCtx
+	p.mu.Unlock()
 
 	runtimeParams := runtime.ParamDescs().ToParams()
 	runtimeParams.CopyFromMap(p.request.ParamValues, "runtime.")
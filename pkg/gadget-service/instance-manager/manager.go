@@ -16,10 +16,13 @@ package instancemanager
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
 
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
@@ -90,6 +93,67 @@ func (m *Manager) RemoveGadget(id string) error {
 	return nil
 }
 
+func (m *Manager) getGadget(id string) (*GadgetInstance, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	gadgetInstance, ok := m.gadgetInstances[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return gadgetInstance, nil
+}
+
+// UpdateGadgetInstance applies a changed configuration to a running gadget in
+// place, keeping its ID and event buffer. Only pausing/resuming and changing
+// live-updatable params is supported; anything else requires restarting the
+// gadget.
+func (m *Manager) UpdateGadgetInstance(instance *api.GadgetInstance) error {
+	gi, err := m.getGadget(instance.Id)
+	if err != nil {
+		return err
+	}
+
+	gi.mu.Lock()
+	current := proto.Clone(gi.request).(*api.GadgetRunRequest)
+	paused := gi.paused
+	gi.mu.Unlock()
+
+	newConfig := proto.Clone(instance.GadgetConfig).(*api.GadgetRunRequest)
+	currentParams := current.ParamValues
+	newParams := newConfig.ParamValues
+	current.ParamValues = nil
+	newConfig.ParamValues = nil
+	if !proto.Equal(current, newConfig) {
+		return errors.New("gadget configuration changed")
+	}
+
+	changedParams := make(api.ParamValues)
+	for k, v := range newParams {
+		if cur, ok := currentParams[k]; !ok || cur != v {
+			changedParams[k] = v
+		}
+	}
+	for k := range currentParams {
+		if _, ok := newParams[k]; !ok {
+			return fmt.Errorf("param %q was removed", k)
+		}
+	}
+
+	if len(changedParams) > 0 {
+		if err := gi.UpdateParams(changedParams); err != nil {
+			return fmt.Errorf("updating params: %w", err)
+		}
+	}
+
+	switch {
+	case instance.Paused && !paused:
+		return gi.Pause()
+	case !instance.Paused && paused:
+		return gi.Resume()
+	}
+	return nil
+}
+
 func (m *Manager) RunGadget(instance *api.GadgetInstance) {
 	ctx, cancel := context.WithCancel(context.Background())
 	gi := &GadgetInstance{
@@ -102,6 +166,7 @@ func (m *Manager) RunGadget(instance *api.GadgetInstance) {
 		cancel:          cancel,
 		clients:         map[*GadgetInstanceClient]struct{}{},
 		ready:           make(chan struct{}),
+		paused:          instance.Paused,
 	}
 	m.mu.Lock()
 	m.gadgetInstances[gi.id] = gi
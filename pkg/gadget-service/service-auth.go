@@ -0,0 +1,107 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetservice
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/auth"
+)
+
+// SetAuth enables authentication of clients and authorization of their
+// requests
+func (s *Service) SetAuth(a *auth.Auth) {
+	s.auth = a
+}
+
+func (s *Service) authServerOptions() []grpc.ServerOption {
+	if s.auth == nil {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.auth.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(s.auth.StreamServerInterceptor()),
+	}
+}
+
+// clientName returns a description of the client of ctx for logging or an
+// empty string if it is unknown
+func clientName(ctx context.Context) string {
+	if id, ok := auth.FromContext(ctx); ok {
+		return id.String()
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.String()
+}
+
+// authorize checks whether the client of ctx is allowed to perform the action
+// on the given image; it always succeeds if authentication is disabled
+func (s *Service) authorize(ctx context.Context, action auth.Action, image string) error {
+	if s.auth == nil {
+		return nil
+	}
+	id, ok := auth.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unauthenticated request")
+	}
+	if err := s.auth.Authorize(id, action, image); err != nil {
+		s.logger.Warnf("[%s] denied %s %q", id, action, image)
+		return err
+	}
+	return nil
+}
+
+// authorizeImages is like authorize, but checks all images run together in a
+// gadget context
+func (s *Service) authorizeImages(ctx context.Context, action auth.Action, image string, additionalImages []string) error {
+	for _, img := range append([]string{image}, additionalImages...) {
+		if err := s.authorize(ctx, action, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeInstance is like authorize, but uses the images of the given gadget
+// instance
+func (s *Service) authorizeInstance(ctx context.Context, action auth.Action, id string) error {
+	if s.auth == nil {
+		return nil
+	}
+	if s.store == nil {
+		return fmt.Errorf("store not initialized")
+	}
+	instance, err := s.store.GetGadgetInstance(ctx, &api.GadgetInstanceId{Id: id})
+	if err != nil {
+		return fmt.Errorf("getting gadget instance %q: %w", id, err)
+	}
+	return s.authorizeImages(ctx, action, instance.GetGadgetConfig().GetImageName(),
+		instance.GetGadgetConfig().GetAdditionalImageNames())
+}
@@ -23,13 +23,12 @@ import (
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/peer"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/auth"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
@@ -60,21 +59,17 @@ func (s *Service) GetGadgetInfo(ctx context.Context, req *api.GetGadgetInfoReque
 		return nil, fmt.Errorf("expected version to be %d, got %d", api.VersionGadgetInfo, req.Version)
 	}
 
-	p, ok := peer.FromContext(ctx)
-	if ok && p.AuthInfo != nil {
-		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
-		if !ok {
-			return nil, fmt.Errorf("authentication information is not TLS: %v", p.AuthInfo)
-		}
-
-		subject := tlsInfo.State.VerifiedChains[0][0].Subject
-		s.logger.Infof("[%s] GetGadgetInfo(%q)", subject, req.ImageName)
+	if client := clientName(ctx); client != "" {
+		s.logger.Infof("[%s] GetGadgetInfo(%q)", client, req.ImageName)
 	}
 
 	if req.Flags&api.GadgetInfoRequestFlagUseInstance != 0 {
 		if s.instanceMgr == nil {
 			return nil, fmt.Errorf("instance manager not initialized")
 		}
+		if err := s.authorizeInstance(ctx, auth.ActionAttach, req.ImageName); err != nil {
+			return nil, err
+		}
 		gi := s.instanceMgr.LookupInstance(req.ImageName)
 		if gi == nil {
 			return nil, fmt.Errorf("instance %s not found", req.ImageName)
@@ -86,6 +81,10 @@ func (s *Service) GetGadgetInfo(ctx context.Context, req *api.GetGadgetInfoReque
 		return &api.GetGadgetInfoResponse{GadgetInfo: gadgetInfo}, nil
 	}
 
+	if err := s.authorizeImages(ctx, auth.ActionRun, req.ImageName, req.AdditionalImageNames); err != nil {
+		return nil, err
+	}
+
 	// Get all available operators
 	ops := make([]operators.DataOperator, 0)
 	for op := range s.operators {
@@ -95,6 +94,7 @@ func (s *Service) GetGadgetInfo(ctx context.Context, req *api.GetGadgetInfoReque
 	gadgetCtx := gadgetcontext.New(
 		ctx,
 		req.ImageName,
+		gadgetcontext.WithAdditionalImages(req.AdditionalImageNames...),
 		gadgetcontext.WithDataOperators(ops...),
 		gadgetcontext.WithAsRemoteCall(true),
 		gadgetcontext.IncludeExtraInfo(req.RequestExtraInfo),
@@ -121,6 +121,9 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 		if s.instanceMgr == nil {
 			return errors.New("instance manager not initialized")
 		}
+		if err := s.authorizeInstance(runGadget.Context(), auth.ActionAttach, attachRequest.Id); err != nil {
+			return err
+		}
 
 		s.ctrAttachGadget.Add(context.Background(), 1)
 		return s.instanceMgr.AttachToGadgetInstance(attachRequest.Id, runGadget)
@@ -136,15 +139,12 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 	)
 	defer s.ctrRunGadget.Add(context.Background(), 1, metric.WithAttributeSet(metricAttribs))
 
-	p, ok := peer.FromContext(runGadget.Context())
-	if ok && p.AuthInfo != nil {
-		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
-		if !ok {
-			return fmt.Errorf("authentication information are not TLS: %v", p.AuthInfo)
-		}
+	if client := clientName(runGadget.Context()); client != "" {
+		s.logger.Infof("[%s] RunGadget(%q)", client, ociRequest.ImageName)
+	}
 
-		subject := tlsInfo.State.VerifiedChains[0][0].Subject
-		s.logger.Infof("[%s] RunGadget(%q)", subject, ociRequest.ImageName)
+	if err := s.authorizeImages(runGadget.Context(), auth.ActionRun, ociRequest.ImageName, ociRequest.AdditionalImageNames); err != nil {
+		return err
 	}
 
 	if ociRequest.Version != api.VersionGadgetRunProtocol {
@@ -284,6 +284,7 @@ func (s *Service) RunGadget(runGadget api.GadgetManager_RunGadgetServer) error {
 	gadgetCtx := gadgetcontext.New(
 		runGadget.Context(),
 		ociRequest.ImageName,
+		gadgetcontext.WithAdditionalImages(ociRequest.AdditionalImageNames...),
 		gadgetcontext.WithLogger(logger),
 		gadgetcontext.WithDataOperators(ops...),
 		gadgetcontext.WithTimeout(time.Duration(ociRequest.Timeout)),
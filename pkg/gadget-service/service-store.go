@@ -1,4 +1,4 @@
-// Copyright 2024 The Inspektor Gadget authors
+// Copyright 2024-2025 The Inspektor Gadget authors
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -21,9 +21,14 @@ import (
 	"github.com/moby/moby/pkg/namesgenerator"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/auth"
 )
 
 func (s *Service) CreateGadgetInstance(ctx context.Context, request *api.CreateGadgetInstanceRequest) (*api.CreateGadgetInstanceResponse, error) {
+	gadgetConfig := request.GetGadgetInstance().GetGadgetConfig()
+	if err := s.authorizeImages(ctx, auth.ActionCreateInstance, gadgetConfig.GetImageName(), gadgetConfig.GetAdditionalImageNames()); err != nil {
+		return nil, err
+	}
 	// Create random ID if not set by the client
 	if request.GadgetInstance.Id == "" {
 		var err error
@@ -46,6 +51,9 @@ func (s *Service) CreateGadgetInstance(ctx context.Context, request *api.CreateG
 }
 
 func (s *Service) ListGadgetInstances(ctx context.Context, request *api.ListGadgetInstancesRequest) (*api.ListGadgetInstanceResponse, error) {
+	if err := s.authorize(ctx, auth.ActionListInstances, ""); err != nil {
+		return nil, err
+	}
 	return s.store.ListGadgetInstances(ctx, request)
 }
 
@@ -53,6 +61,9 @@ func (s *Service) GetGadgetInstance(ctx context.Context, id *api.GadgetInstanceI
 	if !api.IsValidInstanceID(id.Id) {
 		return nil, fmt.Errorf("invalid gadget instance id: %s", id.Id)
 	}
+	if err := s.authorize(ctx, auth.ActionListInstances, ""); err != nil {
+		return nil, err
+	}
 	return s.store.GetGadgetInstance(ctx, id)
 }
 
@@ -60,5 +71,38 @@ func (s *Service) RemoveGadgetInstance(ctx context.Context, id *api.GadgetInstan
 	if !api.IsValidInstanceID(id.Id) {
 		return nil, fmt.Errorf("invalid gadget instance id: %s", id.Id)
 	}
+	if err := s.authorizeInstance(ctx, auth.ActionDeleteInstance, id.Id); err != nil {
+		return nil, err
+	}
 	return s.store.RemoveGadgetInstance(ctx, id)
 }
+
+func (s *Service) PauseGadgetInstance(ctx context.Context, id *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	if !api.IsValidInstanceID(id.Id) {
+		return nil, fmt.Errorf("invalid gadget instance id: %s", id.Id)
+	}
+	if err := s.authorizeInstance(ctx, auth.ActionUpdateInstance, id.Id); err != nil {
+		return nil, err
+	}
+	return s.store.PauseGadgetInstance(ctx, id)
+}
+
+func (s *Service) ResumeGadgetInstance(ctx context.Context, id *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	if !api.IsValidInstanceID(id.Id) {
+		return nil, fmt.Errorf("invalid gadget instance id: %s", id.Id)
+	}
+	if err := s.authorizeInstance(ctx, auth.ActionUpdateInstance, id.Id); err != nil {
+		return nil, err
+	}
+	return s.store.ResumeGadgetInstance(ctx, id)
+}
+
+func (s *Service) UpdateGadgetInstance(ctx context.Context, request *api.UpdateGadgetInstanceRequest) (*api.StatusResponse, error) {
+	if !api.IsValidInstanceID(request.Id) {
+		return nil, fmt.Errorf("invalid gadget instance id: %s", request.Id)
+	}
+	if err := s.authorizeInstance(ctx, auth.ActionUpdateInstance, request.Id); err != nil {
+		return nil, err
+	}
+	return s.store.UpdateGadgetInstance(ctx, request)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
@@ -83,6 +84,12 @@ func loadGadgetFile(filename string) (*api.CreateGadgetInstanceRequest, error) {
 	return gadget, nil
 }
 
+// writeGadgetFile stores a gadget configuration to a file
+func writeGadgetFile(filename string, gadget *api.CreateGadgetInstanceRequest) error {
+	gadgetBlob, _ := protojson.Marshal(gadget)
+	return os.WriteFile(filename, gadgetBlob, 0o644)
+}
+
 // getGadgets returns a list of all installed gadget configurations
 func (s *FileStore) getGadgets() ([]*api.CreateGadgetInstanceRequest, error) {
 	files, err := os.ReadDir(GadgetInstanceDir)
@@ -122,9 +129,6 @@ func (s *FileStore) CreateGadgetInstance(ctx context.Context, req *api.CreateGad
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Store to gadget file
-	gadgetBlob, _ := protojson.Marshal(req)
-
 	// req.GadgetInstance.Id is sanitized in service-store.go
 	filename := filepath.Join(GadgetInstanceDir, fmt.Sprintf("%s.gadget", req.GadgetInstance.Id))
 
@@ -142,7 +146,7 @@ func (s *FileStore) CreateGadgetInstance(ctx context.Context, req *api.CreateGad
 		}
 	}
 
-	err = os.WriteFile(filename, gadgetBlob, 0o644)
+	err = writeGadgetFile(filename, req)
 	if err != nil {
 		return nil, fmt.Errorf("storing gadget information: %w", err)
 	}
@@ -202,3 +206,49 @@ func (s *FileStore) RemoveGadgetInstance(ctx context.Context, request *api.Gadge
 	}
 	return &api.StatusResponse{Result: 0}, nil
 }
+
+// updateGadgetInstance applies the modifications of fn to the stored
+// configuration of a gadget instance and to the running instance
+func (s *FileStore) updateGadgetInstance(id string, fn func(instance *api.GadgetInstance)) (*api.StatusResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(GadgetInstanceDir, fmt.Sprintf("%s.gadget", id))
+	gadget, err := loadGadgetFile(path)
+	if err != nil {
+		return &api.StatusResponse{Result: 1, Message: err.Error()}, nil
+	}
+
+	fn(gadget.GadgetInstance)
+
+	err = s.instanceMgr.UpdateGadgetInstance(gadget.GadgetInstance)
+	if err != nil {
+		return &api.StatusResponse{Result: 1, Message: err.Error()}, nil
+	}
+	err = writeGadgetFile(path, gadget)
+	if err != nil {
+		return &api.StatusResponse{Result: 1, Message: err.Error()}, nil
+	}
+	return &api.StatusResponse{Result: 0}, nil
+}
+
+func (s *FileStore) PauseGadgetInstance(ctx context.Context, request *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	return s.updateGadgetInstance(request.Id, func(instance *api.GadgetInstance) {
+		instance.Paused = true
+	})
+}
+
+func (s *FileStore) ResumeGadgetInstance(ctx context.Context, request *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	return s.updateGadgetInstance(request.Id, func(instance *api.GadgetInstance) {
+		instance.Paused = false
+	})
+}
+
+func (s *FileStore) UpdateGadgetInstance(ctx context.Context, request *api.UpdateGadgetInstanceRequest) (*api.StatusResponse, error) {
+	return s.updateGadgetInstance(request.Id, func(instance *api.GadgetInstance) {
+		if instance.GadgetConfig.ParamValues == nil {
+			instance.GadgetConfig.ParamValues = make(map[string]string)
+		}
+		maps.Copy(instance.GadgetConfig.ParamValues, request.ParamValues)
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"slices"
 	"strconv"
@@ -42,11 +43,13 @@ import (
 const (
 	GadgetInstance = "gadget-instance"
 
-	gadgetImage    = "gadgetImage"
-	gadgetLogLevel = "gadgetLogLevel"
-	gadgetNodes    = "gadgetNodes"
-	gadgetTags     = "gadgetTags"
-	gadgetTimeout  = "gadgetTimeout"
+	gadgetImage            = "gadgetImage"
+	gadgetAdditionalImages = "gadgetAdditionalImages"
+	gadgetLogLevel         = "gadgetLogLevel"
+	gadgetNodes            = "gadgetNodes"
+	gadgetPaused           = "gadgetPaused"
+	gadgetTags             = "gadgetTags"
+	gadgetTimeout          = "gadgetTimeout"
 )
 
 type Store struct {
@@ -183,10 +186,9 @@ func (s *Store) reconcile(key string) error {
 		return fmt.Errorf("invalid key; expected %q, got %q", "namespace/name", key)
 	}
 
-	err = s.instanceMgr.RemoveGadget(namespacedName[1])
 	if !exists {
 		// instance was deleted, so return the result of the deletion
-		return err
+		return s.instanceMgr.RemoveGadget(namespacedName[1])
 	}
 
 	configMap, ok := obj.(*corev1.ConfigMap)
@@ -198,9 +200,19 @@ func (s *Store) reconcile(key string) error {
 		return fmt.Errorf("converting configMap to gadgetInstance: %w", err)
 	}
 	if len(instance.Nodes) > 0 && !slices.Contains(instance.Nodes, s.nodeName) {
+		s.instanceMgr.RemoveGadget(namespacedName[1])
 		return nil
 	}
 
+	// Try to apply changes to a running gadget in place first to keep its
+	// event buffer
+	if err := s.instanceMgr.UpdateGadgetInstance(instance); err == nil {
+		log.Infof("updated gadget %q", configMap.Name)
+		return nil
+	}
+
+	s.instanceMgr.RemoveGadget(namespacedName[1])
+
 	log.Infof("starting gadget %q", configMap.Name)
 	s.instanceMgr.RunGadget(instance)
 	return nil
@@ -246,7 +258,6 @@ func (s *Store) CreateGadgetInstance(ctx context.Context, req *api.CreateGadgetI
 			return nil, fmt.Errorf("gadget instance with name '%s' already exists", req.GadgetInstance.Name)
 		}
 	}
-	tmpTrue := true
 	cmap := &corev1.ConfigMap{
 		TypeMeta: v1.TypeMeta{
 			Kind:       "ConfigMap",
@@ -260,14 +271,15 @@ func (s *Store) CreateGadgetInstance(ctx context.Context, req *api.CreateGadgetI
 				"name": req.GadgetInstance.Name,
 			},
 			Annotations: map[string]string{
-				gadgetImage:    req.GadgetInstance.GadgetConfig.ImageName,
-				gadgetTags:     strings.Join(req.GadgetInstance.Tags, ","),
-				gadgetTimeout:  fmt.Sprintf("%d", req.GadgetInstance.GadgetConfig.Timeout),
-				gadgetLogLevel: fmt.Sprintf("%d", req.GadgetInstance.GadgetConfig.LogLevel),
-				gadgetNodes:    strings.Join(req.GadgetInstance.Nodes, ","),
+				gadgetImage:            req.GadgetInstance.GadgetConfig.ImageName,
+				gadgetAdditionalImages: strings.Join(req.GadgetInstance.GadgetConfig.AdditionalImageNames, ","),
+				gadgetTags:             strings.Join(req.GadgetInstance.Tags, ","),
+				gadgetTimeout:          fmt.Sprintf("%d", req.GadgetInstance.GadgetConfig.Timeout),
+				gadgetLogLevel:         fmt.Sprintf("%d", req.GadgetInstance.GadgetConfig.LogLevel),
+				gadgetNodes:            strings.Join(req.GadgetInstance.Nodes, ","),
+				gadgetPaused:           strconv.FormatBool(req.GadgetInstance.Paused),
 			},
 		},
-		Immutable:  &tmpTrue,
 		Data:       req.GadgetInstance.GadgetConfig.ParamValues,
 		BinaryData: nil,
 	}
@@ -324,6 +336,69 @@ func (s *Store) GetGadgetInstance(ctx context.Context, req *api.GadgetInstanceId
 	return configMapToGadgetInstance(configMap.(*corev1.ConfigMap))
 }
 
+// updateConfigMap applies the modifications of fn to the config map of the
+// given gadget instance; all nodes will then reconcile their instances
+func (s *Store) updateConfigMap(ctx context.Context, id string, fn func(cm *corev1.ConfigMap)) (*api.StatusResponse, error) {
+	configMaps := s.clientset.CoreV1().ConfigMaps(s.gadgetNamespace)
+	cm, err := configMaps.Get(ctx, id, v1.GetOptions{})
+	if err != nil {
+		return &api.StatusResponse{Result: 1, Message: err.Error()}, nil
+	}
+	if cm.Immutable != nil && *cm.Immutable {
+		return &api.StatusResponse{
+			Result:  1,
+			Message: "gadget instance is immutable; it was created by an older version and needs to be recreated",
+		}, nil
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = make(map[string]string)
+	}
+	fn(cm)
+	_, err = configMaps.Update(ctx, cm, v1.UpdateOptions{})
+	if err != nil {
+		return &api.StatusResponse{Result: 1, Message: err.Error()}, nil
+	}
+	return &api.StatusResponse{Result: 0}, nil
+}
+
+// PauseGadgetInstance marks the config map of the given gadget instance as paused
+func (s *Store) PauseGadgetInstance(ctx context.Context, id *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	return s.updateConfigMap(ctx, id.Id, func(cm *corev1.ConfigMap) {
+		cm.Annotations[gadgetPaused] = "true"
+	})
+}
+
+// ResumeGadgetInstance removes the paused mark from the config map of the given gadget instance
+func (s *Store) ResumeGadgetInstance(ctx context.Context, id *api.GadgetInstanceId) (*api.StatusResponse, error) {
+	return s.updateConfigMap(ctx, id.Id, func(cm *corev1.ConfigMap) {
+		cm.Annotations[gadgetPaused] = "false"
+	})
+}
+
+// UpdateGadgetInstance stores new param values in the config map of the given gadget instance. If the instance is
+// running on this node, the values are applied to it first, so that invalid values are reported to the client.
+func (s *Store) UpdateGadgetInstance(ctx context.Context, req *api.UpdateGadgetInstanceRequest) (*api.StatusResponse, error) {
+	instance, err := s.GetGadgetInstance(ctx, &api.GadgetInstanceId{Id: req.Id})
+	if err != nil {
+		return &api.StatusResponse{Result: 1, Message: err.Error()}, nil
+	}
+	if instance.GadgetConfig.ParamValues == nil {
+		instance.GadgetConfig.ParamValues = make(map[string]string)
+	}
+	maps.Copy(instance.GadgetConfig.ParamValues, req.ParamValues)
+	err = s.instanceMgr.UpdateGadgetInstance(instance)
+	if err != nil && !errors.Is(err, instancemanager.ErrNotFound) {
+		return &api.StatusResponse{Result: 1, Message: err.Error()}, nil
+	}
+
+	return s.updateConfigMap(ctx, req.Id, func(cm *corev1.ConfigMap) {
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		maps.Copy(cm.Data, req.ParamValues)
+	})
+}
+
 func (s *Store) ResumeStoredGadgets() error {
 	go s.runController()
 	return nil
@@ -338,23 +413,33 @@ func configMapToGadgetInstance(cm *corev1.ConfigMap) (*api.GadgetInstance, error
 	if err != nil && cm.Annotations[gadgetLogLevel] != "" {
 		return nil, fmt.Errorf("parsing %s annotation for %q: %w", gadgetLogLevel, cm.Name, err)
 	}
+	paused, err := strconv.ParseBool(cm.Annotations[gadgetPaused])
+	if err != nil && cm.Annotations[gadgetPaused] != "" {
+		return nil, fmt.Errorf("parsing %s annotation for %q: %w", gadgetPaused, cm.Name, err)
+	}
 	nodes := strings.Split(cm.Annotations["gadgetNodes"], ",")
 	if len(nodes) == 1 && nodes[0] == "" {
 		// no nodes given, make sure the array is empty
 		nodes = []string{}
 	}
+	var additionalImages []string
+	if cm.Annotations[gadgetAdditionalImages] != "" {
+		additionalImages = strings.Split(cm.Annotations[gadgetAdditionalImages], ",")
+	}
 	return &api.GadgetInstance{
 		Id: cm.Name,
 		GadgetConfig: &api.GadgetRunRequest{
-			ImageName:   cm.Annotations[gadgetImage],
-			ParamValues: cm.Data,
-			LogLevel:    uint32(logLevel),
-			Timeout:     timeout,
-			Version:     api.VersionGadgetRunProtocol,
+			ImageName:            cm.Annotations[gadgetImage],
+			AdditionalImageNames: additionalImages,
+			ParamValues:          cm.Data,
+			LogLevel:             uint32(logLevel),
+			Timeout:              timeout,
+			Version:              api.VersionGadgetRunProtocol,
 		},
 		Nodes:       nodes,
 		Name:        cm.Labels["name"],
 		Tags:        strings.Split(cm.Annotations[gadgetTags], ","),
 		TimeCreated: cm.CreationTimestamp.Unix(),
+		Paused:      paused,
 	}, nil
 }
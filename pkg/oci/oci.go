@@ -673,6 +673,16 @@ func normalizeImageName(image string) (reference.Named, error) {
 	return reference.TagNameOnly(name), nil
 }
 
+// NormalizeImageName returns the fully qualified name of the given image,
+// e.g. "ghcr.io/inspektor-gadget/gadget/trace_open:latest" for "trace_open"
+func NormalizeImageName(image string) (string, error) {
+	named, err := normalizeImageName(image)
+	if err != nil {
+		return "", err
+	}
+	return named.String(), nil
+}
+
 func getHostString(repository string) (string, error) {
 	repo, err := reference.Parse(repository)
 	if err != nil {
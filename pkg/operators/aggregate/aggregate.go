@@ -0,0 +1,605 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aggregate is a data operator that groups the events of data sources
+// of type single by a set of fields and periodically emits the aggregated
+// values (count, sum, min, max, avg and percentiles) of each group as a new
+// data source of type array. Combined with the sort and limiter operators,
+// this allows building "top" like views on top of any tracer.
+package aggregate
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name                   = "aggregate"
+	ParamAggregateBy       = "aggregate-by"
+	ParamAggregateFields   = "aggregate-fields"
+	ParamAggregateInterval = "aggregate-interval"
+
+	// Priority needs to be lower than the one of the sort and limiter operators, so that the aggregated data
+	// sources are already registered when those are instantiated. Events are consumed after the filter operator.
+	Priority = 9100
+
+	DataSourcePrefix = "aggregated"
+	FieldNameCount   = "count"
+)
+
+const (
+	aggCount = "count"
+	aggSum   = "sum"
+	aggMin   = "min"
+	aggMax   = "max"
+	aggAvg   = "avg"
+)
+
+type aggregateOperator struct{}
+
+func (a *aggregateOperator) Name() string {
+	return name
+}
+
+func (a *aggregateOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (a *aggregateOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (a *aggregateOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamAggregateBy,
+			Title: "Aggregate By",
+			Description: "Group events by fields and periodically emit aggregated values. Join multiple fields with ','. " +
+				"If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+		},
+		{
+			Key:   ParamAggregateFields,
+			Title: "Aggregate Fields",
+			Description: "Aggregations to compute for each group in addition to the event count. " +
+				"Supported are sum(field), min(field), max(field), avg(field) and percentiles like p50(field) or p99(field). " +
+				"Join multiple aggregations with ','. If using multiple data sources, prefix aggregations with " +
+				"'datasourcename:' and separate with ';'",
+		},
+		{
+			Key:   ParamAggregateInterval,
+			Title: "Aggregate Interval",
+			Description: "Interval in which aggregated data is emitted. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','",
+			DefaultValue: "5s",
+			TypeHint:     api.TypeString,
+		},
+	}
+}
+
+func (a *aggregateOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	aggregateBy, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamAggregateBy])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamAggregateBy, err)
+	}
+	if len(aggregateBy) == 0 {
+		return nil, nil
+	}
+
+	aggregateFields, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamAggregateFields])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamAggregateFields, err)
+	}
+
+	intervals, err := apihelpers.GetDurationValuesPerDataSource(instanceParamValues[ParamAggregateInterval])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamAggregateInterval, err)
+	}
+
+	dataSources := gadgetCtx.GetDataSources()
+
+	_, global := aggregateBy[""]
+	if !global {
+		for dsName := range aggregateBy {
+			if _, ok := dataSources[dsName]; ok {
+				continue
+			}
+			if _, ok := dataSources[aggregatedName(dsName)]; ok {
+				// Already aggregated remotely
+				continue
+			}
+			return nil, fmt.Errorf("data source %q not found", dsName)
+		}
+	}
+
+	inst := &aggregateOperatorInstance{
+		aggregators: make(map[datasource.DataSource]*aggregator),
+	}
+	for _, ds := range dataSources {
+		groupBy, ok := aggregateBy[ds.Name()]
+		if global {
+			groupBy, ok = aggregateBy[""]
+		}
+		if !ok || ds.Type() != datasource.TypeSingle {
+			if ok && !global {
+				return nil, fmt.Errorf("%s can only be used on data sources of type single", ParamAggregateBy)
+			}
+			continue
+		}
+		if _, exists := dataSources[aggregatedName(ds.Name())]; exists {
+			gadgetCtx.Logger().Debugf("aggregate: data source %q is already aggregated", ds.Name())
+			continue
+		}
+
+		fields := aggregateFields[ds.Name()]
+		if _, ok := aggregateFields[""]; ok {
+			fields = aggregateFields[""]
+		}
+
+		interval, ok := intervals[ds.Name()]
+		if !ok {
+			interval, ok = intervals[""]
+		}
+		if !ok || interval <= 0 {
+			return nil, fmt.Errorf("invalid %s for data source %q", ParamAggregateInterval, ds.Name())
+		}
+
+		agg, err := newAggregator(gadgetCtx, ds, groupBy, fields, interval)
+		if err != nil {
+			return nil, fmt.Errorf("aggregating data source %q: %w", ds.Name(), err)
+		}
+		inst.aggregators[ds] = agg
+	}
+
+	if len(inst.aggregators) == 0 {
+		return nil, nil
+	}
+	return inst, nil
+}
+
+func (a *aggregateOperator) Priority() int {
+	return Priority
+}
+
+func aggregatedName(dsName string) string {
+	return fmt.Sprintf("%s-%s", DataSourcePrefix, dsName)
+}
+
+// maxSamples limits the number of values kept per group to compute
+// percentiles; further values replace random ones (reservoir sampling)
+const maxSamples = 1024
+
+type aggregation struct {
+	fn         string
+	percentile float64
+	isFloat    bool
+	isUint     bool
+	asInt      func(datasource.Data) int64
+	asUint     func(datasource.Data) uint64
+	asFloat    func(datasource.Data) float64
+	out        datasource.FieldAccessor
+}
+
+type accumulator struct {
+	sumInt   int64
+	sumUint  uint64
+	sumFloat float64
+	minInt   int64
+	maxInt   int64
+	minUint  uint64
+	maxUint  uint64
+	minFloat float64
+	maxFloat float64
+	samples  []float64
+}
+
+type group struct {
+	keys  [][]byte
+	count uint64
+	accs  []accumulator
+}
+
+type aggregator struct {
+	ds         datasource.DataSource
+	outDs      datasource.DataSource
+	interval   time.Duration
+	keyFields  []datasource.FieldAccessor
+	outKeys    []datasource.FieldAccessor
+	countField datasource.FieldAccessor
+	aggs       []*aggregation
+
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+// addFieldWithParents adds a field using the full name of fieldName, creating empty parent fields if needed
+func addFieldWithParents(ds datasource.DataSource, fullName string, kind api.Kind, opts ...datasource.FieldOption) (datasource.FieldAccessor, error) {
+	parts := strings.Split(fullName, ".")
+	var parent datasource.FieldAccessor
+	for i, part := range parts[:len(parts)-1] {
+		if f := ds.GetField(strings.Join(parts[:i+1], ".")); f != nil {
+			parent = f
+			continue
+		}
+		var err error
+		if parent == nil {
+			parent, err = ds.AddField(part, api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+		} else {
+			parent, err = parent.AddSubField(part, api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if parent == nil {
+		return ds.AddField(fullName, kind, opts...)
+	}
+	return parent.AddSubField(parts[len(parts)-1], kind, opts...)
+}
+
+func parseAggregation(s string) (fn string, fieldName string, percentile float64, err error) {
+	if s == aggCount {
+		// count is always emitted
+		return aggCount, "", 0, nil
+	}
+	fn, rest, found := strings.Cut(s, "(")
+	if !found || !strings.HasSuffix(rest, ")") {
+		return "", "", 0, fmt.Errorf("invalid aggregation %q, expected function(field)", s)
+	}
+	fieldName = strings.TrimSpace(strings.TrimSuffix(rest, ")"))
+	if fieldName == "" {
+		return "", "", 0, fmt.Errorf("missing field in aggregation %q", s)
+	}
+	switch fn {
+	case aggSum, aggMin, aggMax, aggAvg:
+		return fn, fieldName, 0, nil
+	}
+	if p, ok := strings.CutPrefix(fn, "p"); ok {
+		percentile, err = strconv.ParseFloat(p, 64)
+		if err == nil && percentile > 0 && percentile <= 100 {
+			return fn, fieldName, percentile, nil
+		}
+	}
+	return "", "", 0, fmt.Errorf("unsupported aggregation function %q", fn)
+}
+
+func newAggregator(
+	gadgetCtx operators.GadgetContext,
+	ds datasource.DataSource,
+	groupBy []string,
+	aggregations []string,
+	interval time.Duration,
+) (*aggregator, error) {
+	agg := &aggregator{
+		ds:       ds,
+		interval: interval,
+		groups:   make(map[string]*group),
+	}
+
+	for _, fieldName := range groupBy {
+		f := ds.GetField(fieldName)
+		if f == nil {
+			return nil, fmt.Errorf("field %q not found", fieldName)
+		}
+		if f.Type() == api.Kind_Invalid {
+			return nil, fmt.Errorf("field %q cannot be used for grouping", fieldName)
+		}
+		agg.keyFields = append(agg.keyFields, f)
+	}
+
+	// Disable original data source to avoid other operators subscribing to it
+	ds.Unreference()
+
+	outDs, err := gadgetCtx.RegisterDataSource(datasource.TypeArray, aggregatedName(ds.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("registering aggregated data source: %w", err)
+	}
+	outDs.AddAnnotation(api.FetchIntervalAnnotation, interval.String())
+	outDs.AddAnnotation("cli.clear-screen-before", "true")
+	agg.outDs = outDs
+
+	for _, f := range agg.keyFields {
+		of, err := addFieldWithParents(outDs, f.FullName(), f.Type(),
+			datasource.WithAnnotations(f.Annotations()),
+			datasource.WithTags(f.Tags()...),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", f.FullName(), err)
+		}
+		agg.outKeys = append(agg.outKeys, of)
+	}
+
+	agg.countField, err = outDs.AddField(FieldNameCount, api.Kind_Uint64, datasource.WithAnnotations(map[string]string{
+		metadatav1.DescriptionAnnotation:      "Number of events",
+		metadatav1.ColumnsAlignmentAnnotation: string(metadatav1.AlignmentRight),
+		metadatav1.ColumnsWidthAnnotation:     "8",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("adding field %q: %w", FieldNameCount, err)
+	}
+
+	for _, s := range aggregations {
+		fn, fieldName, percentile, err := parseAggregation(s)
+		if err != nil {
+			return nil, err
+		}
+		if fn == aggCount {
+			continue
+		}
+		f := ds.GetField(fieldName)
+		if f == nil {
+			return nil, fmt.Errorf("field %q not found", fieldName)
+		}
+
+		a := &aggregation{fn: fn, percentile: percentile}
+		switch f.Type() {
+		case api.Kind_Float32, api.Kind_Float64:
+			a.isFloat = true
+			a.asFloat, _ = datasource.AsFloat64(f)
+		case api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64:
+			a.isUint = true
+			a.asUint, _ = datasource.AsUint64(f)
+		default:
+			a.asInt, err = datasource.AsInt64(f)
+			if err != nil {
+				return nil, fmt.Errorf("field %q cannot be aggregated: %w", fieldName, err)
+			}
+		}
+
+		outKind := api.Kind_Int64
+		if a.isFloat || fn == aggAvg || percentile > 0 {
+			outKind = api.Kind_Float64
+		} else if a.isUint {
+			outKind = api.Kind_Uint64
+		}
+		outName := strings.NewReplacer(".", "_").Replace(fmt.Sprintf("%s_%s", fn, f.FullName()))
+		a.out, err = outDs.AddField(outName, outKind, datasource.WithAnnotations(map[string]string{
+			metadatav1.DescriptionAnnotation:      fmt.Sprintf("%s of %s", fn, f.FullName()),
+			metadatav1.ColumnsAlignmentAnnotation: string(metadatav1.AlignmentRight),
+			metadatav1.ColumnsWidthAnnotation:     "12",
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", outName, err)
+		}
+		agg.aggs = append(agg.aggs, a)
+	}
+
+	gadgetCtx.Logger().Debugf("aggregate: aggregating %q by %v into %q every %s", ds.Name(), groupBy,
+		outDs.Name(), interval)
+
+	return agg, nil
+}
+
+func (agg *aggregator) collect(data datasource.Data) {
+	key := common.GroupKey(data, agg.keyFields)
+
+	agg.mu.Lock()
+	defer agg.mu.Unlock()
+
+	g, ok := agg.groups[key]
+	if !ok {
+		g = &group{
+			keys: make([][]byte, 0, len(agg.keyFields)),
+			accs: make([]accumulator, len(agg.aggs)),
+		}
+		// data may not be accessed after returning, so copy the key values
+		for _, f := range agg.keyFields {
+			g.keys = append(g.keys, slices.Clone(f.Get(data)))
+		}
+		for i := range g.accs {
+			g.accs[i] = accumulator{
+				minInt:   math.MaxInt64,
+				maxInt:   math.MinInt64,
+				minUint:  math.MaxUint64,
+				minFloat: math.Inf(1),
+				maxFloat: math.Inf(-1),
+			}
+		}
+		agg.groups[key] = g
+	}
+	g.count++
+
+	for i, a := range agg.aggs {
+		acc := &g.accs[i]
+		var fv float64
+		if a.isFloat {
+			fv = a.asFloat(data)
+			acc.sumFloat += fv
+			acc.minFloat = min(acc.minFloat, fv)
+			acc.maxFloat = max(acc.maxFloat, fv)
+		} else if a.isUint {
+			uv := a.asUint(data)
+			fv = float64(uv)
+			acc.sumUint += uv
+			acc.minUint = min(acc.minUint, uv)
+			acc.maxUint = max(acc.maxUint, uv)
+		} else {
+			iv := a.asInt(data)
+			fv = float64(iv)
+			acc.sumInt += iv
+			acc.minInt = min(acc.minInt, iv)
+			acc.maxInt = max(acc.maxInt, iv)
+		}
+		if a.percentile > 0 {
+			acc.addSample(fv, g.count)
+		}
+	}
+}
+
+// addSample keeps v for computing percentiles. Once maxSamples values are kept,
+// the n-th value replaces a random one with a probability of maxSamples/n, so
+// all values are equally likely to be kept.
+func (acc *accumulator) addSample(v float64, n uint64) {
+	if len(acc.samples) < maxSamples {
+		acc.samples = append(acc.samples, v)
+		return
+	}
+	if i := rand.Uint64N(n); i < maxSamples {
+		acc.samples[i] = v
+	}
+}
+
+// percentile returns the p-th percentile of samples using the nearest-rank method; samples will be sorted
+func percentile(samples []float64, p float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	slices.Sort(samples)
+	rank := int(math.Ceil(p / 100 * float64(len(samples))))
+	return samples[max(rank-1, 0)]
+}
+
+func (agg *aggregator) emit() error {
+	agg.mu.Lock()
+	groups := agg.groups
+	agg.groups = make(map[string]*group, len(groups))
+	agg.mu.Unlock()
+
+	arr, err := agg.outDs.NewPacketArray()
+	if err != nil {
+		return fmt.Errorf("creating new packet: %w", err)
+	}
+
+	for _, g := range groups {
+		d := arr.New()
+		for i, f := range agg.outKeys {
+			if err := f.Set(d, g.keys[i]); err != nil {
+				agg.outDs.Release(arr)
+				return fmt.Errorf("setting field %q: %w", f.Name(), err)
+			}
+		}
+		agg.countField.PutUint64(d, g.count)
+		for i, a := range agg.aggs {
+			acc := &g.accs[i]
+			switch {
+			case a.fn == aggAvg && a.isFloat:
+				a.out.PutFloat64(d, acc.sumFloat/float64(g.count))
+			case a.fn == aggAvg && a.isUint:
+				a.out.PutFloat64(d, float64(acc.sumUint)/float64(g.count))
+			case a.fn == aggAvg:
+				a.out.PutFloat64(d, float64(acc.sumInt)/float64(g.count))
+			case a.percentile > 0:
+				a.out.PutFloat64(d, percentile(acc.samples, a.percentile))
+			case a.isFloat:
+				switch a.fn {
+				case aggSum:
+					a.out.PutFloat64(d, acc.sumFloat)
+				case aggMin:
+					a.out.PutFloat64(d, acc.minFloat)
+				case aggMax:
+					a.out.PutFloat64(d, acc.maxFloat)
+				}
+			case a.isUint:
+				switch a.fn {
+				case aggSum:
+					a.out.PutUint64(d, acc.sumUint)
+				case aggMin:
+					a.out.PutUint64(d, acc.minUint)
+				case aggMax:
+					a.out.PutUint64(d, acc.maxUint)
+				}
+			default:
+				switch a.fn {
+				case aggSum:
+					a.out.PutInt64(d, acc.sumInt)
+				case aggMin:
+					a.out.PutInt64(d, acc.minInt)
+				case aggMax:
+					a.out.PutInt64(d, acc.maxInt)
+				}
+			}
+		}
+		arr.Append(d)
+	}
+
+	return agg.outDs.EmitAndRelease(arr)
+}
+
+type aggregateOperatorInstance struct {
+	aggregators map[datasource.DataSource]*aggregator
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+func (a *aggregateOperatorInstance) Name() string {
+	return name
+}
+
+func (a *aggregateOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for ds, agg := range a.aggregators {
+		err := ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			agg.collect(data)
+			return nil
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (a *aggregateOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	a.done = make(chan struct{})
+	for _, agg := range a.aggregators {
+		a.wg.Add(1)
+		go func(agg *aggregator) {
+			defer a.wg.Done()
+			ticker := time.NewTicker(agg.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-a.done:
+					return
+				case <-ticker.C:
+					if err := agg.emit(); err != nil {
+						gadgetCtx.Logger().Errorf("Failed to emit aggregated data for %q: %v", agg.outDs.Name(), err)
+					}
+				}
+			}
+		}(agg)
+	}
+	return nil
+}
+
+func (a *aggregateOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if a.done != nil {
+		close(a.done)
+		a.wg.Wait()
+		a.done = nil
+	}
+	return nil
+}
+
+func (a *aggregateOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &aggregateOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/sort"
+)
+
+func TestParseAggregation(t *testing.T) {
+	testCases := []struct {
+		input      string
+		fn         string
+		field      string
+		percentile float64
+		error      bool
+	}{
+		{input: "count", fn: "count"},
+		{input: "sum(size)", fn: "sum", field: "size"},
+		{input: "avg(k8s.latency)", fn: "avg", field: "k8s.latency"},
+		{input: "p99(latency)", fn: "p99", field: "latency", percentile: 99},
+		{input: "p99.9(latency)", fn: "p99.9", field: "latency", percentile: 99.9},
+		{input: "p0(latency)", error: true},
+		{input: "p101(latency)", error: true},
+		{input: "median(latency)", error: true},
+		{input: "sum()", error: true},
+		{input: "sum", error: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			fn, field, percentile, err := parseAggregation(tc.input)
+			if tc.error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.fn, fn)
+			assert.Equal(t, tc.field, field)
+			assert.Equal(t, tc.percentile, percentile)
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	samples := []float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}
+	assert.Equal(t, 5.0, percentile(samples, 50))
+	assert.Equal(t, 10.0, percentile(samples, 99))
+	assert.Equal(t, 1.0, percentile(samples, 1))
+	assert.Equal(t, 0.0, percentile(nil, 50))
+}
+
+func TestAddSample(t *testing.T) {
+	var acc accumulator
+	for n := uint64(1); n <= 100*maxSamples; n++ {
+		acc.addSample(float64(n), n)
+	}
+	require.Len(t, acc.samples, maxSamples)
+
+	// The kept samples are spread over all values, so the median is close to
+	// the one of all values
+	assert.InDelta(t, 50*maxSamples, percentile(acc.samples, 50), 10*maxSamples)
+}
+
+type aggregatedRow struct {
+	comm  string
+	pid   uint32
+	count uint64
+	sum   uint64
+	max   uint64
+	avg   float64
+	p50   float64
+}
+
+func TestAggregate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ds datasource.DataSource
+	var commField, pidField, sizeField datasource.FieldAccessor
+
+	events := []struct {
+		comm string
+		pid  uint32
+		size uint32
+	}{
+		{"cat", 1, 10},
+		{"cat", 1, 20},
+		{"cat", 1, 60},
+		{"nginx", 2, 5},
+		{"cat", 3, 100},
+	}
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+			require.NoError(t, err)
+			proc, err := ds.AddField("proc", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+			require.NoError(t, err)
+			commField, err = proc.AddSubField("comm", api.Kind_String)
+			require.NoError(t, err)
+			pidField, err = proc.AddSubField("pid", api.Kind_Uint32)
+			require.NoError(t, err)
+			sizeField, err = ds.AddField("size", api.Kind_Uint32)
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for _, ev := range events {
+				data, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, commField.PutString(data, ev.comm))
+				require.NoError(t, pidField.PutUint32(data, ev.pid))
+				require.NoError(t, sizeField.PutUint32(data, ev.size))
+				require.NoError(t, ds.EmitAndRelease(data))
+			}
+			return nil
+		}),
+	)
+
+	var rows []aggregatedRow
+	verifier := simple.New("verifier",
+		simple.WithPriority(sort.Priority+1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			assert.False(t, ds.IsReferenced(), "original data source should be unreferenced")
+
+			outDs, ok := gadgetCtx.GetDataSources()["aggregated-events"]
+			require.True(t, ok)
+			require.Equal(t, datasource.TypeArray, outDs.Type())
+
+			outComm := outDs.GetField("proc.comm")
+			require.NotNil(t, outComm)
+			outPid := outDs.GetField("proc.pid")
+			require.NotNil(t, outPid)
+			outCount := outDs.GetField("count")
+			require.NotNil(t, outCount)
+			outSum := outDs.GetField("sum_size")
+			require.NotNil(t, outSum)
+			outMax := outDs.GetField("max_size")
+			require.NotNil(t, outMax)
+			outAvg := outDs.GetField("avg_size")
+			require.NotNil(t, outAvg)
+			outP50 := outDs.GetField("p50_size")
+			require.NotNil(t, outP50)
+
+			return outDs.SubscribeArray(func(ds datasource.DataSource, arr datasource.DataArray) error {
+				if arr.Len() == 0 {
+					return nil
+				}
+				for i := 0; i < arr.Len(); i++ {
+					d := arr.Get(i)
+					var row aggregatedRow
+					row.comm, _ = outComm.String(d)
+					row.pid, _ = outPid.Uint32(d)
+					row.count, _ = outCount.Uint64(d)
+					row.sum, _ = outSum.Uint64(d)
+					row.max, _ = outMax.Uint64(d)
+					row.avg, _ = outAvg.Float64(d)
+					row.p50, _ = outP50.Float64(d)
+					rows = append(rows, row)
+				}
+				cancel()
+				return nil
+			}, sort.Priority+1)
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(Operator, sort.Operator, producer, verifier))
+	err := gadgetCtx.Run(api.ParamValues{
+		"operator.aggregate.aggregate-by":       "proc.comm,proc.pid",
+		"operator.aggregate.aggregate-fields":   "sum(size),max(size),avg(size),p50(size)",
+		"operator.aggregate.aggregate-interval": "100ms",
+		"operator.sort.sort":                    "-count",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rows, 3)
+	// sorted by count
+	assert.Equal(t, aggregatedRow{comm: "cat", pid: 1, count: 3, sum: 90, max: 60, avg: 30, p50: 20}, rows[0])
+	assert.ElementsMatch(t, []aggregatedRow{
+		{comm: "nginx", pid: 2, count: 1, sum: 5, max: 5, avg: 5, p50: 5},
+		{comm: "cat", pid: 3, count: 1, sum: 100, max: 100, avg: 100, p50: 100},
+	}, rows[1:])
+}
+
+func TestAggregateErrors(t *testing.T) {
+	testCases := map[string]api.ParamValues{
+		"unknown field": {
+			"operator.aggregate.aggregate-by": "foo",
+		},
+		"unknown aggregation field": {
+			"operator.aggregate.aggregate-by":     "comm",
+			"operator.aggregate.aggregate-fields": "sum(foo)",
+		},
+		"unsupported aggregation": {
+			"operator.aggregate.aggregate-by":     "comm",
+			"operator.aggregate.aggregate-fields": "median(size)",
+		},
+		"unknown data source": {
+			"operator.aggregate.aggregate-by": "other:comm",
+		},
+		"invalid interval": {
+			"operator.aggregate.aggregate-by":       "comm",
+			"operator.aggregate.aggregate-interval": "foo",
+		},
+	}
+	for name, paramValues := range testCases {
+		t.Run(name, func(t *testing.T) {
+			producer := simple.New("producer",
+				simple.WithPriority(Priority-1),
+				simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+					ds, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+					require.NoError(t, err)
+					_, err = ds.AddField("comm", api.Kind_String)
+					require.NoError(t, err)
+					_, err = ds.AddField("size", api.Kind_Uint32)
+					require.NoError(t, err)
+					return nil
+				}),
+			)
+			gadgetCtx := gadgetcontext.New(context.Background(), "", gadgetcontext.WithDataOperators(Operator, producer))
+			require.Error(t, gadgetCtx.Run(paramValues))
+		})
+	}
+}
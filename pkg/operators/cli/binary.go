@@ -0,0 +1,126 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clioperator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/arrow"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
+)
+
+// protobufFlushInterval is the interval in which the protobuf output is
+// flushed, so consumers get packets in time without a write per packet
+const protobufFlushInterval = 100 * time.Millisecond
+
+var arrowFormats = map[string]arrow.Format{
+	ModeArrow:   arrow.FormatArrow,
+	ModeParquet: arrow.FormatParquet,
+}
+
+// startProtobufOutput writes the gadget info followed by all packets of the
+// given data sources as length-delimited api.GadgetEvent messages to w. Packets
+// are buffered and flushed every protobufFlushInterval and when stopping.
+func (o *cliOperatorInstance) startProtobufOutput(gadgetCtx operators.GadgetContext, dataSources []datasource.DataSource, w io.Writer) error {
+	gi, err := common.GadgetInfoForDataSources(gadgetCtx, dataSources)
+	if err != nil {
+		return fmt.Errorf("serializing gadget info: %w", err)
+	}
+
+	pw, err := recording.NewStreamWriter(w, gi)
+	if err != nil {
+		return err
+	}
+	if err := pw.Flush(); err != nil {
+		return err
+	}
+	o.protobuf = pw
+	o.protobufDone = make(chan struct{})
+
+	o.protobufWg.Add(1)
+	go func() {
+		defer o.protobufWg.Done()
+		ticker := time.NewTicker(protobufFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-o.protobufDone:
+				return
+			case <-ticker.C:
+				if err := pw.Flush(); err != nil {
+					gadgetCtx.Logger().Warnf("flushing protobuf output: %v", err)
+					return
+				}
+			}
+		}
+	}()
+
+	for i, ds := range dataSources {
+		dsID := uint32(i)
+		err := ds.SubscribePacket(func(ds datasource.DataSource, packet datasource.Packet) error {
+			if err := pw.WritePacket(dsID, packet.Raw()); err != nil {
+				return fmt.Errorf("writing packet: %w", err)
+			}
+			return nil
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+// startArrowOutput writes all data of the given data source in the given
+// format to w; the output is only complete after closeBinaryOutputs has been
+// called.
+func (o *cliOperatorInstance) startArrowOutput(ds datasource.DataSource, mode string, w io.Writer) error {
+	aw, err := arrow.NewWriter(w, ds, arrowFormats[mode])
+	if err != nil {
+		return err
+	}
+	o.arrow = aw
+
+	switch ds.Type() {
+	case datasource.TypeSingle:
+		return ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			return aw.Write(data)
+		}, Priority)
+	case datasource.TypeArray:
+		return ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+			return aw.WriteArray(dataArray)
+		}, Priority)
+	}
+	return fmt.Errorf("unsupported data source type %d", ds.Type())
+}
+
+func (o *cliOperatorInstance) closeBinaryOutputs() error {
+	var errs []error
+	if o.protobuf != nil {
+		close(o.protobufDone)
+		o.protobufWg.Wait()
+		errs = append(errs, o.protobuf.Flush())
+		o.protobuf = nil
+	}
+	if o.arrow != nil {
+		errs = append(errs, o.arrow.Close())
+	}
+	return errors.Join(errs...)
+}
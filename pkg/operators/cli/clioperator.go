@@ -21,17 +21,22 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/term"
 	"sigs.k8s.io/yaml"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/arrow"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/table"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
 	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
 )
 
 const (
@@ -48,6 +53,13 @@ const (
 	ModeYAML       = "yaml"
 	ModeNone       = "none"
 	ModeRaw        = "raw"
+	ModeTUI        = "tui"
+	ModeCSV        = "csv"
+	ModeTSV        = "tsv"
+	ModeMarkdown   = "markdown"
+	ModeProtobuf   = "protobuf"
+	ModeArrow      = "arrow"
+	ModeParquet    = "parquet"
 
 	DefaultOutputMode = ModeColumns
 
@@ -63,7 +75,21 @@ const (
 	AnnotationDefaultOutputMode = "cli.default-output-mode"
 )
 
-var DefaultSupportedOutputModes = []string{ModeColumns, ModeJSON, ModeJSONPretty, ModeNone, ModeYAML}
+var DefaultSupportedOutputModes = []string{
+	ModeArrow, ModeColumns, ModeCSV, ModeJSON, ModeJSONPretty, ModeMarkdown,
+	ModeNone, ModeParquet, ModeProtobuf, ModeTSV, ModeTUI, ModeYAML,
+}
+
+var tableStyles = map[string]table.Style{
+	ModeCSV:      table.StyleCSV,
+	ModeTSV:      table.StyleTSV,
+	ModeMarkdown: table.StyleMarkdown,
+}
+
+// snapshotColumn is prepended to tabular output of array data sources; it
+// holds the time the array was emitted, so consecutive snapshots can be told
+// apart.
+const snapshotColumn = "snapshot"
 
 type cliOperator struct{}
 
@@ -103,6 +129,16 @@ type cliOperatorInstance struct {
 	supportedOutputModes map[string][]string
 	// key: datasource name, value: default output mode
 	defaultOutputMode map[string]string
+	// tui is shared by all data sources using the tui output mode
+	tui *tui
+	// protobuf is shared by all data sources using the protobuf output mode;
+	// it is flushed periodically until protobufDone is closed
+	protobuf     *recording.Writer
+	protobufDone chan struct{}
+	protobufWg   sync.WaitGroup
+	// arrow is used by the only data source using the arrow or parquet
+	// output mode
+	arrow *arrow.Writer
 }
 
 func (o *cliOperatorInstance) Name() string {
@@ -299,6 +335,8 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 		return fmt.Errorf("parsing default output modes: %w", err)
 	}
 
+	var protobufDataSources []datasource.DataSource
+
 	for _, ds := range gadgetCtx.GetDataSources() {
 		gadgetCtx.Logger().Debugf("subscribing to %s", ds.Name())
 
@@ -327,6 +365,18 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 		clearScreenBefore := ds.Annotations()[AnnotationClearScreenBefore] == "true"
 		isTerminal := term.IsTerminal(int(os.Stdout.Fd()))
 
+		if mode == ModeTUI && (!isTerminal || !term.IsTerminal(int(os.Stdin.Fd()))) {
+			gadgetCtx.Logger().Warnf("output mode %q requires a terminal; using %q for data source %q",
+				ModeTUI, ModeColumns, ds.Name())
+			mode = ModeColumns
+		}
+
+		if (mode == ModeProtobuf || mode == ModeArrow || mode == ModeParquet) && isTerminal {
+			gadgetCtx.Logger().Warnf("output mode %q writes binary data; redirect the output to a file; skipping data source %q",
+				mode, ds.Name())
+			continue
+		}
+
 		switch mode {
 		default:
 			before := func() {}
@@ -340,6 +390,25 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 			}, Priority)
 		case ModeNone:
 			// Do nothing.
+		case ModeTUI:
+			if o.tui == nil {
+				o.tui = newTUI(gadgetCtx.Cancel)
+			}
+
+			var tuiFields []string
+			if hasFields {
+				p, err := ds.Parser()
+				if err != nil {
+					gadgetCtx.Logger().Warnf("failed to get parser: %v; skipping data source %q", err, ds.Name())
+					continue
+				}
+				tuiFields = parseFields(fields, p.GetDefaultColumns())
+			}
+
+			if err := o.tui.addView(ds, tuiFields); err != nil {
+				gadgetCtx.Logger().Warnf("failed to set up terminal UI: %v; skipping data source %q", err, ds.Name())
+				continue
+			}
 		case ModeColumns:
 			p, err := ds.Parser()
 			if err != nil {
@@ -417,6 +486,56 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 					return nil
 				}, Priority)
 			}
+		case ModeCSV, ModeTSV, ModeMarkdown:
+			var opts []table.Option
+			if hasFields {
+				p, err := ds.Parser()
+				if err != nil {
+					gadgetCtx.Logger().Warnf("failed to get parser: %v; skipping data source %q", err, ds.Name())
+					continue
+				}
+				opts = append(opts, table.WithFields(parseFields(fields, p.GetDefaultColumns())))
+			}
+			if ds.Type() == datasource.TypeArray {
+				opts = append(opts, table.WithPrefixColumns(snapshotColumn))
+			}
+
+			tableFormatter, err := table.New(ds, tableStyles[mode], opts...)
+			if err != nil {
+				gadgetCtx.Logger().Warnf("failed to initialize %s formatter: %v; skipping data source %q", mode, err, ds.Name())
+				continue
+			}
+
+			fmt.Fprintln(os.Stdout, tableFormatter.FormatHeader())
+
+			switch ds.Type() {
+			case datasource.TypeSingle:
+				ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+					fmt.Fprintln(os.Stdout, tableFormatter.Format(data))
+					return nil
+				}, Priority)
+			case datasource.TypeArray:
+				ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+					if dataArray.Len() == 0 {
+						return nil
+					}
+					snapshot := time.Now().Format(time.RFC3339Nano)
+					fmt.Fprintln(os.Stdout, tableFormatter.FormatArray(dataArray, snapshot))
+					return nil
+				}, Priority)
+			}
+		case ModeProtobuf:
+			protobufDataSources = append(protobufDataSources, ds)
+		case ModeArrow, ModeParquet:
+			if o.arrow != nil {
+				gadgetCtx.Logger().Warnf("output mode %q supports a single data source; skipping data source %q",
+					mode, ds.Name())
+				continue
+			}
+			if err := o.startArrowOutput(ds, mode, os.Stdout); err != nil {
+				gadgetCtx.Logger().Warnf("failed to initialize %s writer: %v; skipping data source %q", mode, err, ds.Name())
+				continue
+			}
 		case ModeJSON, ModeJSONPretty, ModeYAML:
 			// var opts []json.Option
 			// if hasFields {
@@ -458,6 +577,12 @@ func (o *cliOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error
 		}
 
 	}
+
+	if len(protobufDataSources) > 0 {
+		if err := o.startProtobufOutput(gadgetCtx, protobufDataSources, os.Stdout); err != nil {
+			return fmt.Errorf("initializing protobuf output: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -488,15 +613,24 @@ func jsonArrayDataFn(ds datasource.DataSource, dataArray datasource.DataArray, j
 }
 
 func (o *cliOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	if o.tui != nil {
+		return o.tui.start()
+	}
 	return nil
 }
 
 func (o *cliOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
-	return nil
+	if o.tui != nil {
+		o.tui.stop()
+	}
+	return o.closeBinaryOutputs()
 }
 
 func (o *cliOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
-	return nil
+	if o.tui != nil {
+		o.tui.stop()
+	}
+	return o.closeBinaryOutputs()
 }
 
 var CLIOperator = &cliOperator{}
@@ -0,0 +1,627 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clioperator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/filter"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/sort"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+)
+
+const (
+	// tuiMaxTailEntries is the number of events kept for the tail view of
+	// single data sources
+	tuiMaxTailEntries = 10000
+
+	tuiRefreshInterval = 100 * time.Millisecond
+
+	// lines used by the title, header and help
+	tuiChromeLines = 3
+)
+
+const (
+	escEnterAltScreen = "\x1b[?1049h\x1b[?25l"
+	escLeaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	escHome           = "\x1b[H"
+	escClearLine      = "\x1b[K"
+	escClearBelow     = "\x1b[J"
+	escReverse        = "\x1b[7m"
+	escReset          = "\x1b[0m"
+)
+
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPgUp
+	keyPgDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyTab
+	keyCtrlC
+)
+
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// parseKeys translates the input read from a terminal in raw mode into keys.
+// Unknown escape sequences are dropped.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch b[0] {
+		case 0x03:
+			keys = append(keys, key{code: keyCtrlC})
+		case '\r', '\n':
+			keys = append(keys, key{code: keyEnter})
+		case '\t':
+			keys = append(keys, key{code: keyTab})
+		case 0x7f, 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case 0x1b:
+			if len(b) < 3 || (b[1] != '[' && b[1] != 'O') {
+				keys = append(keys, key{code: keyEsc})
+				break
+			}
+			// CSI sequence: parameters followed by a final byte
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end == len(b) {
+				return keys
+			}
+			seq := string(b[2 : end+1])
+			b = b[end+1:]
+			switch seq {
+			case "A":
+				keys = append(keys, key{code: keyUp})
+			case "B":
+				keys = append(keys, key{code: keyDown})
+			case "C":
+				keys = append(keys, key{code: keyRight})
+			case "D":
+				keys = append(keys, key{code: keyLeft})
+			case "5~":
+				keys = append(keys, key{code: keyPgUp})
+			case "6~":
+				keys = append(keys, key{code: keyPgDown})
+			case "H", "1~", "7~":
+				keys = append(keys, key{code: keyHome})
+			case "F", "4~", "8~":
+				keys = append(keys, key{code: keyEnd})
+			}
+			continue
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r >= ' ' {
+				keys = append(keys, key{code: keyRune, r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// tuiView holds the state of a data source shown in the terminal UI
+type tuiView struct {
+	ds        datasource.DataSource
+	cols      columns.ColumnMap[datasource.DataTuple]
+	formatter *textcolumns.TextColumnsFormatter[datasource.DataTuple]
+	columns   []string
+	array     bool
+
+	// entries contains the last array received or the last events
+	// received, respectively; pending contains what has been received
+	// while the view was paused
+	entries []*datasource.DataTuple
+	pending []*datasource.DataTuple
+
+	filter  string
+	filters *filter.FilterSpecs[datasource.DataTuple]
+
+	// selected is the index of the column selected for sorting, sortBy the
+	// column sorted by (prefixed with "-" for descending order)
+	selected int
+	sortBy   string
+
+	offset int
+	follow bool
+}
+
+func newTUIView(ds datasource.DataSource, fields []string) (*tuiView, error) {
+	p, err := ds.Parser()
+	if err != nil {
+		return nil, fmt.Errorf("getting parser: %w", err)
+	}
+	cols, ok := p.GetColumns().(columns.ColumnMap[datasource.DataTuple])
+	if !ok {
+		return nil, fmt.Errorf("invalid columns: expected columns.ColumnMap[datasource.DataTuple], got %T", p.GetColumns())
+	}
+
+	if fields == nil {
+		fields = p.GetDefaultColumns()
+	}
+	formatter := textcolumns.NewFormatter(cols, textcolumns.WithAutoScale(false))
+	if err := formatter.SetShowColumns(fields); err != nil {
+		return nil, err
+	}
+
+	return &tuiView{
+		ds:        ds,
+		cols:      cols,
+		formatter: formatter,
+		columns:   fields,
+		array:     ds.Type() == datasource.TypeArray,
+		follow:    true,
+	}, nil
+}
+
+// add stores events of single data sources; the tail is trimmed to
+// tuiMaxTailEntries
+func (v *tuiView) add(paused bool, tuples ...*datasource.DataTuple) {
+	if paused {
+		v.pending = trimTail(append(v.pending, tuples...))
+		return
+	}
+	v.entries = trimTail(append(v.entries, tuples...))
+}
+
+// set replaces the entries of array data sources
+func (v *tuiView) set(paused bool, tuples []*datasource.DataTuple) {
+	if paused {
+		v.pending = tuples
+		return
+	}
+	v.entries = tuples
+}
+
+func (v *tuiView) resume() {
+	if v.pending == nil {
+		return
+	}
+	if v.array {
+		v.entries = v.pending
+	} else {
+		v.entries = trimTail(append(v.entries, v.pending...))
+	}
+	v.pending = nil
+}
+
+func trimTail(entries []*datasource.DataTuple) []*datasource.DataTuple {
+	if len(entries) <= tuiMaxTailEntries {
+		return entries
+	}
+	return append(entries[:0:0], entries[len(entries)-tuiMaxTailEntries:]...)
+}
+
+func (v *tuiView) setFilter(f string) error {
+	if f == "" {
+		v.filter = ""
+		v.filters = nil
+		return nil
+	}
+	filters, err := filter.GetFiltersFromStrings(v.cols, strings.Split(f, ","))
+	if err != nil {
+		return err
+	}
+	v.filter = f
+	v.filters = filters
+	v.offset = 0
+	return nil
+}
+
+// sortBySelected sorts by the selected column; if it's already used, the
+// order is reversed instead
+func (v *tuiView) sortBySelected() {
+	if len(v.columns) == 0 {
+		return
+	}
+	name := v.columns[v.selected]
+	switch v.sortBy {
+	case name:
+		v.sortBy = "-" + name
+	default:
+		v.sortBy = name
+	}
+}
+
+// rows returns the entries after applying the filter and the sorting
+func (v *tuiView) rows() []*datasource.DataTuple {
+	rows := v.entries
+	if v.filters != nil {
+		filtered := make([]*datasource.DataTuple, 0, len(rows))
+		for _, e := range rows {
+			if v.filters.MatchAll(e) {
+				filtered = append(filtered, e)
+			}
+		}
+		rows = filtered
+	}
+	if v.array && v.sortBy != "" {
+		if v.filters == nil {
+			rows = append([]*datasource.DataTuple(nil), rows...)
+		}
+		sort.SortEntries(v.cols, rows, []string{v.sortBy})
+	}
+	return rows
+}
+
+func (v *tuiView) scroll(delta, visible, total int) {
+	v.offset += delta
+	if v.offset > total-visible {
+		v.offset = total - visible
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+	if !v.array {
+		v.follow = v.offset >= total-visible
+	}
+}
+
+// tui renders the data sources using the "tui" output mode in a full screen,
+// refreshing view. Array data sources are shown as a table that can be sorted
+// and filtered, single data sources in a scrollable tail view. All of them
+// share the terminal; Tab switches between them.
+type tui struct {
+	mu sync.Mutex
+
+	in     io.Reader
+	out    io.Writer
+	size   func() (int, int, error)
+	cancel func()
+
+	views   []*tuiView
+	current int
+
+	paused  bool
+	editing bool
+	input   string
+	status  string
+
+	// dirty is set when the screen needs to be redrawn; width and height
+	// are the size of the last rendering
+	dirty         bool
+	width, height int
+
+	restore func()
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newTUI(cancel func()) *tui {
+	return &tui{
+		in:  os.Stdin,
+		out: os.Stdout,
+		size: func() (int, int, error) {
+			return term.GetSize(int(os.Stdout.Fd()))
+		},
+		cancel: cancel,
+		dirty:  true,
+	}
+}
+
+func (t *tui) addView(ds datasource.DataSource, fields []string) error {
+	v, err := newTUIView(ds, fields)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.views = append(t.views, v)
+	t.mu.Unlock()
+
+	switch ds.Type() {
+	case datasource.TypeSingle:
+		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			tuple := datasource.NewDataTuple(ds, datasource.CopyData(data))
+			t.mu.Lock()
+			v.add(t.paused, tuple)
+			t.dirty = true
+			t.mu.Unlock()
+			return nil
+		}, Priority)
+	case datasource.TypeArray:
+		ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+			l := dataArray.Len()
+			tuples := make([]*datasource.DataTuple, 0, l)
+			for i := 0; i < l; i++ {
+				tuples = append(tuples, datasource.NewDataTuple(ds, datasource.CopyData(dataArray.Get(i))))
+			}
+
+			t.mu.Lock()
+			v.set(t.paused, tuples)
+			t.dirty = true
+			t.mu.Unlock()
+			return nil
+		}, Priority)
+	}
+	return nil
+}
+
+// start switches the terminal to raw mode and the alternate screen and starts
+// handling input and rendering
+func (t *tui) start() error {
+	fd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("setting terminal to raw mode: %w", err)
+	}
+	fmt.Fprint(t.out, escEnterAltScreen)
+	t.restore = func() {
+		fmt.Fprint(t.out, escLeaveAltScreen)
+		term.Restore(fd, oldState)
+	}
+
+	t.done = make(chan struct{})
+	keys := make(chan []key)
+
+	// Reading from stdin can't be interrupted; this goroutine ends with the
+	// process
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := t.in.Read(buf)
+			if err != nil {
+				return
+			}
+			select {
+			case keys <- parseKeys(buf[:n]):
+			case <-t.done:
+				return
+			}
+		}
+	}()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(tuiRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.done:
+				return
+			case ks := <-keys:
+				for _, k := range ks {
+					t.handleKey(k)
+				}
+				t.render()
+			case <-ticker.C:
+				t.render()
+			}
+		}
+	}()
+	return nil
+}
+
+func (t *tui) stop() {
+	if t.done == nil {
+		return
+	}
+	close(t.done)
+	t.wg.Wait()
+	t.done = nil
+	t.restore()
+}
+
+func (t *tui) visibleRows() int {
+	_, height, err := t.size()
+	if err != nil {
+		return 0
+	}
+	return max(height-tuiChromeLines, 1)
+}
+
+func (t *tui) handleKey(k key) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.views) == 0 {
+		return
+	}
+	t.dirty = true
+	v := t.views[t.current]
+
+	if k.code == keyCtrlC {
+		t.cancel()
+		return
+	}
+
+	if t.editing {
+		switch k.code {
+		case keyEnter:
+			t.editing = false
+			if err := v.setFilter(t.input); err != nil {
+				t.status = err.Error()
+				return
+			}
+			t.status = ""
+		case keyEsc:
+			t.editing = false
+		case keyBackspace:
+			if t.input != "" {
+				_, size := utf8.DecodeLastRuneInString(t.input)
+				t.input = t.input[:len(t.input)-size]
+			}
+		case keyRune:
+			t.input += string(k.r)
+		}
+		return
+	}
+
+	visible := t.visibleRows()
+	total := len(v.rows())
+
+	switch k.code {
+	case keyTab:
+		t.current = (t.current + 1) % len(t.views)
+	case keyLeft:
+		if v.selected > 0 {
+			v.selected--
+		}
+	case keyRight:
+		if v.selected < len(v.columns)-1 {
+			v.selected++
+		}
+	case keyUp:
+		v.scroll(-1, visible, total)
+	case keyDown:
+		v.scroll(1, visible, total)
+	case keyPgUp:
+		v.scroll(-visible, visible, total)
+	case keyPgDown:
+		v.scroll(visible, visible, total)
+	case keyHome:
+		v.scroll(-total, visible, total)
+	case keyEnd:
+		v.scroll(total, visible, total)
+	case keyRune:
+		switch k.r {
+		case 'q':
+			t.cancel()
+		case 's':
+			if v.array {
+				v.sortBySelected()
+			}
+		case '/':
+			t.editing = true
+			t.input = v.filter
+		case 'p', ' ':
+			t.paused = !t.paused
+			if !t.paused {
+				for _, view := range t.views {
+					view.resume()
+				}
+			}
+		case 'k':
+			v.scroll(-1, visible, total)
+		case 'j':
+			v.scroll(1, visible, total)
+		case 'g':
+			v.scroll(-total, visible, total)
+		case 'G':
+			v.scroll(total, visible, total)
+		}
+	}
+}
+
+func fitLine(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// render redraws the screen if anything changed since the last call
+func (t *tui) render() {
+	width, height, err := t.size()
+	if err != nil || width <= 0 || height <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.views) == 0 || (!t.dirty && width == t.width && height == t.height) {
+		return
+	}
+	t.dirty = false
+	t.width, t.height = width, height
+	v := t.views[t.current]
+	v.formatter.RecalculateWidths(width, false)
+
+	rows := v.rows()
+	visible := max(height-tuiChromeLines, 1)
+	if !v.array && v.follow {
+		v.offset = len(rows) - visible
+	}
+	v.scroll(0, visible, len(rows))
+
+	var sb strings.Builder
+	sb.WriteString(escHome)
+
+	// Title
+	title := []string{v.ds.Name()}
+	if len(t.views) > 1 {
+		title[0] = fmt.Sprintf("%s (%d/%d)", v.ds.Name(), t.current+1, len(t.views))
+	}
+	if v.array {
+		if len(v.columns) > 0 {
+			title = append(title, "column: "+v.columns[v.selected])
+		}
+		if v.sortBy != "" {
+			title = append(title, "sort: "+v.sortBy)
+		}
+	}
+	if v.filter != "" {
+		title = append(title, "filter: "+v.filter)
+	}
+	if t.paused {
+		title = append(title, "PAUSED")
+	}
+	end := min(v.offset+visible, len(rows))
+	title = append(title, fmt.Sprintf("%d-%d/%d", min(v.offset+1, end), end, len(rows)))
+	sb.WriteString(escReverse + fitLine(strings.Join(title, " | "), width) + escClearLine + escReset + "\r\n")
+
+	// Table
+	sb.WriteString(fitLine(v.formatter.FormatHeader(), width) + escClearLine + "\r\n")
+	for _, row := range rows[v.offset:end] {
+		sb.WriteString(fitLine(v.formatter.FormatEntry(row), width) + escClearLine + "\r\n")
+	}
+	for i := end - v.offset; i < visible; i++ {
+		sb.WriteString(escClearLine + "\r\n")
+	}
+
+	// Help or input line
+	var footer string
+	switch {
+	case t.editing:
+		footer = "filter (column:value, column:~regex, column:>value; comma-separated): " + t.input
+	case t.status != "":
+		footer = t.status
+	case v.array:
+		footer = "←/→ column  s sort  / filter  p pause  ↑/↓/PgUp/PgDn scroll  tab next  q quit"
+	default:
+		footer = "/ filter  p pause  ↑/↓/PgUp/PgDn scroll  End follow  tab next  q quit"
+	}
+	sb.WriteString(escReverse + fitLine(footer, width) + escClearLine + escReset + escClearBelow)
+
+	io.WriteString(t.out, sb.String())
+}
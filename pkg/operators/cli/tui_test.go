@@ -0,0 +1,186 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clioperator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("q/\x1b[A\x1b[B\x1b[C\x1b[D\x1b[5~\x1b[6~\x1b[H\x1b[4~\x1b\r\t\x7f\x03ä\x1b[1;5A"))
+	assert.Equal(t, []key{
+		{code: keyRune, r: 'q'},
+		{code: keyRune, r: '/'},
+		{code: keyUp},
+		{code: keyDown},
+		{code: keyRight},
+		{code: keyLeft},
+		{code: keyPgUp},
+		{code: keyPgDown},
+		{code: keyHome},
+		{code: keyEnd},
+		{code: keyEsc},
+		{code: keyEnter},
+		{code: keyTab},
+		{code: keyBackspace},
+		{code: keyCtrlC},
+		{code: keyRune, r: 'ä'},
+	}, keys)
+}
+
+type tuiTestDS struct {
+	ds    datasource.DataSource
+	comm  datasource.FieldAccessor
+	count datasource.FieldAccessor
+}
+
+func newTUITestDS(t *testing.T, dsType datasource.Type) *tuiTestDS {
+	ds, err := datasource.New(dsType, "test")
+	require.NoError(t, err)
+	comm, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+	count, err := ds.AddField("count", api.Kind_Uint32)
+	require.NoError(t, err)
+	return &tuiTestDS{ds: ds, comm: comm, count: count}
+}
+
+func (d *tuiTestDS) emitArray(t *testing.T, comms []string, counts []uint32) {
+	arr, err := d.ds.NewPacketArray()
+	require.NoError(t, err)
+	for i := range comms {
+		data := arr.New()
+		require.NoError(t, d.comm.PutString(data, comms[i]))
+		require.NoError(t, d.count.PutUint32(data, counts[i]))
+		arr.Append(data)
+	}
+	require.NoError(t, d.ds.EmitAndRelease(arr))
+}
+
+func (d *tuiTestDS) emitSingle(t *testing.T, comm string, count uint32) {
+	data, err := d.ds.NewPacketSingle()
+	require.NoError(t, err)
+	require.NoError(t, d.comm.PutString(data, comm))
+	require.NoError(t, d.count.PutUint32(data, count))
+	require.NoError(t, d.ds.EmitAndRelease(data))
+}
+
+func newTestTUI(t *testing.T, height int) (*tui, *bytes.Buffer, *bool) {
+	out := &bytes.Buffer{}
+	canceled := false
+	ui := newTUI(func() { canceled = true })
+	ui.out = out
+	ui.size = func() (int, int, error) { return 40, height, nil }
+	return ui, out, &canceled
+}
+
+func (ui *tui) press(keys string) {
+	for _, k := range parseKeys([]byte(keys)) {
+		ui.handleKey(k)
+	}
+}
+
+func (ui *tui) comms() []string {
+	var res []string
+	v := ui.views[ui.current]
+	for _, row := range v.rows() {
+		comm, _ := v.cols["comm"].Get(row).Interface().(string)
+		res = append(res, comm)
+	}
+	return res
+}
+
+func TestTUIArray(t *testing.T) {
+	d := newTUITestDS(t, datasource.TypeArray)
+	ui, out, canceled := newTestTUI(t, 10)
+	require.NoError(t, ui.addView(d.ds, nil))
+
+	d.emitArray(t, []string{"bash", "curl", "sshd"}, []uint32{2, 7, 5})
+	assert.Equal(t, []string{"bash", "curl", "sshd"}, ui.comms())
+
+	// Select the second column and sort by it, then reverse the order
+	ui.press("\x1b[Cs")
+	assert.Equal(t, []string{"bash", "sshd", "curl"}, ui.comms())
+	ui.press("s")
+	assert.Equal(t, []string{"curl", "sshd", "bash"}, ui.comms())
+
+	// Filter
+	ui.press("/count:>=5\r")
+	assert.Equal(t, []string{"curl", "sshd"}, ui.comms())
+	ui.press("/\x7f10\r")
+	assert.Empty(t, ui.comms())
+	ui.press("/\x7f\x7f\x7f\x7f\x7f\x7f\x7f\x7f\x7f\x7f\r")
+	assert.Len(t, ui.comms(), 3)
+	ui.press("/nope:1\r")
+	assert.NotEmpty(t, ui.status)
+
+	// Pausing keeps the current data
+	ui.press("p")
+	d.emitArray(t, []string{"nginx"}, []uint32{1})
+	assert.Len(t, ui.comms(), 3)
+	ui.press("p")
+	assert.Equal(t, []string{"nginx"}, ui.comms())
+
+	ui.render()
+	assert.Contains(t, out.String(), "test | column: count | sort: -count")
+	assert.Contains(t, out.String(), "nginx")
+
+	// Nothing changed, nothing to render
+	out.Reset()
+	ui.render()
+	assert.Empty(t, out.String())
+
+	ui.press("q")
+	assert.True(t, *canceled)
+}
+
+func TestTUITail(t *testing.T) {
+	d := newTUITestDS(t, datasource.TypeSingle)
+	ui, out, _ := newTestTUI(t, 5)
+	require.NoError(t, ui.addView(d.ds, []string{"comm"}))
+
+	for i, comm := range []string{"a", "b", "c", "d", "e"} {
+		d.emitSingle(t, comm, uint32(i))
+	}
+
+	// Only 2 rows fit; the view follows the last events
+	ui.render()
+	lines := strings.Split(out.String(), "\r\n")
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "4-5/5")
+	assert.Equal(t, "COMM", strings.TrimSpace(strings.TrimSuffix(lines[1], escClearLine)))
+	assert.Equal(t, "d", strings.TrimSpace(strings.TrimSuffix(lines[2], escClearLine)))
+	assert.Equal(t, "e", strings.TrimSpace(strings.TrimSuffix(lines[3], escClearLine)))
+
+	// Scrolling up stops following
+	ui.press("\x1b[A")
+	d.emitSingle(t, "f", 5)
+	out.Reset()
+	ui.render()
+	assert.Contains(t, out.String(), "3-4/6")
+
+	// End follows again
+	ui.press("G")
+	out.Reset()
+	ui.render()
+	assert.Contains(t, out.String(), "5-6/6")
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 
@@ -339,6 +341,95 @@ func TestInventoryCacheDelete(t *testing.T) {
 	}
 }
 
+func TestInventoryCacheNodesEndpointsIngresses(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "192.168.0.10"},
+				{Type: v1.NodeExternalIP, Address: "203.0.113.10"},
+				{Type: v1.NodeHostName, Address: "node-1"},
+			},
+		},
+	}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "external-db-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "external-db"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"198.51.100.1"}},
+			{Addresses: []string{"198.51.100.2"}},
+		},
+	}
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Status: networkingv1.IngressStatus{
+			LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+				Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.100"}},
+			},
+		},
+	}
+
+	EnableK8sInventoryIngresses()
+	t.Cleanup(func() { k8sInventoryIngresses.Store(false) })
+
+	fakeClientSet := fake.NewSimpleClientset(node, slice, ingress)
+	cache := &inventoryCache{
+		clientset: fakeClientSet,
+	}
+	cache.Start()
+	defer cache.Stop()
+
+	require.Len(t, cache.GetNodes(), 1)
+	require.NotNil(t, cache.GetNodeByName("node-1"))
+	for _, ip := range []string{"192.168.0.10", "203.0.113.10"} {
+		n := cache.GetNodeByIp(ip)
+		require.NotNil(t, n, ip)
+		assert.Equal(t, "node-1", n.Name)
+	}
+	assert.Nil(t, cache.GetNodeByIp("node-1"))
+
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2"} {
+		s := cache.GetEndpointSliceByIp(ip)
+		require.NotNil(t, s, ip)
+		assert.Equal(t, "external-db", s.ServiceName)
+		assert.Equal(t, "default", s.Namespace)
+	}
+
+	i := cache.GetIngressByIp("203.0.113.100")
+	require.NotNil(t, i)
+	assert.Equal(t, "web", i.Name)
+
+	// Endpoints that are gone are removed from the index
+	updatedSlice := slice.DeepCopy()
+	updatedSlice.Endpoints = updatedSlice.Endpoints[:1]
+	cache.OnUpdate(slice, updatedSlice)
+	cache.OnDelete(ingress)
+	require.Eventually(t, func() bool {
+		return cache.GetEndpointSliceByIp("198.51.100.2") == nil && cache.GetIngressByIp("203.0.113.100") == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.NotNil(t, cache.GetEndpointSliceByIp("198.51.100.1"))
+}
+
+func TestUpdateIpsKeepsOtherObjects(t *testing.T) {
+	m := cachedmap.NewCachedMap[string, *SlimEndpointSlice](time.Nanosecond)
+	defer m.Close()
+
+	a := &SlimEndpointSlice{SlimObjectMeta: SlimObjectMeta{Name: "a", Namespace: "default"}, Addresses: []string{"10.0.0.1"}}
+	b := &SlimEndpointSlice{SlimObjectMeta: SlimObjectMeta{Name: "b", Namespace: "default"}, Addresses: []string{"10.0.0.1"}}
+	updateIps(m, a.objectKey(), a, nil, a.Addresses)
+	updateIps(m, b.objectKey(), b, nil, b.Addresses)
+
+	// Removing a must not remove the IP taken over by b
+	updateIps(m, a.objectKey(), nil, a.Addresses, nil)
+	cur, ok := m.Get("10.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, "b", cur.Name)
+}
+
 func constructPod(name, namespace, ip string) *v1.Pod {
 	return &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,35 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// ContainerCollectionVar is the variable the local manager uses to share its
+// container collection with other operators
+const ContainerCollectionVar = "ContainerCollection"
+
+// GetContainerCollection returns the container collection shared by the local
+// manager of the gadget context, if any
+func GetContainerCollection(gadgetCtx operators.GadgetContext) (*containercollection.ContainerCollection, bool) {
+	v, ok := gadgetCtx.GetVar(ContainerCollectionVar)
+	if !ok {
+		return nil, false
+	}
+	cc, ok := v.(*containercollection.ContainerCollection)
+	return cc, ok && cc != nil
+}
@@ -15,8 +15,10 @@
 package common
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 )
@@ -50,3 +52,18 @@ func GetIPForVersion(data datasource.Data, version, ipAddr datasource.FieldAcces
 
 	return ipStr, nil
 }
+
+// GroupKey builds a key from the raw values of the given fields that is unique
+// for each combination of values. Every value is prefixed by its length, so
+// values of variable length like strings can't collide.
+func GroupKey(data datasource.Data, fields []datasource.FieldAccessor) string {
+	var sb strings.Builder
+	var l [4]byte
+	for _, f := range fields {
+		v := f.Get(data)
+		binary.LittleEndian.PutUint32(l[:], uint32(len(v)))
+		sb.Write(l[:])
+		sb.Write(v)
+	}
+	return sb.String()
+}
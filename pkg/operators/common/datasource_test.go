@@ -0,0 +1,53 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestGroupKey(t *testing.T) {
+	ds, err := datasource.New(datasource.TypeSingle, "test")
+	require.NoError(t, err)
+	a, err := ds.AddField("a", api.Kind_String)
+	require.NoError(t, err)
+	b, err := ds.AddField("b", api.Kind_String)
+	require.NoError(t, err)
+	n, err := ds.AddField("n", api.Kind_Uint32)
+	require.NoError(t, err)
+	fields := []datasource.FieldAccessor{a, b, n}
+
+	key := func(va, vb string, vn uint32) string {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		defer ds.Release(data)
+		require.NoError(t, a.PutString(data, va))
+		require.NoError(t, b.PutString(data, vb))
+		require.NoError(t, n.PutUint32(data, vn))
+		return GroupKey(data, fields)
+	}
+
+	assert.Equal(t, key("foo", "bar", 1), key("foo", "bar", 1))
+	assert.NotEqual(t, key("foo", "bar", 1), key("foo", "bar", 2))
+	// Values are length-prefixed, so moving bytes between fields changes the key
+	assert.NotEqual(t, key("foo", "bar", 1), key("foob", "ar", 1))
+	assert.NotEqual(t, key("", "foo", 1), key("foo", "", 1))
+}
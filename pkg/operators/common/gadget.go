@@ -0,0 +1,62 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// ImageShortName returns the last path component of an image name without tag
+// or digest, e.g. "trace_exec" for "ghcr.io/inspektor-gadget/gadget/trace_exec:latest"
+func ImageShortName(image string) string {
+	name, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// GadgetInfoForDataSources returns the serialized gadget info describing only
+// the given data sources, without params. The IDs of the data sources match
+// their index in dataSources, so it can be used to decode api.GadgetEvent
+// messages created for them.
+func GadgetInfoForDataSources(gadgetCtx operators.GadgetContext, dataSources []datasource.DataSource) (*api.GadgetInfo, error) {
+	gi, err := gadgetCtx.SerializeGadgetInfo(false)
+	if err != nil {
+		return nil, err
+	}
+	gi.Params = nil
+
+	infos := make(map[string]*api.DataSource, len(gi.DataSources))
+	for _, di := range gi.DataSources {
+		infos[di.Name] = di
+	}
+
+	gi.DataSources = make([]*api.DataSource, 0, len(dataSources))
+	for i, ds := range dataSources {
+		di, ok := infos[ds.Name()]
+		if !ok {
+			return nil, fmt.Errorf("data source %q not found", ds.Name())
+		}
+		di.Id = uint32(i)
+		gi.DataSources = append(gi.DataSources, di)
+	}
+	return gi, nil
+}
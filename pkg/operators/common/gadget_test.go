@@ -0,0 +1,29 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageShortName(t *testing.T) {
+	assert.Equal(t, "trace_exec", ImageShortName("ghcr.io/inspektor-gadget/gadget/trace_exec:latest"))
+	assert.Equal(t, "trace_exec", ImageShortName("trace_exec"))
+	assert.Equal(t, "trace_exec", ImageShortName("trace_exec:v1"))
+	assert.Equal(t, "trace_exec", ImageShortName("localhost:5000/trace_exec"))
+	assert.Equal(t, "trace_exec", ImageShortName("localhost:5000/trace_exec@sha256:1234"))
+}
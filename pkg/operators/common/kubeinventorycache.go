@@ -16,11 +16,15 @@ package common
 
 import (
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -106,6 +110,105 @@ func NewSlimService(s *v1.Service) *SlimService {
 	}
 }
 
+// SlimNode is a reduced version of v1.Node, it only contains the fields that
+// are needed to enrich events.
+type SlimNode struct {
+	metav1.TypeMeta `json:",inline"`
+	SlimObjectMeta  `json:",inline"`
+	Status          SlimNodeStatus `json:"status"`
+}
+
+type SlimNodeStatus struct {
+	// Addresses contains the internal and external IPs of the node
+	Addresses []string `json:"addresses"`
+}
+
+func NewSlimNode(n *v1.Node) *SlimNode {
+	var addresses []string
+	for _, addr := range n.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP || addr.Type == v1.NodeExternalIP {
+			addresses = append(addresses, addr.Address)
+		}
+	}
+	return &SlimNode{
+		TypeMeta: n.TypeMeta,
+		SlimObjectMeta: SlimObjectMeta{
+			Name:            n.Name,
+			ResourceVersion: n.ResourceVersion,
+			Labels:          n.Labels,
+			OwnerReferences: n.OwnerReferences,
+		},
+		Status: SlimNodeStatus{
+			Addresses: addresses,
+		},
+	}
+}
+
+// SlimEndpointSlice is a reduced version of discoveryv1.EndpointSlice, it only
+// contains the fields that are needed to enrich events.
+type SlimEndpointSlice struct {
+	metav1.TypeMeta `json:",inline"`
+	SlimObjectMeta  `json:",inline"`
+	// ServiceName is the name of the service the endpoints belong to
+	ServiceName string `json:"serviceName"`
+	// Addresses contains the IPs of all endpoints
+	Addresses []string `json:"addresses"`
+}
+
+func NewSlimEndpointSlice(e *discoveryv1.EndpointSlice) *SlimEndpointSlice {
+	var addresses []string
+	for _, endpoint := range e.Endpoints {
+		addresses = append(addresses, endpoint.Addresses...)
+	}
+	return &SlimEndpointSlice{
+		TypeMeta: e.TypeMeta,
+		SlimObjectMeta: SlimObjectMeta{
+			Name:            e.Name,
+			Namespace:       e.Namespace,
+			ResourceVersion: e.ResourceVersion,
+			Labels:          e.Labels,
+			OwnerReferences: e.OwnerReferences,
+		},
+		ServiceName: e.Labels[discoveryv1.LabelServiceName],
+		Addresses:   addresses,
+	}
+}
+
+// SlimIngress is a reduced version of networkingv1.Ingress, it only contains
+// the fields that are needed to enrich events.
+type SlimIngress struct {
+	metav1.TypeMeta `json:",inline"`
+	SlimObjectMeta  `json:",inline"`
+	Status          SlimIngressStatus `json:"status"`
+}
+
+type SlimIngressStatus struct {
+	// Addresses contains the IPs of the load balancers of the ingress
+	Addresses []string `json:"addresses"`
+}
+
+func NewSlimIngress(i *networkingv1.Ingress) *SlimIngress {
+	var addresses []string
+	for _, lb := range i.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			addresses = append(addresses, lb.IP)
+		}
+	}
+	return &SlimIngress{
+		TypeMeta: i.TypeMeta,
+		SlimObjectMeta: SlimObjectMeta{
+			Name:            i.Name,
+			Namespace:       i.Namespace,
+			ResourceVersion: i.ResourceVersion,
+			Labels:          i.Labels,
+			OwnerReferences: i.OwnerReferences,
+		},
+		Status: SlimIngressStatus{
+			Addresses: addresses,
+		},
+	}
+}
+
 // K8sInventoryCache is a cache of Kubernetes resources such as pods and services
 // that can be used by operators to enrich events.
 type K8sInventoryCache interface {
@@ -119,6 +222,19 @@ type K8sInventoryCache interface {
 	GetSvcs() []*SlimService
 	GetSvcByName(namespace string, name string) *SlimService
 	GetSvcByIp(ip string) *SlimService
+
+	GetNodes() []*SlimNode
+	GetNodeByName(name string) *SlimNode
+	GetNodeByIp(ip string) *SlimNode
+
+	// GetEndpointSliceByIp returns the EndpointSlice containing an endpoint
+	// with the given IP, e.g. a backend of a headless service or of a
+	// service without selector
+	GetEndpointSliceByIp(ip string) *SlimEndpointSlice
+
+	// GetIngressByIp returns the Ingress with the given load balancer IP;
+	// Ingresses are only indexed if enabled by EnableK8sInventoryIngresses()
+	GetIngressByIp(ip string) *SlimIngress
 }
 
 type inventoryCache struct {
@@ -133,6 +249,12 @@ type inventoryCache struct {
 	svcs     cachedmap.CachedMap[string, *SlimService]
 	svcsByIp cachedmap.CachedMap[string, *SlimService]
 
+	nodes            cachedmap.CachedMap[string, *SlimNode]
+	nodesByIp        cachedmap.CachedMap[string, *SlimNode]
+	endpointsByIp    cachedmap.CachedMap[string, *SlimEndpointSlice]
+	ingressesByIp    cachedmap.CachedMap[string, *SlimIngress]
+	ingressesEnabled bool
+
 	exit chan struct{}
 
 	useCount      int
@@ -147,8 +269,17 @@ var (
 	k8sInventorySingleton *inventoryCache
 	k8sInventoryErr       error
 	k8sInventoryOnce      sync.Once
+
+	k8sInventoryIngresses atomic.Bool
 )
 
+// EnableK8sInventoryIngresses makes the K8sInventoryCache index the load
+// balancer IPs of Ingresses as well; it takes effect the next time the cache
+// is started.
+func EnableK8sInventoryIngresses() {
+	k8sInventoryIngresses.Store(true)
+}
+
 func GetK8sInventoryCache() (K8sInventoryCache, error) {
 	k8sInventoryOnce.Do(func() {
 		k8sInventorySingleton, k8sInventoryErr = newCache()
@@ -193,6 +324,49 @@ func transformObject(obj any) (any, error) {
 			},
 		}
 		return s, nil
+	case *v1.Node:
+		n := &v1.Node{
+			TypeMeta: t.TypeMeta,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            t.Name,
+				ResourceVersion: t.ResourceVersion,
+				Labels:          t.Labels,
+				OwnerReferences: t.OwnerReferences,
+			},
+			Status: v1.NodeStatus{
+				Addresses: t.Status.Addresses,
+			},
+		}
+		return n, nil
+	case *discoveryv1.EndpointSlice:
+		e := &discoveryv1.EndpointSlice{
+			TypeMeta: t.TypeMeta,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            t.Name,
+				Namespace:       t.Namespace,
+				ResourceVersion: t.ResourceVersion,
+				Labels:          t.Labels,
+				OwnerReferences: t.OwnerReferences,
+			},
+			Endpoints: make([]discoveryv1.Endpoint, 0, len(t.Endpoints)),
+		}
+		for _, endpoint := range t.Endpoints {
+			e.Endpoints = append(e.Endpoints, discoveryv1.Endpoint{Addresses: endpoint.Addresses})
+		}
+		return e, nil
+	case *networkingv1.Ingress:
+		i := &networkingv1.Ingress{
+			TypeMeta: t.TypeMeta,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            t.Name,
+				Namespace:       t.Namespace,
+				ResourceVersion: t.ResourceVersion,
+				Labels:          t.Labels,
+				OwnerReferences: t.OwnerReferences,
+			},
+			Status: t.Status,
+		}
+		return i, nil
 	default:
 		return obj, nil
 	}
@@ -234,6 +408,22 @@ func (cache *inventoryCache) Close() {
 		cache.svcsByIp.Close()
 		cache.svcsByIp = nil
 	}
+	if cache.nodes != nil {
+		cache.nodes.Close()
+		cache.nodes = nil
+	}
+	if cache.nodesByIp != nil {
+		cache.nodesByIp.Close()
+		cache.nodesByIp = nil
+	}
+	if cache.endpointsByIp != nil {
+		cache.endpointsByIp.Close()
+		cache.endpointsByIp = nil
+	}
+	if cache.ingressesByIp != nil {
+		cache.ingressesByIp.Close()
+		cache.ingressesByIp = nil
+	}
 }
 
 func (cache *inventoryCache) Start() {
@@ -249,9 +439,19 @@ func (cache *inventoryCache) Start() {
 		cache.podsByIp = cachedmap.NewCachedMap[string, *SlimPod](2 * time.Second)
 		cache.svcs = cachedmap.NewCachedMap[string, *SlimService](2 * time.Second)
 		cache.svcsByIp = cachedmap.NewCachedMap[string, *SlimService](2 * time.Second)
+		cache.nodes = cachedmap.NewCachedMap[string, *SlimNode](2 * time.Second)
+		cache.nodesByIp = cachedmap.NewCachedMap[string, *SlimNode](2 * time.Second)
+		cache.endpointsByIp = cachedmap.NewCachedMap[string, *SlimEndpointSlice](2 * time.Second)
+		cache.ingressesByIp = cachedmap.NewCachedMap[string, *SlimIngress](2 * time.Second)
 
 		cache.factory.Core().V1().Pods().Informer().AddEventHandler(cache)
 		cache.factory.Core().V1().Services().Informer().AddEventHandler(cache)
+		cache.factory.Core().V1().Nodes().Informer().AddEventHandler(cache)
+		cache.factory.Discovery().V1().EndpointSlices().Informer().AddEventHandler(cache)
+		cache.ingressesEnabled = k8sInventoryIngresses.Load()
+		if cache.ingressesEnabled {
+			cache.factory.Networking().V1().Ingresses().Informer().AddEventHandler(cache)
+		}
 		cache.exit = make(chan struct{})
 		cache.factory.Start(cache.exit)
 		cache.factory.WaitForCacheSync(cache.exit)
@@ -310,6 +510,70 @@ func (cache *inventoryCache) GetSvcByIp(ip string) *SlimService {
 	return svc
 }
 
+func (cache *inventoryCache) GetNodes() []*SlimNode {
+	return cache.nodes.Values()
+}
+
+func (cache *inventoryCache) GetNodeByName(name string) *SlimNode {
+	node, found := cache.nodes.Get(name)
+	if !found {
+		return nil
+	}
+	return node
+}
+
+func (cache *inventoryCache) GetNodeByIp(ip string) *SlimNode {
+	node, found := cache.nodesByIp.Get(ip)
+	if !found {
+		return nil
+	}
+	return node
+}
+
+func (cache *inventoryCache) GetEndpointSliceByIp(ip string) *SlimEndpointSlice {
+	slice, found := cache.endpointsByIp.Get(ip)
+	if !found {
+		return nil
+	}
+	return slice
+}
+
+func (cache *inventoryCache) GetIngressByIp(ip string) *SlimIngress {
+	if cache.ingressesByIp == nil {
+		return nil
+	}
+	ingress, found := cache.ingressesByIp.Get(ip)
+	if !found {
+		return nil
+	}
+	return ingress
+}
+
+type slimObject interface {
+	objectKey() string
+}
+
+func (m *SlimObjectMeta) objectKey() string {
+	return m.Namespace + "/" + m.Name
+}
+
+// updateIps indexes obj by its new IPs and removes the IPs it doesn't have
+// anymore from the index, unless they have been taken over by another object
+// meanwhile
+func updateIps[T slimObject](m cachedmap.CachedMap[string, T], key string, obj T, oldIps, newIps []string) {
+	for _, ip := range oldIps {
+		if slices.Contains(newIps, ip) {
+			continue
+		}
+		if cur, ok := m.Get(ip); ok && cur.objectKey() == key {
+			m.Remove(ip)
+		}
+	}
+	for _, ip := range newIps {
+		m.Add(ip, obj)
+	}
+}
+
 func (cache *inventoryCache) OnAdd(obj any, _ bool) {
 	switch o := obj.(type) {
 	case *v1.Pod:
@@ -342,12 +606,26 @@ func (cache *inventoryCache) OnAdd(obj any, _ bool) {
 		if ip := slimService.Spec.ClusterIP; ip != "" {
 			cache.svcsByIp.Add(ip, slimService)
 		}
+	case *v1.Node:
+		if o.Name == "" {
+			log.Warnf("OnAdd: empty key for node")
+			return
+		}
+		slimNode := NewSlimNode(o)
+		cache.nodes.Add(o.Name, slimNode)
+		updateIps(cache.nodesByIp, slimNode.objectKey(), slimNode, nil, slimNode.Status.Addresses)
+	case *discoveryv1.EndpointSlice:
+		slimSlice := NewSlimEndpointSlice(o)
+		updateIps(cache.endpointsByIp, slimSlice.objectKey(), slimSlice, nil, slimSlice.Addresses)
+	case *networkingv1.Ingress:
+		slimIngress := NewSlimIngress(o)
+		updateIps(cache.ingressesByIp, slimIngress.objectKey(), slimIngress, nil, slimIngress.Status.Addresses)
 	default:
 		log.Warnf("OnAdd: unknown object type: %T", o)
 	}
 }
 
-func (cache *inventoryCache) OnUpdate(_, newObj any) {
+func (cache *inventoryCache) OnUpdate(oldObj, newObj any) {
 	switch o := newObj.(type) {
 	case *v1.Pod:
 		key, err := k8sCache.MetaNamespaceKeyFunc(o)
@@ -379,6 +657,32 @@ func (cache *inventoryCache) OnUpdate(_, newObj any) {
 		if ip := slimService.Spec.ClusterIP; ip != "" {
 			cache.svcsByIp.Add(ip, slimService)
 		}
+	case *v1.Node:
+		if o.Name == "" {
+			log.Warnf("OnUpdate: empty key for node")
+			return
+		}
+		var oldIps []string
+		if old, ok := oldObj.(*v1.Node); ok {
+			oldIps = NewSlimNode(old).Status.Addresses
+		}
+		slimNode := NewSlimNode(o)
+		cache.nodes.Add(o.Name, slimNode)
+		updateIps(cache.nodesByIp, slimNode.objectKey(), slimNode, oldIps, slimNode.Status.Addresses)
+	case *discoveryv1.EndpointSlice:
+		var oldIps []string
+		if old, ok := oldObj.(*discoveryv1.EndpointSlice); ok {
+			oldIps = NewSlimEndpointSlice(old).Addresses
+		}
+		slimSlice := NewSlimEndpointSlice(o)
+		updateIps(cache.endpointsByIp, slimSlice.objectKey(), slimSlice, oldIps, slimSlice.Addresses)
+	case *networkingv1.Ingress:
+		var oldIps []string
+		if old, ok := oldObj.(*networkingv1.Ingress); ok {
+			oldIps = NewSlimIngress(old).Status.Addresses
+		}
+		slimIngress := NewSlimIngress(o)
+		updateIps(cache.ingressesByIp, slimIngress.objectKey(), slimIngress, oldIps, slimIngress.Status.Addresses)
 	default:
 		log.Warnf("OnUpdate: unknown object type: %T", o)
 	}
@@ -406,6 +710,16 @@ func (cache *inventoryCache) OnDelete(obj any) {
 		if ip := o.Spec.ClusterIP; ip != "" {
 			cache.svcsByIp.Remove(ip)
 		}
+	case *v1.Node:
+		cache.nodes.Remove(o.Name)
+		slimNode := NewSlimNode(o)
+		updateIps(cache.nodesByIp, slimNode.objectKey(), nil, slimNode.Status.Addresses, nil)
+	case *discoveryv1.EndpointSlice:
+		slimSlice := NewSlimEndpointSlice(o)
+		updateIps(cache.endpointsByIp, slimSlice.objectKey(), nil, slimSlice.Addresses, nil)
+	case *networkingv1.Ingress:
+		slimIngress := NewSlimIngress(o)
+		updateIps(cache.ingressesByIp, slimIngress.objectKey(), nil, slimIngress.Status.Addresses, nil)
 	case k8sCache.DeletedFinalStateUnknown:
 		cache.OnDelete(o.Obj)
 	default:
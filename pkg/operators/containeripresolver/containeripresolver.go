@@ -0,0 +1,293 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package containeripresolver provides an operator that enriches events by
+// looking up IP addresses in the containers of the container collection shared
+// by the local manager. The addresses are read from the network namespaces of
+// the containers. It's the counterpart of the KubeIPResolver for hosts without
+// Kubernetes.
+package containeripresolver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	OperatorName = "ContainerIPResolver"
+	Priority     = 11
+)
+
+const (
+	endpointL4Type = "gadget_l4endpoint_t"
+	ipAddrType     = "gadget_ip_addr_t"
+)
+
+type ContainerIPResolver struct{}
+
+func (c *ContainerIPResolver) Name() string {
+	return OperatorName
+}
+
+func (c *ContainerIPResolver) Description() string {
+	return "ContainerIPResolver resolves IP addresses to container names"
+}
+
+func (c *ContainerIPResolver) Init(params *params.Params) error {
+	return nil
+}
+
+func (c *ContainerIPResolver) GlobalParams() api.Params {
+	return nil
+}
+
+func (c *ContainerIPResolver) InstanceParams() api.Params {
+	return nil
+}
+
+func (c *ContainerIPResolver) Priority() int {
+	return Priority
+}
+
+type endpointAccessors struct {
+	root              datasource.FieldAccessor
+	ip                datasource.FieldAccessor
+	version           datasource.FieldAccessor
+	subContainerName  datasource.FieldAccessor
+	subContainerID    datasource.FieldAccessor
+	subRuntimeName    datasource.FieldAccessor
+	subContainerImage datasource.FieldAccessor
+
+	column datasource.FieldAccessor
+	port   datasource.FieldAccessor
+}
+
+func (c *ContainerIPResolver) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	logger := gadgetCtx.Logger()
+
+	// The container collection is only shared by the local manager, i.e.
+	// there's nothing to do when running on Kubernetes or remotely
+	collection, ok := common.GetContainerCollection(gadgetCtx)
+	if !ok {
+		logger.Debugf("ContainerIPResolverOperator: no container collection available")
+		return nil, nil
+	}
+
+	epAccessors := make(map[datasource.DataSource][]endpointAccessors)
+	for _, ds := range gadgetCtx.GetDataSources() {
+		logger.Debugf("ContainerIPResolverOperator inspecting datasource %q", ds.Name())
+
+		endpoints := ds.GetFieldsWithTag("type:" + endpointL4Type)
+		if len(endpoints) == 0 {
+			logger.Debugf("> no endpoint fields found")
+			continue
+		}
+
+		logger.Debugf("> found %d endpoint fields", len(endpoints))
+		for _, ep := range endpoints {
+			// validate the endpoint fields
+			ips := ep.GetSubFieldsWithTag("type:" + ipAddrType)
+			if len(ips) != 1 {
+				return nil, fmt.Errorf("%s: expected %d %q field, got %d", ep.Name(), 1, ipAddrType, len(ips))
+			}
+
+			if ips[0].Size() != 16 {
+				return nil, fmt.Errorf("%s: expected %q field to have size %d, got %d", ep.Name(), ipAddrType, 16, ips[0].Size())
+			}
+
+			version := ep.GetSubFieldsWithTag("name:version")
+			if len(version) != 1 {
+				return nil, fmt.Errorf("%s: expected %d %q field, got %d", ep.Name(), 1, "version", len(version))
+			}
+
+			// Add subfields for container metadata to the endpoint, named
+			// like the ones the local manager adds to events
+			runtimeSubAcc, err := ep.AddSubField("runtime", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+			if err != nil {
+				return nil, fmt.Errorf("adding field %q: %w", "runtime", err)
+			}
+			containerNameAcc, err := runtimeSubAcc.AddSubField("containerName",
+				api.Kind_String,
+				datasource.WithAnnotations(map[string]string{
+					metadatav1.TemplateAnnotation: "container",
+				}),
+				datasource.WithFlags(datasource.FieldFlagHidden),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("adding field %q: %w", "containerName", err)
+			}
+			containerIDAcc, err := runtimeSubAcc.AddSubField("containerId",
+				api.Kind_String,
+				datasource.WithAnnotations(map[string]string{
+					metadatav1.ColumnsWidthAnnotation:    "13",
+					metadatav1.ColumnsMaxWidthAnnotation: "64",
+				}),
+				datasource.WithFlags(datasource.FieldFlagHidden),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("adding field %q: %w", "containerId", err)
+			}
+			runtimeNameAcc, err := runtimeSubAcc.AddSubField("runtimeName",
+				api.Kind_String,
+				datasource.WithAnnotations(map[string]string{
+					metadatav1.ColumnsWidthAnnotation: "19",
+					metadatav1.ColumnsFixedAnnotation: "true",
+				}),
+				datasource.WithFlags(datasource.FieldFlagHidden),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("adding field %q: %w", "runtimeName", err)
+			}
+			containerImageAcc, err := runtimeSubAcc.AddSubField("containerImageName", api.Kind_String, datasource.WithFlags(datasource.FieldFlagHidden))
+			if err != nil {
+				return nil, fmt.Errorf("adding field %q: %w", "containerImageName", err)
+			}
+
+			// control how the field is displayed
+			var endpointColAcc datasource.FieldAccessor
+			if ec := ep.GetSubFieldsWithTag("endpoint"); len(ec) == 1 {
+				endpointColAcc = ec[0]
+			}
+			var portAcc datasource.FieldAccessor
+			if p := ep.GetSubFieldsWithTag("name:port"); len(p) == 1 && p[0].Size() == 2 {
+				portAcc = p[0]
+			}
+
+			ea := endpointAccessors{
+				root:              ep,
+				ip:                ips[0],
+				version:           version[0],
+				subContainerName:  containerNameAcc,
+				subContainerID:    containerIDAcc,
+				subRuntimeName:    runtimeNameAcc,
+				subContainerImage: containerImageAcc,
+				column:            endpointColAcc,
+				port:              portAcc,
+			}
+			epAccessors[ds] = append(epAccessors[ds], ea)
+		}
+	}
+
+	// No endpoints found, nothing to do
+	if len(epAccessors) == 0 {
+		return nil, nil
+	}
+
+	hostNetns, err := containerutils.GetNetNs(1)
+	if err != nil {
+		logger.Debugf("getting host network namespace: %s", err)
+	}
+
+	return &ContainerIPResolverInstance{
+		collection:         collection,
+		index:              newIPIndex(containerutils.GetIPs, hostNetns),
+		subscriptionKey:    uuid.New().String(),
+		endpointsAccessors: epAccessors,
+	}, nil
+}
+
+type ContainerIPResolverInstance struct {
+	collection         *containercollection.ContainerCollection
+	index              *ipIndex
+	subscriptionKey    string
+	endpointsAccessors map[datasource.DataSource][]endpointAccessors
+}
+
+func (m *ContainerIPResolverInstance) Name() string {
+	return "ContainerIPResolverInstance"
+}
+
+func (m *ContainerIPResolverInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	// Keep the index in sync with the containers; this includes all
+	// containers, not only the ones the gadget is filtering for
+	containers := m.collection.Subscribe(m.subscriptionKey, containercollection.ContainerSelector{},
+		func(event containercollection.PubSubEvent) {
+			switch event.Type {
+			case containercollection.EventTypeAddContainer:
+				m.index.add(event.Container)
+			case containercollection.EventTypeRemoveContainer:
+				m.index.remove(event.Container)
+			}
+		},
+	)
+	for _, container := range containers {
+		m.index.add(container)
+	}
+	m.index.start()
+	return nil
+}
+
+func (m *ContainerIPResolverInstance) Start(gadgetCtx operators.GadgetContext) error {
+	for ds, acc := range m.endpointsAccessors {
+		err := ds.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
+			var errs error
+			for _, a := range acc {
+				addrStr, err := common.GetIPForVersion(data, a.version, a.ip)
+				if err != nil {
+					errs = errors.Join(errs, fmt.Errorf("%s: getting IP: %w", a.root.Name(), err))
+					continue
+				}
+
+				container := m.index.lookup(addrStr)
+				if container == nil {
+					continue
+				}
+
+				a.subContainerName.Set(data, []byte(container.Runtime.ContainerName))
+				a.subContainerID.Set(data, []byte(container.Runtime.ContainerID))
+				a.subRuntimeName.Set(data, []byte(container.Runtime.RuntimeName))
+				a.subContainerImage.Set(data, []byte(container.Runtime.ContainerImageName))
+				if a.column != nil && a.port != nil {
+					p, _ := a.port.Uint16(data)
+					v := fmt.Sprintf("c/%s:%d", container.Runtime.ContainerName, p)
+					a.column.Set(data, []byte(v))
+				}
+			}
+			return errs
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to data source %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (m *ContainerIPResolverInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (m *ContainerIPResolverInstance) PostStop(gadgetCtx operators.GadgetContext) error {
+	m.collection.Unsubscribe(m.subscriptionKey)
+	m.index.stop()
+	return nil
+}
+
+func (m *ContainerIPResolverInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func init() {
+	operators.RegisterDataOperator(&ContainerIPResolver{})
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containeripresolver
+
+import (
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+)
+
+const (
+	// refreshInterval is the time between two attempts to get the IPs of
+	// containers that didn't have any yet
+	refreshInterval = time.Second
+
+	// maxAttempts is the number of attempts to get the IPs of a container
+	// before giving up
+	maxAttempts = 10
+)
+
+// ipIndex maps the IP addresses of containers to the containers. The addresses
+// are read from the network namespaces of the containers when they are added.
+// As the network of a container might not be set up yet at that time,
+// containers without addresses are retried in the background a few times.
+type ipIndex struct {
+	mu sync.RWMutex
+
+	// getIPs returns the IPs of the network namespace of the given pid
+	getIPs func(pid int) ([]net.IP, error)
+	// hostNetns is the network namespace of the host; containers using it
+	// are not indexed
+	hostNetns uint64
+
+	containers map[string][]*containercollection.Container
+	ipsByID    map[string][]string
+	pending    map[string]*pendingContainer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// pendingContainer is a container whose IPs couldn't be read yet
+type pendingContainer struct {
+	container *containercollection.Container
+	attempts  int
+}
+
+func newIPIndex(getIPs func(pid int) ([]net.IP, error), hostNetns uint64) *ipIndex {
+	return &ipIndex{
+		getIPs:     getIPs,
+		hostNetns:  hostNetns,
+		containers: make(map[string][]*containercollection.Container),
+		ipsByID:    make(map[string][]string),
+		pending:    make(map[string]*pendingContainer),
+	}
+}
+
+// start retries getting the IPs of pending containers in the background until
+// stop is called
+func (idx *ipIndex) start() {
+	idx.done = make(chan struct{})
+	idx.wg.Add(1)
+	go func() {
+		defer idx.wg.Done()
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-idx.done:
+				return
+			case <-ticker.C:
+				idx.refresh()
+			}
+		}
+	}()
+}
+
+func (idx *ipIndex) stop() {
+	if idx.done == nil {
+		return
+	}
+	close(idx.done)
+	idx.wg.Wait()
+	idx.done = nil
+}
+
+// readIPs returns the IPs of the container; the network namespace is entered
+// to read them, so idx.mu must not be held
+func (idx *ipIndex) readIPs(container *containercollection.Container) []net.IP {
+	ips, err := idx.getIPs(int(container.ContainerPid()))
+	if err != nil {
+		log.Debugf("getting IPs of container %q: %s", container.Runtime.ContainerName, err)
+		return nil
+	}
+	return ips
+}
+
+func (idx *ipIndex) add(container *containercollection.Container) {
+	if container.HostNetwork || (idx.hostNetns != 0 && container.Netns == idx.hostNetns) {
+		return
+	}
+
+	ips := idx.readIPs(container)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if len(ips) == 0 {
+		idx.pending[container.Runtime.ContainerID] = &pendingContainer{container: container, attempts: 1}
+		return
+	}
+	idx.index(container, ips)
+}
+
+// refresh tries again to get the IPs of pending containers
+func (idx *ipIndex) refresh() {
+	idx.mu.RLock()
+	pending := make([]*pendingContainer, 0, len(idx.pending))
+	for _, p := range idx.pending {
+		pending = append(pending, p)
+	}
+	idx.mu.RUnlock()
+
+	for _, p := range pending {
+		ips := idx.readIPs(p.container)
+
+		idx.mu.Lock()
+		id := p.container.Runtime.ContainerID
+		if idx.pending[id] != p {
+			// Removed meanwhile
+			idx.mu.Unlock()
+			continue
+		}
+		if len(ips) > 0 {
+			delete(idx.pending, id)
+			idx.index(p.container, ips)
+		} else if p.attempts++; p.attempts >= maxAttempts {
+			log.Debugf("giving up getting IPs of container %q", p.container.Runtime.ContainerName)
+			delete(idx.pending, id)
+		}
+		idx.mu.Unlock()
+	}
+}
+
+// index adds the IPs of the container to the index; idx.mu must be held
+func (idx *ipIndex) index(container *containercollection.Container, ips []net.IP) {
+	id := container.Runtime.ContainerID
+	for _, ip := range ips {
+		ipStr := ip.String()
+		// Containers sharing the network namespace share the IPs as well
+		if !slices.Contains(idx.containers[ipStr], container) {
+			idx.containers[ipStr] = append(idx.containers[ipStr], container)
+		}
+		idx.ipsByID[id] = append(idx.ipsByID[id], ipStr)
+	}
+}
+
+func (idx *ipIndex) remove(container *containercollection.Container) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	id := container.Runtime.ContainerID
+	delete(idx.pending, id)
+	for _, ip := range idx.ipsByID[id] {
+		containers := slices.DeleteFunc(idx.containers[ip], func(c *containercollection.Container) bool {
+			return c.Runtime.ContainerID == id
+		})
+		if len(containers) == 0 {
+			delete(idx.containers, ip)
+			continue
+		}
+		idx.containers[ip] = containers
+	}
+	delete(idx.ipsByID, id)
+}
+
+// lookup returns the container having the given IP, or nil if there is none
+func (idx *ipIndex) lookup(ip string) *containercollection.Container {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if containers := idx.containers[ip]; len(containers) > 0 {
+		return containers[0]
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containeripresolver
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func newContainer(id string, pid uint32, netns uint64) *containercollection.Container {
+	return &containercollection.Container{
+		Runtime: containercollection.RuntimeMetadata{
+			BasicRuntimeMetadata: types.BasicRuntimeMetadata{
+				ContainerID:   id,
+				ContainerName: id,
+				ContainerPID:  pid,
+			},
+		},
+		Netns: netns,
+	}
+}
+
+func TestIPIndex(t *testing.T) {
+	ips := map[int][]net.IP{
+		100: {net.ParseIP("172.17.0.2"), net.ParseIP("fd00::2")},
+		200: {net.ParseIP("172.17.0.3")},
+	}
+	idx := newIPIndex(func(pid int) ([]net.IP, error) {
+		return ips[pid], nil
+	}, 1)
+
+	web := newContainer("web", 100, 10)
+	sidecar := newContainer("sidecar", 101, 10)
+	db := newContainer("db", 200, 20)
+	host := newContainer("host", 300, 1)
+
+	idx.add(web)
+	idx.add(db)
+	idx.add(host)
+	require.Equal(t, web, idx.lookup("172.17.0.2"))
+	require.Equal(t, web, idx.lookup("fd00::2"))
+	require.Equal(t, db, idx.lookup("172.17.0.3"))
+	require.Nil(t, idx.lookup("10.0.0.1"))
+	require.Empty(t, idx.pending)
+
+	// The sidecar shares the network namespace with web, but its IPs can't
+	// be read yet
+	idx.add(sidecar)
+	require.Len(t, idx.pending, 1)
+
+	// IPs of pending containers are read again when refreshing
+	ips[101] = ips[100]
+	idx.refresh()
+	require.Empty(t, idx.pending)
+
+	idx.remove(web)
+	require.Equal(t, sidecar, idx.lookup("172.17.0.2"))
+	idx.remove(sidecar)
+	require.Nil(t, idx.lookup("172.17.0.2"))
+	require.Equal(t, db, idx.lookup("172.17.0.3"))
+
+	idx.remove(db)
+	require.Empty(t, idx.containers)
+	require.Empty(t, idx.ipsByID)
+}
+
+func TestIPIndexGivesUp(t *testing.T) {
+	attempts := 0
+	idx := newIPIndex(func(pid int) ([]net.IP, error) {
+		attempts++
+		return nil, nil
+	}, 1)
+
+	idx.add(newContainer("web", 100, 10))
+	for i := 0; i < 2*maxAttempts; i++ {
+		idx.refresh()
+	}
+	require.Empty(t, idx.pending)
+	require.Equal(t, maxAttempts, attempts)
+}
+
+func TestIPIndexRefreshInBackground(t *testing.T) {
+	var mu sync.Mutex
+	var ips []net.IP
+	idx := newIPIndex(func(pid int) ([]net.IP, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return ips, nil
+	}, 1)
+	idx.start()
+	defer idx.stop()
+
+	web := newContainer("web", 100, 10)
+	idx.add(web)
+	require.Nil(t, idx.lookup("172.17.0.2"))
+
+	mu.Lock()
+	ips = []net.IP{net.ParseIP("172.17.0.2")}
+	mu.Unlock()
+	require.Eventually(t, func() bool {
+		return idx.lookup("172.17.0.2") == web
+	}, 5*refreshInterval, 10*time.Millisecond)
+}
@@ -0,0 +1,493 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dedup is a data operator that collapses identical events of data
+// sources of type single. Events with the same values for a user-chosen set of
+// fields that are seen within a time window are emitted only once, together
+// with the number of occurrences and the time of the first and last one.
+package dedup
+
+import (
+	"container/list"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name                 = "dedup"
+	ParamDedup           = "dedup"
+	ParamDedupWindow     = "dedup-window"
+	ParamDedupMaxEntries = "dedup-max-entries"
+
+	// Priority is after the filter operator, so that only matching events are
+	// collapsed, and before the ratelimit and aggregate operators, so that
+	// they work on the deduplicated data sources.
+	Priority = 9025
+
+	DataSourcePrefix   = "deduplicated"
+	FieldNameCount     = "count"
+	FieldNameFirstSeen = "first_seen"
+	FieldNameLastSeen  = "last_seen"
+
+	timestampFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+	// maxTick is the maximum interval in which expired windows are checked;
+	// it limits the delay added on top of the window
+	maxTick = 100 * time.Millisecond
+)
+
+type dedupOperator struct{}
+
+func (d *dedupOperator) Name() string {
+	return name
+}
+
+func (d *dedupOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (d *dedupOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (d *dedupOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamDedup,
+			Title: "Deduplicate",
+			Description: "Collapse events having the same values for the given fields within the dedup window into a " +
+				"single event with the number of occurrences. Join multiple fields with ','. " +
+				"If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+		},
+		{
+			Key:   ParamDedupWindow,
+			Title: "Deduplication Window",
+			Description: "Time window starting with the first occurrence of an event in which identical events are " +
+				"collapsed. If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','",
+			DefaultValue: "1s",
+			TypeHint:     api.TypeString,
+		},
+		{
+			Key:   ParamDedupMaxEntries,
+			Title: "Deduplication Max Entries",
+			Description: "Maximum number of distinct events to collapse at a time per data source; if exceeded, the " +
+				"oldest ones are emitted before their window is over",
+			DefaultValue: "16384",
+			TypeHint:     api.TypeUint,
+		},
+	}
+}
+
+func (d *dedupOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	dedupBy, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamDedup])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamDedup, err)
+	}
+	if len(dedupBy) == 0 {
+		return nil, nil
+	}
+
+	windows, err := apihelpers.GetDurationValuesPerDataSource(instanceParamValues[ParamDedupWindow])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamDedupWindow, err)
+	}
+
+	maxEntries, err := strconv.ParseUint(instanceParamValues[ParamDedupMaxEntries], 10, 32)
+	if err != nil || maxEntries == 0 {
+		return nil, fmt.Errorf("invalid %s %q", ParamDedupMaxEntries, instanceParamValues[ParamDedupMaxEntries])
+	}
+
+	dataSources := gadgetCtx.GetDataSources()
+
+	_, global := dedupBy[""]
+	if !global {
+		for dsName := range dedupBy {
+			if _, ok := dataSources[dsName]; ok {
+				continue
+			}
+			if _, ok := dataSources[deduplicatedName(dsName)]; ok {
+				// Already deduplicated remotely
+				continue
+			}
+			return nil, fmt.Errorf("data source %q not found", dsName)
+		}
+	}
+
+	inst := &dedupOperatorInstance{}
+	for _, ds := range dataSources {
+		keys, ok := dedupBy[ds.Name()]
+		if global {
+			keys, ok = dedupBy[""]
+		}
+		if !ok || ds.Type() != datasource.TypeSingle {
+			if ok && !global {
+				return nil, fmt.Errorf("%s can only be used on data sources of type single", ParamDedup)
+			}
+			continue
+		}
+		if _, exists := dataSources[deduplicatedName(ds.Name())]; exists {
+			gadgetCtx.Logger().Debugf("dedup: data source %q is already deduplicated", ds.Name())
+			continue
+		}
+
+		window, ok := windows[ds.Name()]
+		if !ok {
+			window, ok = windows[""]
+		}
+		if !ok || window <= 0 {
+			return nil, fmt.Errorf("invalid %s for data source %q", ParamDedupWindow, ds.Name())
+		}
+
+		dd, err := newDeduplicator(gadgetCtx, ds, keys, window, int(maxEntries))
+		if err != nil {
+			return nil, fmt.Errorf("deduplicating data source %q: %w", ds.Name(), err)
+		}
+		inst.deduplicators = append(inst.deduplicators, dd)
+	}
+
+	if len(inst.deduplicators) == 0 {
+		return nil, nil
+	}
+	return inst, nil
+}
+
+func (d *dedupOperator) Priority() int {
+	return Priority
+}
+
+func deduplicatedName(dsName string) string {
+	return fmt.Sprintf("%s-%s", DataSourcePrefix, dsName)
+}
+
+// entry holds the first occurrence of an event (as a packet of the output
+// data source) and the number of times it has been seen
+type entry struct {
+	key    string
+	packet datasource.PacketSingle
+	count  uint64
+
+	// start is the time the entry was created and its window started
+	start time.Time
+	// first and last are the timestamps of the first and last event
+	first time.Time
+	last  time.Time
+
+	// elem is the element of the entry in deduplicator.order
+	elem *list.Element
+}
+
+type deduplicator struct {
+	ds         datasource.DataSource
+	outDs      datasource.DataSource
+	window     time.Duration
+	maxEntries int
+	keyFields  []datasource.FieldAccessor
+
+	// timestamp returns the time of an event, taken from the timestamp field
+	// of the data source if it has one
+	timestamp func(data datasource.Data, now time.Time) time.Time
+
+	// inFields and outFields hold the fields that carry their own payload;
+	// values of other fields (static members) are copied along with them
+	inFields  []datasource.FieldAccessor
+	outFields []datasource.FieldAccessor
+
+	countField     datasource.FieldAccessor
+	firstSeenField datasource.FieldAccessor
+	lastSeenField  datasource.FieldAccessor
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	// order holds the entries in the order they were created, which is also
+	// the order in which their windows expire
+	order   *list.List
+	expired []*entry
+}
+
+func newDeduplicator(
+	gadgetCtx operators.GadgetContext,
+	ds datasource.DataSource,
+	keys []string,
+	window time.Duration,
+	maxEntries int,
+) (*deduplicator, error) {
+	dd := &deduplicator{
+		ds:         ds,
+		window:     window,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*entry),
+		order:      list.New(),
+	}
+
+	dd.timestamp = func(data datasource.Data, now time.Time) time.Time { return now }
+	if tsFields := ds.GetFieldsWithTag("type:" + ebpftypes.TimestampTypeName); len(tsFields) > 0 {
+		ts := tsFields[0]
+		dd.timestamp = func(data datasource.Data, now time.Time) time.Time {
+			v, err := ts.Uint64(data)
+			if err != nil || v == 0 {
+				return now
+			}
+			return time.Unix(0, int64(v))
+		}
+	}
+
+	for _, fieldName := range keys {
+		f := ds.GetField(fieldName)
+		if f == nil {
+			return nil, fmt.Errorf("field %q not found", fieldName)
+		}
+		if f.Type() == api.Kind_Invalid {
+			return nil, fmt.Errorf("field %q cannot be used as key", fieldName)
+		}
+		dd.keyFields = append(dd.keyFields, f)
+	}
+
+	for _, fieldName := range []string{FieldNameCount, FieldNameFirstSeen, FieldNameLastSeen} {
+		if ds.GetField(fieldName) != nil {
+			return nil, fmt.Errorf("field %q already exists", fieldName)
+		}
+	}
+
+	// Disable original data source to avoid other operators subscribing to it
+	ds.Unreference()
+
+	outDs, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, deduplicatedName(ds.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("registering deduplicated data source: %w", err)
+	}
+	if err := ds.CopyFieldsTo(outDs); err != nil {
+		return nil, fmt.Errorf("copying fields: %w", err)
+	}
+	for k, v := range ds.Annotations() {
+		outDs.AddAnnotation(k, v)
+	}
+	outDs.AddTags(ds.Tags()...)
+	dd.outDs = outDs
+
+	// Accessors are returned in the same order for both data sources, as the
+	// fields have been copied
+	outAccessors := outDs.Accessors(false)
+	for i, f := range ds.Accessors(false) {
+		if datasource.FieldFlagEmpty.In(f.Flags()) || datasource.FieldFlagStaticMember.In(f.Flags()) {
+			continue
+		}
+		dd.inFields = append(dd.inFields, f)
+		dd.outFields = append(dd.outFields, outAccessors[i])
+	}
+
+	dd.countField, err = outDs.AddField(FieldNameCount, api.Kind_Uint64, datasource.WithAnnotations(map[string]string{
+		metadatav1.DescriptionAnnotation:      "Number of identical events in the deduplication window",
+		metadatav1.ColumnsAlignmentAnnotation: string(metadatav1.AlignmentRight),
+		metadatav1.ColumnsWidthAnnotation:     "8",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("adding field %q: %w", FieldNameCount, err)
+	}
+	dd.firstSeenField, err = outDs.AddField(FieldNameFirstSeen, api.Kind_String, datasource.WithAnnotations(map[string]string{
+		metadatav1.DescriptionAnnotation: "Time of the first occurrence of the event",
+		metadatav1.TemplateAnnotation:    "timestamp",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("adding field %q: %w", FieldNameFirstSeen, err)
+	}
+	dd.lastSeenField, err = outDs.AddField(FieldNameLastSeen, api.Kind_String, datasource.WithAnnotations(map[string]string{
+		metadatav1.DescriptionAnnotation: "Time of the last occurrence of the event",
+		metadatav1.TemplateAnnotation:    "timestamp",
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("adding field %q: %w", FieldNameLastSeen, err)
+	}
+
+	gadgetCtx.Logger().Debugf("dedup: deduplicating %q by %v into %q with a window of %s", ds.Name(), keys,
+		outDs.Name(), window)
+
+	return dd, nil
+}
+
+// expire moves e to the entries to be emitted on the next flush; dd.mu must be
+// held
+func (dd *deduplicator) expire(e *entry) {
+	dd.expired = append(dd.expired, e)
+	delete(dd.entries, e.key)
+	dd.order.Remove(e.elem)
+}
+
+func (dd *deduplicator) collect(data datasource.Data, now time.Time) error {
+	key := common.GroupKey(data, dd.keyFields)
+	ts := dd.timestamp(data, now)
+
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	if e, ok := dd.entries[key]; ok {
+		if now.Sub(e.start) < dd.window {
+			e.count++
+			e.last = ts
+			return nil
+		}
+		// The window of the entry is over but it hasn't been flushed yet;
+		// queue it and start a new one with this event
+		dd.expire(e)
+	}
+
+	if len(dd.entries) >= dd.maxEntries {
+		// Make room by emitting the oldest entry early
+		dd.expire(dd.order.Front().Value.(*entry))
+	}
+
+	p, err := dd.outDs.NewPacketSingle()
+	if err != nil {
+		return fmt.Errorf("creating new packet: %w", err)
+	}
+	// data may not be accessed after returning, so copy the values
+	for i, f := range dd.inFields {
+		if err := dd.outFields[i].Set(p, slices.Clone(f.Get(data))); err != nil {
+			dd.outDs.Release(p)
+			return fmt.Errorf("copying field %q: %w", f.FullName(), err)
+		}
+	}
+	e := &entry{
+		key:    key,
+		packet: p,
+		count:  1,
+		start:  now,
+		first:  ts,
+		last:   ts,
+	}
+	e.elem = dd.order.PushBack(e)
+	dd.entries[key] = e
+	return nil
+}
+
+// flush emits all entries whose window has expired at the given time or all
+// entries if force is set
+func (dd *deduplicator) flush(now time.Time, force bool) error {
+	dd.mu.Lock()
+	for dd.order.Len() > 0 {
+		e := dd.order.Front().Value.(*entry)
+		if !force && now.Sub(e.start) < dd.window {
+			// Entries are ordered by the start of their window
+			break
+		}
+		dd.expire(e)
+	}
+	expired := dd.expired
+	dd.expired = nil
+	dd.mu.Unlock()
+
+	// Keep the order in which events have been seen for the first time
+	slices.SortStableFunc(expired, func(a, b *entry) int {
+		return a.start.Compare(b.start)
+	})
+
+	for i, e := range expired {
+		dd.countField.PutUint64(e.packet, e.count)
+		dd.firstSeenField.PutString(e.packet, e.first.Format(timestampFormat))
+		dd.lastSeenField.PutString(e.packet, e.last.Format(timestampFormat))
+		if err := dd.outDs.EmitAndRelease(e.packet); err != nil {
+			for _, e := range expired[i+1:] {
+				dd.outDs.Release(e.packet)
+			}
+			return fmt.Errorf("emitting data: %w", err)
+		}
+	}
+	return nil
+}
+
+type dedupOperatorInstance struct {
+	deduplicators []*deduplicator
+	done          chan struct{}
+	wg            sync.WaitGroup
+}
+
+func (d *dedupOperatorInstance) Name() string {
+	return name
+}
+
+func (d *dedupOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for _, dd := range d.deduplicators {
+		err := dd.ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			if err := dd.collect(data, time.Now()); err != nil {
+				gadgetCtx.Logger().Warnf("dedup: %q: %v", ds.Name(), err)
+			}
+			return nil
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", dd.ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (d *dedupOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	d.done = make(chan struct{})
+	for _, dd := range d.deduplicators {
+		d.wg.Add(1)
+		go func(dd *deduplicator) {
+			defer d.wg.Done()
+			ticker := time.NewTicker(min(dd.window, maxTick))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-d.done:
+					return
+				case now := <-ticker.C:
+					if err := dd.flush(now, false); err != nil {
+						gadgetCtx.Logger().Errorf("Failed to emit deduplicated data for %q: %v", dd.outDs.Name(), err)
+					}
+				}
+			}
+		}(dd)
+	}
+	return nil
+}
+
+func (d *dedupOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if d.done == nil {
+		return nil
+	}
+	close(d.done)
+	d.wg.Wait()
+	d.done = nil
+
+	// Emit pending events so that they don't get lost
+	for _, dd := range d.deduplicators {
+		if err := dd.flush(time.Now(), true); err != nil {
+			gadgetCtx.Logger().Errorf("Failed to emit deduplicated data for %q: %v", dd.outDs.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (d *dedupOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &dedupOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+type event struct {
+	comm string
+	path string
+	pid  uint32
+	// timestamp is emitted as the timestamp field of the event if set
+	timestamp time.Time
+}
+
+type dedupedEvent struct {
+	event
+	count     uint64
+	firstSeen string
+	lastSeen  string
+}
+
+// run emits the given events to a data source called "events" and returns
+// the events received from the deduplicated data source
+func run(t *testing.T, events []event, delay time.Duration, paramValues api.ParamValues) ([]dedupedEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ds datasource.DataSource
+	var commF, pathF, pidF, tsF datasource.FieldAccessor
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+			require.NoError(t, err)
+			ds.AddAnnotation("foo", "bar")
+			commF, err = ds.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			pathF, err = ds.AddField("path", api.Kind_String)
+			require.NoError(t, err)
+			pidF, err = ds.AddField("pid", api.Kind_Uint32)
+			require.NoError(t, err)
+			tsF, err = ds.AddField("timestamp_raw", api.Kind_Uint64,
+				datasource.WithTags("type:"+ebpftypes.TimestampTypeName))
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			go func() {
+				for _, ev := range events {
+					data, err := ds.NewPacketSingle()
+					require.NoError(t, err)
+					require.NoError(t, commF.PutString(data, ev.comm))
+					require.NoError(t, pathF.PutString(data, ev.path))
+					require.NoError(t, pidF.PutUint32(data, ev.pid))
+					if !ev.timestamp.IsZero() {
+						require.NoError(t, tsF.PutUint64(data, uint64(ev.timestamp.UnixNano())))
+					}
+					require.NoError(t, ds.EmitAndRelease(data))
+					time.Sleep(delay)
+				}
+				cancel()
+			}()
+			return nil
+		}),
+	)
+
+	var res []dedupedEvent
+	consumer := simple.New("consumer",
+		simple.WithPriority(Priority+1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			outDs, ok := gadgetCtx.GetDataSources()[deduplicatedName("events")]
+			if !ok {
+				return nil
+			}
+			assert.Equal(t, "bar", outDs.Annotations()["foo"])
+
+			commF := outDs.GetField("comm")
+			pathF := outDs.GetField("path")
+			pidF := outDs.GetField("pid")
+			countF := outDs.GetField(FieldNameCount)
+			firstF := outDs.GetField(FieldNameFirstSeen)
+			lastF := outDs.GetField(FieldNameLastSeen)
+			return outDs.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				var ev dedupedEvent
+				var err error
+				ev.comm, err = commF.String(data)
+				require.NoError(t, err)
+				ev.path, err = pathF.String(data)
+				require.NoError(t, err)
+				ev.pid, err = pidF.Uint32(data)
+				require.NoError(t, err)
+				ev.count, err = countF.Uint64(data)
+				require.NoError(t, err)
+				ev.firstSeen, err = firstF.String(data)
+				require.NoError(t, err)
+				ev.lastSeen, err = lastF.String(data)
+				require.NoError(t, err)
+				res = append(res, ev)
+				return nil
+			}, Priority+1)
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(Operator, producer, consumer))
+	err := gadgetCtx.Run(paramValues)
+	return res, err
+}
+
+func TestDedup(t *testing.T) {
+	var events []event
+	for i := range 10 {
+		events = append(events,
+			event{comm: "cat", path: "/etc/passwd", pid: uint32(100 + i)},
+			event{comm: "ls", path: "/tmp", pid: 200},
+			event{comm: "cat", path: "/etc/shadow", pid: 300},
+		)
+	}
+
+	res, err := run(t, events, 0, api.ParamValues{
+		"operator.dedup.dedup":        "comm,path",
+		"operator.dedup.dedup-window": "1h",
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	// events are emitted in the order they have been seen first and keep the
+	// values of the first occurrence
+	assert.Equal(t, event{comm: "cat", path: "/etc/passwd", pid: 100}, res[0].event)
+	assert.Equal(t, event{comm: "ls", path: "/tmp", pid: 200}, res[1].event)
+	assert.Equal(t, event{comm: "cat", path: "/etc/shadow", pid: 300}, res[2].event)
+	for _, ev := range res {
+		assert.Equal(t, uint64(10), ev.count)
+
+		first, err := time.Parse(timestampFormat, ev.firstSeen)
+		require.NoError(t, err)
+		last, err := time.Parse(timestampFormat, ev.lastSeen)
+		require.NoError(t, err)
+		assert.False(t, last.Before(first))
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	res, err := run(t, []event{
+		{comm: "cat", pid: 1},
+		{comm: "cat", pid: 2},
+		{comm: "cat", pid: 3},
+		{comm: "cat", pid: 4},
+	}, 60*time.Millisecond, api.ParamValues{
+		"operator.dedup.dedup":        "events:comm",
+		"operator.dedup.dedup-window": "events:100ms",
+	})
+	require.NoError(t, err)
+
+	// the window is started by the first event and not extended by the
+	// following ones, so there must be more than a single event
+	require.Greater(t, len(res), 1)
+	total := uint64(0)
+	for _, ev := range res {
+		total += ev.count
+	}
+	assert.Equal(t, uint64(4), total)
+}
+
+func TestDedupErrors(t *testing.T) {
+	testCases := map[string]api.ParamValues{
+		"unknown data source": {
+			"operator.dedup.dedup": "foo:comm",
+		},
+		"unknown field": {
+			"operator.dedup.dedup": "foo",
+		},
+		"mixed data sources": {
+			"operator.dedup.dedup": "comm;events:path",
+		},
+		"invalid window": {
+			"operator.dedup.dedup":        "comm",
+			"operator.dedup.dedup-window": "foo",
+		},
+		"negative window": {
+			"operator.dedup.dedup":        "comm",
+			"operator.dedup.dedup-window": "-1s",
+		},
+		"invalid max entries": {
+			"operator.dedup.dedup":             "comm",
+			"operator.dedup.dedup-max-entries": "0",
+		},
+	}
+	for name, paramValues := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := run(t, nil, 0, paramValues)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	// keys must not collide when values are split differently between fields
+	res, err := run(t, []event{
+		{comm: "ab", path: "c"},
+		{comm: "a", path: "bc"},
+	}, 0, api.ParamValues{
+		"operator.dedup.dedup": "comm,path",
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	for i, ev := range res {
+		assert.Equal(t, uint64(1), ev.count, fmt.Sprintf("event %d", i))
+	}
+}
+
+func TestDedupTimestamps(t *testing.T) {
+	first := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	res, err := run(t, []event{
+		{comm: "cat", timestamp: first},
+		{comm: "cat", timestamp: first.Add(time.Second)},
+		{comm: "cat", timestamp: first.Add(2 * time.Second)},
+	}, 0, api.ParamValues{
+		"operator.dedup.dedup": "comm",
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	// first_seen and last_seen are taken from the events
+	assert.Equal(t, uint64(3), res[0].count)
+	assert.Equal(t, first.Local().Format(timestampFormat), res[0].firstSeen)
+	assert.Equal(t, first.Add(2*time.Second).Local().Format(timestampFormat), res[0].lastSeen)
+}
+
+func TestDedupMaxEntries(t *testing.T) {
+	res, err := run(t, []event{
+		{comm: "a"},
+		{comm: "b"},
+		{comm: "c"},
+		{comm: "a"},
+	}, 0, api.ParamValues{
+		"operator.dedup.dedup":             "comm",
+		"operator.dedup.dedup-window":      "1h",
+		"operator.dedup.dedup-max-entries": "2",
+	})
+	require.NoError(t, err)
+
+	// "a" is emitted early to make room for "c", so its second occurrence
+	// starts a new entry
+	var comms []string
+	for _, ev := range res {
+		comms = append(comms, ev.comm)
+		assert.Equal(t, uint64(1), ev.count)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, comms)
+}
@@ -17,13 +17,10 @@ package ebpfoperator
 import (
 	"fmt"
 	"net"
-	"runtime"
-	"strconv"
 	"strings"
 
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/link"
-	"golang.org/x/sys/unix"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/uprobetracer"
@@ -144,87 +141,55 @@ func (i *ebpfInstance) attachProgram(gadgetCtx operators.GadgetContext, p *ebpf.
 
 		i.logger.Debugf("Attaching sched_cls %q", p.Name)
 		return nil, handler.AttachProg(prog)
+	case ebpf.XDP:
+		handler := i.xdpHandlers[p.Name]
+
+		ifaceName := i.paramValues[ParamIface]
+		if ifaceName != "" {
+			iface, err := net.InterfaceByName(ifaceName)
+			if err != nil {
+				return nil, fmt.Errorf("getting interface %q: %w", ifaceName, err)
+			}
+
+			if err := handler.AttachIface(iface); err != nil {
+				return nil, fmt.Errorf("attaching iface %q: %w", ifaceName, err)
+			}
+		}
+
+		i.logger.Debugf("Attaching xdp %q", p.Name)
+		return nil, handler.AttachProg(prog)
+	case ebpf.CGroupSKB, ebpf.CGroupSock, ebpf.CGroupSockAddr, ebpf.CGroupSockopt,
+		ebpf.CGroupSysctl, ebpf.CGroupDevice:
+		handler := i.cgroupHandlers[p.Name]
+
+		if cgroupPath := i.paramValues[ParamCgroupPath]; cgroupPath != "" {
+			if err := handler.AttachCgroup(cgroupPath); err != nil {
+				return nil, fmt.Errorf("attaching cgroup %q: %w", cgroupPath, err)
+			}
+		}
+
+		i.logger.Debugf("Attaching cgroup program %q (%s)", p.Name, p.AttachType)
+		return nil, handler.AttachProg(prog)
 	case ebpf.LSM:
 		i.logger.Debugf("Attaching LSM %q to %q", p.Name, attachTo)
 		return link.AttachLSM(link.LSMOptions{
 			Program: prog,
 		})
 	case ebpf.PerfEvent:
-		perfType := uint32(unix.PERF_TYPE_SOFTWARE)
-		perfConfig := uint64(unix.PERF_COUNT_SW_CPU_CLOCK)
-		perfSampleType := uint64(unix.PERF_SAMPLE_RAW)
-		frequency := uint64(0)
 		name, ok := strings.CutPrefix(p.SectionName, perfEventPrefix)
 		if !ok {
 			return nil, fmt.Errorf("perf_event programs require a name")
 		}
 
-		switch tmp := i.config.GetString("programs." + name + ".perf.type"); tmp {
-		case "":
-			return nil, fmt.Errorf("perf.type not specified for program %q", name)
-		case "software":
-			perfType = unix.PERF_TYPE_SOFTWARE
-		default:
-			return nil, fmt.Errorf("unsupported perf.type %q", tmp)
-		}
-		switch tmp := i.config.GetString("programs." + name + ".perf.config"); tmp {
-		case "":
-			return nil, fmt.Errorf("perf.config not specified for program %q", name)
-		case "count_sw_cpu_clock":
-			perfConfig = unix.PERF_COUNT_SW_CPU_CLOCK
-		default:
-			return nil, fmt.Errorf("unsupported perf.config %q", tmp)
-		}
-		switch tmp := i.config.GetString("programs." + name + ".perf.sampleType"); tmp {
-		case "":
-			return nil, fmt.Errorf("perf.sampleType not specified for program %q", name)
-		case "sample_raw":
-			perfSampleType = unix.PERF_SAMPLE_RAW
-		default:
-			return nil, fmt.Errorf("unsupported perf.sampleType %q", tmp)
-		}
-		if tmpFrequency := i.config.GetString("programs." + name + ".sampler.frequency"); tmpFrequency != "" {
-			var err error
-			frequency, err = strconv.ParseUint(tmpFrequency, 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("parsing frequency %q for program %q: %w", tmpFrequency, name, err)
-			}
-		} else {
-			return nil, fmt.Errorf("sampler.frequency not specified for program %q", name)
+		cfg, err := i.perfEventConfig(name)
+		if err != nil {
+			return nil, err
 		}
-		if frequency == 0 {
-			return nil, fmt.Errorf("sampler.frequency is zero for program %q", name)
-		}
-		for cpu := 0; cpu < runtime.NumCPU(); cpu++ {
-			fd, err := unix.PerfEventOpen(
-				&unix.PerfEventAttr{
-					Type:        perfType,
-					Config:      perfConfig,
-					Sample_type: perfSampleType,
-					Sample:      frequency,
-					Bits:        unix.PerfBitFreq,
-				},
-				-1,
-				cpu,
-				-1,
-				unix.PERF_FLAG_FD_CLOEXEC,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("opening perf event: %w", err)
-			}
-			i.perfFds = append(i.perfFds, fd)
-
-			// Attach program to perf event.
-			if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_SET_BPF, prog.FD()); err != nil {
-				return nil, fmt.Errorf("attaching eBPF program to perf fd: %w", err)
-			}
 
-			// Start perf event.
-			if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
-				return nil, fmt.Errorf("enabling perf fd: %w", err)
-			}
-		}
-		return nil, nil
+		i.logger.Debugf("Attaching perf event %q", p.Name)
+		fds, err := openPerfEvents(cfg, prog, i.logger)
+		i.perfFds = append(i.perfFds, fds...)
+		return nil, err
 	default:
 		return nil, fmt.Errorf("unsupported program %q of type %q", p.Name, p.Type)
 	}
@@ -38,6 +38,7 @@ import (
 	"oras.land/oras-go/v2"
 
 	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/cgrouphandler"
 	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
@@ -53,6 +54,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/tchandler"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/uprobetracer"
 	ebpfutils "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/ebpf"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/xdphandler"
 )
 
 const (
@@ -61,7 +63,11 @@ const (
 	typeSplitter = "___"
 
 	ParamIface       = "iface"
+	ParamCgroupPath  = "cgroup-path"
 	ParamTraceKernel = "trace-pipe"
+	ParamXDPMode     = "xdp-mode"
+	ParamTCMode      = "tc-mode"
+	ParamTCOrder     = "tc-order"
 
 	kernelTypesVar = "kernelTypes"
 
@@ -79,6 +85,10 @@ type gadgetObjects struct {
 type ebpfOperator struct {
 	mu         sync.Mutex
 	gadgetObjs map[operators.GadgetContext]gadgetObjects
+
+	// tcMode and tcOrder are set by the global parameters
+	tcMode  tchandler.Mode
+	tcOrder tchandler.Order
 }
 
 func (o *ebpfOperator) Name() string {
@@ -134,8 +144,12 @@ func (o *ebpfOperator) InstantiateImageOperator(
 
 		networkTracers: make(map[string]*networktracer.Tracer[api.GadgetData]),
 		tcHandlers:     make(map[string]*tchandler.Handler),
+		xdpHandlers:    make(map[string]*xdphandler.Handler),
+		cgroupHandlers: make(map[string]*cgrouphandler.Handler),
 		uprobeTracers:  make(map[string]*uprobetracer.Tracer[api.GadgetData]),
 
+		progLinks: make(map[string]link.Link),
+
 		paramValues: paramValues,
 	}
 
@@ -180,6 +194,8 @@ type ebpfInstance struct {
 
 	networkTracers map[string]*networktracer.Tracer[api.GadgetData]
 	tcHandlers     map[string]*tchandler.Handler
+	xdpHandlers    map[string]*xdphandler.Handler
+	cgroupHandlers map[string]*cgrouphandler.Handler
 	uprobeTracers  map[string]*uprobetracer.Tracer[api.GadgetData]
 
 	// map from ebpf variable name to ebpfVar struct
@@ -188,6 +204,11 @@ type ebpfInstance struct {
 	links   []link.Link
 	perfFds []int
 
+	// progLinks holds the links that are closed when pausing the gadget; the
+	// names of those programs are moved to pausedProgs until resuming
+	progLinks   map[string]link.Link
+	pausedProgs []string
+
 	containers map[string]*containercollection.Container
 
 	enums      []*enum
@@ -391,16 +412,37 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 				return fmt.Errorf("unsupported hook type %q", parts[1])
 			}
 
-			handler, err := tchandler.NewHandler(direction)
+			handler, err := tchandler.NewHandler(direction,
+				tchandler.WithMode(i.bpfOperator.tcMode), tchandler.WithOrder(i.bpfOperator.tcOrder))
 			if err != nil {
 				return fmt.Errorf("creating tc network tracer: %w", err)
 			}
 
 			i.tcHandlers[p.Name] = handler
+		case ebpf.XDP:
+			side, err := xdphandler.ParseAttachmentSide(i.config.GetString("programs." + p.Name + ".xdp.attach"))
+			if err != nil {
+				return fmt.Errorf("program %q: %w", p.Name, err)
+			}
+			mode, err := xdphandler.ParseMode(i.paramValues[ParamXDPMode])
+			if err != nil {
+				return err
+			}
+
+			handler, err := xdphandler.NewHandler(side, mode)
+			if err != nil {
+				return fmt.Errorf("creating xdp handler: %w", err)
+			}
+
+			i.xdpHandlers[p.Name] = handler
+		default:
+			if cgrouphandler.IsCgroupProgram(p.Type) {
+				i.cgroupHandlers[p.Name] = cgrouphandler.NewHandler(p.AttachType)
+			}
 		}
 	}
 
-	if len(i.tcHandlers) > 0 {
+	if len(i.tcHandlers) > 0 || len(i.xdpHandlers) > 0 {
 		// For now, override enrichment
 		gadgetCtx.SetVar("NeedContainerEvents", true)
 		i.params["iface"] = &param{
@@ -411,6 +453,62 @@ func (i *ebpfInstance) init(gadgetCtx operators.GadgetContext) error {
 		}
 	}
 
+	if len(i.cgroupHandlers) > 0 {
+		gadgetCtx.SetVar("NeedContainerEvents", true)
+		i.params[ParamCgroupPath] = &param{
+			Param: &api.Param{
+				Key:         ParamCgroupPath,
+				Description: "Path of a cgroup v2 (e.g. /sys/fs/cgroup/system.slice) to attach cgroup programs to instead of the containers",
+			},
+		}
+	}
+
+	for _, p := range i.collectionSpec.Programs {
+		if p.Type != ebpf.PerfEvent {
+			continue
+		}
+		i.params[ParamPerfFrequency] = &param{
+			Param: &api.Param{
+				Key:          ParamPerfFrequency,
+				Description:  "Number of samples per second taken by perf event programs, overrides the sampling of the gadget if not 0",
+				DefaultValue: "0",
+				TypeHint:     api.TypeUint64,
+			},
+		}
+		i.params[ParamPerfPeriod] = &param{
+			Param: &api.Param{
+				Key:          ParamPerfPeriod,
+				Description:  "Number of events between samples taken by perf event programs, overrides the sampling of the gadget if not 0",
+				DefaultValue: "0",
+				TypeHint:     api.TypeUint64,
+			},
+		}
+		i.params[ParamPerfCPUs] = &param{
+			Param: &api.Param{
+				Key:         ParamPerfCPUs,
+				Description: "CPUs to run perf event programs on, e.g. 0-3,6. All CPUs if not set",
+			},
+		}
+		i.params[ParamPerfCgroup] = &param{
+			Param: &api.Param{
+				Key:         ParamPerfCgroup,
+				Description: "Path of a cgroup (e.g. /sys/fs/cgroup/system.slice) to restrict perf event programs to",
+			},
+		}
+		break
+	}
+
+	if len(i.xdpHandlers) > 0 {
+		i.params[ParamXDPMode] = &param{
+			Param: &api.Param{
+				Key:            ParamXDPMode,
+				Description:    "Mode used to attach XDP programs; auto uses native mode if supported by the driver and falls back to generic mode otherwise",
+				DefaultValue:   string(xdphandler.ModeAuto),
+				PossibleValues: []string{string(xdphandler.ModeAuto), string(xdphandler.ModeGeneric), string(xdphandler.ModeNative)},
+			},
+		}
+	}
+
 	i.params[ParamTraceKernel] = &param{
 		Param: &api.Param{
 			Key:          ParamTraceKernel,
@@ -785,8 +883,14 @@ func (i *ebpfInstance) Start(gadgetCtx operators.GadgetContext) error {
 
 		i.links = append(i.links, l)
 
+		isIter := p.Type == ebpf.Tracing && strings.HasPrefix(p.SectionName, iterPrefix)
+		if !isIter {
+			// Links of all programs but iterators are closed when pausing
+			i.progLinks[progName] = l
+		}
+
 		// We need to store iterators' links because we need them to run the programs
-		if p.Type == ebpf.Tracing && strings.HasPrefix(p.SectionName, iterPrefix) {
+		if isIter {
 			lIter, ok := l.(*link.Iter)
 			if !ok {
 				return fmt.Errorf("link is not an iterator")
@@ -867,6 +971,12 @@ func (i *ebpfInstance) Close(gadgetCtx operators.GadgetContext) error {
 	for _, handler := range i.tcHandlers {
 		handler.Close()
 	}
+	for _, handler := range i.xdpHandlers {
+		handler.Close()
+	}
+	for _, handler := range i.cgroupHandlers {
+		handler.Close()
+	}
 	for _, uprobeTracer := range i.uprobeTracers {
 		uprobeTracer.Close()
 	}
@@ -897,6 +1007,19 @@ func (i *ebpfInstance) AttachContainer(container *containercollection.Container)
 				return err
 			}
 		}
+		for _, handler := range i.xdpHandlers {
+			if err := handler.AttachContainer(container); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cgroupPath := i.paramValues[ParamCgroupPath]; cgroupPath == "" {
+		for _, handler := range i.cgroupHandlers {
+			if err := handler.AttachContainer(container); err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, handler := range i.uprobeTracers {
@@ -925,6 +1048,19 @@ func (i *ebpfInstance) DetachContainer(container *containercollection.Container)
 				return err
 			}
 		}
+		for _, handler := range i.xdpHandlers {
+			if err := handler.DetachContainer(container); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cgroupPath := i.paramValues[ParamCgroupPath]; cgroupPath == "" {
+		for _, handler := range i.cgroupHandlers {
+			if err := handler.DetachContainer(container); err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, uTracer := range i.uprobeTracers {
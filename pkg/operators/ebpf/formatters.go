@@ -115,6 +115,8 @@ func getFormattersForEnums(enums []*enum, ds datasource.DataSource, btfSpec *btf
 
 		var formatter func(ds datasource.DataSource, data datasource.Data) error
 
+		getVal := enumValueFunc(in, enum.Signed, ds)
+
 		isBitField := strings.HasSuffix(enum.Name, "_set")
 		if isBitField {
 			separator := in.Annotations()[enumBitfieldSeparatorAnnotation]
@@ -123,8 +125,7 @@ func getFormattersForEnums(enums []*enum, ds datasource.DataSource, btfSpec *btf
 			}
 
 			formatter = func(ds datasource.DataSource, data datasource.Data) error {
-				inBytes := in.Get(data)
-				val := byteSliceAsUint64(inBytes, enum.Signed, ds)
+				val := getVal(data)
 
 				var arr []string
 				for _, v := range enum.Values {
@@ -138,8 +139,7 @@ func getFormattersForEnums(enums []*enum, ds datasource.DataSource, btfSpec *btf
 		} else {
 			formatter = func(ds datasource.DataSource, data datasource.Data) error {
 				// TODO: lookup table?
-				inBytes := in.Get(data)
-				val := byteSliceAsUint64(inBytes, enum.Signed, ds)
+				val := getVal(data)
 				for _, v := range enum.Values {
 					if val == v.Value {
 						return out.Set(data, []byte(v.Name))
@@ -155,6 +155,26 @@ func getFormattersForEnums(enums []*enum, ds datasource.DataSource, btfSpec *btf
 	return formatters, nil
 }
 
+// enumValueFunc returns a function reading the value of an enum field; integer fields with
+// matching signedness are read using the accessor, as it takes care of C bitfields
+func enumValueFunc(in datasource.FieldAccessor, signed bool, ds datasource.DataSource) func(datasource.Data) uint64 {
+	switch in.Type() {
+	case api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64:
+		if signed {
+			asInt64, _ := datasource.AsInt64(in)
+			return func(data datasource.Data) uint64 { return uint64(asInt64(data)) }
+		}
+	case api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64:
+		if !signed {
+			asInt64, _ := datasource.AsInt64(in)
+			return func(data datasource.Data) uint64 { return uint64(asInt64(data)) }
+		}
+	}
+	return func(data datasource.Data) uint64 {
+		return byteSliceAsUint64(in.Get(data), signed, ds)
+	}
+}
+
 func (i *ebpfInstance) initStackConverter(gadgetCtx operators.GadgetContext) error {
 	var kernelSymbolResolver *kallsyms.KAllSyms = nil
 	for _, ds := range gadgetCtx.GetDataSources() {
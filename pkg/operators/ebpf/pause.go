@@ -0,0 +1,76 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/cilium/ebpf/link"
+	"golang.org/x/sys/unix"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// Pause detaches all programs attached using links and disables perf events.
+// The collection stays loaded, so maps keep their content. Iterators and
+// programs managed by handlers attaching them per container, interface or
+// cgroup (socket filters, tc, xdp and cgroup programs as well as uprobes) stay
+// attached and keep running; the events they emit are dropped by the instance
+// manager. Pause and Resume are serialized with Start and Stop by the gadget
+// context.
+func (i *ebpfInstance) Pause(gadgetCtx operators.GadgetContext) error {
+	for progName, l := range i.progLinks {
+		i.logger.Debugf("detaching eBPF program %q", progName)
+		gadgets.CloseLink(l)
+		i.links = slices.DeleteFunc(i.links, func(other link.Link) bool {
+			return other == l
+		})
+		i.pausedProgs = append(i.pausedProgs, progName)
+	}
+	clear(i.progLinks)
+	sort.Strings(i.pausedProgs)
+
+	for _, fd := range i.perfFds {
+		if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_DISABLE, 0); err != nil {
+			return fmt.Errorf("disabling perf fd: %w", err)
+		}
+	}
+	return nil
+}
+
+// Resume re-attaches the programs detached by Pause and enables perf events
+// again
+func (i *ebpfInstance) Resume(gadgetCtx operators.GadgetContext) error {
+	for len(i.pausedProgs) > 0 {
+		progName := i.pausedProgs[0]
+		l, err := i.attachProgram(gadgetCtx, i.collectionSpec.Programs[progName], i.collection.Programs[progName])
+		if err != nil {
+			return fmt.Errorf("attaching eBPF program %q: %w", progName, err)
+		}
+		i.links = append(i.links, l)
+		i.progLinks[progName] = l
+		i.pausedProgs = i.pausedProgs[1:]
+	}
+
+	for _, fd := range i.perfFds {
+		if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
+			return fmt.Errorf("enabling perf fd: %w", err)
+		}
+	}
+	return nil
+}
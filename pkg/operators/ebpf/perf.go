@@ -0,0 +1,312 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sys/unix"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+const (
+	ParamPerfFrequency = "perf-frequency"
+	ParamPerfPeriod    = "perf-period"
+	ParamPerfCPUs      = "perf-cpus"
+	ParamPerfCgroup    = "perf-cgroup"
+)
+
+var perfSoftwareConfigs = map[string]uint64{
+	"count_sw_cpu_clock":        unix.PERF_COUNT_SW_CPU_CLOCK,
+	"count_sw_task_clock":       unix.PERF_COUNT_SW_TASK_CLOCK,
+	"count_sw_page_faults":      unix.PERF_COUNT_SW_PAGE_FAULTS,
+	"count_sw_page_faults_min":  unix.PERF_COUNT_SW_PAGE_FAULTS_MIN,
+	"count_sw_page_faults_maj":  unix.PERF_COUNT_SW_PAGE_FAULTS_MAJ,
+	"count_sw_context_switches": unix.PERF_COUNT_SW_CONTEXT_SWITCHES,
+	"count_sw_cpu_migrations":   unix.PERF_COUNT_SW_CPU_MIGRATIONS,
+}
+
+var perfHardwareConfigs = map[string]uint64{
+	"count_hw_cpu_cycles":          unix.PERF_COUNT_HW_CPU_CYCLES,
+	"count_hw_instructions":        unix.PERF_COUNT_HW_INSTRUCTIONS,
+	"count_hw_cache_references":    unix.PERF_COUNT_HW_CACHE_REFERENCES,
+	"count_hw_cache_misses":        unix.PERF_COUNT_HW_CACHE_MISSES,
+	"count_hw_branch_instructions": unix.PERF_COUNT_HW_BRANCH_INSTRUCTIONS,
+	"count_hw_branch_misses":       unix.PERF_COUNT_HW_BRANCH_MISSES,
+	"count_hw_bus_cycles":          unix.PERF_COUNT_HW_BUS_CYCLES,
+	"count_hw_ref_cpu_cycles":      unix.PERF_COUNT_HW_REF_CPU_CYCLES,
+}
+
+// tracefsPaths are the possible mountpoints of tracefs
+var tracefsPaths = []string{"/sys/kernel/tracing", "/sys/kernel/debug/tracing"}
+
+type perfEventConfig struct {
+	perfType   uint32
+	config     uint64
+	sampleType uint64
+	// configName is the name of config as given in the metadata
+	configName string
+
+	// Only one of frequency (samples per second) and period (number of events between
+	// samples) is set
+	frequency uint64
+	period    uint64
+
+	// cpus to open the perf event on, all if empty
+	cpus []int
+	// cgroupPath restricts the perf event to the processes of a cgroup if set
+	cgroupPath string
+}
+
+// tracepointID returns the ID of the tracepoint "<category>/<name>"
+func tracepointID(tracepoint string) (uint64, error) {
+	category, name, ok := strings.Cut(tracepoint, "/")
+	if !ok || category == "" || name == "" {
+		return 0, fmt.Errorf("invalid tracepoint %q: expected <category>/<name>", tracepoint)
+	}
+	var err error
+	for _, tracefs := range tracefsPaths {
+		var content []byte
+		content, err = os.ReadFile(filepath.Join(tracefs, "events", category, name, "id"))
+		if err != nil {
+			continue
+		}
+		return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	}
+	return 0, fmt.Errorf("reading id of tracepoint %q: %w", tracepoint, err)
+}
+
+// parseCPUList parses a list of CPUs like "0-3,6"
+func parseCPUList(s string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu %q", part)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(last)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid cpu range %q", part)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// paramValueOrEmpty returns an empty string for the default value "0" of the sampling parameters
+func paramValueOrEmpty(value string) string {
+	if value == "0" {
+		return ""
+	}
+	return value
+}
+
+// perfEventConfig reads the settings of the perf event program with the given name from the
+// gadget metadata and applies the overrides of the instance parameters
+func (i *ebpfInstance) perfEventConfig(name string) (*perfEventConfig, error) {
+	prefix := "programs." + name
+
+	perfConfig := i.config.GetString(prefix + ".perf.config")
+	if perfConfig == "" {
+		return nil, fmt.Errorf("perf.config not specified for program %q", name)
+	}
+	cfg := &perfEventConfig{configName: perfConfig}
+
+	var ok bool
+	switch tmp := i.config.GetString(prefix + ".perf.type"); tmp {
+	case "":
+		return nil, fmt.Errorf("perf.type not specified for program %q", name)
+	case "software":
+		cfg.perfType = unix.PERF_TYPE_SOFTWARE
+		cfg.config, ok = perfSoftwareConfigs[perfConfig]
+	case "hardware":
+		cfg.perfType = unix.PERF_TYPE_HARDWARE
+		cfg.config, ok = perfHardwareConfigs[perfConfig]
+	case "tracepoint":
+		cfg.perfType = unix.PERF_TYPE_TRACEPOINT
+		id, err := tracepointID(perfConfig)
+		if err != nil {
+			return nil, fmt.Errorf("program %q: %w", name, err)
+		}
+		cfg.config, ok = id, true
+	default:
+		return nil, fmt.Errorf("unsupported perf.type %q", tmp)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unsupported perf.config %q", perfConfig)
+	}
+
+	switch tmp := i.config.GetString(prefix + ".perf.sampleType"); tmp {
+	case "":
+		return nil, fmt.Errorf("perf.sampleType not specified for program %q", name)
+	case "sample_raw":
+		cfg.sampleType = unix.PERF_SAMPLE_RAW
+	default:
+		return nil, fmt.Errorf("unsupported perf.sampleType %q", tmp)
+	}
+
+	// Sampling set by the instance parameters takes precedence over the metadata
+	frequency := paramValueOrEmpty(i.paramValues[ParamPerfFrequency])
+	period := paramValueOrEmpty(i.paramValues[ParamPerfPeriod])
+	if frequency == "" && period == "" {
+		frequency = i.config.GetString(prefix + ".sampler.frequency")
+		period = i.config.GetString(prefix + ".sampler.period")
+	}
+	switch {
+	case frequency != "" && period != "":
+		return nil, fmt.Errorf("only one of frequency and period can be set for program %q", name)
+	case frequency != "":
+		var err error
+		cfg.frequency, err = strconv.ParseUint(frequency, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing frequency %q for program %q: %w", frequency, name, err)
+		}
+		if cfg.frequency == 0 {
+			return nil, fmt.Errorf("sampler.frequency is zero for program %q", name)
+		}
+	case period != "":
+		var err error
+		cfg.period, err = strconv.ParseUint(period, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing period %q for program %q: %w", period, name, err)
+		}
+		if cfg.period == 0 {
+			return nil, fmt.Errorf("sampler.period is zero for program %q", name)
+		}
+	case cfg.perfType == unix.PERF_TYPE_TRACEPOINT:
+		// Run the program on every hit of the tracepoint by default
+		cfg.period = 1
+	default:
+		return nil, fmt.Errorf("sampler.frequency not specified for program %q", name)
+	}
+
+	if cpus := i.paramValues[ParamPerfCPUs]; cpus != "" {
+		var err error
+		cfg.cpus, err = parseCPUList(cpus)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", ParamPerfCPUs, err)
+		}
+	}
+	cfg.cgroupPath = i.paramValues[ParamPerfCgroup]
+
+	return cfg, nil
+}
+
+func (cfg *perfEventConfig) attr() *unix.PerfEventAttr {
+	attr := &unix.PerfEventAttr{
+		Type:        cfg.perfType,
+		Config:      cfg.config,
+		Sample_type: cfg.sampleType,
+	}
+	if cfg.frequency != 0 {
+		attr.Sample = cfg.frequency
+		attr.Bits = unix.PerfBitFreq
+	} else {
+		attr.Sample = cfg.period
+	}
+	return attr
+}
+
+// hardwareUnavailable returns whether the error returned by perf_event_open() for a hardware
+// event means that the hardware counter is not available, e.g. in virtual machines without PMU
+func hardwareUnavailable(err error) bool {
+	return errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENODEV)
+}
+
+// fallbackToCPUClock returns whether a hardware event that isn't available can be replaced by the
+// cpu clock. That's only the case when sampling by frequency, as a period counts hardware events
+// and would be a number of nanoseconds for the cpu clock.
+func (cfg *perfEventConfig) fallbackToCPUClock(err error) (bool, error) {
+	if cfg.perfType != unix.PERF_TYPE_HARDWARE || !hardwareUnavailable(err) {
+		return false, nil
+	}
+	if cfg.frequency == 0 {
+		return false, fmt.Errorf("hardware perf event %q not available (%w) and sampling by period can't fall back to the cpu clock; use a frequency instead", cfg.configName, err)
+	}
+	return true, nil
+}
+
+// openPerfEvents opens the perf event described by cfg on every CPU and attaches the program to
+// them. It returns the file descriptors of the perf events, even the ones opened before an error
+// occurred, so that the caller can close them.
+func openPerfEvents(cfg *perfEventConfig, prog *ebpf.Program, logger logger.Logger) ([]int, error) {
+	cpus := cfg.cpus
+	if len(cpus) == 0 {
+		for cpu := 0; cpu < runtime.NumCPU(); cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+
+	pid := -1
+	flags := unix.PERF_FLAG_FD_CLOEXEC
+	if cfg.cgroupPath != "" {
+		cgroup, err := os.Open(cfg.cgroupPath)
+		if err != nil {
+			return nil, fmt.Errorf("opening cgroup: %w", err)
+		}
+		// The perf events keep a reference to the cgroup
+		defer cgroup.Close()
+		pid = int(cgroup.Fd())
+		flags |= unix.PERF_FLAG_PID_CGROUP
+	}
+
+	attr := cfg.attr()
+	var fds []int
+	for _, cpu := range cpus {
+		fd, err := unix.PerfEventOpen(attr, pid, cpu, -1, flags)
+		if err != nil && len(fds) == 0 && attr.Type == unix.PERF_TYPE_HARDWARE {
+			fallback, fallbackErr := cfg.fallbackToCPUClock(err)
+			if fallbackErr != nil {
+				return fds, fallbackErr
+			}
+			if fallback {
+				logger.Warnf("hardware perf event %q not available (%s), falling back to count_sw_cpu_clock", cfg.configName, err)
+				attr.Type = unix.PERF_TYPE_SOFTWARE
+				attr.Config = unix.PERF_COUNT_SW_CPU_CLOCK
+				fd, err = unix.PerfEventOpen(attr, pid, cpu, -1, flags)
+			}
+		}
+		if err != nil {
+			return fds, fmt.Errorf("opening perf event on cpu %d: %w", cpu, err)
+		}
+		fds = append(fds, fd)
+
+		// Attach program to perf event.
+		if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_SET_BPF, prog.FD()); err != nil {
+			return fds, fmt.Errorf("attaching eBPF program to perf fd: %w", err)
+		}
+
+		// Start perf event.
+		if err := unix.IoctlSetInt(fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
+			return fds, fmt.Errorf("enabling perf fd: %w", err)
+		}
+	}
+	return fds, nil
+}
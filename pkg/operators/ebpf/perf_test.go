@@ -0,0 +1,357 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	utilstest "github.com/inspektor-gadget/inspektor-gadget/internal/test"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/cgroups"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+func newPerfInstance(t *testing.T, metadata map[string]any, paramValues api.ParamValues) *ebpfInstance {
+	config := viper.New()
+	require.NoError(t, config.MergeConfigMap(map[string]any{
+		"programs": map[string]any{"test": metadata},
+	}))
+	return &ebpfInstance{
+		config:      config,
+		paramValues: paramValues,
+		logger:      logger.DefaultLogger(),
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-3,6")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 6}, cpus)
+
+	cpus, err = parseCPUList("2")
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, cpus)
+
+	for _, s := range []string{"", "a", "3-1", "1-"} {
+		_, err = parseCPUList(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestPerfEventConfig(t *testing.T) {
+	type testCase struct {
+		metadata    map[string]any
+		paramValues api.ParamValues
+		expected    *perfEventConfig
+		expectedErr bool
+	}
+
+	software := map[string]any{
+		"type":       "software",
+		"config":     "count_sw_cpu_clock",
+		"sampleType": "sample_raw",
+	}
+
+	tests := map[string]testCase{
+		"software": {
+			metadata: map[string]any{
+				"perf":    software,
+				"sampler": map[string]any{"frequency": 49},
+			},
+			expected: &perfEventConfig{
+				perfType:   unix.PERF_TYPE_SOFTWARE,
+				config:     unix.PERF_COUNT_SW_CPU_CLOCK,
+				sampleType: unix.PERF_SAMPLE_RAW,
+				configName: "count_sw_cpu_clock",
+				frequency:  49,
+			},
+		},
+		"hardware_period": {
+			metadata: map[string]any{
+				"perf": map[string]any{
+					"type":       "hardware",
+					"config":     "count_hw_cache_misses",
+					"sampleType": "sample_raw",
+				},
+				"sampler": map[string]any{"period": 10000},
+			},
+			expected: &perfEventConfig{
+				perfType:   unix.PERF_TYPE_HARDWARE,
+				config:     unix.PERF_COUNT_HW_CACHE_MISSES,
+				sampleType: unix.PERF_SAMPLE_RAW,
+				configName: "count_hw_cache_misses",
+				period:     10000,
+			},
+		},
+		"params_override": {
+			metadata: map[string]any{
+				"perf":    software,
+				"sampler": map[string]any{"frequency": 49},
+			},
+			paramValues: api.ParamValues{
+				ParamPerfFrequency: "0",
+				ParamPerfPeriod:    "1000000",
+				ParamPerfCPUs:      "0,2-3",
+				ParamPerfCgroup:    "/sys/fs/cgroup/foo",
+			},
+			expected: &perfEventConfig{
+				perfType:   unix.PERF_TYPE_SOFTWARE,
+				config:     unix.PERF_COUNT_SW_CPU_CLOCK,
+				sampleType: unix.PERF_SAMPLE_RAW,
+				configName: "count_sw_cpu_clock",
+				period:     1000000,
+				cpus:       []int{0, 2, 3},
+				cgroupPath: "/sys/fs/cgroup/foo",
+			},
+		},
+		"missing_sampler": {
+			metadata:    map[string]any{"perf": software},
+			expectedErr: true,
+		},
+		"frequency_and_period": {
+			metadata: map[string]any{
+				"perf":    software,
+				"sampler": map[string]any{"frequency": 49, "period": 1000},
+			},
+			expectedErr: true,
+		},
+		"unsupported_config": {
+			metadata: map[string]any{
+				"perf": map[string]any{
+					"type":       "hardware",
+					"config":     "count_sw_cpu_clock",
+					"sampleType": "sample_raw",
+				},
+				"sampler": map[string]any{"frequency": 49},
+			},
+			expectedErr: true,
+		},
+		"invalid_tracepoint": {
+			metadata: map[string]any{
+				"perf": map[string]any{
+					"type":       "tracepoint",
+					"config":     "sched_switch",
+					"sampleType": "sample_raw",
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			i := newPerfInstance(t, tc.metadata, tc.paramValues)
+			cfg, err := i.perfEventConfig("test")
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+func TestPerfEventConfigTracepoint(t *testing.T) {
+	if _, err := tracepointID("sched/sched_switch"); err != nil {
+		t.Skipf("tracefs not available: %s", err)
+	}
+
+	i := newPerfInstance(t, map[string]any{
+		"perf": map[string]any{
+			"type":       "tracepoint",
+			"config":     "sched/sched_switch",
+			"sampleType": "sample_raw",
+		},
+	}, nil)
+	cfg, err := i.perfEventConfig("test")
+	require.NoError(t, err)
+	assert.EqualValues(t, unix.PERF_TYPE_TRACEPOINT, cfg.perfType)
+	assert.NotZero(t, cfg.config)
+	// Tracepoints run the program on every hit by default
+	assert.EqualValues(t, 1, cfg.period)
+}
+
+func newPerfEventProg(t *testing.T) *ebpf.Program {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:    ebpf.PerfEvent,
+		License: "GPL",
+		Instructions: asm.Instructions{
+			asm.Mov.Imm(asm.R0, 0),
+			asm.Return(),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { prog.Close() })
+	return prog
+}
+
+// newCountingPerfEventProg returns a program counting its runs in the first entry of counter
+func newCountingPerfEventProg(t *testing.T, counter *ebpf.Map) *ebpf.Program {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:    ebpf.PerfEvent,
+		License: "GPL",
+		Instructions: asm.Instructions{
+			asm.StoreImm(asm.RFP, -4, 0, asm.Word),
+			asm.Mov.Reg(asm.R2, asm.RFP),
+			asm.Add.Imm(asm.R2, -4),
+			asm.LoadMapPtr(asm.R1, counter.FD()),
+			asm.FnMapLookupElem.Call(),
+			asm.JEq.Imm(asm.R0, 0, "exit"),
+			asm.Mov.Imm(asm.R1, 1),
+			asm.StoreXAdd(asm.R0, asm.R1, asm.DWord),
+			asm.Mov.Imm(asm.R0, 0).WithSymbol("exit"),
+			asm.Return(),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { prog.Close() })
+	return prog
+}
+
+func closePerfFds(fds []int) {
+	for _, fd := range fds {
+		unix.Close(fd)
+	}
+}
+
+func TestOpenPerfEvents(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	prog := newPerfEventProg(t)
+
+	t.Run("software", func(t *testing.T) {
+		fds, err := openPerfEvents(&perfEventConfig{
+			perfType:   unix.PERF_TYPE_SOFTWARE,
+			config:     unix.PERF_COUNT_SW_CPU_CLOCK,
+			sampleType: unix.PERF_SAMPLE_RAW,
+			frequency:  49,
+			cpus:       []int{0},
+		}, prog, logger.DefaultLogger())
+		defer closePerfFds(fds)
+		require.NoError(t, err)
+		assert.Len(t, fds, 1)
+	})
+
+	// Falls back to the cpu clock if hardware counters aren't available
+	t.Run("hardware", func(t *testing.T) {
+		fds, err := openPerfEvents(&perfEventConfig{
+			perfType:   unix.PERF_TYPE_HARDWARE,
+			config:     unix.PERF_COUNT_HW_CPU_CYCLES,
+			sampleType: unix.PERF_SAMPLE_RAW,
+			configName: "count_hw_cpu_cycles",
+			frequency:  49,
+			cpus:       []int{0},
+		}, prog, logger.DefaultLogger())
+		defer closePerfFds(fds)
+		require.NoError(t, err)
+		assert.Len(t, fds, 1)
+	})
+
+	// A period can't be converted to the cpu clock
+	t.Run("hardware_period", func(t *testing.T) {
+		cfg := &perfEventConfig{
+			perfType:   unix.PERF_TYPE_HARDWARE,
+			config:     unix.PERF_COUNT_HW_CPU_CYCLES,
+			sampleType: unix.PERF_SAMPLE_RAW,
+			configName: "count_hw_cpu_cycles",
+			period:     1000000,
+			cpus:       []int{0},
+		}
+		fds, err := openPerfEvents(cfg, prog, logger.DefaultLogger())
+		defer closePerfFds(fds)
+
+		fd, openErr := unix.PerfEventOpen(cfg.attr(), -1, 0, -1, unix.PERF_FLAG_FD_CLOEXEC)
+		if openErr == nil {
+			unix.Close(fd)
+			require.NoError(t, err)
+			return
+		}
+		require.ErrorContains(t, err, "count_hw_cpu_cycles")
+	})
+
+	t.Run("cgroup", func(t *testing.T) {
+		root, err := cgroups.CgroupPathV2AddMountpoint("/")
+		if err != nil {
+			t.Skipf("cgroup v2 not available: %s", err)
+		}
+		path, err := os.MkdirTemp(root, "ig-perf-")
+		if err != nil {
+			t.Skipf("creating cgroup: %s", err)
+		}
+		defer os.Remove(path)
+
+		fds, err := openPerfEvents(&perfEventConfig{
+			perfType:   unix.PERF_TYPE_SOFTWARE,
+			config:     unix.PERF_COUNT_SW_CPU_CLOCK,
+			sampleType: unix.PERF_SAMPLE_RAW,
+			frequency:  49,
+			cgroupPath: path,
+		}, prog, logger.DefaultLogger())
+		defer closePerfFds(fds)
+		if err != nil {
+			t.Skipf("cgroup perf events not supported: %s", err)
+		}
+		assert.NotEmpty(t, fds)
+	})
+
+	// The program must actually run once attached
+	t.Run("run", func(t *testing.T) {
+		counter, err := ebpf.NewMap(&ebpf.MapSpec{
+			Type:       ebpf.Array,
+			KeySize:    4,
+			ValueSize:  8,
+			MaxEntries: 1,
+		})
+		require.NoError(t, err)
+		defer counter.Close()
+
+		fds, err := openPerfEvents(&perfEventConfig{
+			perfType:   unix.PERF_TYPE_SOFTWARE,
+			config:     unix.PERF_COUNT_SW_CPU_CLOCK,
+			sampleType: unix.PERF_SAMPLE_RAW,
+			frequency:  1000,
+		}, newCountingPerfEventProg(t, counter), logger.DefaultLogger())
+		defer closePerfFds(fds)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			var count uint64
+			require.NoError(t, counter.Lookup(uint32(0), &count))
+			return count > 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("invalid_cpu", func(t *testing.T) {
+		fds, err := openPerfEvents(&perfEventConfig{
+			perfType:   unix.PERF_TYPE_SOFTWARE,
+			config:     unix.PERF_COUNT_SW_CPU_CLOCK,
+			sampleType: unix.PERF_SAMPLE_RAW,
+			frequency:  49,
+			cpus:       []int{100000},
+		}, prog, logger.DefaultLogger())
+		defer closePerfFds(fds)
+		require.Error(t, err)
+	})
+}
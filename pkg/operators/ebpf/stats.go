@@ -30,6 +30,7 @@ import (
 	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/tchandler"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/processmap"
 	processmaptypes "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/processmap/types"
 )
@@ -42,10 +43,34 @@ const (
 )
 
 func (o *ebpfOperator) GlobalParams() api.Params {
-	return nil
+	return api.Params{
+		{
+			Key:            ParamTCMode,
+			Description:    "How to attach SchedCLS programs to network interfaces: tcx uses TCX links, legacy adds a filter to the clsact qdisc and auto uses tcx if supported by the kernel",
+			DefaultValue:   string(tchandler.ModeAuto),
+			PossibleValues: []string{string(tchandler.ModeAuto), string(tchandler.ModeTCX), string(tchandler.ModeLegacy)},
+			Title:          "TC mode",
+		},
+		{
+			Key:            ParamTCOrder,
+			Description:    "Whether SchedCLS programs run before or after other programs attached to the same network interface when using TCX or netkit links",
+			DefaultValue:   string(tchandler.OrderBefore),
+			PossibleValues: []string{string(tchandler.OrderBefore), string(tchandler.OrderAfter)},
+			Title:          "TC order",
+		},
+	}
 }
 
 func (o *ebpfOperator) Init(params *params.Params) error {
+	var err error
+	o.tcMode, err = tchandler.ParseMode(params.Get(ParamTCMode).AsString())
+	if err != nil {
+		return err
+	}
+	o.tcOrder, err = tchandler.ParseOrder(params.Get(ParamTCOrder).AsString())
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
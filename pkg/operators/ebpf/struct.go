@@ -18,6 +18,7 @@ import (
 	"maps"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/cilium/ebpf/btf"
@@ -36,6 +37,14 @@ type Field struct {
 	parent      int
 	name        string
 	kind        api.Kind
+
+	// bitOffset and bitSize are set for bitfields; Offset and Size then describe the integer
+	// holding the bitfield
+	bitOffset uint32
+	bitSize   uint32
+
+	// arrayDims is set for multidimensional arrays and arrays of structs
+	arrayDims []uint32
 }
 
 type Struct struct {
@@ -76,6 +85,18 @@ func (f *Field) FieldAnnotations() map[string]string {
 	return maps.Clone(f.Annotations)
 }
 
+func (f *Field) FieldBitOffset() uint32 {
+	return f.bitOffset
+}
+
+func (f *Field) FieldBitSize() uint32 {
+	return f.bitSize
+}
+
+func (f *Field) FieldArrayDims() []uint32 {
+	return f.arrayDims
+}
+
 func (i *ebpfInstance) populateStructDirect(btfStruct *btf.Struct) error {
 	gadgetStruct := i.structs[btfStruct.Name]
 
@@ -122,16 +143,15 @@ func getFieldKind(typ reflect.Type, tags []string) api.Kind {
 		return api.Kind_Float64
 	case reflect.Array:
 		// Special case to handle char arrays as strings
-		// TODO: Handle other cases once we support arrays
 		if typ.Elem().Kind() == reflect.Int8 && slices.Contains(tags, "type:char") {
 			return api.Kind_CString
 		}
-		kind := getFieldKind(typ.Elem(), tags)
-		if api.IsArrayKind(kind) {
-			// we don't support arrays of arrays for now
-			return api.Kind_Invalid
+		// Multidimensional arrays are flattened; their dimensions are stored in the field
+		elem := typ.Elem()
+		for elem.Kind() == reflect.Array {
+			elem = elem.Elem()
 		}
-		return api.ArrayOf(kind)
+		return api.ArrayOf(getFieldKind(elem, nil))
 	}
 	return api.Kind_Invalid
 }
@@ -216,6 +236,22 @@ func (i *ebpfInstance) getFieldsFromMember(member btf.Member, fields *[]*Field,
 		return
 	}
 
+	// Arrays of structs and unions are added as a parent with one sub field per element
+	if elem, dims := arrayElemAndDims(member.Type); len(dims) > 0 {
+		switch elem.(type) {
+		case *btf.Struct, *btf.Union:
+			elemSize, _ := btf.Sizeof(elem)
+			field := newField(arraySize(dims, uint32(elemSize)), api.Kind_Bytes)
+			field.arrayDims = dims
+			newParent := len(*fields)
+			*fields = append(*fields, field)
+
+			i.logger.Debugf(" adding field %q (%s) at %d (dims %v)", prefix+field.name, "array", field.Offset, dims)
+			i.getFieldsFromArray(elem, dims, fields, prefix+member.Name+".", field.Offset, newParent)
+			return
+		}
+	}
+
 	if refType == nil {
 		i.logger.Debugf(" skipping field %q (%T)", prefix+member.Name, member.Type)
 		return
@@ -229,15 +265,38 @@ func (i *ebpfInstance) getFieldsFromMember(member btf.Member, fields *[]*Field,
 		return
 	}
 
+	kind := getFieldKind(refType, tags)
+
+	field := newField(fsize, kind)
+
+	if api.IsArrayKind(kind) {
+		if _, dims := arrayElemAndDims(member.Type); len(dims) > 1 {
+			field.arrayDims = dims
+		}
+	}
+
+	if member.BitfieldSize > 0 {
+		if !isBitfieldKind(kind) {
+			i.logger.Debugf(" skipping bitfield %q (kind: %s)", prefix+member.Name, kind.String())
+			return
+		}
+
+		// Use the naturally aligned integer of the member's type holding the bitfield
+		bitOffset := uint32(member.Offset)
+		field.Offset = offset + bitOffset/(fsize*8)*fsize
+		field.bitOffset = bitOffset % (fsize * 8)
+		field.bitSize = uint32(member.BitfieldSize)
+		if field.bitOffset+field.bitSize > fsize*8 {
+			i.logger.Debugf(" skipping bitfield %q crossing the boundary of its type", prefix+member.Name)
+			return
+		}
+	}
+
 	// Keep enums to convert them to strings
 	if en, ok := member.Type.(*btf.Enum); ok {
 		i.enums = append(i.enums, &enum{Enum: en, memberName: prefix + member.Name})
 	}
 
-	kind := getFieldKind(refType, tags)
-
-	field := newField(fsize, kind)
-
 	i.logger.Debugf(" adding field %q (%s) (kind: %s) at %d (parent %d) (%v)",
 		prefix+field.name, fieldType, kind.String(), field.Offset, parent, tags)
 	*fields = append(*fields, field)
@@ -249,8 +308,76 @@ func (i *ebpfInstance) getFieldsFromStruct(btfStruct *btf.Struct, fields *[]*Fie
 	}
 }
 
+// getFieldsFromArray adds the elements of an array of structs or unions as sub fields named after
+// their index; elements of multidimensional arrays are arrays themselves
+func (i *ebpfInstance) getFieldsFromArray(elem btf.Type, dims []uint32, fields *[]*Field, prefix string, offset uint32, parent int) {
+	size, _ := btf.Sizeof(elem)
+	elemSize := arraySize(dims[1:], uint32(size))
+	for idx := range dims[0] {
+		name := strconv.FormatUint(uint64(idx), 10)
+		field := &Field{
+			Size:        elemSize,
+			Tags:        []string{"name:" + name, api.TagSrcEbpf},
+			Offset:      offset + idx*elemSize,
+			parent:      parent,
+			name:        name,
+			kind:        api.Kind_Bytes,
+			Annotations: map[string]string{},
+		}
+		newParent := len(*fields)
+		*fields = append(*fields, field)
+
+		if len(dims) > 1 {
+			field.arrayDims = dims[1:]
+			i.getFieldsFromArray(elem, dims[1:], fields, prefix+name+".", field.Offset, newParent)
+			continue
+		}
+
+		switch t := elem.(type) {
+		case *btf.Struct:
+			i.getFieldsFromStruct(t, fields, prefix+name+".", field.Offset, newParent)
+		case *btf.Union:
+			i.getFieldsFromUnion(t, fields, prefix+name+".", field.Offset, newParent)
+		}
+	}
+}
+
 func (i *ebpfInstance) getFieldsFromUnion(btfUnion *btf.Union, fields *[]*Field, prefix string, offset uint32, parent int) {
 	for _, member := range btfUnion.Members {
 		i.getFieldsFromMember(member, fields, prefix, offset, parent, btfUnion.Name)
 	}
 }
+
+// arrayElemAndDims returns the element type and the dimensions of a (multidimensional) array or
+// no dimensions if typ is not an array
+func arrayElemAndDims(typ btf.Type) (btf.Type, []uint32) {
+	var dims []uint32
+	typ = btfhelpers.ResolveType(typ)
+	for {
+		arr, ok := typ.(*btf.Array)
+		if !ok {
+			return typ, dims
+		}
+		dims = append(dims, arr.Nelems)
+		typ = btfhelpers.ResolveType(arr.Type)
+	}
+}
+
+// arraySize returns the size of an array with the given dimensions and element size
+func arraySize(dims []uint32, elemSize uint32) uint32 {
+	size := elemSize
+	for _, dim := range dims {
+		size *= dim
+	}
+	return size
+}
+
+func isBitfieldKind(kind api.Kind) bool {
+	switch kind {
+	case api.Kind_Bool,
+		api.Kind_Int8, api.Kind_Int16, api.Kind_Int32, api.Kind_Int64,
+		api.Kind_Uint8, api.Kind_Uint16, api.Kind_Uint32, api.Kind_Uint64:
+		return true
+	}
+	return false
+}
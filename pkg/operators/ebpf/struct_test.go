@@ -0,0 +1,108 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfoperator
+
+import (
+	"testing"
+
+	"github.com/cilium/ebpf/btf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+func TestGetFieldsFromStruct(t *testing.T) {
+	u16 := &btf.Int{Name: "u16", Size: 2, Encoding: btf.Unsigned}
+	u32 := &btf.Int{Name: "u32", Size: 4, Encoding: btf.Unsigned}
+	s32 := &btf.Int{Name: "s32", Size: 4, Encoding: btf.Signed}
+	inner := &btf.Struct{
+		Name: "inner",
+		Size: 4,
+		Members: []btf.Member{
+			{Name: "x", Type: u16, Offset: 0},
+			{Name: "y", Type: u16, Offset: 16},
+		},
+	}
+	event := &btf.Struct{
+		Name: "event",
+		Size: 40,
+		Members: []btf.Member{
+			{Name: "a", Type: u32, Offset: 0, BitfieldSize: 3},
+			{Name: "b", Type: s32, Offset: 3, BitfieldSize: 5},
+			{Name: "c", Type: u32, Offset: 30, BitfieldSize: 4}, // crosses the boundary of u32
+			{Name: "m", Type: &btf.Array{Type: &btf.Array{Type: u32, Nelems: 3}, Nelems: 2}, Offset: 32},
+			{Name: "arr", Type: &btf.Array{Type: inner, Nelems: 2}, Offset: 224},
+			{Name: "d", Type: u16, Offset: 296, BitfieldSize: 2},
+		},
+	}
+
+	i := &ebpfInstance{logger: logger.DefaultLogger()}
+	var fields []*Field
+	i.getFieldsFromStruct(event, &fields, "", 0, -1)
+
+	type expectedField struct {
+		name      string
+		offset    uint32
+		size      uint32
+		kind      api.Kind
+		parent    int
+		bitOffset uint32
+		bitSize   uint32
+		arrayDims []uint32
+	}
+	expected := []expectedField{
+		{name: "a", offset: 0, size: 4, kind: api.Kind_Uint32, parent: -1, bitOffset: 0, bitSize: 3},
+		{name: "b", offset: 0, size: 4, kind: api.Kind_Int32, parent: -1, bitOffset: 3, bitSize: 5},
+		{name: "m", offset: 4, size: 24, kind: api.ArrayOf(api.Kind_Uint32), parent: -1, arrayDims: []uint32{2, 3}},
+		{name: "arr", offset: 28, size: 8, kind: api.Kind_Bytes, parent: -1, arrayDims: []uint32{2}},
+		{name: "0", offset: 28, size: 4, kind: api.Kind_Bytes, parent: 3},
+		{name: "x", offset: 28, size: 2, kind: api.Kind_Uint16, parent: 4},
+		{name: "y", offset: 30, size: 2, kind: api.Kind_Uint16, parent: 4},
+		{name: "1", offset: 32, size: 4, kind: api.Kind_Bytes, parent: 3},
+		{name: "x", offset: 32, size: 2, kind: api.Kind_Uint16, parent: 7},
+		{name: "y", offset: 34, size: 2, kind: api.Kind_Uint16, parent: 7},
+		{name: "d", offset: 36, size: 2, kind: api.Kind_Uint16, parent: -1, bitOffset: 8, bitSize: 2},
+	}
+
+	require.Len(t, fields, len(expected))
+	for idx, e := range expected {
+		f := fields[idx]
+		assert.Equal(t, e, expectedField{
+			name:      f.FieldName(),
+			offset:    f.FieldOffset(),
+			size:      f.FieldSize(),
+			kind:      f.FieldType(),
+			parent:    f.FieldParent(),
+			bitOffset: f.FieldBitOffset(),
+			bitSize:   f.FieldBitSize(),
+			arrayDims: f.FieldArrayDims(),
+		}, "field %d", idx)
+	}
+
+	// Check that the fields can be added to a data source
+	ds, err := datasource.New(datasource.TypeSingle, "event")
+	require.NoError(t, err)
+	staticFields := make([]datasource.StaticField, 0, len(fields))
+	for _, f := range fields {
+		staticFields = append(staticFields, f)
+	}
+	_, err = ds.AddStaticFields(event.Size, staticFields)
+	require.NoError(t, err)
+	assert.NotNil(t, ds.GetField("arr.1.y"))
+	assert.Equal(t, []uint32{2}, ds.GetField("arr").ArrayDims())
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fileoperator writes the events of data sources as JSON lines to
+// files, optionally rotating and compressing them.
+package fileoperator
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "file"
+
+	// Priority is the same as the one of the cli operator, as this operator is
+	// used as a sink as well
+	Priority = 10000
+
+	ParamOutputFile       = "output-file"
+	ParamOutputMaxSize    = "output-file-max-size"
+	ParamOutputMaxAge     = "output-file-max-age"
+	ParamOutputMaxBackups = "output-file-max-backups"
+	ParamOutputCompress   = "output-file-compress"
+
+	// DataSourcePlaceholder is replaced by the name of the data source in the
+	// output file path
+	DataSourcePlaceholder = "{datasource}"
+)
+
+type fileOperator struct{}
+
+func (o *fileOperator) Name() string {
+	return name
+}
+
+func (o *fileOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (o *fileOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (o *fileOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key: ParamOutputFile,
+			Description: "Write events as JSON lines to the given file. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','. " +
+				"The string " + DataSourcePlaceholder + " will be replaced by the name of the data source; " +
+				"if it is missing and multiple data sources are written, the name of the data source is added to the file name.",
+			DefaultValue: "",
+			TypeHint:     api.TypeString,
+		},
+		{
+			Key:          ParamOutputMaxSize,
+			Description:  "Rotate the output file when it exceeds the given size (e.g. 100M); 0 disables rotation by size",
+			DefaultValue: "0",
+			TypeHint:     api.TypeString,
+		},
+		{
+			Key:          ParamOutputMaxAge,
+			Description:  "Rotate the output file when it has been written to for the given duration; 0 disables rotation by age",
+			DefaultValue: "0",
+			TypeHint:     api.TypeDuration,
+		},
+		{
+			Key:          ParamOutputMaxBackups,
+			Description:  "Number of rotated files to keep; 0 keeps all of them",
+			DefaultValue: "0",
+			TypeHint:     api.TypeUint,
+		},
+		{
+			Key:          ParamOutputCompress,
+			Description:  "Compress rotated files using gzip",
+			DefaultValue: "false",
+			TypeHint:     api.TypeBool,
+		},
+	}
+}
+
+func (o *fileOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	if instanceParamValues[ParamOutputFile] == "" {
+		return nil, nil
+	}
+
+	// Remote calls without an ID are attached to a client that will write the
+	// output itself; only headless instances write their output on the server.
+	if gadgetCtx.IsRemoteCall() && gadgetCtx.ID() == "" {
+		return nil, nil
+	}
+
+	paths, err := apihelpers.GetStringValuesPerDataSource(instanceParamValues[ParamOutputFile])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamOutputFile, err)
+	}
+
+	p := apihelpers.ToParamDescs(o.InstanceParams()).ToParams()
+	err = p.CopyFromMap(instanceParamValues, "")
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize, err := units.RAMInBytes(p.Get(ParamOutputMaxSize).AsString())
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamOutputMaxSize, err)
+	}
+	if maxSize < 0 {
+		return nil, fmt.Errorf("invalid value for %s: %d", ParamOutputMaxSize, maxSize)
+	}
+
+	maxAge := p.Get(ParamOutputMaxAge).AsDuration()
+	if maxAge < 0 {
+		return nil, fmt.Errorf("invalid value for %s: %s", ParamOutputMaxAge, maxAge)
+	}
+
+	return &fileOperatorInstance{
+		paths:      paths,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: int(p.Get(ParamOutputMaxBackups).AsUint()),
+		compress:   p.Get(ParamOutputCompress).AsBool(),
+	}, nil
+}
+
+func (o *fileOperator) Priority() int {
+	return Priority
+}
+
+type fileOperatorInstance struct {
+	paths      map[string]string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+
+	files []*rotatingFile
+}
+
+func (o *fileOperatorInstance) Name() string {
+	return name
+}
+
+// outputPaths returns the file path for each data source that should be
+// written
+func (o *fileOperatorInstance) outputPaths(dataSources map[string]datasource.DataSource) (map[datasource.DataSource]string, error) {
+	res := make(map[datasource.DataSource]string)
+
+	var usingDefault []datasource.DataSource
+	for _, ds := range dataSources {
+		if !ds.IsReferenced() {
+			continue
+		}
+		if path, ok := o.paths[ds.Name()]; ok {
+			res[ds] = path
+			continue
+		}
+		if _, ok := o.paths[""]; ok {
+			usingDefault = append(usingDefault, ds)
+		}
+	}
+
+	for dsName := range o.paths {
+		if _, ok := dataSources[dsName]; dsName != "" && !ok {
+			return nil, fmt.Errorf("data source %q not found", dsName)
+		}
+	}
+
+	for _, ds := range usingDefault {
+		path := o.paths[""]
+		if len(usingDefault) > 1 && !strings.Contains(path, DataSourcePlaceholder) {
+			ext := filepath.Ext(path)
+			path = strings.TrimSuffix(path, ext) + "-" + DataSourcePlaceholder + ext
+		}
+		res[ds] = path
+	}
+
+	seen := make(map[string]string)
+	for ds, path := range res {
+		path = strings.ReplaceAll(path, DataSourcePlaceholder, ds.Name())
+		if other, ok := seen[path]; ok {
+			return nil, fmt.Errorf("data sources %q and %q can't be written to the same file %q", other, ds.Name(), path)
+		}
+		seen[path] = ds.Name()
+		res[ds] = path
+	}
+	return res, nil
+}
+
+func (o *fileOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	paths, err := o.outputPaths(gadgetCtx.GetDataSources())
+	if err != nil {
+		return err
+	}
+
+	for ds, path := range paths {
+		jsonFormatter, err := json.New(ds,
+			json.WithShowAll(true),
+			json.WithArray(ds.Type() == datasource.TypeArray),
+		)
+		if err != nil {
+			return fmt.Errorf("initializing JSON formatter for data source %q: %w", ds.Name(), err)
+		}
+
+		f, err := newRotatingFile(path, o.maxSize, o.maxAge, o.maxBackups, o.compress)
+		if err != nil {
+			return err
+		}
+		o.files = append(o.files, f)
+
+		gadgetCtx.Logger().Debugf("file: writing data source %q to %q", ds.Name(), path)
+
+		write := func(b []byte) error {
+			// b may be backed by a shared buffer of the formatter, so copy it
+			line := make([]byte, 0, len(b)+1)
+			line = append(append(line, b...), '\n')
+			if _, err := f.Write(line); err != nil {
+				gadgetCtx.Logger().Warnf("file: writing to %q: %v", path, err)
+			}
+			return nil
+		}
+
+		switch ds.Type() {
+		case datasource.TypeSingle:
+			ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				return write(jsonFormatter.Marshal(data))
+			}, Priority)
+		case datasource.TypeArray:
+			ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+				return write(jsonFormatter.MarshalArray(dataArray))
+			}, Priority)
+		}
+	}
+	return nil
+}
+
+func (o *fileOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *fileOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *fileOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	var errs []error
+	for _, f := range o.files {
+		errs = append(errs, f.Close())
+	}
+	o.files = nil
+	return errors.Join(errs...)
+}
+
+var Operator = &fileOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileoperator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+func runProducer(t *testing.T, paramValues api.ParamValues, dsNames ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type dsFields struct {
+		ds   datasource.DataSource
+		comm datasource.FieldAccessor
+		pid  datasource.FieldAccessor
+	}
+	var dataSources []dsFields
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			for _, name := range dsNames {
+				ds, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, name)
+				require.NoError(t, err)
+				comm, err := ds.AddField("comm", api.Kind_String)
+				require.NoError(t, err)
+				pid, err := ds.AddField("pid", api.Kind_Uint32)
+				require.NoError(t, err)
+				dataSources = append(dataSources, dsFields{ds: ds, comm: comm, pid: pid})
+			}
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for _, d := range dataSources {
+				for i, comm := range []string{"cat", "ls"} {
+					data, err := d.ds.NewPacketSingle()
+					require.NoError(t, err)
+					require.NoError(t, d.comm.PutString(data, comm))
+					require.NoError(t, d.pid.PutUint32(data, uint32(i+1)))
+					require.NoError(t, d.ds.EmitAndRelease(data))
+				}
+			}
+			cancel()
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(Operator, producer))
+	return gadgetCtx.Run(paramValues)
+}
+
+func TestFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	err := runProducer(t, api.ParamValues{
+		"operator.file.output-file": path,
+	}, "events")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"comm\":\"cat\",\"pid\":1}\n{\"comm\":\"ls\",\"pid\":2}\n", string(content))
+}
+
+func TestFileOutputPerDataSource(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("automatic", func(t *testing.T) {
+		err := runProducer(t, api.ParamValues{
+			"operator.file.output-file": filepath.Join(dir, "out.json"),
+		}, "open", "exec")
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, "out-open.json"))
+		assert.FileExists(t, filepath.Join(dir, "out-exec.json"))
+	})
+	t.Run("placeholder", func(t *testing.T) {
+		err := runProducer(t, api.ParamValues{
+			"operator.file.output-file": filepath.Join(dir, DataSourcePlaceholder, "events.json"),
+		}, "open", "exec")
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, "open", "events.json"))
+		assert.FileExists(t, filepath.Join(dir, "exec", "events.json"))
+	})
+	t.Run("mapping", func(t *testing.T) {
+		err := runProducer(t, api.ParamValues{
+			"operator.file.output-file": "open:" + filepath.Join(dir, "open.json"),
+		}, "open", "exec")
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dir, "open.json"))
+		assert.NoFileExists(t, filepath.Join(dir, "exec.json"))
+	})
+}
+
+func TestFileOutputErrors(t *testing.T) {
+	dir := t.TempDir()
+	testCases := map[string]api.ParamValues{
+		"unknown data source": {
+			"operator.file.output-file": "foo:" + filepath.Join(dir, "out.json"),
+		},
+		"same file": {
+			"operator.file.output-file": "open:" + filepath.Join(dir, "out.json") + ",exec:" + filepath.Join(dir, "out.json"),
+		},
+		"invalid size": {
+			"operator.file.output-file":          filepath.Join(dir, "out.json"),
+			"operator.file.output-file-max-size": "foo",
+		},
+		"invalid age": {
+			"operator.file.output-file":         filepath.Join(dir, "out.json"),
+			"operator.file.output-file-max-age": "foo",
+		},
+	}
+	for name, paramValues := range testCases {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, runProducer(t, paramValues, "open", "exec"))
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileoperator
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock returns a new time for every call, so that backups get unique names
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func readBackups(t *testing.T, r *rotatingFile) []string {
+	backups, err := r.backups()
+	require.NoError(t, err)
+
+	res := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		f, err := os.Open(backup)
+		require.NoError(t, err)
+		defer f.Close()
+
+		var reader io.Reader = f
+		if strings.HasSuffix(backup, compressSuffix) {
+			gz, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			reader = gz
+		}
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		res = append(res, string(content))
+	}
+	return res
+}
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	r, err := newRotatingFile(path, 10, 0, 0, false)
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Now()}
+	r.now = clock.Now
+
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n", "this is a long line\n", "dddd\n"} {
+		_, err := r.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "dddd\n", string(content))
+	assert.Equal(t, []string{"aaaa\nbbbb\n", "cccc\n", "this is a long line\n"}, readBackups(t, r))
+}
+
+func TestRotateByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	r, err := newRotatingFile(path, 0, time.Minute, 0, false)
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	r.openedAt = now
+
+	_, err = r.Write([]byte("a\n"))
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, err = r.Write([]byte("b\n"))
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, err = r.Write([]byte("c\n"))
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "c\n", string(content))
+	assert.Equal(t, []string{"a\nb\n"}, readBackups(t, r))
+}
+
+func TestRotateBackupsAndCompression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	r, err := newRotatingFile(path, 2, 0, 2, true)
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Now()}
+	r.now = clock.Now
+
+	for _, line := range []string{"1\n", "2\n", "3\n", "4\n", "5\n"} {
+		_, err := r.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Close())
+
+	backups, err := r.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	for _, backup := range backups {
+		assert.True(t, strings.HasSuffix(backup, compressSuffix), "backup %q should be compressed", backup)
+	}
+	assert.Equal(t, []string{"3\n", "4\n"}, readBackups(t, r))
+}
+
+func TestRotateAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0o600))
+
+	r, err := newRotatingFile(path, 0, 0, 0, false)
+	require.NoError(t, err)
+	_, err = r.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old\nnew\n", string(content))
+
+	_, err = r.Write([]byte("closed\n"))
+	require.ErrorIs(t, err, os.ErrClosed)
+}
@@ -17,11 +17,16 @@ package filter
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/exp/constraints"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/expr"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
@@ -30,9 +35,14 @@ import (
 type comparisonType int
 
 const (
-	name        = "filter"
-	ParamFilter = "filter"
-	Priority    = 9000
+	name            = "filter"
+	ParamFilter     = "filter"
+	ParamFilterExpr = "filter-expr"
+	Priority        = 9000
+
+	// exprErrorLogInterval limits how often errors evaluating a filter
+	// expression are logged
+	exprErrorLogInterval = 10 * time.Second
 )
 
 const (
@@ -60,9 +70,10 @@ func (f *filterOperator) GlobalParams() api.Params {
 }
 
 func (f *filterOperator) InstanceParams() api.Params {
-	return api.Params{&api.Param{
-		Key: ParamFilter,
-		Description: `Filter rules
+	return api.Params{
+		&api.Param{
+			Key: ParamFilter,
+			Description: `Filter rules
   A filter can match any field using the following syntax:
     field==value     - matches, if the content of field equals exactly value
     field!=value     - matches, if the content of field does not equal exactly value
@@ -77,28 +88,40 @@ func (f *filterOperator) InstanceParams() api.Params {
   It is recommended to use single quotes to escape the filter string, especially if using regular expressions.
   Example: --filter 'field!~regex'
         `,
-		Alias: "F",
-	}}
+			Alias: "F",
+			Tags:  []string{api.TagLiveUpdate},
+		},
+		&api.Param{
+			Key: ParamFilterExpr,
+			Description: `Filter expressions
+  An expression can combine any fields using the expr language, for example:
+    comm == "nginx" || uid == 0
+    latency > 10000000 && (comm matches "^(curl|wget)$" || k8s.namespace == "default")
+    bytes_sent > 2 * bytes_received
+  Only events for which the expression evaluates to true will be kept.
+  Prefix an expression with the name of a data source to apply it only to that data source: datasource:expression
+  Multiple expressions can be combined using a comma: expression1,datasource:expression2
+  see [https://expr-lang.org/docs/language-definition] for more information on the syntax
+  Example: --filter-expr 'comm == "nginx" && uid != 0'
+        `,
+			Tags: []string{api.TagLiveUpdate},
+		},
+	}
 }
 
 func (f *filterOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
-	filterCfg := instanceParamValues[ParamFilter]
-
 	fop := &filterOperatorInstance{
-		ffns: map[datasource.DataSource][]func(datasource.DataSource, datasource.Data) bool{},
+		filter:     instanceParamValues[ParamFilter],
+		filterExpr: instanceParamValues[ParamFilterExpr],
 	}
 
-	filters := api.SplitStringWithEscape(filterCfg, ',')
-	for _, filter := range filters {
-		if filter == "" {
-			continue
-		}
-		gadgetCtx.Logger().Debugf("adding filter %q", filter)
-		err := fop.addFilter(gadgetCtx, filter)
-		if err != nil {
-			return nil, err
-		}
+	// Filter rules are checked early; expressions are compiled in PreStart, so that fields added by other operators
+	// are available
+	ffns := filterFuncs{}
+	if err := ffns.addFilters(gadgetCtx, fop.filter); err != nil {
+		return nil, err
 	}
+	fop.ffns.Store(&ffns)
 
 	return fop, nil
 }
@@ -107,8 +130,19 @@ func (f *filterOperator) Priority() int {
 	return Priority
 }
 
+// filterFuncs holds the filter functions per data source
+type filterFuncs map[datasource.DataSource][]func(datasource.DataSource, datasource.Data) bool
+
 type filterOperatorInstance struct {
-	ffns map[datasource.DataSource][]func(datasource.DataSource, datasource.Data) bool
+	filter     string
+	filterExpr string
+
+	// ffns is replaced as a whole when params are updated while running
+	ffns atomic.Pointer[filterFuncs]
+
+	// subscribed holds the data sources subscribed to; only data sources with
+	// filters are subscribed to, further ones when params are updated
+	subscribed map[datasource.DataSource]struct{}
 }
 
 func (f *filterOperatorInstance) Name() string {
@@ -116,20 +150,67 @@ func (f *filterOperatorInstance) Name() string {
 }
 
 func (f *filterOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
-	for ds, funcs := range f.ffns {
-		funcs := funcs
-		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
-			for _, fn := range funcs {
+	// Nothing is subscribed yet, so the filters can be extended in place
+	if err := f.ffns.Load().addFilterExpressions(gadgetCtx, f.filterExpr); err != nil {
+		return err
+	}
+
+	return f.subscribe(*f.ffns.Load())
+}
+
+// subscribe subscribes to the data sources of ffns that haven't been
+// subscribed to yet. Subscriptions are kept if the filters of a data source are
+// removed later on, in which case they let all data pass.
+func (f *filterOperatorInstance) subscribe(ffns filterFuncs) error {
+	if f.subscribed == nil {
+		f.subscribed = make(map[datasource.DataSource]struct{})
+	}
+	for ds := range ffns {
+		if _, ok := f.subscribed[ds]; ok {
+			continue
+		}
+		err := ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			for _, fn := range (*f.ffns.Load())[ds] {
 				if !fn(ds, data) {
 					return datasource.ErrDiscard
 				}
 			}
 			return nil
 		}, Priority) // TODO: need some predefined & sane values
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+		f.subscribed[ds] = struct{}{}
 	}
 	return nil
 }
 
+// UpdateParams replaces the filter rules and/or expressions of a running gadget; the previous filters stay active
+// if the new ones are invalid
+func (f *filterOperatorInstance) UpdateParams(gadgetCtx operators.GadgetContext, paramValues api.ParamValues) error {
+	filter := f.filter
+	if val, ok := paramValues[ParamFilter]; ok {
+		filter = val
+	}
+	filterExpr := f.filterExpr
+	if val, ok := paramValues[ParamFilterExpr]; ok {
+		filterExpr = val
+	}
+
+	ffns := filterFuncs{}
+	if err := ffns.addFilters(gadgetCtx, filter); err != nil {
+		return err
+	}
+	if err := ffns.addFilterExpressions(gadgetCtx, filterExpr); err != nil {
+		return err
+	}
+
+	f.filter = filter
+	f.filterExpr = filterExpr
+	f.ffns.Store(&ffns)
+	return f.subscribe(ffns)
+}
+
 func (f *filterOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
 	return nil
 }
@@ -232,7 +313,20 @@ nextChar:
 	return "", "", comparisonTypeUnknown, false, "", fmt.Errorf("incomplete filter rule")
 }
 
-func (f *filterOperatorInstance) addFilter(gadgetCtx operators.GadgetContext, filter string) error {
+func (ffns filterFuncs) addFilters(gadgetCtx operators.GadgetContext, filterCfg string) error {
+	for _, filter := range api.SplitStringWithEscape(filterCfg, ',') {
+		if filter == "" {
+			continue
+		}
+		gadgetCtx.Logger().Debugf("adding filter %q", filter)
+		if err := ffns.addFilter(gadgetCtx, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ffns filterFuncs) addFilter(gadgetCtx operators.GadgetContext, filter string) error {
 	dsName, fieldName, op, negate, value, err := extractFilter(filter)
 	if err != nil {
 		return fmt.Errorf("extracting filter rule %q: %w", filter, err)
@@ -264,7 +358,154 @@ func (f *filterOperatorInstance) addFilter(gadgetCtx operators.GadgetContext, fi
 		return err
 	}
 
-	f.ffns[filterds] = append(f.ffns[filterds], ff)
+	ffns[filterds] = append(ffns[filterds], ff)
+	return nil
+}
+
+// splitExpressions splits a list of comma separated expressions; commas inside of brackets, braces, parentheses
+// or string literals are not treated as separators
+func splitExpressions(s string) []string {
+	var res []string
+	var quote rune
+	var escape bool
+	depth := 0
+	start := 0
+	for i, c := range s {
+		if quote != 0 {
+			switch {
+			case escape:
+				escape = false
+			case c == '\\':
+				escape = true
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				res = append(res, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	res = append(res, s[start:])
+
+	expressions := make([]string, 0, len(res))
+	for _, e := range res {
+		if e = strings.TrimSpace(e); e != "" {
+			expressions = append(expressions, e)
+		}
+	}
+	return expressions
+}
+
+// dsPrefixRegex matches the data source prefix of an expression; data sources of gadgets run
+// together with other gadgets are namespaced as "<image>.<name>"
+var dsPrefixRegex = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_-]*(?:\.[a-zA-Z0-9_][a-zA-Z0-9_-]*)*):`)
+
+// extractFilterExpression splits an optional data source prefix from an expression
+func extractFilterExpression(filterExpr string) (dsName string, expression string) {
+	if m := dsPrefixRegex.FindStringSubmatch(filterExpr); m != nil {
+		return m[1], strings.TrimSpace(filterExpr[len(m[0]):])
+	}
+	return "", filterExpr
+}
+
+func (ffns filterFuncs) addFilterExpressions(gadgetCtx operators.GadgetContext, filterExprCfg string) error {
+	for _, filterExpr := range splitExpressions(filterExprCfg) {
+		gadgetCtx.Logger().Debugf("adding filter expression %q", filterExpr)
+		if err := ffns.addFilterExpression(gadgetCtx, filterExpr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ffns filterFuncs) addFilterExpression(gadgetCtx operators.GadgetContext, filterExpr string) error {
+	dsName, expression := extractFilterExpression(filterExpr)
+	if expression == "" {
+		return fmt.Errorf("empty filter expression %q", filterExpr)
+	}
+
+	dataSources := gadgetCtx.GetDataSources()
+
+	var filterds datasource.DataSource
+	if dsName != "" {
+		ds, ok := dataSources[dsName]
+		if !ok {
+			return fmt.Errorf("data source %q not found for filter expression %q", dsName, filterExpr)
+		}
+		unknown, err := expr.UnknownFields(ds, expression)
+		if err != nil {
+			return fmt.Errorf("invalid filter expression %q: %w", expression, err)
+		}
+		if len(unknown) > 0 {
+			return fmt.Errorf("filter expression %q references unknown fields of data source %q: %s", expression,
+				dsName, strings.Join(unknown, ", "))
+		}
+		filterds = ds
+	} else {
+		// Find the data source that provides all referenced fields
+		dsNames := make([]string, 0, len(dataSources))
+		for name := range dataSources {
+			dsNames = append(dsNames, name)
+		}
+		sort.Strings(dsNames)
+
+		var mismatches []string
+		for _, name := range dsNames {
+			ds := dataSources[name]
+			unknown, err := expr.UnknownFields(ds, expression)
+			if err != nil {
+				return fmt.Errorf("invalid filter expression %q: %w", expression, err)
+			}
+			if len(unknown) > 0 {
+				mismatches = append(mismatches, fmt.Sprintf("%s: %s", name, strings.Join(unknown, ", ")))
+				continue
+			}
+			if filterds != nil {
+				return fmt.Errorf("ambiguous filter expression %q, please specify the datasource", expression)
+			}
+			filterds = ds
+		}
+		if filterds == nil {
+			return fmt.Errorf("filter expression %q references unknown fields (%s)", expression,
+				strings.Join(mismatches, "; "))
+		}
+	}
+
+	prog, err := expr.CompileFilterProgram(filterds, expression)
+	if err != nil {
+		return fmt.Errorf("filter expression %q: %w", expression, err)
+	}
+
+	logger := gadgetCtx.Logger()
+	var failed atomic.Uint64
+	var lastLog atomic.Int64
+	ffns[filterds] = append(ffns[filterds], func(ds datasource.DataSource, data datasource.Data) bool {
+		res, err := expr.Run(prog, data)
+		if err != nil {
+			// Events the expression can't be evaluated for are dropped; report
+			// that without logging every single one
+			failed.Add(1)
+			now := time.Now().UnixNano()
+			last := lastLog.Load()
+			if (last == 0 || now-last >= int64(exprErrorLogInterval)) && lastLog.CompareAndSwap(last, now) {
+				logger.Warnf("filter expression %q failed, dropped %d events: %v", expression, failed.Swap(0), err)
+			}
+			return false
+		}
+		match, _ := res.(bool)
+		return match
+	})
 	return nil
 }
 
@@ -16,6 +16,7 @@ package filter
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
 )
@@ -496,6 +498,375 @@ func TestFilter(t *testing.T) {
 	}
 }
 
+func TestSplitExpressions(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "",
+			expected: []string{},
+		},
+		{
+			input:    "a == 1",
+			expected: []string{"a == 1"},
+		},
+		{
+			input:    "a == 1, ds:b == 2",
+			expected: []string{"a == 1", "ds:b == 2"},
+		},
+		{
+			input:    `a in ["x", "y"],b == "1,2",c == 'it\'s,'`,
+			expected: []string{`a in ["x", "y"]`, `b == "1,2"`, `c == 'it\'s,'`},
+		},
+		{
+			input:    "max(a, b) > 1,",
+			expected: []string{"max(a, b) > 1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.expected, splitExpressions(tc.input))
+		})
+	}
+}
+
+func TestFilterExpression(t *testing.T) {
+	type testCase struct {
+		name       string
+		expression string
+		matches    int
+		error      bool
+	}
+	testCases := []testCase{
+		{
+			name:       "simple match",
+			expression: `comm == "nginx"`,
+			matches:    1,
+		},
+		{
+			name:       "or with parentheses",
+			expression: `latency > 10 && (comm == "nginx" || uid == 0)`,
+			matches:    2,
+		},
+		{
+			name:       "arithmetic and cross-field comparison",
+			expression: `latency * 2 > uid`,
+			matches:    1,
+		},
+		{
+			name:       "with data source",
+			expression: `events:comm matches "^(curl|nginx)$"`,
+			matches:    2,
+		},
+		{
+			name:       "multiple expressions",
+			expression: `comm in ["nginx", "curl"],uid == 0`,
+			matches:    1,
+		},
+		{
+			name:       "no match",
+			expression: `comm == "bash"`,
+			matches:    0,
+		},
+		{
+			name:       "unknown field",
+			expression: `foo == 1 && comm == "nginx"`,
+			error:      true,
+		},
+		{
+			name:       "unknown field with data source",
+			expression: `events:foo == 1`,
+			error:      true,
+		},
+		{
+			name:       "unknown data source",
+			expression: `other:comm == "nginx"`,
+			error:      true,
+		},
+		{
+			name:       "invalid expression",
+			expression: `comm ==`,
+			error:      true,
+		},
+		{
+			name:       "not a boolean expression",
+			expression: `comm`,
+			error:      true,
+		},
+	}
+	events := []struct {
+		comm    string
+		uid     uint32
+		latency uint64
+	}{
+		{comm: "nginx", uid: 1000, latency: 20},
+		{comm: "curl", uid: 0, latency: 30},
+		{comm: "cat", uid: 1000, latency: 5},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ds datasource.DataSource
+			var commField, uidField, latencyField datasource.FieldAccessor
+			rows := 0
+			err := Tester(
+				t,
+				&filterOperator{},
+				api.ParamValues{
+					"operator.filter.filter-expr": tc.expression,
+				},
+				func(gadgetCtx operators.GadgetContext) error {
+					var err error
+					ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+					require.NoError(t, err)
+					commField, err = ds.AddField("comm", api.Kind_String)
+					require.NoError(t, err)
+					uidField, err = ds.AddField("uid", api.Kind_Uint32)
+					require.NoError(t, err)
+					latencyField, err = ds.AddField("latency", api.Kind_Uint64)
+					require.NoError(t, err)
+					return nil
+				},
+				func(gadgetCtx operators.GadgetContext) error {
+					for _, ev := range events {
+						data, err := ds.NewPacketSingle()
+						require.NoError(t, err)
+						require.NoError(t, commField.PutString(data, ev.comm))
+						require.NoError(t, uidField.PutUint32(data, ev.uid))
+						require.NoError(t, latencyField.PutUint64(data, ev.latency))
+						require.NoError(t, ds.EmitAndRelease(data))
+					}
+					return nil
+				},
+				func(gadgetCtx operators.GadgetContext) error {
+					err := ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+						rows++
+						return nil
+					}, Priority+1)
+					require.NoError(t, err)
+					return nil
+				},
+			)
+			if tc.error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.matches, rows)
+			}
+		})
+	}
+}
+
+func TestExtractFilterExpression(t *testing.T) {
+	dsName, expression := extractFilterExpression(`trace_exec.events: comm == "nginx"`)
+	assert.Equal(t, "trace_exec.events", dsName)
+	assert.Equal(t, `comm == "nginx"`, expression)
+
+	dsName, expression = extractFilterExpression(`events:comm == "nginx"`)
+	assert.Equal(t, "events", dsName)
+	assert.Equal(t, `comm == "nginx"`, expression)
+
+	dsName, expression = extractFilterExpression(`proc.comm == "a:b"`)
+	assert.Equal(t, "", dsName)
+	assert.Equal(t, `proc.comm == "a:b"`, expression)
+}
+
+func TestFilterExpressionNamespacedDataSource(t *testing.T) {
+	var execDs, openDs datasource.DataSource
+	var execComm, openComm datasource.FieldAccessor
+	execRows, openRows := 0, 0
+	err := Tester(
+		t,
+		&filterOperator{},
+		api.ParamValues{
+			"operator.filter.filter-expr": `trace_exec.events:comm == "nginx"`,
+		},
+		func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			// Data sources of gadgets run together are prefixed by their image
+			execDs, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "trace_exec.events")
+			require.NoError(t, err)
+			execComm, err = execDs.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			openDs, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "trace_open.events")
+			require.NoError(t, err)
+			openComm, err = openDs.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			return nil
+		},
+		func(gadgetCtx operators.GadgetContext) error {
+			for _, comm := range []string{"nginx", "curl"} {
+				for ds, field := range map[datasource.DataSource]datasource.FieldAccessor{execDs: execComm, openDs: openComm} {
+					data, err := ds.NewPacketSingle()
+					require.NoError(t, err)
+					require.NoError(t, field.PutString(data, comm))
+					require.NoError(t, ds.EmitAndRelease(data))
+				}
+			}
+			return nil
+		},
+		func(gadgetCtx operators.GadgetContext) error {
+			require.NoError(t, execDs.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				execRows++
+				return nil
+			}, Priority+1))
+			require.NoError(t, openDs.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				openRows++
+				return nil
+			}, Priority+1))
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, execRows)
+	assert.Equal(t, 2, openRows)
+}
+
+func TestFilterUpdateParams(t *testing.T) {
+	gadgetCtx := gadgetcontext.New(context.Background(), "")
+	ds, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	commField, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+
+	var received []string
+	require.NoError(t, ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		comm, _ := commField.String(data)
+		received = append(received, comm)
+		return nil
+	}, Priority+1))
+
+	emit := func() {
+		for _, comm := range []string{"nginx", "curl", "cat"} {
+			data, err := ds.NewPacketSingle()
+			require.NoError(t, err)
+			require.NoError(t, commField.PutString(data, comm))
+			require.NoError(t, ds.EmitAndRelease(data))
+		}
+	}
+
+	opInst, err := (&filterOperator{}).InstantiateDataOperator(gadgetCtx, api.ParamValues{
+		ParamFilter: "comm==nginx",
+	})
+	require.NoError(t, err)
+	fop := opInst.(*filterOperatorInstance)
+	require.NoError(t, fop.PreStart(gadgetCtx))
+
+	emit()
+	assert.Equal(t, []string{"nginx"}, received)
+
+	// Replacing the filter rules keeps the (empty) expressions
+	received = nil
+	require.NoError(t, fop.UpdateParams(gadgetCtx, api.ParamValues{ParamFilter: "comm!=nginx"}))
+	emit()
+	assert.Equal(t, []string{"curl", "cat"}, received)
+
+	// Adding an expression keeps the filter rules
+	received = nil
+	require.NoError(t, fop.UpdateParams(gadgetCtx, api.ParamValues{ParamFilterExpr: `comm startsWith "c" && comm != "curl"`}))
+	emit()
+	assert.Equal(t, []string{"cat"}, received)
+
+	// Invalid values keep the current filters
+	received = nil
+	require.Error(t, fop.UpdateParams(gadgetCtx, api.ParamValues{ParamFilter: "foo==bar"}))
+	require.Error(t, fop.UpdateParams(gadgetCtx, api.ParamValues{ParamFilterExpr: "comm =="}))
+	emit()
+	assert.Equal(t, []string{"cat"}, received)
+
+	// Removing all filters
+	received = nil
+	require.NoError(t, fop.UpdateParams(gadgetCtx, api.ParamValues{ParamFilter: "", ParamFilterExpr: ""}))
+	emit()
+	assert.Equal(t, []string{"nginx", "curl", "cat"}, received)
+}
+
+// warningLogger keeps all messages logged with level warning
+type warningLogger struct {
+	warnings []string
+}
+
+func (l *warningLogger) Log(severity logger.Level, params ...any) {
+	l.Logf(severity, "%s", fmt.Sprint(params...))
+}
+
+func (l *warningLogger) Logf(severity logger.Level, format string, params ...any) {
+	if severity == logger.WarnLevel {
+		l.warnings = append(l.warnings, fmt.Sprintf(format, params...))
+	}
+}
+
+func (l *warningLogger) SetLevel(logger.Level) {}
+
+func (l *warningLogger) GetLevel() logger.Level { return logger.DebugLevel }
+
+func TestFilterExpressionRuntimeError(t *testing.T) {
+	l := &warningLogger{}
+	gadgetCtx := gadgetcontext.New(context.Background(), "",
+		gadgetcontext.WithLogger(logger.NewFromGenericLogger(l)))
+	ds, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	uidField, err := ds.AddField("uid", api.Kind_Int32)
+	require.NoError(t, err)
+
+	received := 0
+	require.NoError(t, ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		received++
+		return nil
+	}, Priority+1))
+
+	opInst, err := (&filterOperator{}).InstantiateDataOperator(gadgetCtx, api.ParamValues{
+		ParamFilterExpr: "uid % uid == 0",
+	})
+	require.NoError(t, err)
+	require.NoError(t, opInst.(*filterOperatorInstance).PreStart(gadgetCtx))
+
+	// uid 0 can't be evaluated and must be dropped; the error is only logged once
+	for _, uid := range []int32{0, 1, 0} {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, uidField.PutInt32(data, uid))
+		require.NoError(t, ds.EmitAndRelease(data))
+	}
+	assert.Equal(t, 1, received)
+	require.Len(t, l.warnings, 1)
+	assert.Contains(t, l.warnings[0], `filter expression "uid % uid == 0" failed, dropped 1 events`)
+}
+
+func TestFilterUpdateParamsSubscribesLazily(t *testing.T) {
+	gadgetCtx := gadgetcontext.New(context.Background(), "")
+	ds, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+	require.NoError(t, err)
+	commField, err := ds.AddField("comm", api.Kind_String)
+	require.NoError(t, err)
+
+	opInst, err := (&filterOperator{}).InstantiateDataOperator(gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	fop := opInst.(*filterOperatorInstance)
+	require.NoError(t, fop.PreStart(gadgetCtx))
+
+	// Without filters, no data source is subscribed to
+	assert.Empty(t, fop.subscribed)
+
+	var received []string
+	require.NoError(t, ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+		comm, _ := commField.String(data)
+		received = append(received, comm)
+		return nil
+	}, Priority+1))
+
+	require.NoError(t, fop.UpdateParams(gadgetCtx, api.ParamValues{ParamFilter: "comm==nginx"}))
+	assert.Len(t, fop.subscribed, 1)
+
+	for _, comm := range []string{"nginx", "curl"} {
+		data, err := ds.NewPacketSingle()
+		require.NoError(t, err)
+		require.NoError(t, commField.PutString(data, comm))
+		require.NoError(t, ds.EmitAndRelease(data))
+	}
+	assert.Equal(t, []string{"nginx"}, received)
+}
+
 func Tester(
 	t *testing.T,
 	operator operators.DataOperator,
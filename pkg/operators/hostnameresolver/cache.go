@@ -0,0 +1,101 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostnameresolver
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// cacheKey identifies an IP address in a network namespace; netns 0 is used
+// for entries that apply to all network namespaces
+type cacheKey struct {
+	netns uint64
+	ip    string
+}
+
+type cacheEntry struct {
+	key      cacheKey
+	hostname string
+	expires  time.Time
+}
+
+// dnsCache keeps the last hostname that resolved to an IP address. Entries
+// expire after the TTL; once the cache is full, the least recently updated
+// entries are dropped first.
+type dnsCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[cacheKey]*list.Element
+	order      *list.List
+}
+
+func newDNSCache(ttl time.Duration, maxEntries int) *dnsCache {
+	return &dnsCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[cacheKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// put stores the hostname for the given key; an empty hostname is stored as
+// well to remember that an IP couldn't be resolved
+func (c *dnsCache) put(key cacheKey, hostname string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.hostname = hostname
+		entry.expires = now.Add(c.ttl)
+		c.order.MoveToBack(el)
+		return
+	}
+
+	for c.order.Len() >= c.maxEntries {
+		c.removeElement(c.order.Front())
+	}
+	c.entries[key] = c.order.PushBack(&cacheEntry{
+		key:      key,
+		hostname: hostname,
+		expires:  now.Add(c.ttl),
+	})
+}
+
+// get returns the hostname for the given key and whether there is a (non
+// expired) entry for it
+func (c *dnsCache) get(key cacheKey, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*cacheEntry)
+	if now.After(entry.expires) {
+		c.removeElement(el)
+		return "", false
+	}
+	return entry.hostname, true
+}
+
+func (c *dnsCache) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
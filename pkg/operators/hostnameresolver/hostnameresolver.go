@@ -0,0 +1,430 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostnameresolver provides an operator that enriches IP addresses
+// with the last hostname that resolved to them. It keeps a passive DNS cache
+// that is fed by the DNS responses seen by gadgets like trace_dns and shared by
+// all gadgets running on the same node. Optionally, addresses not found in the
+// cache are looked up actively using reverse DNS.
+package hostnameresolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	OperatorName = "HostnameResolver"
+	Priority     = 12
+
+	ParamResolveHostnames     = "resolve-hostnames"
+	ParamCacheSize            = "hostname-cache-size"
+	ParamCacheTTL             = "hostname-cache-ttl"
+	ParamReverseLookup        = "hostname-reverse-lookup"
+	ParamReverseLookupTimeout = "hostname-reverse-lookup-timeout"
+
+	// AnnotationName and AnnotationAddresses mark data sources carrying DNS
+	// responses; their values are the names of the fields holding the queried
+	// name and the comma-separated list of addresses of the response
+	AnnotationName      = "hostnameresolver.name"
+	AnnotationAddresses = "hostnameresolver.addresses"
+
+	HostnameFieldName = "hostname"
+
+	ipAddrType  = "gadget_ip_addr_t"
+	netnsIDType = "gadget_netns_id"
+
+	// reverseLookupWorkers is the number of concurrent reverse lookups
+	reverseLookupWorkers = 4
+	// reverseLookupQueueSize is the number of addresses waiting to be looked
+	// up; further addresses are dropped until the queue has space again
+	reverseLookupQueueSize = 1024
+)
+
+type hostnameResolver struct {
+	cache *dnsCache
+
+	reverseLookup        bool
+	reverseLookupTimeout time.Duration
+	lookupAddr           func(ctx context.Context, addr string) ([]string, error)
+
+	lookupQueue chan string
+	pendingMu   sync.Mutex
+	pending     map[string]struct{}
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func (h *hostnameResolver) Name() string {
+	return OperatorName
+}
+
+func (h *hostnameResolver) Description() string {
+	return "HostnameResolver resolves IP addresses to the hostnames seen in DNS responses"
+}
+
+func (h *hostnameResolver) GlobalParams() api.Params {
+	return api.Params{
+		{
+			Key:          ParamCacheSize,
+			Title:        "Hostname Cache Size",
+			Description:  "Maximum number of IP addresses to keep the hostname for; the oldest ones are dropped first",
+			DefaultValue: "16384",
+			TypeHint:     api.TypeUint,
+		},
+		{
+			Key:          ParamCacheTTL,
+			Title:        "Hostname Cache TTL",
+			Description:  "Time for which a hostname seen in a DNS response (or found by a reverse lookup) is used",
+			DefaultValue: "10m",
+			TypeHint:     api.TypeDuration,
+		},
+		{
+			Key:   ParamReverseLookup,
+			Title: "Hostname Reverse Lookup",
+			Description: "Look up IP addresses not found in the hostname cache using reverse DNS. Lookups are " +
+				"done in the background, so events are only enriched once the result is known",
+			DefaultValue: "false",
+			TypeHint:     api.TypeBool,
+		},
+		{
+			Key:          ParamReverseLookupTimeout,
+			Title:        "Hostname Reverse Lookup Timeout",
+			Description:  "Timeout for a single reverse lookup",
+			DefaultValue: "2s",
+			TypeHint:     api.TypeDuration,
+		},
+	}
+}
+
+func (h *hostnameResolver) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:          ParamResolveHostnames,
+			Title:        "Resolve Hostnames",
+			Description:  "Add the hostname IP addresses were resolved from to the endpoints",
+			DefaultValue: "false",
+			TypeHint:     api.TypeBool,
+		},
+	}
+}
+
+func (h *hostnameResolver) Init(params *params.Params) error {
+	cacheSize := params.Get(ParamCacheSize).AsInt()
+	if cacheSize <= 0 {
+		return fmt.Errorf("%s must be greater than 0", ParamCacheSize)
+	}
+	h.cache = newDNSCache(params.Get(ParamCacheTTL).AsDuration(), cacheSize)
+
+	h.reverseLookup = params.Get(ParamReverseLookup).AsBool()
+	h.reverseLookupTimeout = params.Get(ParamReverseLookupTimeout).AsDuration()
+	if h.lookupAddr == nil {
+		h.lookupAddr = net.DefaultResolver.LookupAddr
+	}
+	if h.reverseLookup {
+		h.startReverseLookups()
+	}
+	return nil
+}
+
+func (h *hostnameResolver) Close() error {
+	if h.cancel != nil {
+		h.cancel()
+		h.wg.Wait()
+		h.cancel = nil
+	}
+	return nil
+}
+
+func (h *hostnameResolver) startReverseLookups() {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.lookupQueue = make(chan string, reverseLookupQueueSize)
+	h.pending = make(map[string]struct{})
+
+	for range reverseLookupWorkers {
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ip := <-h.lookupQueue:
+					h.lookup(ctx, ip)
+				}
+			}
+		}()
+	}
+}
+
+func (h *hostnameResolver) lookup(ctx context.Context, ip string) {
+	lookupCtx, cancel := context.WithTimeout(ctx, h.reverseLookupTimeout)
+	defer cancel()
+
+	var hostname string
+	names, err := h.lookupAddr(lookupCtx, ip)
+	if err != nil {
+		log.Debugf("reverse lookup of %s: %s", ip, err)
+	} else if len(names) > 0 {
+		hostname = strings.TrimSuffix(names[0], ".")
+	}
+
+	// Negative results are cached as well, so that addresses without a name
+	// aren't looked up over and over again
+	h.cache.put(cacheKey{ip: ip}, hostname, time.Now())
+
+	h.pendingMu.Lock()
+	delete(h.pending, ip)
+	h.pendingMu.Unlock()
+}
+
+// enqueueLookup schedules a reverse lookup of the given IP, unless one is
+// already pending or the queue is full
+func (h *hostnameResolver) enqueueLookup(ip string) {
+	h.pendingMu.Lock()
+	defer h.pendingMu.Unlock()
+
+	if _, ok := h.pending[ip]; ok {
+		return
+	}
+	select {
+	case h.lookupQueue <- ip:
+		h.pending[ip] = struct{}{}
+	default:
+	}
+}
+
+// addResponse stores the addresses of a DNS response for the given name
+func (h *hostnameResolver) addResponse(netns uint64, name string, addresses string, now time.Time) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return
+	}
+	for _, addr := range strings.Split(addresses, ",") {
+		ip := net.ParseIP(strings.TrimSpace(addr))
+		if ip == nil {
+			continue
+		}
+		ipStr := ip.String()
+		h.cache.put(cacheKey{ip: ipStr}, name, now)
+		if netns != 0 {
+			h.cache.put(cacheKey{netns: netns, ip: ipStr}, name, now)
+		}
+	}
+}
+
+// hostname returns the hostname for the IP, preferring the one seen in the
+// given network namespace
+func (h *hostnameResolver) hostname(netns uint64, ip string, now time.Time) string {
+	if netns != 0 {
+		if hostname, ok := h.cache.get(cacheKey{netns: netns, ip: ip}, now); ok {
+			return hostname
+		}
+	}
+	hostname, ok := h.cache.get(cacheKey{ip: ip}, now)
+	if !ok && h.reverseLookup {
+		h.enqueueLookup(ip)
+	}
+	return hostname
+}
+
+type dnsSource struct {
+	name      datasource.FieldAccessor
+	addresses datasource.FieldAccessor
+	netns     datasource.FieldAccessor
+}
+
+type ipAccessors struct {
+	ip       datasource.FieldAccessor
+	version  datasource.FieldAccessor
+	hostname datasource.FieldAccessor
+}
+
+type enrichTarget struct {
+	netns datasource.FieldAccessor
+	ips   []ipAccessors
+}
+
+// netnsField returns the first field holding a network namespace ID
+func netnsField(ds datasource.DataSource) datasource.FieldAccessor {
+	if fields := ds.GetFieldsWithTag("type:" + netnsIDType); len(fields) > 0 {
+		return fields[0]
+	}
+	return nil
+}
+
+func getNetns(f datasource.FieldAccessor, data datasource.Data) uint64 {
+	if f == nil {
+		return 0
+	}
+	switch f.Type() {
+	case api.Kind_Uint32:
+		v, _ := f.Uint32(data)
+		return uint64(v)
+	case api.Kind_Uint64:
+		v, _ := f.Uint64(data)
+		return v
+	}
+	return 0
+}
+
+func (h *hostnameResolver) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	logger := gadgetCtx.Logger()
+
+	params := apihelpers.ToParamDescs(h.InstanceParams()).ToParams()
+	if err := params.CopyFromMap(instanceParamValues, ""); err != nil {
+		return nil, err
+	}
+	resolve := params.Get(ParamResolveHostnames).AsBool()
+
+	sources := make(map[datasource.DataSource]dnsSource)
+	targets := make(map[datasource.DataSource]enrichTarget)
+	for _, ds := range gadgetCtx.GetDataSources() {
+		annotations := ds.Annotations()
+		nameField, addressesField := annotations[AnnotationName], annotations[AnnotationAddresses]
+		if nameField != "" && addressesField != "" {
+			src := dnsSource{
+				name:      ds.GetField(nameField),
+				addresses: ds.GetField(addressesField),
+				netns:     netnsField(ds),
+			}
+			if src.name == nil || src.addresses == nil {
+				return nil, fmt.Errorf("data source %q: fields %q and %q annotated for hostname resolution not found",
+					ds.Name(), nameField, addressesField)
+			}
+			logger.Debugf("HostnameResolverOperator: collecting DNS responses from %q", ds.Name())
+			sources[ds] = src
+		}
+
+		if !resolve {
+			continue
+		}
+
+		var ips []ipAccessors
+		for _, ip := range ds.GetFieldsWithTag("type:" + ipAddrType) {
+			// The version is needed to tell IPv4 and IPv6 addresses apart;
+			// it's only available for endpoints
+			parent := ip.Parent()
+			if parent == nil || ip.Size() != 16 {
+				continue
+			}
+			version := parent.GetSubFieldsWithTag("name:version")
+			if len(version) != 1 {
+				continue
+			}
+			hostname, err := parent.AddSubField(HostnameFieldName, api.Kind_String,
+				datasource.WithAnnotations(map[string]string{
+					metadatav1.DescriptionAnnotation:     "Last hostname that resolved to the address",
+					metadatav1.ColumnsMaxWidthAnnotation: "64",
+				}),
+				datasource.WithFlags(datasource.FieldFlagHidden),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("adding field %q: %w", HostnameFieldName, err)
+			}
+			ips = append(ips, ipAccessors{ip: ip, version: version[0], hostname: hostname})
+		}
+		if len(ips) > 0 {
+			targets[ds] = enrichTarget{netns: netnsField(ds), ips: ips}
+		}
+	}
+
+	if len(sources) == 0 && len(targets) == 0 {
+		return nil, nil
+	}
+
+	return &hostnameResolverInstance{
+		op:      h,
+		sources: sources,
+		targets: targets,
+	}, nil
+}
+
+func (h *hostnameResolver) Priority() int {
+	return Priority
+}
+
+type hostnameResolverInstance struct {
+	op      *hostnameResolver
+	sources map[datasource.DataSource]dnsSource
+	targets map[datasource.DataSource]enrichTarget
+}
+
+func (i *hostnameResolverInstance) Name() string {
+	return OperatorName
+}
+
+func (i *hostnameResolverInstance) Start(gadgetCtx operators.GadgetContext) error {
+	for ds, src := range i.sources {
+		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			addresses, _ := src.addresses.String(data)
+			if addresses == "" {
+				return nil
+			}
+			name, _ := src.name.String(data)
+			i.op.addResponse(getNetns(src.netns, data), name, addresses, time.Now())
+			return nil
+		}, Priority)
+	}
+
+	for ds, target := range i.targets {
+		ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			var errs error
+			netns := getNetns(target.netns, data)
+			now := time.Now()
+			for _, a := range target.ips {
+				ip, err := common.GetIPForVersion(data, a.version, a.ip)
+				if err != nil {
+					errs = errors.Join(errs, fmt.Errorf("%s: getting IP: %w", a.ip.FullName(), err))
+					continue
+				}
+				if hostname := i.op.hostname(netns, ip, now); hostname != "" {
+					a.hostname.PutString(data, hostname)
+				}
+			}
+			return errs
+		}, Priority)
+	}
+	return nil
+}
+
+func (i *hostnameResolverInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (i *hostnameResolverInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &hostnameResolver{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostnameresolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+)
+
+func newResolver(t *testing.T, values map[string]string, lookupAddr func(ctx context.Context, addr string) ([]string, error)) *hostnameResolver {
+	h := &hostnameResolver{lookupAddr: lookupAddr}
+	params := apihelpers.ToParamDescs(h.GlobalParams()).ToParams()
+	require.NoError(t, params.CopyFromMap(values, ""))
+	require.NoError(t, h.Init(params))
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestDNSCache(t *testing.T) {
+	now := time.Now()
+	c := newDNSCache(time.Minute, 2)
+
+	a, b, d := cacheKey{ip: "10.0.0.1"}, cacheKey{ip: "10.0.0.2"}, cacheKey{netns: 1, ip: "10.0.0.1"}
+	c.put(a, "a.example.com", now)
+	c.put(b, "b.example.com", now)
+	c.put(a, "a.example.org", now.Add(time.Second))
+
+	hostname, ok := c.get(a, now)
+	assert.True(t, ok)
+	assert.Equal(t, "a.example.org", hostname)
+
+	// b is the oldest entry now
+	c.put(d, "d.example.com", now.Add(2*time.Second))
+	_, ok = c.get(b, now)
+	assert.False(t, ok)
+
+	// Entries expire after the TTL
+	_, ok = c.get(a, now.Add(2*time.Minute))
+	assert.False(t, ok)
+	assert.Len(t, c.entries, 1)
+}
+
+func TestPassiveDNS(t *testing.T) {
+	h := newResolver(t, nil, nil)
+	now := time.Now()
+
+	h.addResponse(0, "example.com.", "93.184.215.14,2606:2800:21f:cb07:6820:80da:af6b:8b2c", now)
+	h.addResponse(42, "other.example.", "93.184.215.14", now)
+	h.addResponse(0, "", "10.0.0.1", now)
+
+	assert.Equal(t, "example.com", h.hostname(0, "2606:2800:21f:cb07:6820:80da:af6b:8b2c", now))
+	// The last hostname wins, but hostnames seen in the same network
+	// namespace are preferred
+	assert.Equal(t, "other.example", h.hostname(0, "93.184.215.14", now))
+	assert.Equal(t, "other.example", h.hostname(42, "93.184.215.14", now))
+	h.addResponse(7, "example.com", "93.184.215.14", now)
+	assert.Equal(t, "other.example", h.hostname(42, "93.184.215.14", now))
+	assert.Equal(t, "example.com", h.hostname(1, "93.184.215.14", now))
+	assert.Empty(t, h.hostname(0, "10.0.0.1", now))
+}
+
+func TestReverseLookup(t *testing.T) {
+	lookups := make(chan string, 10)
+	h := newResolver(t, map[string]string{
+		ParamReverseLookup: "true",
+	}, func(ctx context.Context, addr string) ([]string, error) {
+		lookups <- addr
+		if addr == "10.0.0.1" {
+			return []string{"db.example.com."}, nil
+		}
+		return nil, errors.New("not found")
+	})
+
+	// The first lookup is done in the background
+	assert.Empty(t, h.hostname(0, "10.0.0.1", time.Now()))
+	assert.Equal(t, "10.0.0.1", <-lookups)
+	require.Eventually(t, func() bool {
+		return h.hostname(0, "10.0.0.1", time.Now()) == "db.example.com"
+	}, time.Second, 10*time.Millisecond)
+
+	// Failed lookups are cached as well
+	assert.Empty(t, h.hostname(0, "10.0.0.2", time.Now()))
+	assert.Equal(t, "10.0.0.2", <-lookups)
+	require.Eventually(t, func() bool {
+		_, ok := h.cache.get(cacheKey{ip: "10.0.0.2"}, time.Now())
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, h.hostname(0, "10.0.0.2", time.Now()))
+	assert.Empty(t, lookups)
+}
@@ -0,0 +1,485 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package join is a data operator that enriches the events of a data source
+// with the latest event of another data source having the same value for a
+// key field, like the pid or the mount namespace. This is mostly useful when
+// running several gadgets together, e.g. to add the arguments of the process
+// from trace_exec to the connections seen by trace_tcp.
+package join
+
+import (
+	"container/list"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name                = "join"
+	ParamJoin           = "join"
+	ParamJoinFields     = "join-fields"
+	ParamJoinTTL        = "join-ttl"
+	ParamJoinMaxEntries = "join-max-entries"
+
+	// Priority is after the enrichers, so that their fields can be used as
+	// keys and are joined as well, and before the record and filter
+	// operators, so that joined fields can be recorded and filtered on.
+	Priority = 8500
+
+	// FieldTag is set on the fields added by the operator; it is used to
+	// detect data sources that have already been joined remotely
+	FieldTag = "join"
+)
+
+type joinOperator struct{}
+
+func (j *joinOperator) Name() string {
+	return name
+}
+
+func (j *joinOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (j *joinOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (j *joinOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamJoin,
+			Title: "Join",
+			Description: "Add the fields of the latest event of a data source to the events of another data source " +
+				"having the same value for a key field. Use 'target:field=source:field' or 'target:field=source' if " +
+				"both key fields have the same name and separate multiple joins with ';'",
+		},
+		{
+			Key:   ParamJoinFields,
+			Title: "Join Fields",
+			Description: "Fields of the source data source to add to the target data source; all fields are added " +
+				"by default. Join multiple fields with ','. If using multiple source data sources, prefix fields " +
+				"with 'datasourcename:' and separate with ';'",
+		},
+		{
+			Key:          ParamJoinTTL,
+			Title:        "Join TTL",
+			Description:  "Time for which the latest event of the source data source is used for joining; 0 to keep it until it's replaced",
+			DefaultValue: "5m",
+			TypeHint:     api.TypeDuration,
+		},
+		{
+			Key:          ParamJoinMaxEntries,
+			Title:        "Join Max Entries",
+			Description:  "Maximum number of keys to keep the latest event for per join; the oldest ones are dropped first",
+			DefaultValue: "16384",
+			TypeHint:     api.TypeUint,
+		},
+	}
+}
+
+type joinSpec struct {
+	target    string
+	targetKey string
+	source    string
+	sourceKey string
+}
+
+// parseJoins parses values like `target:field=source:field;target2:field=source2`
+func parseJoins(s string) ([]joinSpec, error) {
+	var res []joinSpec
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		targetStr, sourceStr, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("join %q must be in target:field=source:field format", entry)
+		}
+		var spec joinSpec
+		spec.target, spec.targetKey, found = strings.Cut(targetStr, ":")
+		if !found || spec.target == "" || spec.targetKey == "" {
+			return nil, fmt.Errorf("join %q: target must be in datasource:field format", entry)
+		}
+		spec.source, spec.sourceKey, found = strings.Cut(sourceStr, ":")
+		if !found {
+			spec.sourceKey = spec.targetKey
+		}
+		if spec.source == "" || spec.sourceKey == "" {
+			return nil, fmt.Errorf("join %q: source must be in datasource:field or datasource format", entry)
+		}
+		if spec.source == spec.target {
+			return nil, fmt.Errorf("join %q: can't join a data source with itself", entry)
+		}
+		res = append(res, spec)
+	}
+	return res, nil
+}
+
+func (j *joinOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	specs, err := parseJoins(instanceParamValues[ParamJoin])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamJoin, err)
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	fields, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamJoinFields])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamJoinFields, err)
+	}
+
+	ttl, err := time.ParseDuration(instanceParamValues[ParamJoinTTL])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamJoinTTL, err)
+	}
+	maxEntries, err := strconv.ParseUint(instanceParamValues[ParamJoinMaxEntries], 10, 32)
+	if err != nil || maxEntries == 0 {
+		return nil, fmt.Errorf("invalid %s %q", ParamJoinMaxEntries, instanceParamValues[ParamJoinMaxEntries])
+	}
+
+	dataSources := gadgetCtx.GetDataSources()
+
+	inst := &joinOperatorInstance{}
+	for _, spec := range specs {
+		target, ok := dataSources[spec.target]
+		if !ok {
+			return nil, fmt.Errorf("data source %q not found", spec.target)
+		}
+		source, ok := dataSources[spec.source]
+		if !ok {
+			return nil, fmt.Errorf("data source %q not found", spec.source)
+		}
+
+		sourceFields, ok := fields[spec.source]
+		if !ok {
+			sourceFields = fields[""]
+		}
+
+		jn, err := newJoiner(gadgetCtx, target, source, spec, sourceFields, newTable(ttl, int(maxEntries)))
+		if err != nil {
+			return nil, fmt.Errorf("joining %q with %q: %w", spec.target, spec.source, err)
+		}
+		if jn == nil {
+			continue
+		}
+		inst.joiners = append(inst.joiners, jn)
+	}
+
+	if len(inst.joiners) == 0 {
+		return nil, nil
+	}
+	return inst, nil
+}
+
+func (j *joinOperator) Priority() int {
+	return Priority
+}
+
+// joinedFieldName returns the name of the field holding the fields joined
+// from the given data source; for data sources of gadgets run together, this
+// is the name of the data source without the image prefix
+func joinedFieldName(source string) string {
+	return source[strings.LastIndex(source, ".")+1:]
+}
+
+type joiner struct {
+	target    datasource.DataSource
+	source    datasource.DataSource
+	targetKey datasource.FieldAccessor
+	sourceKey datasource.FieldAccessor
+
+	// sourceFields are copied to targetFields with the same index
+	sourceFields []datasource.FieldAccessor
+	targetFields []datasource.FieldAccessor
+
+	table *table
+}
+
+func newJoiner(
+	gadgetCtx operators.GadgetContext,
+	target datasource.DataSource,
+	source datasource.DataSource,
+	spec joinSpec,
+	fields []string,
+	table *table,
+) (*joiner, error) {
+	if target.Type() != datasource.TypeSingle || source.Type() != datasource.TypeSingle {
+		return nil, fmt.Errorf("%s can only be used on data sources of type single", ParamJoin)
+	}
+
+	jn := &joiner{
+		target: target,
+		source: source,
+		table:  table,
+	}
+
+	jn.targetKey = target.GetField(spec.targetKey)
+	if jn.targetKey == nil {
+		return nil, fmt.Errorf("field %q not found in %q", spec.targetKey, spec.target)
+	}
+	jn.sourceKey = source.GetField(spec.sourceKey)
+	if jn.sourceKey == nil {
+		return nil, fmt.Errorf("field %q not found in %q", spec.sourceKey, spec.source)
+	}
+	if jn.targetKey.Type() == api.Kind_Invalid || jn.targetKey.Type() != jn.sourceKey.Type() {
+		return nil, fmt.Errorf("fields %q and %q can't be used as keys, as their types don't match",
+			spec.targetKey, spec.sourceKey)
+	}
+
+	for _, fieldName := range fields {
+		if source.GetField(fieldName) == nil {
+			return nil, fmt.Errorf("field %q not found in %q", fieldName, spec.source)
+		}
+	}
+
+	joinedName := joinedFieldName(source.Name())
+	if f := target.GetField(joinedName); f != nil {
+		if slices.Contains(f.Tags(), FieldTag) {
+			gadgetCtx.Logger().Debugf("join: %q has already been joined with %q", target.Name(), source.Name())
+			return nil, nil
+		}
+		return nil, fmt.Errorf("field %q already exists in %q", joinedName, spec.target)
+	}
+
+	parent, err := target.AddField(joinedName, api.Kind_Invalid,
+		datasource.WithFlags(datasource.FieldFlagEmpty),
+		datasource.WithTags(FieldTag),
+		datasource.WithAnnotations(map[string]string{
+			metadatav1.DescriptionAnnotation: fmt.Sprintf("Latest event of %s with the same %s", source.Name(), spec.sourceKey),
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("adding field %q: %w", joinedName, err)
+	}
+
+	// Recreate the selected fields (and their parents) below parent; fields
+	// are returned with parents first
+	added := make(map[string]datasource.FieldAccessor)
+	for _, f := range source.Accessors(false) {
+		if datasource.FieldFlagUnreferenced.In(f.Flags()) || !isSelected(f, fields) {
+			continue
+		}
+
+		targetParent := parent
+		if p := f.Parent(); p != nil {
+			var ok bool
+			targetParent, ok = added[p.FullName()]
+			if !ok {
+				// The parent has been skipped
+				continue
+			}
+		}
+
+		kind := f.Type()
+		flags := datasource.FieldFlag(f.Flags()) & datasource.FieldFlagHidden
+		if kind == api.Kind_Invalid || datasource.FieldFlagEmpty.In(f.Flags()) {
+			kind = api.Kind_Invalid
+			flags |= datasource.FieldFlagEmpty
+		}
+		tf, err := targetParent.AddSubField(f.Name(), kind,
+			datasource.WithFlags(flags),
+			datasource.WithAnnotations(f.Annotations()),
+			datasource.WithTags(FieldTag),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("adding field %q: %w", f.FullName(), err)
+		}
+		added[f.FullName()] = tf
+
+		if !datasource.FieldFlagEmpty.In(uint32(flags)) {
+			jn.sourceFields = append(jn.sourceFields, f)
+			jn.targetFields = append(jn.targetFields, tf)
+		}
+	}
+
+	gadgetCtx.Logger().Debugf("join: adding %d fields of %q to %q by %s=%s", len(jn.targetFields), source.Name(),
+		target.Name(), spec.targetKey, spec.sourceKey)
+
+	return jn, nil
+}
+
+// isSelected returns whether a field is to be joined; this is the case for
+// the given fields, their parents and their subfields
+func isSelected(f datasource.FieldAccessor, fields []string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	fullName := f.FullName()
+	for _, name := range fields {
+		if fullName == name || strings.HasPrefix(fullName, name+".") || strings.HasPrefix(name, fullName+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// collect stores the values of an event of the source data source
+func (jn *joiner) collect(data datasource.Data, now time.Time) {
+	values := make([][]byte, len(jn.sourceFields))
+	for i, f := range jn.sourceFields {
+		// data may not be accessed after returning, so copy the values
+		values[i] = slices.Clone(f.Get(data))
+	}
+	jn.table.put(string(jn.sourceKey.Get(data)), values, now)
+}
+
+// enrich adds the values of the latest matching event of the source data
+// source to an event of the target data source
+func (jn *joiner) enrich(data datasource.Data, now time.Time) error {
+	values := jn.table.get(string(jn.targetKey.Get(data)), now)
+	if values == nil {
+		return nil
+	}
+	for i, f := range jn.targetFields {
+		if err := f.Set(data, slices.Clone(values[i])); err != nil {
+			return fmt.Errorf("setting field %q: %w", f.FullName(), err)
+		}
+	}
+	return nil
+}
+
+type record struct {
+	key    string
+	values [][]byte
+	seen   time.Time
+}
+
+// table holds the values of the latest event per key; its entries are kept
+// in the order they have been updated, so that the oldest ones can be
+// dropped first
+type table struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newTable(ttl time.Duration, maxEntries int) *table {
+	return &table{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (t *table) expired(r *record, now time.Time) bool {
+	return t.ttl > 0 && now.Sub(r.seen) > t.ttl
+}
+
+func (t *table) remove(e *list.Element) {
+	t.order.Remove(e)
+	delete(t.entries, e.Value.(*record).key)
+}
+
+func (t *table) put(key string, values [][]byte, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if e, ok := t.entries[key]; ok {
+		r := e.Value.(*record)
+		r.values = values
+		r.seen = now
+		t.order.MoveToBack(e)
+	} else {
+		t.entries[key] = t.order.PushBack(&record{key: key, values: values, seen: now})
+	}
+
+	for e := t.order.Front(); e != nil; e = t.order.Front() {
+		if len(t.entries) <= t.maxEntries && !t.expired(e.Value.(*record), now) {
+			break
+		}
+		t.remove(e)
+	}
+}
+
+func (t *table) get(key string, now time.Time) [][]byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+	r := e.Value.(*record)
+	if t.expired(r, now) {
+		t.remove(e)
+		return nil
+	}
+	return r.values
+}
+
+type joinOperatorInstance struct {
+	joiners []*joiner
+}
+
+func (j *joinOperatorInstance) Name() string {
+	return name
+}
+
+func (j *joinOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for _, jn := range j.joiners {
+		err := jn.source.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			jn.collect(data, time.Now())
+			return nil
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", jn.source.Name(), err)
+		}
+		err = jn.target.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			if err := jn.enrich(data, time.Now()); err != nil {
+				gadgetCtx.Logger().Warnf("join: %q: %v", ds.Name(), err)
+			}
+			return nil
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", jn.target.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (j *joinOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (j *joinOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (j *joinOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &joinOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
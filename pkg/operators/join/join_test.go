@@ -0,0 +1,259 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package join
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+type execEvent struct {
+	pid  uint32
+	comm string
+	args string
+}
+
+type tcpEvent struct {
+	pid  uint32
+	addr string
+}
+
+type joinedEvent struct {
+	tcpEvent
+	execPid  uint32
+	execComm string
+	execArgs string
+}
+
+// run emits the given events to the data sources "trace_exec.exec" and
+// "trace_tcp.tcp" (registered like images run together would do it) and
+// returns the events received from "trace_tcp.tcp"; all exec events are
+// emitted before the tcp events
+func run(t *testing.T, execEvents []execEvent, tcpEvents []tcpEvent, paramValues api.ParamValues) ([]joinedEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var execDs, tcpDs datasource.DataSource
+	var execPidF, execCommF, execArgsF, tcpPidF, tcpAddrF datasource.FieldAccessor
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			execDs, err = gadgetCtx.ImageContext("trace_exec", "trace_exec").RegisterDataSource(datasource.TypeSingle, "exec")
+			require.NoError(t, err)
+			proc, err := execDs.AddField("proc", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+			require.NoError(t, err)
+			execPidF, err = proc.AddSubField("pid", api.Kind_Uint32)
+			require.NoError(t, err)
+			execCommF, err = proc.AddSubField("comm", api.Kind_String)
+			require.NoError(t, err)
+			execArgsF, err = execDs.AddField("args", api.Kind_String)
+			require.NoError(t, err)
+
+			tcpDs, err = gadgetCtx.ImageContext("trace_tcp", "trace_tcp").RegisterDataSource(datasource.TypeSingle, "tcp")
+			require.NoError(t, err)
+			proc, err = tcpDs.AddField("proc", api.Kind_Invalid, datasource.WithFlags(datasource.FieldFlagEmpty))
+			require.NoError(t, err)
+			tcpPidF, err = proc.AddSubField("pid", api.Kind_Uint32)
+			require.NoError(t, err)
+			tcpAddrF, err = tcpDs.AddField("addr", api.Kind_String)
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			go func() {
+				for _, ev := range execEvents {
+					data, err := execDs.NewPacketSingle()
+					require.NoError(t, err)
+					require.NoError(t, execPidF.PutUint32(data, ev.pid))
+					require.NoError(t, execCommF.PutString(data, ev.comm))
+					require.NoError(t, execArgsF.PutString(data, ev.args))
+					require.NoError(t, execDs.EmitAndRelease(data))
+				}
+				for _, ev := range tcpEvents {
+					data, err := tcpDs.NewPacketSingle()
+					require.NoError(t, err)
+					require.NoError(t, tcpPidF.PutUint32(data, ev.pid))
+					require.NoError(t, tcpAddrF.PutString(data, ev.addr))
+					require.NoError(t, tcpDs.EmitAndRelease(data))
+				}
+				cancel()
+			}()
+			return nil
+		}),
+	)
+
+	var res []joinedEvent
+	consumer := simple.New("consumer",
+		simple.WithPriority(Priority+1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			ds := gadgetCtx.GetDataSources()["trace_tcp.tcp"]
+			pidF := ds.GetField("proc.pid")
+			addrF := ds.GetField("addr")
+			execPidF := ds.GetField("exec.proc.pid")
+			execCommF := ds.GetField("exec.proc.comm")
+			execArgsF := ds.GetField("exec.args")
+			return ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				var ev joinedEvent
+				ev.pid, _ = pidF.Uint32(data)
+				ev.addr, _ = addrF.String(data)
+				if execPidF != nil {
+					ev.execPid, _ = execPidF.Uint32(data)
+				}
+				if execCommF != nil {
+					ev.execComm, _ = execCommF.String(data)
+				}
+				if execArgsF != nil {
+					ev.execArgs, _ = execArgsF.String(data)
+				}
+				res = append(res, ev)
+				return nil
+			}, Priority+1)
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "trace_exec",
+		gadgetcontext.WithAdditionalImages("trace_tcp"),
+		gadgetcontext.WithDataOperators(Operator, producer, consumer),
+	)
+	err := gadgetCtx.Run(paramValues)
+	return res, err
+}
+
+var (
+	testExecEvents = []execEvent{
+		{pid: 100, comm: "curl", args: "curl example.com"},
+		{pid: 200, comm: "wget", args: "wget example.com"},
+		{pid: 100, comm: "curl", args: "curl example.org"},
+	}
+	testTcpEvents = []tcpEvent{
+		{pid: 100, addr: "93.184.215.14"},
+		{pid: 200, addr: "93.184.215.14"},
+		{pid: 300, addr: "127.0.0.1"},
+	}
+)
+
+func TestJoin(t *testing.T) {
+	res, err := run(t, testExecEvents, testTcpEvents, api.ParamValues{
+		"operator.join.join": "trace_tcp.tcp:proc.pid=trace_exec.exec",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []joinedEvent{
+		{tcpEvent: testTcpEvents[0], execPid: 100, execComm: "curl", execArgs: "curl example.org"},
+		{tcpEvent: testTcpEvents[1], execPid: 200, execComm: "wget", execArgs: "wget example.com"},
+		{tcpEvent: testTcpEvents[2]},
+	}, res)
+}
+
+func TestJoinFields(t *testing.T) {
+	res, err := run(t, testExecEvents, testTcpEvents, api.ParamValues{
+		"operator.join.join":        "trace_tcp.tcp:proc.pid=trace_exec.exec:proc.pid",
+		"operator.join.join-fields": "trace_exec.exec:args",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []joinedEvent{
+		{tcpEvent: testTcpEvents[0], execArgs: "curl example.org"},
+		{tcpEvent: testTcpEvents[1], execArgs: "wget example.com"},
+		{tcpEvent: testTcpEvents[2]},
+	}, res)
+}
+
+func TestJoinErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		paramValues api.ParamValues
+	}{
+		{
+			name:        "unknown target",
+			paramValues: api.ParamValues{"operator.join.join": "tcp:proc.pid=trace_exec.exec"},
+		},
+		{
+			name:        "unknown source",
+			paramValues: api.ParamValues{"operator.join.join": "trace_tcp.tcp:proc.pid=exec"},
+		},
+		{
+			name:        "unknown key",
+			paramValues: api.ParamValues{"operator.join.join": "trace_tcp.tcp:addr=trace_exec.exec"},
+		},
+		{
+			name:        "mismatching key types",
+			paramValues: api.ParamValues{"operator.join.join": "trace_tcp.tcp:addr=trace_exec.exec:proc.pid"},
+		},
+		{
+			name: "unknown field",
+			paramValues: api.ParamValues{
+				"operator.join.join":        "trace_tcp.tcp:proc.pid=trace_exec.exec",
+				"operator.join.join-fields": "foo",
+			},
+		},
+		{
+			name: "invalid max entries",
+			paramValues: api.ParamValues{
+				"operator.join.join":             "trace_tcp.tcp:proc.pid=trace_exec.exec",
+				"operator.join.join-max-entries": "0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, nil, nil, tt.paramValues)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseJoins(t *testing.T) {
+	specs, err := parseJoins("a.b:pid=c.d; e:mntns_id=f:mntns")
+	require.NoError(t, err)
+	assert.Equal(t, []joinSpec{
+		{target: "a.b", targetKey: "pid", source: "c.d", sourceKey: "pid"},
+		{target: "e", targetKey: "mntns_id", source: "f", sourceKey: "mntns"},
+	}, specs)
+
+	for _, invalid := range []string{"a:pid", "a=b:pid", ":pid=b", "a:pid=", "a:pid=a"} {
+		_, err := parseJoins(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTable(t *testing.T) {
+	now := time.Now()
+	tbl := newTable(time.Minute, 2)
+
+	tbl.put("a", [][]byte{[]byte("a1")}, now)
+	tbl.put("b", [][]byte{[]byte("b1")}, now)
+	tbl.put("a", [][]byte{[]byte("a2")}, now.Add(time.Second))
+	assert.Equal(t, [][]byte{[]byte("a2")}, tbl.get("a", now))
+
+	// "b" is the oldest entry now
+	tbl.put("c", [][]byte{[]byte("c1")}, now.Add(2*time.Second))
+	assert.Nil(t, tbl.get("b", now))
+	assert.NotNil(t, tbl.get("c", now))
+
+	// Entries expire after the TTL
+	assert.Nil(t, tbl.get("a", now.Add(2*time.Minute)))
+	tbl.put("d", [][]byte{[]byte("d1")}, now.Add(3*time.Minute))
+	assert.Len(t, tbl.entries, 1)
+}
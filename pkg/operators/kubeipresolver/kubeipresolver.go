@@ -33,6 +33,8 @@ import (
 const (
 	OperatorName = "KubeIPResolver"
 	Priority     = 10
+
+	ParamResolveIngresses = "resolve-ingresses"
 )
 
 const (
@@ -55,6 +57,9 @@ func (k *KubeIPResolver) Description() string {
 }
 
 func (k *KubeIPResolver) Init(params *params.Params) error {
+	if params.Get(ParamResolveIngresses).AsBool() {
+		common.EnableK8sInventoryIngresses()
+	}
 	return nil
 }
 
@@ -116,7 +121,15 @@ func (m *KubeIPResolverInstance) EnrichEvent(ev any) error {
 }
 
 func (k *KubeIPResolver) GlobalParams() api.Params {
-	return nil
+	return api.Params{
+		{
+			Key:          ParamResolveIngresses,
+			Title:        "Resolve Ingresses",
+			Description:  "Resolve the load balancer IPs of Ingresses; requires permissions to list and watch Ingresses",
+			DefaultValue: "false",
+			TypeHint:     api.TypeBool,
+		},
+	}
 }
 
 func (k *KubeIPResolver) InstanceParams() api.Params {
@@ -250,6 +263,73 @@ func (m *KubeIPResolverInstance) PreStart(gadgetCtx operators.GadgetContext) err
 	return nil
 }
 
+// setK8s fills the k8s subfields of the endpoint and formats it as
+// <prefix>/<namespace>/<name>:<port> (or <prefix>/<name>:<port> for objects
+// without namespace)
+func (a *endpointAccessors) setK8s(data datasource.Data, kind, prefix, namespace, name string, labels map[string]string) {
+	a.subK8sKind.Set(data, []byte(kind))
+	a.subK8sName.Set(data, []byte(name))
+	a.subK8sNamespace.Set(data, []byte(namespace))
+	// TODO: labels should be a map/slice
+	var labelsStr []string
+	for key, val := range labels {
+		labelsStr = append(labelsStr, fmt.Sprintf("%s=%s", key, val))
+	}
+	a.subK8sLabels.Set(data, []byte(strings.Join(labelsStr, ",")))
+	if a.column != nil && a.port != nil {
+		p, _ := a.port.Uint16(data)
+		v := fmt.Sprintf("%s/%s/%s:%d", prefix, namespace, name, p)
+		if namespace == "" {
+			v = fmt.Sprintf("%s/%s:%d", prefix, name, p)
+		}
+		a.column.Set(data, []byte(v))
+	}
+}
+
+// resolve looks up the IP in the k8s inventory and fills the k8s subfields of
+// the endpoint; it returns false if the IP wasn't found
+func (m *KubeIPResolverInstance) resolve(a *endpointAccessors, data datasource.Data, addrStr string) bool {
+	// Pods using the host network share the IP of the node, they are
+	// reported as node
+	pod := m.k8sInventory.GetPodByIp(addrStr)
+	if pod != nil && !pod.Spec.HostNetwork {
+		a.setK8s(data, "pod", "p", pod.Namespace, pod.Name, pod.Labels)
+		return true
+	}
+
+	svc := m.k8sInventory.GetSvcByIp(addrStr)
+	if svc != nil {
+		a.setK8s(data, "svc", "s", svc.Namespace, svc.Name, svc.Labels)
+		return true
+	}
+
+	node := m.k8sInventory.GetNodeByIp(addrStr)
+	if node != nil {
+		a.setK8s(data, "node", "n", "", node.Name, node.Labels)
+		return true
+	}
+
+	// Backends of services not matched above, like the ones of services
+	// without selector (e.g. pointing to external IPs)
+	slice := m.k8sInventory.GetEndpointSliceByIp(addrStr)
+	if slice != nil && slice.ServiceName != "" {
+		labels := slice.Labels
+		if svc := m.k8sInventory.GetSvcByName(slice.Namespace, slice.ServiceName); svc != nil {
+			labels = svc.Labels
+		}
+		a.setK8s(data, "endpoint", "e", slice.Namespace, slice.ServiceName, labels)
+		return true
+	}
+
+	ingress := m.k8sInventory.GetIngressByIp(addrStr)
+	if ingress != nil {
+		a.setK8s(data, "ingress", "i", ingress.Namespace, ingress.Name, ingress.Labels)
+		return true
+	}
+
+	return false
+}
+
 func (m *KubeIPResolverInstance) Start(gadgetCtx operators.GadgetContext) error {
 	for ds, acc := range m.endpointsAccessors {
 		ds.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
@@ -259,49 +339,11 @@ func (m *KubeIPResolverInstance) Start(gadgetCtx operators.GadgetContext) error
 				version := a.root.GetSubFieldsWithTag("name:version")[0]
 				addrStr, err := common.GetIPForVersion(data, version, ip)
 				if err != nil {
-					errors.Join(errs, fmt.Errorf("%s: getting IP: %w", a.root.Name(), err))
-					continue
-				}
-
-				pod := m.k8sInventory.GetPodByIp(addrStr)
-				if pod != nil {
-					if pod.Spec.HostNetwork {
-						continue
-					}
-					a.subK8sName.Set(data, []byte(pod.Name))
-					a.subK8sKind.Set(data, []byte("pod"))
-					a.subK8sNamespace.Set(data, []byte(pod.Namespace))
-					// TODO: labels should be a map/slice
-					var labels []string
-					for key, val := range pod.Labels {
-						labels = append(labels, fmt.Sprintf("%s=%s", key, val))
-					}
-					a.subK8sLabels.Set(data, []byte(strings.Join(labels, ",")))
-					if a.column != nil && a.port != nil {
-						p, _ := a.port.Uint16(data)
-						v := fmt.Sprintf("p/%s/%s:%d", pod.Namespace, pod.Name, p)
-						a.column.Set(data, []byte(v))
-					}
+					errs = errors.Join(errs, fmt.Errorf("%s: getting IP: %w", a.root.Name(), err))
 					continue
 				}
 
-				svc := m.k8sInventory.GetSvcByIp(addrStr)
-				if svc != nil {
-					a.subK8sKind.Set(data, []byte("svc"))
-					a.subK8sName.Set(data, []byte(svc.Name))
-					a.subK8sNamespace.Set(data, []byte(svc.Namespace))
-					// TODO: labels should be a map/slice
-					var labels []string
-					for key, val := range svc.Labels {
-						labels = append(labels, fmt.Sprintf("%s=%s", key, val))
-					}
-					a.subK8sLabels.Set(data, []byte(strings.Join(labels, ",")))
-
-					if a.column != nil && a.port != nil {
-						p, _ := a.port.Uint16(data)
-						v := fmt.Sprintf("s/%s/%s:%d", svc.Namespace, svc.Name, p)
-						a.column.Set(data, []byte(v))
-					}
+				if m.resolve(&a, data, addrStr) {
 					continue
 				}
 
@@ -45,6 +45,7 @@ const (
 	ParamAllNamespaces = "all-namespaces"
 	ParamPodName       = "podname"
 	ParamNamespace     = "namespace"
+	ParamAnnotations   = "pod-annotations"
 )
 
 type MountNsMapSetter interface {
@@ -125,6 +126,10 @@ func (k *KubeManager) ParamDescs() params.ParamDescs {
 			Description: "Show only data from pods in a given namespace",
 			ValueHint:   gadgets.K8SNamespace,
 		},
+		{
+			Key:         ParamAnnotations,
+			Description: "Comma-separated list of pod annotations to add to the k8s.podAnnotations field",
+		},
 	}
 }
 
@@ -399,6 +404,11 @@ func (k *KubeManager) InstantiateDataOperator(gadgetCtx operators.GadgetContext,
 	if err != nil {
 		return nil, fmt.Errorf("getting event wrappers: %w", err)
 	}
+	for _, wrapper := range wrappers {
+		if err := wrapper.AddPodAnnotations(params.Get(ParamAnnotations).AsStringSlice()); err != nil {
+			return nil, fmt.Errorf("adding pod annotations field: %w", err)
+		}
+	}
 	traceInstance.eventWrappers = wrappers
 	if len(wrappers) > 0 {
 		activate = true
@@ -20,6 +20,7 @@ package limiter
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
@@ -59,6 +60,7 @@ func (l *limiterOperator) InstanceParams() api.Params {
 				"Use -1 to disable the limiter.",
 			DefaultValue: "-1",
 			TypeHint:     api.TypeString,
+			Tags:         []string{api.TagLiveUpdate},
 		},
 	}
 }
@@ -102,49 +104,114 @@ func (l *limiterOperator) Priority() int {
 
 type limiterOperatorInstance struct {
 	limitsPerDs map[string]int
+
+	// maxEntries holds the limit per data source; it is replaced as a whole when params are updated while running
+	maxEntries atomic.Pointer[map[datasource.DataSource]int]
+
+	// subscribed holds the data sources subscribed to; only data sources with a limit are subscribed to, further
+	// ones when params are updated
+	subscribed map[datasource.DataSource]struct{}
 }
 
 func (l *limiterOperatorInstance) Name() string {
 	return name
 }
 
-func (l *limiterOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
-	if val, ok := l.limitsPerDs[""]; ok && val == -1 {
+// getMaxEntries maps the configured limits to the array data sources; data sources without limit are omitted
+func getMaxEntries(gadgetCtx operators.GadgetContext, limitsPerDs map[string]int) (map[datasource.DataSource]int, error) {
+	res := make(map[datasource.DataSource]int)
+
+	if val, ok := limitsPerDs[""]; ok && val == -1 {
 		gadgetCtx.Logger().Debug("limiter: disabled for all data sources")
-		return nil
+		return res, nil
 	}
 
 	for _, ds := range gadgetCtx.GetDataSources() {
 		var maxEntries int
-		if val, ok := l.limitsPerDs[""]; ok {
+		if val, ok := limitsPerDs[""]; ok {
 			if ds.Type() != datasource.TypeArray {
 				continue
 			}
 			maxEntries = val
-		} else if val, ok := l.limitsPerDs[ds.Name()]; ok {
+		} else if val, ok := limitsPerDs[ds.Name()]; ok {
 			if ds.Type() != datasource.TypeArray {
-				return fmt.Errorf("%s can only be used on array data sources", ParamMaxEntries)
+				return nil, fmt.Errorf("%s can only be used on array data sources", ParamMaxEntries)
 			}
 			if val == -1 {
 				gadgetCtx.Logger().Debugf("limiter: disabled for data source %q", ds.Name())
 				continue
 			}
 			maxEntries = val
+		} else {
+			continue
 		}
 
 		if maxEntries < -1 {
-			return fmt.Errorf("invalid value of %s for data source %q: %d", ParamMaxEntries, ds.Name(), maxEntries)
+			return nil, fmt.Errorf("invalid value of %s for data source %q: %d", ParamMaxEntries, ds.Name(), maxEntries)
 		}
 
 		gadgetCtx.Logger().Debugf("limiter: data source %q max-entries %d", ds.Name(), maxEntries)
+		res[ds] = maxEntries
+	}
+	return res, nil
+}
 
-		ds.SubscribeArray(func(ds datasource.DataSource, data datasource.DataArray) error {
+func (l *limiterOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	maxEntries, err := getMaxEntries(gadgetCtx, l.limitsPerDs)
+	if err != nil {
+		return err
+	}
+	l.maxEntries.Store(&maxEntries)
+	return l.subscribe(maxEntries)
+}
+
+// subscribe subscribes to the data sources of maxEntries that haven't been subscribed to yet. Subscriptions are kept
+// if the limit of a data source is removed later on.
+func (l *limiterOperatorInstance) subscribe(maxEntries map[datasource.DataSource]int) error {
+	if l.subscribed == nil {
+		l.subscribed = make(map[datasource.DataSource]struct{})
+	}
+	for ds := range maxEntries {
+		if _, ok := l.subscribed[ds]; ok {
+			continue
+		}
+		err := ds.SubscribeArray(func(ds datasource.DataSource, data datasource.DataArray) error {
+			maxEntries, ok := (*l.maxEntries.Load())[ds]
+			if !ok {
+				return nil
+			}
 			return limiterFn(ds, data, maxEntries)
 		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+		l.subscribed[ds] = struct{}{}
 	}
 	return nil
 }
 
+// UpdateParams changes the limits of a running gadget; the previous limits are kept if the new ones are invalid
+func (l *limiterOperatorInstance) UpdateParams(gadgetCtx operators.GadgetContext, paramValues api.ParamValues) error {
+	val, ok := paramValues[ParamMaxEntries]
+	if !ok {
+		return nil
+	}
+	limitsPerDs, err := apihelpers.GetIntValuesPerDataSource(val)
+	if err != nil {
+		return fmt.Errorf("parsing %s (%q): %w", ParamMaxEntries, val, err)
+	}
+	if len(limitsPerDs) == 0 {
+		return fmt.Errorf("invalid value for %s: %s", ParamMaxEntries, val)
+	}
+	maxEntries, err := getMaxEntries(gadgetCtx, limitsPerDs)
+	if err != nil {
+		return err
+	}
+	l.limitsPerDs = limitsPerDs
+	l.maxEntries.Store(&maxEntries)
+	return l.subscribe(maxEntries)
+}
+
 func limiterFn(ds datasource.DataSource, data datasource.DataArray, maxEntries int) error {
 	if data.Len() <= maxEntries {
 		return nil
@@ -519,6 +519,12 @@ func (l *localManager) InstantiateDataOperator(gadgetCtx operators.GadgetContext
 		},
 	}
 
+	// Allow other operators (like the container IP resolver) to look up
+	// containers
+	if l.igManager != nil {
+		gadgetCtx.SetVar(common.ContainerCollectionVar, &l.igManager.ContainerCollection)
+	}
+
 	activate := false
 
 	// Check, whether the gadget requested a map from us
@@ -0,0 +1,117 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocihandler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+)
+
+// ebpfInstanceVar is the variable the eBPF operator uses to hand over its
+// instance to the operators managing containers
+const ebpfInstanceVar = "ebpfInstance"
+
+// imageOperatorInstance is an instance of an image operator together with the
+// context of the image it has been instantiated for
+type imageOperatorInstance struct {
+	operators.ImageOperatorInstance
+	gadgetCtx operators.GadgetContext
+}
+
+// imageNamespaces returns the namespaces of the given images, making sure they
+// are unique
+func imageNamespaces(images []string) ([]string, error) {
+	namespaces := make([]string, 0, len(images))
+	seen := make(map[string]string)
+	for _, image := range images {
+		namespace := common.ImageShortName(image)
+		if namespace == "" {
+			return nil, fmt.Errorf("invalid image name %q", image)
+		}
+		if other, ok := seen[namespace]; ok {
+			return nil, fmt.Errorf("images %q and %q can't be run together, as both would use %q to prefix their data sources",
+				other, image, namespace)
+		}
+		seen[namespace] = image
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
+
+// annotationForImage returns the annotation (in the format of the annotate
+// param) that applies to the image with the given namespace. Annotations
+// whose subject starts with the namespace of an image only apply to that
+// image, others apply to all images.
+func annotationForImage(ann string, namespace string, namespaces []string) (string, bool) {
+	if namespace == "" {
+		return ann, true
+	}
+	if trimmed, ok := strings.CutPrefix(ann, namespace+"."); ok {
+		return trimmed, true
+	}
+	for _, other := range namespaces {
+		if strings.HasPrefix(ann, other+".") {
+			return "", false
+		}
+	}
+	return ann, true
+}
+
+// namespaceParams prefixes the keys of params with the namespace of an image;
+// aliases are removed, as they would clash with the ones of other images
+func namespaceParams(params api.Params, namespace string) api.Params {
+	for _, p := range params {
+		p.Key = namespace + "." + p.Key
+		p.Alias = ""
+	}
+	return params
+}
+
+type containerAttacher interface {
+	AttachContainer(container *containercollection.Container) error
+	DetachContainer(container *containercollection.Container) error
+}
+
+// containerAttachers attaches containers to the eBPF instances of all images
+// when running several images
+type containerAttachers []containerAttacher
+
+func (c containerAttachers) AttachContainer(container *containercollection.Container) error {
+	for i, attacher := range c {
+		if err := attacher.AttachContainer(container); err != nil {
+			// The container won't be detached by the caller, so undo
+			// attaching it to the other instances
+			for _, attached := range c[:i] {
+				attached.DetachContainer(container)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c containerAttachers) DetachContainer(container *containercollection.Container) error {
+	var errs []error
+	for _, attacher := range c {
+		errs = append(errs, attacher.DetachContainer(container))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocihandler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+)
+
+func TestImageNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		images  []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "short names",
+			images: []string{"trace_exec", "trace_tcp"},
+			want:   []string{"trace_exec", "trace_tcp"},
+		},
+		{
+			name: "full references",
+			images: []string{
+				"ghcr.io/inspektor-gadget/gadget/trace_exec:latest",
+				"localhost:5000/trace_tcp@sha256:0123456789abcdef",
+				"localhost:5000/trace_open",
+			},
+			want: []string{"trace_exec", "trace_tcp", "trace_open"},
+		},
+		{
+			name:    "duplicated",
+			images:  []string{"trace_exec:v0.40.0", "ghcr.io/inspektor-gadget/gadget/trace_exec:latest"},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			images:  []string{"trace_exec", "ghcr.io/"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := imageNamespaces(tt.images)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAnnotationForImage(t *testing.T) {
+	namespaces := []string{"trace_exec", "trace_tcp"}
+	tests := []struct {
+		name      string
+		ann       string
+		namespace string
+		want      string
+		wantOk    bool
+	}{
+		{
+			name:   "single image",
+			ann:    "trace_exec.exec:foo=bar",
+			want:   "trace_exec.exec:foo=bar",
+			wantOk: true,
+		},
+		{
+			name:      "own image",
+			ann:       "trace_exec.exec.proc.comm:foo=bar",
+			namespace: "trace_exec",
+			want:      "exec.proc.comm:foo=bar",
+			wantOk:    true,
+		},
+		{
+			name:      "other image",
+			ann:       "trace_tcp.tcp:foo=bar",
+			namespace: "trace_exec",
+		},
+		{
+			name:      "all images",
+			ann:       "events:foo=bar",
+			namespace: "trace_tcp",
+			want:      "events:foo=bar",
+			wantOk:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ns []string
+			if tt.namespace != "" {
+				ns = namespaces
+			}
+			got, ok := annotationForImage(tt.ann, tt.namespace, ns)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type fakeAttacher struct {
+	fail     bool
+	attached int
+}
+
+func (f *fakeAttacher) AttachContainer(*containercollection.Container) error {
+	if f.fail {
+		return errors.New("failed")
+	}
+	f.attached++
+	return nil
+}
+
+func (f *fakeAttacher) DetachContainer(*containercollection.Container) error {
+	f.attached--
+	return nil
+}
+
+func TestContainerAttachers(t *testing.T) {
+	a, b := &fakeAttacher{}, &fakeAttacher{}
+	attachers := containerAttachers{a, b}
+	container := &containercollection.Container{}
+
+	require.NoError(t, attachers.AttachContainer(container))
+	require.Equal(t, 1, a.attached)
+	require.Equal(t, 1, b.attached)
+	require.NoError(t, attachers.DetachContainer(container))
+	require.Equal(t, 0, a.attached)
+	require.Equal(t, 0, b.attached)
+
+	b.fail = true
+	require.Error(t, attachers.AttachContainer(container))
+	require.Equal(t, 0, a.attached, "container must be detached again from a")
+}
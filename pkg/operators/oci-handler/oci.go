@@ -303,7 +303,8 @@ func constructTempConfig(ann string) (map[string]any, int, error) {
 }
 
 func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
-	if len(gadgetCtx.ImageName()) == 0 {
+	imageNames := gadgetCtx.ImageNames()
+	if slices.Contains(imageNames, "") {
 		return fmt.Errorf("imageName empty")
 	}
 
@@ -346,28 +347,70 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 
 	gadgetCtx.Logger().Debugf("image options: %+v", imgOpts)
 
-	target := gadgetCtx.OrasTarget()
+	if len(imageNames) == 1 {
+		return o.initImage(gadgetCtx, gadgetCtx, imgOpts, "", nil)
+	}
+
+	// When running several images, the data sources and params of each
+	// image are namespaced to avoid clashes between them
+	namespaces, err := imageNamespaces(imageNames)
+	if err != nil {
+		return err
+	}
+
+	var attachers containerAttachers
+	for i, imageName := range imageNames {
+		imgCtx := gadgetCtx.ImageContext(imageName, namespaces[i])
+		if err := o.initImage(gadgetCtx, imgCtx, imgOpts, namespaces[i], namespaces); err != nil {
+			return fmt.Errorf("initializing image %q: %w", imageName, err)
+		}
+
+		// The eBPF operator hands over its instance to attach containers to;
+		// make sure containers get attached to the instances of all images
+		if inst, ok := gadgetCtx.GetVar(ebpfInstanceVar); ok {
+			if attacher, ok := inst.(containerAttacher); ok && !slices.Contains(attachers, attacher) {
+				attachers = append(attachers, attacher)
+			}
+		}
+	}
+	if len(attachers) > 1 {
+		gadgetCtx.SetVar(ebpfInstanceVar, attachers)
+	}
+	return nil
+}
+
+// initImage instantiates the image operators of the image of imgCtx. If
+// running several images, namespace is the namespace of the image and
+// namespaces the ones of all images.
+func (o *OciHandlerInstance) initImage(
+	gadgetCtx operators.GadgetContext,
+	imgCtx operators.GadgetContext,
+	imgOpts *oci.ImageOptions,
+	namespace string,
+	namespaces []string,
+) error {
+	target := imgCtx.OrasTarget()
 	// If the target wasn't explicitly set, use the local store. In this case we
 	// need to be sure the image is available.
 	if target == nil {
 		// Make sure the image is available, either through pulling or by just accessing a local copy
 		// TODO: add security constraints (e.g. don't allow pulling - add GlobalParams for that)
-		err := oci.EnsureImage(gadgetCtx.Context(), gadgetCtx.ImageName(),
+		err := oci.EnsureImage(imgCtx.Context(), imgCtx.ImageName(),
 			imgOpts, o.instanceParams.Get(pullParam).AsString())
 		if err != nil {
 			return fmt.Errorf("ensuring image: %w", err)
 		}
 	}
 
-	manifest, err := oci.GetManifestForHost(gadgetCtx.Context(), target, gadgetCtx.ImageName())
+	manifest, err := oci.GetManifestForHost(imgCtx.Context(), target, imgCtx.ImageName())
 	if err != nil {
 		return fmt.Errorf("getting manifest: %w", err)
 	}
 
-	log := gadgetCtx.Logger()
+	log := imgCtx.Logger()
 	checkBuilderVersion(manifest, log, version.Version())
 
-	r, err := oci.GetContentFromDescriptor(gadgetCtx.Context(), target, manifest.Config)
+	r, err := oci.GetContentFromDescriptor(imgCtx.Context(), target, manifest.Config)
 	if err != nil {
 		return fmt.Errorf("getting metadata: %w", err)
 	}
@@ -378,10 +421,14 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 	}
 	r.Close()
 
-	// Store metadata for serialization
-	gadgetCtx.SetMetadata(metadata)
+	// Store metadata for serialization; if running several images, the
+	// metadata of the first one is used for the whole context
+	imgCtx.SetMetadata(metadata)
+	if imgCtx != gadgetCtx && namespace == namespaces[0] {
+		gadgetCtx.SetMetadata(metadata)
+	}
 
-	cfg, ok := gadgetCtx.GetVar("config")
+	cfg, ok := imgCtx.GetVar("config")
 	if !ok {
 		return fmt.Errorf("missing configuration")
 	}
@@ -394,6 +441,10 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 		if len(ann) == 0 {
 			continue
 		}
+		ann, ok := annotationForImage(ann, namespace, namespaces)
+		if !ok {
+			continue
+		}
 		tmpConfig, lenSubject, err := constructTempConfig(ann)
 		if err != nil {
 			return err
@@ -412,8 +463,9 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 		}
 	}
 
-	gadgetCtx.SetVar("config", viper)
+	imgCtx.SetVar("config", viper)
 
+	var imageOperatorInstances []imageOperatorInstance
 	for _, layer := range manifest.Layers {
 		log.Debugf("layer > %+v", layer)
 		op, ok := operators.GetImageOperatorForMediaType(layer.MediaType)
@@ -422,7 +474,11 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 		}
 
 		log.Debugf("found image op %q", op.Name())
-		opInst, err := op.InstantiateImageOperator(gadgetCtx, target, layer, o.paramValues.ExtractPrefixedValues(op.Name()))
+		paramValues := o.paramValues.ExtractPrefixedValues(op.Name())
+		if namespace != "" {
+			paramValues = paramValues.ExtractPrefixedValues(namespace)
+		}
+		opInst, err := op.InstantiateImageOperator(imgCtx, target, layer, paramValues)
 		if err != nil {
 			return fmt.Errorf("instantiating operator %q: %w", op.Name(), err)
 		}
@@ -430,37 +486,41 @@ func (o *OciHandlerInstance) init(gadgetCtx operators.GadgetContext) error {
 			log.Debugf("> skipped %s", op.Name())
 			continue
 		}
-		o.imageOperatorInstances = append(o.imageOperatorInstances, opInst)
+		imageOperatorInstances = append(imageOperatorInstances, imageOperatorInstance{
+			ImageOperatorInstance: opInst,
+			gadgetCtx:             imgCtx,
+		})
 	}
 
-	if len(o.imageOperatorInstances) == 0 {
+	if len(imageOperatorInstances) == 0 {
 		return nil
 	}
+	o.imageOperatorInstances = append(o.imageOperatorInstances, imageOperatorInstances...)
 
 	// add extra info if requested
-	if gadgetCtx.ExtraInfo() {
-		err := addExtraInfo(gadgetCtx, metadata, manifest)
+	if imgCtx.ExtraInfo() {
+		err := addExtraInfo(imgCtx, metadata, manifest)
 		if err != nil {
 			return fmt.Errorf("adding extra info: %w", err)
 		}
 	}
 
-	extraParams := make([]*api.Param, 0)
-	for _, opInst := range o.imageOperatorInstances {
-		if extra, ok := opInst.(operators.ExtraParams); ok {
-			params := extra.ExtraParams(gadgetCtx)
-			extraParams = append(extraParams, params.AddPrefix(opInst.Name())...)
+	for _, opInst := range imageOperatorInstances {
+		if extra, ok := opInst.ImageOperatorInstance.(operators.ExtraParams); ok {
+			params := extra.ExtraParams(imgCtx)
+			if namespace != "" {
+				params = namespaceParams(params, namespace)
+			}
+			o.extraParams = append(o.extraParams, params.AddPrefix(opInst.Name())...)
 		}
 	}
-
-	o.extraParams = extraParams
 	return nil
 }
 
 func (o *OciHandlerInstance) PreStart(gadgetCtx operators.GadgetContext) error {
 	for _, opInst := range o.imageOperatorInstances {
-		if preStart, ok := opInst.(operators.PreStart); ok {
-			err := preStart.PreStart(gadgetCtx)
+		if preStart, ok := opInst.ImageOperatorInstance.(operators.PreStart); ok {
+			err := preStart.PreStart(opInst.gadgetCtx)
 			if err != nil {
 				return fmt.Errorf("pre-starting operator %q: %w", opInst.Name(), err)
 			}
@@ -470,17 +530,17 @@ func (o *OciHandlerInstance) PreStart(gadgetCtx operators.GadgetContext) error {
 }
 
 func (o *OciHandlerInstance) Start(gadgetCtx operators.GadgetContext) error {
-	started := []operators.ImageOperatorInstance{}
+	started := []imageOperatorInstance{}
 
 	for _, opInst := range o.imageOperatorInstances {
-		err := opInst.Start(o.gadgetCtx)
+		err := opInst.Start(opInst.gadgetCtx)
 		if err != nil {
 			// Stop all operators that were started to be sure they're able to
 			// release resources there
 			for _, startedOp := range started {
-				startedOp.Stop(o.gadgetCtx)
-				if postStop, ok := startedOp.(operators.PostStop); ok {
-					postStop.PostStop(o.gadgetCtx)
+				startedOp.Stop(startedOp.gadgetCtx)
+				if postStop, ok := startedOp.ImageOperatorInstance.(operators.PostStop); ok {
+					postStop.PostStop(startedOp.gadgetCtx)
 				}
 			}
 			return fmt.Errorf("starting operator %q: %w", opInst.Name(), err)
@@ -493,11 +553,11 @@ func (o *OciHandlerInstance) Start(gadgetCtx operators.GadgetContext) error {
 
 func (o *OciHandlerInstance) PreStop(gadgetCtx operators.GadgetContext) error {
 	for _, opInst := range o.imageOperatorInstances {
-		preStop, ok := opInst.(operators.PreStop)
+		preStop, ok := opInst.ImageOperatorInstance.(operators.PreStop)
 		if !ok {
 			continue
 		}
-		err := preStop.PreStop(gadgetCtx)
+		err := preStop.PreStop(opInst.gadgetCtx)
 		if err != nil {
 			o.gadgetCtx.Logger().Errorf("pre-stopping operator %q: %v", opInst.Name(), err)
 		}
@@ -509,7 +569,7 @@ func (o *OciHandlerInstance) Stop(gadgetCtx operators.GadgetContext) error {
 	var errs []error
 
 	for _, opInst := range o.imageOperatorInstances {
-		err := opInst.Stop(o.gadgetCtx)
+		err := opInst.Stop(opInst.gadgetCtx)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("stopping operator %q: %w", opInst.Name(), err))
 		}
@@ -518,12 +578,42 @@ func (o *OciHandlerInstance) Stop(gadgetCtx operators.GadgetContext) error {
 	return errors.Join(errs...)
 }
 
+func (o *OciHandlerInstance) Pause(gadgetCtx operators.GadgetContext) error {
+	var errs []error
+
+	for _, opInst := range o.imageOperatorInstances {
+		if pauser, ok := opInst.ImageOperatorInstance.(operators.Pauser); ok {
+			err := pauser.Pause(opInst.gadgetCtx)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("pausing operator %q: %w", opInst.Name(), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o *OciHandlerInstance) Resume(gadgetCtx operators.GadgetContext) error {
+	var errs []error
+
+	for _, opInst := range o.imageOperatorInstances {
+		if pauser, ok := opInst.ImageOperatorInstance.(operators.Pauser); ok {
+			err := pauser.Resume(opInst.gadgetCtx)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("resuming operator %q: %w", opInst.Name(), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func (o *OciHandlerInstance) PostStop(gadgetCtx operators.GadgetContext) error {
 	var errs []error
 
 	for _, opInst := range o.imageOperatorInstances {
-		if preStart, ok := opInst.(operators.PostStop); ok {
-			err := preStart.PostStop(gadgetCtx)
+		if preStart, ok := opInst.ImageOperatorInstance.(operators.PostStop); ok {
+			err := preStart.PostStop(opInst.gadgetCtx)
 			if err != nil {
 				errs = append(errs, fmt.Errorf("post-stopping operator %q: %w", opInst.Name(), err))
 			}
@@ -537,7 +627,7 @@ func (o *OciHandlerInstance) Close(gadgetCtx operators.GadgetContext) error {
 	var errs []error
 
 	for _, opInst := range o.imageOperatorInstances {
-		errs = append(errs, opInst.Close(o.gadgetCtx))
+		errs = append(errs, opInst.Close(opInst.gadgetCtx))
 	}
 
 	return errors.Join(errs...)
@@ -546,7 +636,7 @@ func (o *OciHandlerInstance) Close(gadgetCtx operators.GadgetContext) error {
 type OciHandlerInstance struct {
 	ociHandler             *ociHandler
 	gadgetCtx              operators.GadgetContext
-	imageOperatorInstances []operators.ImageOperatorInstance
+	imageOperatorInstances []imageOperatorInstance
 	extraParams            api.Params
 	paramValues            api.ParamValues
 	globalParams           *params.Params
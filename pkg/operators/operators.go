@@ -37,6 +37,13 @@ type GadgetContext interface {
 	Cancel()
 	SerializeGadgetInfo(requestExtraInfo bool) (*api.GadgetInfo, error)
 	ImageName() string
+	// ImageNames returns the names of all images run in this context; the
+	// first one is the one returned by ImageName
+	ImageNames() []string
+	// ImageContext returns a view of the context for one of several images
+	// run together; data sources registered through it are prefixed with
+	// namespace
+	ImageContext(imageName string, namespace string) GadgetContext
 	RegisterDataSource(datasource.Type, string) (datasource.DataSource, error)
 	GetDataSources() map[string]datasource.DataSource
 	SetVar(string, any)
@@ -117,6 +124,21 @@ type PostStop interface {
 	PostStop(gadgetCtx GadgetContext) error
 }
 
+// Pauser is implemented by operators that can temporarily stop producing data
+// without releasing their resources, e.g. by detaching eBPF programs while
+// keeping them loaded
+type Pauser interface {
+	Pause(gadgetCtx GadgetContext) error
+	Resume(gadgetCtx GadgetContext) error
+}
+
+// ParamUpdater is implemented by operators that can apply new values for their
+// instance params tagged with api.TagLiveUpdate while running; paramValues only
+// contains the changed params (without prefix)
+type ParamUpdater interface {
+	UpdateParams(gadgetCtx GadgetContext, paramValues api.ParamValues) error
+}
+
 // ContainerInfoFromMountNSID is a typical kubernetes operator interface that adds node, pod, namespace and container
 // information given the MountNSID
 type ContainerInfoFromMountNSID interface {
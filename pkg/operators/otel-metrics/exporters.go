@@ -0,0 +1,142 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelmetrics
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	ExporterOTLPGRPC              = "otlp-grpc"
+	ExporterOTLPHTTP              = "otlp-http"
+	ExporterPrometheusRemoteWrite = "prometheus-remote-write"
+)
+
+var supportedExporters = []string{ExporterOTLPGRPC, ExporterOTLPHTTP, ExporterPrometheusRemoteWrite}
+
+type tlsConfig struct {
+	CAFile             string `json:"caFile" yaml:"caFile"`
+	CertFile           string `json:"certFile" yaml:"certFile"`
+	KeyFile            string `json:"keyFile" yaml:"keyFile"`
+	ServerName         string `json:"serverName" yaml:"serverName"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+}
+
+// toTLSConfig returns the TLS configuration to use when connecting to the
+// endpoint or nil if the defaults of the exporter should be used
+func (c *tlsConfig) toTLSConfig() (*tls.Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		caCert, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func newExporter(v *metricsConfig) (sdkmetric.Exporter, error) {
+	if v.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint required for %s exporter", v.Exporter)
+	}
+
+	tlsCfg, err := v.TLS.toTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	delta := false
+	switch v.Temporality {
+	default:
+		return nil, fmt.Errorf("unsupported temporality %q", v.Temporality)
+	case "", "cumulative":
+	case "delta":
+		delta = true
+	}
+
+	switch v.Exporter {
+	case ExporterOTLPGRPC:
+		var options []otlpmetricgrpc.Option
+		options = append(options, otlpmetricgrpc.WithEndpoint(v.Endpoint))
+		if v.Insecure {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		} else if tlsCfg != nil {
+			options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		}
+		if len(v.Headers) > 0 {
+			options = append(options, otlpmetricgrpc.WithHeaders(v.Headers))
+		}
+		if delta {
+			options = append(options, otlpmetricgrpc.WithTemporalitySelector(deltaSelector))
+		}
+		return otlpmetricgrpc.New(context.Background(), options...)
+	case ExporterOTLPHTTP:
+		var options []otlpmetrichttp.Option
+		options = append(options, otlpmetrichttp.WithEndpoint(v.Endpoint))
+		if v.URLPath != "" {
+			options = append(options, otlpmetrichttp.WithURLPath(v.URLPath))
+		}
+		if v.Insecure {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		} else if tlsCfg != nil {
+			options = append(options, otlpmetrichttp.WithTLSClientConfig(tlsCfg))
+		}
+		if len(v.Headers) > 0 {
+			options = append(options, otlpmetrichttp.WithHeaders(v.Headers))
+		}
+		if delta {
+			options = append(options, otlpmetrichttp.WithTemporalitySelector(deltaSelector))
+		}
+		return otlpmetrichttp.New(context.Background(), options...)
+	case ExporterPrometheusRemoteWrite:
+		if delta {
+			return nil, fmt.Errorf("%s exporter only supports cumulative temporality", v.Exporter)
+		}
+		return newRemoteWriteExporter(v.Endpoint, v.Headers, tlsCfg)
+	}
+	return nil, fmt.Errorf("unsupported metric exporter %q; expected one of %s", v.Exporter,
+		strings.Join(supportedExporters, ", "))
+}
@@ -0,0 +1,324 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelmetrics
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+// receiver is a stand-in for a metrics backend that stores the bodies of all
+// requests it receives
+type receiver struct {
+	mu       sync.Mutex
+	headers  []http.Header
+	requests [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.headers = append(r.headers, req.Header.Clone())
+	r.requests = append(r.requests, body)
+	r.mu.Unlock()
+}
+
+func newReceiver(t *testing.T) (*receiver, *httptest.Server) {
+	r := &receiver{}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func newOperatorWithExporter(t *testing.T, exporter map[string]any) *otelMetricsOperator {
+	oldConfig := config.Config
+	t.Cleanup(func() {
+		config.Config = oldConfig
+	})
+	config.Config = viper.New()
+	config.Config.Set("operator.otel-metrics.exporters", map[string]any{
+		"test": exporter,
+	})
+
+	o := &otelMetricsOperator{skipListen: true}
+	require.NoError(t, o.Init(apihelpers.ToParamDescs(o.GlobalParams()).ToParams()))
+	require.Contains(t, o.providers, "test")
+	return o
+}
+
+// runMetricsGadget emits a counter and a histogram for two different comms
+// and exports them using the "test" exporter
+func runMetricsGadget(t *testing.T, o *otelMetricsOperator) {
+	var ds datasource.DataSource
+	var comm, ctr, latency datasource.FieldAccessor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+			require.NoError(t, err)
+			ds.AddAnnotation(AnnotationMetricsCollect, "true")
+			comm, err = ds.AddField("comm", api.Kind_String, datasource.WithAnnotations(map[string]string{
+				AnnotationMetricsType: MetricTypeKey,
+			}))
+			require.NoError(t, err)
+			ctr, err = ds.AddField("calls", api.Kind_Uint32, datasource.WithAnnotations(map[string]string{
+				AnnotationMetricsType: MetricTypeCounter,
+			}))
+			require.NoError(t, err)
+			latency, err = ds.AddField("latency", api.Kind_Uint32, datasource.WithAnnotations(map[string]string{
+				AnnotationMetricsType:       MetricTypeHistogram,
+				AnnotationMetricsBoundaries: "10,100",
+			}))
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for i, c := range []string{"cat", "cat", "ls"} {
+				data, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, comm.PutString(data, c))
+				require.NoError(t, ctr.PutUint32(data, 1))
+				require.NoError(t, latency.PutUint32(data, uint32(5*(i+1))))
+				require.NoError(t, ds.EmitAndRelease(data))
+			}
+			cancel()
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(o, producer))
+	err := gadgetCtx.Run(api.ParamValues{
+		"operator.otel-metrics.otel-metrics-name":     "events:myevents",
+		"operator.otel-metrics.otel-metrics-exporter": "test",
+	})
+	require.NoError(t, err)
+}
+
+// decodeWriteRequest decodes a prometheus.WriteRequest into a map of series
+// (formatted like "name{label="value",...}") and their values
+func decodeWriteRequest(t *testing.T, b []byte) map[string]float64 {
+	fields := func(b []byte, cb func(num protowire.Number, typ protowire.Type, v []byte, u uint64)) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			require.GreaterOrEqual(t, n, 0)
+			b = b[n:]
+			switch typ {
+			case protowire.BytesType:
+				v, n := protowire.ConsumeBytes(b)
+				require.GreaterOrEqual(t, n, 0)
+				cb(num, typ, v, 0)
+				b = b[n:]
+			case protowire.Fixed64Type:
+				v, n := protowire.ConsumeFixed64(b)
+				require.GreaterOrEqual(t, n, 0)
+				cb(num, typ, nil, v)
+				b = b[n:]
+			case protowire.VarintType:
+				v, n := protowire.ConsumeVarint(b)
+				require.GreaterOrEqual(t, n, 0)
+				cb(num, typ, nil, v)
+				b = b[n:]
+			default:
+				t.Fatalf("unexpected wire type %d", typ)
+			}
+		}
+	}
+
+	res := make(map[string]float64)
+	fields(b, func(_ protowire.Number, _ protowire.Type, ts []byte, _ uint64) {
+		var name string
+		var labels []string
+		var value float64
+		fields(ts, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+			switch num {
+			case 1:
+				var lname, lvalue string
+				fields(v, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+					if num == 1 {
+						lname = string(v)
+					} else {
+						lvalue = string(v)
+					}
+				})
+				if lname == "__name__" {
+					name = lvalue
+					return
+				}
+				labels = append(labels, lname+"=\""+lvalue+"\"")
+			case 2:
+				fields(v, func(num protowire.Number, _ protowire.Type, _ []byte, u uint64) {
+					if num == 1 {
+						value = math.Float64frombits(u)
+					}
+				})
+			}
+		})
+		res[name+"{"+strings.Join(labels, ",")+"}"] = value
+	})
+	return res
+}
+
+func TestPrometheusRemoteWriteExporter(t *testing.T) {
+	r, srv := newReceiver(t)
+	o := newOperatorWithExporter(t, map[string]any{
+		"exporter": ExporterPrometheusRemoteWrite,
+		"endpoint": srv.URL + "/api/v1/write",
+		"interval": "1h",
+		"headers": map[string]any{
+			"Authorization": "Bearer secret",
+		},
+	})
+
+	runMetricsGadget(t, o)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.NotEmpty(t, r.requests)
+
+	h := r.headers[len(r.headers)-1]
+	assert.Equal(t, "snappy", h.Get("Content-Encoding"))
+	assert.Equal(t, "application/x-protobuf", h.Get("Content-Type"))
+	assert.Equal(t, "Bearer secret", h.Get("Authorization"))
+
+	body, err := snappy.Decode(nil, r.requests[len(r.requests)-1])
+	require.NoError(t, err)
+	series := decodeWriteRequest(t, body)
+
+	expected := map[string]float64{
+		`calls_total{comm="cat",otel_scope_name="myevents"}`:            2,
+		`calls_total{comm="ls",otel_scope_name="myevents"}`:             1,
+		`latency_bucket{comm="cat",le="10",otel_scope_name="myevents"}`: 2,
+		`latency_bucket{comm="ls",le="10",otel_scope_name="myevents"}`:  0,
+		`latency_bucket{comm="ls",le="100",otel_scope_name="myevents"}`: 1,
+		`latency_count{comm="cat",otel_scope_name="myevents"}`:          2,
+		`latency_sum{comm="cat",otel_scope_name="myevents"}`:            15,
+	}
+	for k, v := range expected {
+		assert.Contains(t, series, k)
+		assert.Equal(t, v, series[k], k)
+	}
+}
+
+func TestOTLPHTTPExporter(t *testing.T) {
+	r, srv := newReceiver(t)
+	o := newOperatorWithExporter(t, map[string]any{
+		"exporter": ExporterOTLPHTTP,
+		"endpoint": strings.TrimPrefix(srv.URL, "http://"),
+		"insecure": true,
+		"interval": "1h",
+		"headers": map[string]any{
+			"X-Scope-OrgID": "tenant",
+		},
+	})
+
+	runMetricsGadget(t, o)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.NotEmpty(t, r.requests)
+	assert.Equal(t, "tenant", r.headers[len(r.headers)-1].Get("X-Scope-OrgID"))
+
+	req := &collectormetrics.ExportMetricsServiceRequest{}
+	require.NoError(t, proto.Unmarshal(r.requests[len(r.requests)-1], req))
+
+	names := map[string]bool{}
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			if sm.Scope.Name != "myevents" {
+				continue
+			}
+			for _, m := range sm.Metrics {
+				names[m.Name] = true
+			}
+		}
+	}
+	assert.Equal(t, map[string]bool{"calls": true, "latency": true}, names)
+}
+
+func TestExporterConfigErrors(t *testing.T) {
+	testCases := map[string]*metricsConfig{
+		"missing endpoint": {
+			Exporter: ExporterOTLPHTTP,
+		},
+		"invalid temporality": {
+			Exporter:    ExporterOTLPGRPC,
+			Endpoint:    "localhost:4317",
+			Temporality: "foo",
+		},
+		"remote-write with delta": {
+			Exporter:    ExporterPrometheusRemoteWrite,
+			Endpoint:    "http://localhost:9090/api/v1/write",
+			Temporality: "delta",
+		},
+		"remote-write without URL": {
+			Exporter: ExporterPrometheusRemoteWrite,
+			Endpoint: "localhost:9090",
+		},
+		"missing CA file": {
+			Exporter: ExporterOTLPHTTP,
+			Endpoint: "localhost:4318",
+			TLS:      &tlsConfig{CAFile: "/nonexistent"},
+		},
+		"unsupported exporter": {
+			Exporter: "foo",
+			Endpoint: "localhost:4317",
+		},
+	}
+	for name, cfg := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newExporter(cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, "k8s_podName", sanitizeName("k8s.podName", false))
+	assert.Equal(t, "_1st", sanitizeName("1st", false))
+	assert.Equal(t, "ns:metric_name", sanitizeName("ns:metric-name", true))
+	assert.Equal(t, "ns_metric", sanitizeName("ns:metric", false))
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,7 +31,6 @@ import (
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
@@ -88,13 +88,16 @@ var renderedDsCliAnnotations = map[string]string{
 }
 
 type metricsConfig struct {
-	Exporter         string        `json:"exporter" yaml:"exporter"`
-	Endpoint         string        `json:"endpoint" yaml:"endpoint"`
-	Insecure         bool          `json:"insecure" yaml:"insecure"`
-	Temporality      string        `json:"temporality" yaml:"temporality"`
-	Interval         time.Duration `json:"interval" yaml:"interval"`
-	CollectGoMetrics bool          `json:"collectGoMetrics" yaml:"collectGoMetrics"`
-	CollectIGMetrics bool          `json:"collectIGMetrics" yaml:"collectIGMetrics"`
+	Exporter         string            `json:"exporter" yaml:"exporter"`
+	Endpoint         string            `json:"endpoint" yaml:"endpoint"`
+	URLPath          string            `json:"urlPath" yaml:"urlPath"`
+	Insecure         bool              `json:"insecure" yaml:"insecure"`
+	Headers          map[string]string `json:"headers" yaml:"headers"`
+	TLS              *tlsConfig        `json:"tls" yaml:"tls"`
+	Temporality      string            `json:"temporality" yaml:"temporality"`
+	Interval         time.Duration     `json:"interval" yaml:"interval"`
+	CollectGoMetrics bool              `json:"collectGoMetrics" yaml:"collectGoMetrics"`
+	CollectIGMetrics bool              `json:"collectIGMetrics" yaml:"collectIGMetrics"`
 }
 
 func deltaSelector(kind sdkmetric.InstrumentKind) metricdata.Temporality {
@@ -117,7 +120,7 @@ type otelMetricsOperator struct {
 	exporter      *otelprometheus.Exporter
 	meterProvider metric.MeterProvider
 
-	providers map[string]metric.MeterProvider
+	providers map[string]*sdkmetric.MeterProvider
 
 	// if skipListen is set to true, it will not expose the metrics using http
 	// this is used mainly for unit tests (you can still use the meterProvider & exporter)
@@ -130,7 +133,7 @@ func (m *otelMetricsOperator) Name() string {
 
 func (m *otelMetricsOperator) Init(globalParams *params.Params) error {
 	// Initialize provider map
-	m.providers = map[string]metric.MeterProvider{}
+	m.providers = map[string]*sdkmetric.MeterProvider{}
 
 	// Initialize named metric providers
 	mc := make(map[string]*metricsConfig, 0)
@@ -141,64 +144,47 @@ func (m *otelMetricsOperator) Init(globalParams *params.Params) error {
 			log.Warnf("failed to load operator.otel-metrics.exporters: %v", err)
 		}
 		for k, v := range mc {
-			switch v.Exporter {
-			default:
+			if !slices.Contains(supportedExporters, v.Exporter) {
 				log.Errorf("invalid metric exporter %q", v.Exporter)
-			case "otlp-grpc":
-				if v.Endpoint == "" {
-					return fmt.Errorf("endpoint required for otlp-grpc exporter")
-				}
-				var options []otlpmetricgrpc.Option
-				options = append(options, otlpmetricgrpc.WithEndpoint(v.Endpoint))
-				if v.Insecure {
-					options = append(options, otlpmetricgrpc.WithInsecure())
-				}
-				switch v.Temporality {
-				case "", "cumulative":
-				case "delta":
-					options = append(options, otlpmetricgrpc.WithTemporalitySelector(deltaSelector))
-				}
-				otlpcollector, err := otlpmetricgrpc.New(
-					context.Background(),
-					options...,
-				)
+				continue
+			}
+			exporter, err := newExporter(v)
+			if err != nil {
+				return fmt.Errorf("initializing metric exporter %q: %w", k, err)
+			}
+			var periodicReaderOptions []sdkmetric.PeriodicReaderOption
+			if v.Interval > 0 {
+				periodicReaderOptions = append(periodicReaderOptions, sdkmetric.WithInterval(v.Interval))
+			}
+			m.providers[k] = sdkmetric.NewMeterProvider(
+				sdkmetric.WithReader(
+					sdkmetric.NewPeriodicReader(exporter, periodicReaderOptions...),
+				),
+			)
+
+			if v.CollectIGMetrics {
+				// Register with internal metrics
+				log.Debugf("registering internal metrics for provider %q", k)
+				err := metrics.RegisterProvider(m.providers[k])
 				if err != nil {
-					return fmt.Errorf("initializting otlp metrics collector")
-				}
-				var periodicReaderOptions []sdkmetric.PeriodicReaderOption
-				if v.Interval > 0 {
-					periodicReaderOptions = append(periodicReaderOptions, sdkmetric.WithInterval(v.Interval))
-				}
-				m.providers[k] = sdkmetric.NewMeterProvider(
-					sdkmetric.WithReader(
-						sdkmetric.NewPeriodicReader(otlpcollector, periodicReaderOptions...),
-					),
-				)
-
-				if v.CollectIGMetrics {
-					// Register with internal metrics
-					log.Debugf("registering internal metrics for provider %q", k)
-					err := metrics.RegisterProvider(m.providers[k])
-					if err != nil {
-						return fmt.Errorf("registering internal metrics for provider %q: %w", k, err)
-					}
+					return fmt.Errorf("registering internal metrics for provider %q: %w", k, err)
 				}
+			}
 
-				if v.CollectGoMetrics {
-					// Don't use deprecated runtime metrics
-					os.Setenv("OTEL_GO_X_DEPRECATED_RUNTIME_METRICS", "false")
-					// Also register go internal metrics
-					log.Debugf("registering go metrics for provider %q", k)
-					if err := runtime.Start(
-						runtime.WithMeterProvider(m.providers[k]),
-						runtime.WithMinimumReadMemStatsInterval(v.Interval),
-					); err != nil {
-						return fmt.Errorf("starting runtime instrumentation (internal Go metrics) for provider %q: %w", k, err)
-					}
+			if v.CollectGoMetrics {
+				// Don't use deprecated runtime metrics
+				os.Setenv("OTEL_GO_X_DEPRECATED_RUNTIME_METRICS", "false")
+				// Also register go internal metrics
+				log.Debugf("registering go metrics for provider %q", k)
+				if err := runtime.Start(
+					runtime.WithMeterProvider(m.providers[k]),
+					runtime.WithMinimumReadMemStatsInterval(v.Interval),
+				); err != nil {
+					return fmt.Errorf("starting runtime instrumentation (internal Go metrics) for provider %q: %w", k, err)
 				}
-
-				log.Debugf("initialized metric provider %q", k)
 			}
+
+			log.Debugf("initialized metric provider %q (%s)", k, v.Exporter)
 		}
 	}
 
@@ -343,7 +329,7 @@ type otelMetricsOperatorInstance struct {
 	outputDS      datasource.DataSource
 	outputField   datasource.FieldAccessor
 	printInterval time.Duration
-	provider      metric.MeterProvider
+	provider      *sdkmetric.MeterProvider
 	done          chan struct{}
 	wg            sync.WaitGroup
 }
@@ -869,6 +855,14 @@ func (m *otelMetricsOperatorInstance) Close(gadgetCtx operators.GadgetContext) e
 		}
 	}
 	m.wg.Wait()
+
+	// Push pending metrics of named providers, as the process might exit right
+	// after the gadget is done
+	if m.provider != nil {
+		if err := m.provider.ForceFlush(ctx); err != nil {
+			gadgetCtx.Logger().Warnf("flushing metrics: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,265 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelmetrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	remoteWriteTimeout = 30 * time.Second
+
+	// scopeNameLabel holds the name of the meter (the exported name of the data
+	// source); it's the same label the Prometheus exporter uses
+	scopeNameLabel = "otel_scope_name"
+)
+
+// remoteWriteExporter pushes metrics to an endpoint implementing the
+// Prometheus remote-write protocol (version 1.0).
+type remoteWriteExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+func newRemoteWriteExporter(endpoint string, headers map[string]string, tlsCfg *tls.Config) (*remoteWriteExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing remote-write endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("remote-write endpoint must be an http or https URL, got %q", endpoint)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsCfg != nil {
+		transport.TLSClientConfig = tlsCfg
+	}
+	return &remoteWriteExporter{
+		endpoint: endpoint,
+		headers:  headers,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   remoteWriteTimeout,
+		},
+	}, nil
+}
+
+func (e *remoteWriteExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	// remote-write expects counters and histograms to be cumulative
+	return metricdata.CumulativeTemporality
+}
+
+func (e *remoteWriteExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *remoteWriteExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	req := encodeWriteRequest(rm)
+	if len(req) == 0 {
+		return nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(snappy.Encode(nil, req)))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("sending metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sending metrics: server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (e *remoteWriteExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func (e *remoteWriteExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type promLabel struct {
+	name  string
+	value string
+}
+
+// encodeWriteRequest converts the given metrics to a serialized
+// prometheus.WriteRequest. Metric and label names are sanitized to match the
+// Prometheus data model.
+func encodeWriteRequest(rm *metricdata.ResourceMetrics) []byte {
+	var b []byte
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			name := sanitizeName(m.Name, true)
+			series := func(suffix string, attrs attribute.Set, ts time.Time, value float64, extra ...promLabel) {
+				labels := make([]promLabel, 0, attrs.Len()+len(extra)+2)
+				labels = append(labels, promLabel{"__name__", name + suffix})
+				if sm.Scope.Name != "" {
+					labels = append(labels, promLabel{scopeNameLabel, sm.Scope.Name})
+				}
+				for _, kv := range attrs.ToSlice() {
+					labels = append(labels, promLabel{sanitizeName(string(kv.Key), false), kv.Value.Emit()})
+				}
+				labels = append(labels, extra...)
+				b = appendTimeSeries(b, labels, value, ts.UnixMilli())
+			}
+
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				suffix := sumSuffix(name, data.IsMonotonic)
+				for _, dp := range data.DataPoints {
+					series(suffix, dp.Attributes, dp.Time, float64(dp.Value))
+				}
+			case metricdata.Sum[float64]:
+				suffix := sumSuffix(name, data.IsMonotonic)
+				for _, dp := range data.DataPoints {
+					series(suffix, dp.Attributes, dp.Time, dp.Value)
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					series("", dp.Attributes, dp.Time, float64(dp.Value))
+				}
+			case metricdata.Gauge[float64]:
+				for _, dp := range data.DataPoints {
+					series("", dp.Attributes, dp.Time, dp.Value)
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					appendHistogram(series, dp.Attributes, dp.Time, dp.Bounds, dp.BucketCounts, dp.Count, float64(dp.Sum))
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					appendHistogram(series, dp.Attributes, dp.Time, dp.Bounds, dp.BucketCounts, dp.Count, dp.Sum)
+				}
+			}
+		}
+	}
+	return b
+}
+
+func sumSuffix(name string, monotonic bool) string {
+	if monotonic && !strings.HasSuffix(name, "_total") {
+		return "_total"
+	}
+	return ""
+}
+
+func appendHistogram(
+	series func(string, attribute.Set, time.Time, float64, ...promLabel),
+	attrs attribute.Set,
+	ts time.Time,
+	bounds []float64,
+	counts []uint64,
+	count uint64,
+	sum float64,
+) {
+	// Prometheus buckets are cumulative
+	cumulative := uint64(0)
+	for i, bound := range bounds {
+		if i < len(counts) {
+			cumulative += counts[i]
+		}
+		series("_bucket", attrs, ts, float64(cumulative), promLabel{"le", strconv.FormatFloat(bound, 'g', -1, 64)})
+	}
+	series("_bucket", attrs, ts, float64(count), promLabel{"le", "+Inf"})
+	series("_sum", attrs, ts, sum)
+	series("_count", attrs, ts, float64(count))
+}
+
+// appendTimeSeries appends a prometheus.TimeSeries with a single sample as
+// field 1 of a prometheus.WriteRequest to b
+func appendTimeSeries(b []byte, labels []promLabel, value float64, ts int64) []byte {
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+
+	var tsb []byte
+	for _, l := range labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.value)
+
+		tsb = protowire.AppendTag(tsb, 1, protowire.BytesType)
+		tsb = protowire.AppendBytes(tsb, lb)
+	}
+
+	var sb []byte
+	sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+	sb = protowire.AppendFixed64(sb, math.Float64bits(value))
+	sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+	sb = protowire.AppendVarint(sb, uint64(ts))
+
+	tsb = protowire.AppendTag(tsb, 2, protowire.BytesType)
+	tsb = protowire.AppendBytes(tsb, sb)
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, tsb)
+}
+
+// sanitizeName replaces all characters that are not allowed in Prometheus
+// metric names (allowColon = true) or label names with underscores
+func sanitizeName(name string, allowColon bool) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r >= '0' && r <= '9' && i > 0:
+		case r == ':' && allowColon:
+		default:
+			if i == 0 && r >= '0' && r <= '9' {
+				sb.WriteByte('_')
+				sb.WriteRune(r)
+				continue
+			}
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,601 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oteltraces exports events of data sources as OpenTelemetry spans.
+// A span is either built from a single event or from a pair of events (like a
+// request and its response) that share a correlation key.
+package oteltraces
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr/vm"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/inspektor-gadget/inspektor-gadget/internal/version"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/expr"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	ParamOtelTracesExporter = "otel-traces-exporter"
+
+	AnnotationTracesName = "traces.name"
+
+	AnnotationTracesSpanName = "traces.span-name"
+	AnnotationTracesSpanKind = "traces.span-kind"
+	AnnotationTracesBegin    = "traces.begin"
+	AnnotationTracesEnd      = "traces.end"
+	AnnotationTracesTimeout  = "traces.timeout"
+
+	FieldNameTimestamp = "timestamp"
+	FieldNameEnd       = "end"
+	FieldNameDuration  = "duration"
+	FieldNameKey       = "key"
+	FieldNameStatus    = "status"
+
+	ExporterOTLPGRPC = "otlp-grpc"
+
+	CompressionNone = "none"
+	CompressionGZIP = "gzip"
+
+	// Priority is right before the cli operator, so spans are created from
+	// the events left after filtering and rate limiting
+	Priority = 9998
+
+	defaultTimeout = time.Minute
+
+	// maxPendingSpans limits the number of begin events that are kept per data
+	// source while waiting for their corresponding end event
+	maxPendingSpans = 16384
+)
+
+var supportedExporters = []string{ExporterOTLPGRPC}
+
+var spanKinds = map[string]trace.SpanKind{
+	"internal": trace.SpanKindInternal,
+	"server":   trace.SpanKindServer,
+	"client":   trace.SpanKindClient,
+	"producer": trace.SpanKindProducer,
+	"consumer": trace.SpanKindConsumer,
+}
+
+type traceConfig struct {
+	Exporter    string `json:"exporter" yaml:"exporter"`
+	Endpoint    string `json:"endpoint" yaml:"endpoint"`
+	Insecure    bool   `json:"insecure" yaml:"insecure"`
+	Compression string `json:"compression" yaml:"compression"`
+}
+
+type otelTracesOperator struct {
+	providers map[string]*sdktrace.TracerProvider
+}
+
+func (o *otelTracesOperator) Name() string {
+	return "otel-traces"
+}
+
+func (o *otelTracesOperator) Init(params *params.Params) error {
+	o.providers = make(map[string]*sdktrace.TracerProvider)
+
+	res, _ := resource.New(context.Background(), resource.WithAttributes(
+		semconv.ServiceNameKey.String("inspektor-gadget"),
+		semconv.ServiceVersionKey.String(version.Version().String()),
+	))
+
+	if config.Config == nil {
+		return nil
+	}
+
+	configs := make(map[string]*traceConfig, 0)
+	log.Debugf("loading trace exporters")
+	err := config.Config.UnmarshalKey("operator.otel-traces.exporters", &configs)
+	if err != nil {
+		log.Warnf("failed to load operator.otel-traces.exporters: %v", err)
+	}
+	for k, v := range configs {
+		if v.Exporter != ExporterOTLPGRPC {
+			return fmt.Errorf("unsupported trace exporter %q; expected one of %s", v.Exporter,
+				strings.Join(supportedExporters, ", "))
+		}
+		var options []otlptracegrpc.Option
+
+		options = append(options, otlptracegrpc.WithEndpoint(v.Endpoint))
+		if v.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		switch v.Compression {
+		default:
+			return fmt.Errorf("unsupported trace compression %q", v.Compression)
+		case "", CompressionNone:
+		case CompressionGZIP:
+			options = append(options, otlptracegrpc.WithCompressor("gzip"))
+		}
+
+		exp, err := otlptracegrpc.New(context.Background(), options...)
+		if err != nil {
+			return fmt.Errorf("creating otlp exporter: %w", err)
+		}
+		provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+		o.providers[k] = provider
+		log.Debugf("> trace exporter %q with endpoint %q loaded", k, v.Endpoint)
+	}
+
+	return nil
+}
+
+func (o *otelTracesOperator) GlobalParams() api.Params {
+	return api.Params{}
+}
+
+func (o *otelTracesOperator) InstanceParams() api.Params {
+	return api.Params{
+		&api.Param{
+			Key:          ParamOtelTracesExporter,
+			Description:  "Exporter to use for trace exporting",
+			DefaultValue: "",
+		},
+	}
+}
+
+func (o *otelTracesOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	if len(o.providers) == 0 {
+		return nil, nil
+	}
+	mappings, err := apihelpers.GetStringValuesPerDataSource(instanceParamValues[ParamOtelTracesExporter])
+	if err != nil {
+		return nil, fmt.Errorf("parsing name mappings: %w", err)
+	}
+	inst := &otelTracesOperatorInstance{
+		o:        o,
+		mappings: mappings,
+		tracers:  make(map[datasource.DataSource]*dsTracer),
+	}
+	err = inst.init(gadgetCtx)
+	if err != nil {
+		return nil, err
+	}
+	if len(inst.tracers) == 0 {
+		return nil, nil
+	}
+	return inst, nil
+}
+
+func (o *otelTracesOperator) Priority() int {
+	return Priority
+}
+
+// pendingSpan holds the information of a begin event until the matching end
+// event arrives
+type pendingSpan struct {
+	name    string
+	start   time.Time
+	attribs []attribute.KeyValue
+	failed  bool
+	seen    time.Time
+}
+
+type dsTracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+
+	spanKind trace.SpanKind
+	spanName func(datasource.Data) string
+	attribs  []func(datasource.Data) attribute.KeyValue
+	failed   func(datasource.Data) bool
+
+	timestamp func(datasource.Data) int64
+	end       func(datasource.Data) int64
+	duration  func(datasource.Data) int64
+
+	// correlation of begin and end events
+	keys      []datasource.FieldAccessor
+	isBegin   *vm.Program
+	isEnd     *vm.Program
+	timeout   time.Duration
+	mu        sync.Mutex
+	pending   map[string]*pendingSpan
+	lastSweep time.Time
+}
+
+type otelTracesOperatorInstance struct {
+	o        *otelTracesOperator
+	mappings map[string]string
+	tracers  map[datasource.DataSource]*dsTracer
+}
+
+func (o *otelTracesOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
+	for _, ds := range gadgetCtx.GetDataSources() {
+		annotations := ds.Annotations()
+
+		// Find mapping
+		exporterName, ok := o.mappings[ds.Name()]
+		if !ok {
+			exporterName, ok = o.mappings[""]
+			if !ok {
+				continue
+			}
+		}
+
+		provider, ok := o.o.providers[exporterName]
+		if !ok {
+			return fmt.Errorf("exporter not found: %q", exporterName)
+		}
+
+		tracerName := annotations[AnnotationTracesName]
+		if tracerName == "" {
+			tracerName = gadgetCtx.ImageName()
+		}
+
+		gadgetCtx.Logger().Debugf("tracing %q to exporter %q", ds.Name(), exporterName)
+		o.tracers[ds] = &dsTracer{
+			provider: provider,
+			tracer:   provider.Tracer(tracerName),
+			pending:  make(map[string]*pendingSpan),
+		}
+	}
+	return nil
+}
+
+func (o *otelTracesOperatorInstance) Name() string {
+	return "otel-traces"
+}
+
+func (t *dsTracer) configure(ds datasource.DataSource) error {
+	annotations := ds.Annotations()
+
+	t.spanKind = trace.SpanKindInternal
+	if kind, ok := annotations[AnnotationTracesSpanKind]; ok {
+		t.spanKind, ok = spanKinds[kind]
+		if !ok {
+			return fmt.Errorf("invalid span kind %q", kind)
+		}
+	}
+
+	dsName := ds.Name()
+	t.spanName = func(datasource.Data) string {
+		return dsName
+	}
+	if spanName, ok := annotations[AnnotationTracesSpanName]; ok {
+		if err := checkFields(ds, spanName); err != nil {
+			return err
+		}
+		prog, err := expr.CompileStringProgram(ds, spanName)
+		if err != nil {
+			return fmt.Errorf("compiling expression %q: %w", spanName, err)
+		}
+		t.spanName = func(data datasource.Data) string {
+			s, err := expr.Run(prog, data)
+			if err != nil {
+				return dsName
+			}
+			return s.(string)
+		}
+	}
+
+	if begin, ok := annotations[AnnotationTracesBegin]; ok {
+		if err := checkFields(ds, begin); err != nil {
+			return err
+		}
+		prog, err := expr.CompileFilterProgram(ds, begin)
+		if err != nil {
+			return fmt.Errorf("compiling expression %q: %w", begin, err)
+		}
+		t.isBegin = prog
+	}
+	if end, ok := annotations[AnnotationTracesEnd]; ok {
+		if err := checkFields(ds, end); err != nil {
+			return err
+		}
+		prog, err := expr.CompileFilterProgram(ds, end)
+		if err != nil {
+			return fmt.Errorf("compiling expression %q: %w", end, err)
+		}
+		t.isEnd = prog
+	}
+
+	t.timeout = defaultTimeout
+	if timeout, ok := annotations[AnnotationTracesTimeout]; ok {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", timeout, err)
+		}
+		t.timeout = d
+	}
+
+	for _, f := range ds.Accessors(false) {
+		name, ok := f.Annotations()[AnnotationTracesName]
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case FieldNameTimestamp, FieldNameEnd, FieldNameDuration, FieldNameStatus:
+			fn, err := datasource.AsInt64(f)
+			if err != nil {
+				return fmt.Errorf("using field %q as %q: %w", f.Name(), name, err)
+			}
+			switch name {
+			case FieldNameTimestamp:
+				t.timestamp = fn
+			case FieldNameEnd:
+				t.end = fn
+			case FieldNameDuration:
+				t.duration = fn
+			case FieldNameStatus:
+				t.failed = func(data datasource.Data) bool {
+					return fn(data) != 0
+				}
+			}
+			continue
+		case FieldNameKey:
+			t.keys = append(t.keys, f)
+			continue
+		}
+
+		kvf, err := datasource.GetKeyValueFunc[attribute.Key, attribute.Value](f, name, attribute.Int64Value, attribute.Float64Value, attribute.StringValue)
+		if err != nil {
+			return fmt.Errorf("getting key/val func for %s.%s: %w", ds.Name(), f.Name(), err)
+		}
+		t.attribs = append(t.attribs, func(data datasource.Data) attribute.KeyValue {
+			key, val := kvf(data)
+			return attribute.KeyValue{
+				Key:   key,
+				Value: val,
+			}
+		})
+	}
+
+	if (t.isBegin != nil || t.isEnd != nil) && len(t.keys) == 0 {
+		return fmt.Errorf("%q and %q require at least one field annotated with %s=%s",
+			AnnotationTracesBegin, AnnotationTracesEnd, AnnotationTracesName, FieldNameKey)
+	}
+	return nil
+}
+
+// eventTime returns the timestamp of the event or the current time, if the
+// data source doesn't have a timestamp field
+func (t *dsTracer) eventTime(data datasource.Data) time.Time {
+	if t.timestamp == nil {
+		return time.Now()
+	}
+	return time.Unix(0, t.timestamp(data))
+}
+
+// endTime returns the end of the span started at start using the end or
+// duration fields of the event
+func (t *dsTracer) endTime(data datasource.Data, start time.Time) time.Time {
+	if t.end != nil {
+		return time.Unix(0, t.end(data))
+	}
+	if t.duration != nil {
+		return start.Add(time.Duration(t.duration(data)))
+	}
+	return start
+}
+
+func (t *dsTracer) collectAttributes(data datasource.Data, attribs []attribute.KeyValue) []attribute.KeyValue {
+	for _, fn := range t.attribs {
+		attribs = append(attribs, fn(data))
+	}
+	return attribs
+}
+
+func (t *dsTracer) emitSpan(ctx context.Context, name string, start, end time.Time, attribs []attribute.KeyValue, failed bool) {
+	_, span := t.tracer.Start(ctx, name,
+		trace.WithTimestamp(start),
+		trace.WithSpanKind(t.spanKind),
+		trace.WithAttributes(attribs...),
+	)
+	if failed {
+		span.SetStatus(codes.Error, "")
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// checkFields makes sure the expression only references fields of the data
+// source, as unknown fields would silently evaluate to nil
+func checkFields(ds datasource.DataSource, expression string) error {
+	unknown, err := expr.UnknownFields(ds, expression)
+	if err != nil {
+		return fmt.Errorf("parsing expression %q: %w", expression, err)
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("expression %q references unknown field(s): %s", expression, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func (t *dsTracer) handleSingle(ctx context.Context, data datasource.Data) {
+	start := t.eventTime(data)
+	failed := t.failed != nil && t.failed(data)
+	t.emitSpan(ctx, t.spanName(data), start, t.endTime(data, start), t.collectAttributes(data, nil), failed)
+}
+
+// sweep removes begin events that didn't see their end event within the
+// configured timeout; t.mu must be held
+func (t *dsTracer) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.timeout/2 && len(t.pending) < maxPendingSpans {
+		return
+	}
+	t.lastSweep = now
+	for k, p := range t.pending {
+		if now.Sub(p.seen) > t.timeout {
+			delete(t.pending, k)
+		}
+	}
+}
+
+func (t *dsTracer) handlePaired(ctx context.Context, data datasource.Data) error {
+	key := common.GroupKey(data, t.keys)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var begin bool
+	switch {
+	case t.isBegin != nil:
+		res, err := expr.Run(t.isBegin, data)
+		if err != nil {
+			return err
+		}
+		begin = res.(bool)
+		if !begin && t.isEnd != nil {
+			res, err := expr.Run(t.isEnd, data)
+			if err != nil {
+				return err
+			}
+			if !res.(bool) {
+				// neither begin nor end
+				return nil
+			}
+		}
+	case t.isEnd != nil:
+		res, err := expr.Run(t.isEnd, data)
+		if err != nil {
+			return err
+		}
+		begin = !res.(bool)
+	default:
+		_, ok := t.pending[key]
+		begin = !ok
+	}
+
+	now := time.Now()
+
+	if begin {
+		t.sweep(now)
+		if _, ok := t.pending[key]; !ok && len(t.pending) >= maxPendingSpans {
+			return nil
+		}
+		t.pending[key] = &pendingSpan{
+			name:    t.spanName(data),
+			start:   t.eventTime(data),
+			attribs: t.collectAttributes(data, nil),
+			failed:  t.failed != nil && t.failed(data),
+			seen:    now,
+		}
+		return nil
+	}
+
+	p, ok := t.pending[key]
+	if !ok {
+		return nil
+	}
+	delete(t.pending, key)
+
+	var end time.Time
+	switch {
+	case t.end != nil:
+		end = time.Unix(0, t.end(data))
+	case t.timestamp != nil:
+		end = time.Unix(0, t.timestamp(data))
+	default:
+		end = now
+	}
+	failed := p.failed || (t.failed != nil && t.failed(data))
+
+	// attributes of the end event take precedence
+	t.emitSpan(ctx, p.name, p.start, end, t.collectAttributes(data, p.attribs), failed)
+	return nil
+}
+
+func (o *otelTracesOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for ds, tracer := range o.tracers {
+		err := tracer.configure(ds)
+		if err != nil {
+			return fmt.Errorf("configuring traces for data source %q: %w", ds.Name(), err)
+		}
+
+		handler := func(data datasource.Data) {
+			tracer.handleSingle(gadgetCtx.Context(), data)
+		}
+		if len(tracer.keys) > 0 {
+			handler = func(data datasource.Data) {
+				if err := tracer.handlePaired(gadgetCtx.Context(), data); err != nil {
+					gadgetCtx.Logger().Debugf("otel-traces: handling event of %q: %v", ds.Name(), err)
+				}
+			}
+		}
+
+		switch ds.Type() {
+		case datasource.TypeSingle:
+			err = ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				handler(data)
+				return nil
+			}, Priority)
+		case datasource.TypeArray:
+			err = ds.SubscribeArray(func(ds datasource.DataSource, arr datasource.DataArray) error {
+				for i := range arr.Len() {
+					handler(arr.Get(i))
+				}
+				return nil
+			}, Priority)
+		}
+		if err != nil {
+			return fmt.Errorf("subscribing to data source %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (o *otelTracesOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *otelTracesOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	// Make sure that spans of this instance are sent, even if the provider is
+	// shared with other instances and kept alive
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	flushed := make(map[*sdktrace.TracerProvider]struct{})
+	for _, tracer := range o.tracers {
+		if _, ok := flushed[tracer.provider]; ok {
+			continue
+		}
+		flushed[tracer.provider] = struct{}{}
+		if err := tracer.provider.ForceFlush(ctx); err != nil {
+			gadgetCtx.Logger().Warnf("otel-traces: flushing spans: %v", err)
+		}
+	}
+	return nil
+}
+
+func (o *otelTracesOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &otelTracesOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,307 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltraces
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+// collector is a minimal OTLP trace receiver
+type collector struct {
+	collectortrace.UnimplementedTraceServiceServer
+	mu    sync.Mutex
+	spans []*tracev1.Span
+}
+
+func (c *collector) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func (c *collector) getSpans() map[string]*tracev1.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spans := make(map[string]*tracev1.Span)
+	for _, span := range c.spans {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func startCollector(t *testing.T) (*collector, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	c := &collector{}
+	srv := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(srv, c)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return c, lis.Addr().String()
+}
+
+func newOperator(t *testing.T, endpoint string) *otelTracesOperator {
+	oldConfig := config.Config
+	t.Cleanup(func() {
+		config.Config = oldConfig
+	})
+	config.Config = viper.New()
+	config.Config.Set("operator.otel-traces.exporters", map[string]any{
+		"test": map[string]any{
+			"exporter": ExporterOTLPGRPC,
+			"endpoint": endpoint,
+			"insecure": true,
+		},
+	})
+
+	o := &otelTracesOperator{}
+	require.NoError(t, o.Init(nil))
+	require.Contains(t, o.providers, "test")
+	return o
+}
+
+func stringAttributes(span *tracev1.Span) map[string]string {
+	res := make(map[string]string)
+	for _, kv := range span.Attributes {
+		res[kv.Key] = kv.Value.GetStringValue()
+	}
+	return res
+}
+
+type event struct {
+	id    uint32
+	qr    string
+	name  string
+	ts    uint64
+	rcode uint8
+}
+
+func TestPairedSpans(t *testing.T) {
+	c, endpoint := startCollector(t)
+	o := newOperator(t, endpoint)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := []event{
+		{id: 1, qr: "Q", name: "a.example.com", ts: 1000},
+		{id: 2, qr: "Q", name: "b.example.com", ts: 2000},
+		{id: 1, qr: "R", name: "a.example.com", ts: 1500},
+		{id: 3, qr: "R", name: "c.example.com", ts: 3000}, // no matching query
+		{id: 2, qr: "R", name: "b.example.com", ts: 4000, rcode: 3},
+	}
+
+	var ds datasource.DataSource
+	var idField, qrField, nameField, tsField, rcodeField datasource.FieldAccessor
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "dns")
+			require.NoError(t, err)
+			ds.AddAnnotation(AnnotationTracesSpanName, `"query " + name`)
+			ds.AddAnnotation(AnnotationTracesSpanKind, "client")
+			ds.AddAnnotation(AnnotationTracesBegin, `qr == "Q"`)
+			ds.AddAnnotation(AnnotationTracesEnd, `qr == "R"`)
+
+			idField, err = ds.AddField("id", api.Kind_Uint32, datasource.WithAnnotations(map[string]string{
+				AnnotationTracesName: FieldNameKey,
+			}))
+			require.NoError(t, err)
+			qrField, err = ds.AddField("qr", api.Kind_String)
+			require.NoError(t, err)
+			nameField, err = ds.AddField("name", api.Kind_String, datasource.WithAnnotations(map[string]string{
+				AnnotationTracesName: "dns.question.name",
+			}))
+			require.NoError(t, err)
+			tsField, err = ds.AddField("timestamp", api.Kind_Uint64, datasource.WithAnnotations(map[string]string{
+				AnnotationTracesName: FieldNameTimestamp,
+			}))
+			require.NoError(t, err)
+			rcodeField, err = ds.AddField("rcode", api.Kind_Uint8, datasource.WithAnnotations(map[string]string{
+				AnnotationTracesName: FieldNameStatus,
+			}))
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for _, ev := range events {
+				data, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, idField.PutUint32(data, ev.id))
+				require.NoError(t, qrField.PutString(data, ev.qr))
+				require.NoError(t, nameField.PutString(data, ev.name))
+				require.NoError(t, tsField.PutUint64(data, ev.ts))
+				require.NoError(t, rcodeField.PutUint8(data, ev.rcode))
+				require.NoError(t, ds.EmitAndRelease(data))
+			}
+			cancel()
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(o, producer))
+	err := gadgetCtx.Run(api.ParamValues{
+		"operator.otel-traces.otel-traces-exporter": "dns:test",
+	})
+	require.NoError(t, err)
+
+	spans := c.getSpans()
+	require.Len(t, spans, 2)
+
+	span, ok := spans["query a.example.com"]
+	require.True(t, ok)
+	assert.Equal(t, uint64(1000), span.StartTimeUnixNano)
+	assert.Equal(t, uint64(1500), span.EndTimeUnixNano)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_CLIENT, span.Kind)
+	assert.NotEqual(t, tracev1.Status_STATUS_CODE_ERROR, span.GetStatus().GetCode())
+	assert.Equal(t, "a.example.com", stringAttributes(span)["dns.question.name"])
+
+	span, ok = spans["query b.example.com"]
+	require.True(t, ok)
+	assert.Equal(t, uint64(2000), span.StartTimeUnixNano)
+	assert.Equal(t, uint64(4000), span.EndTimeUnixNano)
+	assert.Equal(t, tracev1.Status_STATUS_CODE_ERROR, span.GetStatus().GetCode())
+}
+
+func TestSingleSpans(t *testing.T) {
+	c, endpoint := startCollector(t)
+	o := newOperator(t, endpoint)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ds datasource.DataSource
+	var commField, tsField, latencyField datasource.FieldAccessor
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "io")
+			require.NoError(t, err)
+			commField, err = ds.AddField("comm", api.Kind_String, datasource.WithAnnotations(map[string]string{
+				AnnotationTracesName: "process.command",
+			}))
+			require.NoError(t, err)
+			tsField, err = ds.AddField("timestamp", api.Kind_Uint64, datasource.WithAnnotations(map[string]string{
+				AnnotationTracesName: FieldNameTimestamp,
+			}))
+			require.NoError(t, err)
+			latencyField, err = ds.AddField("latency", api.Kind_Uint32, datasource.WithAnnotations(map[string]string{
+				AnnotationTracesName: FieldNameDuration,
+			}))
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			data, err := ds.NewPacketSingle()
+			require.NoError(t, err)
+			require.NoError(t, commField.PutString(data, "cat"))
+			require.NoError(t, tsField.PutUint64(data, 5000))
+			require.NoError(t, latencyField.PutUint32(data, 250))
+			require.NoError(t, ds.EmitAndRelease(data))
+			cancel()
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(o, producer))
+	err := gadgetCtx.Run(api.ParamValues{
+		"operator.otel-traces.otel-traces-exporter": "test",
+	})
+	require.NoError(t, err)
+
+	spans := c.getSpans()
+	require.Len(t, spans, 1)
+
+	span, ok := spans["io"]
+	require.True(t, ok)
+	assert.Equal(t, uint64(5000), span.StartTimeUnixNano)
+	assert.Equal(t, uint64(5250), span.EndTimeUnixNano)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_INTERNAL, span.Kind)
+	assert.Equal(t, "cat", stringAttributes(span)["process.command"])
+}
+
+func TestConfigurationErrors(t *testing.T) {
+	_, endpoint := startCollector(t)
+	o := newOperator(t, endpoint)
+
+	testCases := map[string]struct {
+		annotations      map[string]string
+		fieldAnnotations map[string]string
+	}{
+		"invalid span kind": {
+			annotations: map[string]string{AnnotationTracesSpanKind: "foo"},
+		},
+		"invalid timeout": {
+			annotations: map[string]string{AnnotationTracesTimeout: "foo"},
+		},
+		"begin without key": {
+			annotations: map[string]string{AnnotationTracesBegin: `comm == "a"`},
+		},
+		"unknown field in span name": {
+			annotations: map[string]string{AnnotationTracesSpanName: `foo`},
+		},
+		"string as timestamp": {
+			fieldAnnotations: map[string]string{AnnotationTracesName: FieldNameTimestamp},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			producer := simple.New("producer",
+				simple.WithPriority(Priority-1),
+				simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+					ds, err := gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+					require.NoError(t, err)
+					for k, v := range tc.annotations {
+						ds.AddAnnotation(k, v)
+					}
+					_, err = ds.AddField("comm", api.Kind_String, datasource.WithAnnotations(tc.fieldAnnotations))
+					require.NoError(t, err)
+					return nil
+				}),
+			)
+			gadgetCtx := gadgetcontext.New(context.Background(), "", gadgetcontext.WithDataOperators(o, producer))
+			require.Error(t, gadgetCtx.Run(api.ParamValues{
+				"operator.otel-traces.otel-traces-exporter": "test",
+			}))
+		})
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit is a data operator that reduces the number of events of
+// data sources of type single, either by sampling (only keeping 1 in N events)
+// or by limiting the rate of events using a token bucket, optionally per key
+// (like per container or per process name). Dropped events are reported as
+// lost data of the data source and their number is logged periodically.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "ratelimit"
+
+	// Priority is right after the filter operator, so that filtered events
+	// don't use up the rate limit
+	Priority = 9050
+
+	ParamRateLimit      = "rate-limit"
+	ParamRateLimitBurst = "rate-limit-burst"
+	ParamRateLimitBy    = "rate-limit-by"
+	ParamSample         = "sample"
+
+	// annotationApplied is set on data sources that are already limited, so
+	// that the client of a remote run doesn't limit (or sample) them again
+	annotationApplied = "ratelimit.applied"
+
+	// maxKeys limits the number of token buckets kept per data source when
+	// using per-key rate limits
+	maxKeys = 16384
+
+	// reportInterval is how often the number of dropped events is logged
+	reportInterval = 10 * time.Second
+)
+
+type rateLimitOperator struct{}
+
+func (o *rateLimitOperator) Name() string {
+	return name
+}
+
+func (o *rateLimitOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (o *rateLimitOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (o *rateLimitOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:   ParamRateLimit,
+			Title: "Rate Limit",
+			Description: "Maximum number of events per second; additional events are dropped. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','",
+			TypeHint: api.TypeString,
+		},
+		{
+			Key:   ParamRateLimitBurst,
+			Title: "Rate Limit Burst",
+			Description: "Number of events that can be emitted at once before the rate limit kicks in; defaults to the rate limit. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','",
+			TypeHint: api.TypeString,
+		},
+		{
+			Key:   ParamRateLimitBy,
+			Title: "Rate Limit By",
+			Description: "Apply the rate limit per distinct value of the given fields (e.g. 'proc.comm'). Join multiple fields with ','. " +
+				"If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+			TypeHint: api.TypeString,
+		},
+		{
+			Key:   ParamSample,
+			Title: "Sample",
+			Description: "Only keep 1 in N events. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','",
+			TypeHint: api.TypeString,
+		},
+	}
+}
+
+// valueFor returns the value for the given data source, falling back to the
+// value that applies to all data sources
+func valueFor[T any](m map[string]T, dsName string) (T, bool) {
+	if v, ok := m[dsName]; ok {
+		return v, true
+	}
+	v, ok := m[""]
+	return v, ok
+}
+
+func parseFloatValues(s string) (map[string]float64, error) {
+	m, err := apihelpers.GetStringValuesPerDataSource(s)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]float64, len(m))
+	for k, v := range m {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s to number: %w", v, err)
+		}
+		if f < 0 {
+			return nil, fmt.Errorf("value must not be negative: %s", v)
+		}
+		res[k] = f
+	}
+	return res, nil
+}
+
+func (o *rateLimitOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	rates, err := parseFloatValues(instanceParamValues[ParamRateLimit])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamRateLimit, err)
+	}
+	bursts, err := parseFloatValues(instanceParamValues[ParamRateLimitBurst])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamRateLimitBurst, err)
+	}
+	keys, err := apihelpers.GetListValuesPerDataSource(instanceParamValues[ParamRateLimitBy])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamRateLimitBy, err)
+	}
+	samples, err := apihelpers.GetIntValuesPerDataSource(instanceParamValues[ParamSample])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ParamSample, err)
+	}
+	if len(rates) == 0 && len(samples) == 0 {
+		if len(keys) > 0 || len(bursts) > 0 {
+			return nil, fmt.Errorf("%s and %s require %s", ParamRateLimitBy, ParamRateLimitBurst, ParamRateLimit)
+		}
+		return nil, nil
+	}
+
+	dataSources := gadgetCtx.GetDataSources()
+	for _, m := range []map[string]bool{keysOf(rates), keysOf(bursts), keysOf(keys), keysOf(samples)} {
+		for dsName := range m {
+			if dsName == "" {
+				continue
+			}
+			ds, ok := dataSources[dsName]
+			if !ok {
+				return nil, fmt.Errorf("data source %q not found", dsName)
+			}
+			if ds.Type() != datasource.TypeSingle {
+				return nil, fmt.Errorf("data source %q: rate limiting can only be used on data sources of type single", dsName)
+			}
+		}
+	}
+
+	inst := &rateLimitOperatorInstance{
+		limiters: make(map[datasource.DataSource]*limiter),
+	}
+	for _, ds := range dataSources {
+		if ds.Type() != datasource.TypeSingle {
+			continue
+		}
+		if ds.Annotations()[annotationApplied] == "true" {
+			gadgetCtx.Logger().Debugf("ratelimit: data source %q is already limited", ds.Name())
+			continue
+		}
+
+		rate, hasRate := valueFor(rates, ds.Name())
+		sample, hasSample := valueFor(samples, ds.Name())
+		if sample < 0 {
+			return nil, fmt.Errorf("invalid %s for data source %q: %d", ParamSample, ds.Name(), sample)
+		}
+		if (!hasRate || rate == 0) && (!hasSample || sample <= 1) {
+			continue
+		}
+
+		l := &limiter{
+			ds:      ds,
+			rate:    rate,
+			sample:  uint64(max(sample, 1)),
+			buckets: make(map[string]*bucket),
+			now:     time.Now,
+		}
+		if rate > 0 {
+			burst, ok := valueFor(bursts, ds.Name())
+			if !ok || burst == 0 {
+				burst = rate
+			}
+			l.burst = max(burst, 1)
+			l.global = bucket{tokens: l.burst}
+
+			fieldNames, _ := valueFor(keys, ds.Name())
+			for _, fieldName := range fieldNames {
+				f := ds.GetField(fieldName)
+				if f == nil {
+					return nil, fmt.Errorf("data source %q: field %q not found", ds.Name(), fieldName)
+				}
+				l.keyFields = append(l.keyFields, f)
+			}
+		}
+		inst.limiters[ds] = l
+		ds.AddAnnotation(annotationApplied, "true")
+	}
+	if len(inst.limiters) == 0 {
+		return nil, nil
+	}
+	return inst, nil
+}
+
+func keysOf[T any](m map[string]T) map[string]bool {
+	res := make(map[string]bool, len(m))
+	for k := range m {
+		res[k] = true
+	}
+	return res
+}
+
+func (o *rateLimitOperator) Priority() int {
+	return Priority
+}
+
+// bucket is a token bucket that is refilled with rate tokens per second up to
+// burst tokens
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time, rate, burst float64) {
+	if !b.last.IsZero() {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+}
+
+func (b *bucket) take(now time.Time, rate, burst float64) bool {
+	b.refill(now, rate, burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type limiter struct {
+	ds        datasource.DataSource
+	rate      float64
+	burst     float64
+	sample    uint64
+	keyFields []datasource.FieldAccessor
+
+	mu      sync.Mutex
+	seen    uint64
+	global  bucket
+	buckets map[string]*bucket
+	now     func() time.Time
+
+	// dropped counts the events dropped since the last report
+	dropped atomic.Uint64
+}
+
+// pruneBuckets removes buckets that are full again, as they behave just like
+// new ones; if that's not enough, all buckets are reset
+func (l *limiter) pruneBuckets(now time.Time) {
+	for k, b := range l.buckets {
+		b.refill(now, l.rate, l.burst)
+		if b.tokens >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+	if len(l.buckets) >= maxKeys {
+		clear(l.buckets)
+	}
+}
+
+// allow returns whether the given event should be kept
+func (l *limiter) allow(data datasource.Data) bool {
+	var key string
+	if len(l.keyFields) > 0 {
+		key = common.GroupKey(data, l.keyFields)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seen++
+	if l.sample > 1 && (l.seen-1)%l.sample != 0 {
+		return false
+	}
+	if l.rate == 0 {
+		return true
+	}
+
+	now := l.now()
+	if len(l.keyFields) == 0 {
+		return l.global.take(now, l.rate, l.burst)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxKeys {
+			l.pruneBuckets(now)
+		}
+		b = &bucket{tokens: l.burst}
+		l.buckets[key] = b
+	}
+	return b.take(now, l.rate, l.burst)
+}
+
+type rateLimitOperatorInstance struct {
+	limiters map[datasource.DataSource]*limiter
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// report logs the number of events dropped per data source since the last
+// report
+func (o *rateLimitOperatorInstance) report(logger logger.Logger) {
+	for ds, l := range o.limiters {
+		if dropped := l.dropped.Swap(0); dropped > 0 {
+			logger.Warnf("ratelimit: dropped %d events of data source %q", dropped, ds.Name())
+		}
+	}
+}
+
+func (o *rateLimitOperatorInstance) Name() string {
+	return name
+}
+
+func (o *rateLimitOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for ds, l := range o.limiters {
+		gadgetCtx.Logger().Debugf("ratelimit: data source %q rate %v burst %v sample %d keys %d",
+			ds.Name(), l.rate, l.burst, l.sample, len(l.keyFields))
+		err := ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			if l.allow(data) {
+				return nil
+			}
+			l.dropped.Add(1)
+			ds.ReportLostData(1)
+			return datasource.ErrDiscard
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to data source %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (o *rateLimitOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	o.done = make(chan struct{})
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		ticker := time.NewTicker(reportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-o.done:
+				return
+			case <-ticker.C:
+				o.report(gadgetCtx.Logger())
+			}
+		}
+	}()
+	return nil
+}
+
+func (o *rateLimitOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	if o.done != nil {
+		close(o.done)
+		o.wg.Wait()
+		o.done = nil
+	}
+	o.report(gadgetCtx.Logger())
+	return nil
+}
+
+func (o *rateLimitOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+var Operator = &rateLimitOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
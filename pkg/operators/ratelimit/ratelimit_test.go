@@ -0,0 +1,225 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+type result struct {
+	received map[string]int
+	lost     uint64
+	warnings []string
+}
+
+// warningLogger keeps all messages logged with level warning
+type warningLogger struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *warningLogger) Log(severity logger.Level, params ...any) {
+	l.Logf(severity, "%s", fmt.Sprint(params...))
+}
+
+func (l *warningLogger) Logf(severity logger.Level, format string, params ...any) {
+	if severity != logger.WarnLevel {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf(format, params...))
+}
+
+func (l *warningLogger) SetLevel(logger.Level) {}
+
+func (l *warningLogger) GetLevel() logger.Level { return logger.DebugLevel }
+
+// run emits the given comms as events of a data source called "events" and
+// counts the events that pass the operator per comm
+func run(t *testing.T, comms []string, paramValues api.ParamValues, annotations map[string]string) (*result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := &result{received: make(map[string]int)}
+
+	var ds datasource.DataSource
+	var commF datasource.FieldAccessor
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+			require.NoError(t, err)
+			for k, v := range annotations {
+				ds.AddAnnotation(k, v)
+			}
+			commF, err = ds.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for _, comm := range comms {
+				data, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, commF.PutString(data, comm))
+				require.NoError(t, ds.EmitAndRelease(data))
+			}
+			res.lost = ds.LostDataCount()
+			cancel()
+			return nil
+		}),
+	)
+	consumer := simple.New("consumer",
+		simple.WithPriority(Priority+1),
+		simple.OnPreStart(func(gadgetCtx operators.GadgetContext) error {
+			return ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				comm, err := commF.String(data)
+				require.NoError(t, err)
+				res.received[comm]++
+				return nil
+			}, Priority+1)
+		}),
+	)
+
+	l := &warningLogger{}
+	gadgetCtx := gadgetcontext.New(ctx, "",
+		gadgetcontext.WithDataOperators(Operator, producer, consumer),
+		gadgetcontext.WithLogger(logger.NewFromGenericLogger(l)),
+	)
+	err := gadgetCtx.Run(paramValues)
+	res.warnings = l.warnings
+	return res, err
+}
+
+func repeat(s string, n int) []string {
+	res := make([]string, n)
+	for i := range res {
+		res[i] = s
+	}
+	return res
+}
+
+func TestSample(t *testing.T) {
+	res, err := run(t, repeat("cat", 100), api.ParamValues{
+		"operator.ratelimit.sample": "10",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"cat": 10}, res.received)
+	assert.Equal(t, uint64(90), res.lost)
+}
+
+func TestRateLimit(t *testing.T) {
+	// All events are emitted at once, so only the burst gets through
+	res, err := run(t, repeat("cat", 20), api.ParamValues{
+		"operator.ratelimit.rate-limit": "5",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"cat": 5}, res.received)
+	assert.Equal(t, uint64(15), res.lost)
+	assert.Contains(t, res.warnings, `ratelimit: dropped 15 events of data source "events"`)
+
+	res, err = run(t, repeat("cat", 20), api.ParamValues{
+		"operator.ratelimit.rate-limit":       "events:1",
+		"operator.ratelimit.rate-limit-burst": "events:3",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"cat": 3}, res.received)
+	assert.Equal(t, uint64(17), res.lost)
+}
+
+func TestRateLimitPerKey(t *testing.T) {
+	comms := append(repeat("cat", 10), repeat("ls", 10)...)
+	res, err := run(t, comms, api.ParamValues{
+		"operator.ratelimit.rate-limit":    "2",
+		"operator.ratelimit.rate-limit-by": "comm",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"cat": 2, "ls": 2}, res.received)
+	assert.Equal(t, uint64(16), res.lost)
+}
+
+func TestAlreadyLimited(t *testing.T) {
+	res, err := run(t, repeat("cat", 10), api.ParamValues{
+		"operator.ratelimit.sample": "10",
+	}, map[string]string{annotationApplied: "true"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"cat": 10}, res.received)
+	assert.Zero(t, res.lost)
+}
+
+func TestRateLimitErrors(t *testing.T) {
+	testCases := map[string]api.ParamValues{
+		"unknown data source": {
+			"operator.ratelimit.rate-limit": "foo:10",
+		},
+		"invalid rate": {
+			"operator.ratelimit.rate-limit": "foo",
+		},
+		"negative rate": {
+			"operator.ratelimit.rate-limit": "-1",
+		},
+		"negative sample": {
+			"operator.ratelimit.sample": "-1",
+		},
+		"unknown field": {
+			"operator.ratelimit.rate-limit":    "10",
+			"operator.ratelimit.rate-limit-by": "foo",
+		},
+		"key without rate": {
+			"operator.ratelimit.rate-limit-by": "comm",
+		},
+	}
+	for name, paramValues := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := run(t, nil, paramValues, nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := &bucket{tokens: 2}
+
+	assert.True(t, b.take(now, 1, 2))
+	assert.True(t, b.take(now, 1, 2))
+	assert.False(t, b.take(now, 1, 2))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.False(t, b.take(now, 1, 2))
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, b.take(now, 1, 2))
+
+	// refilling doesn't exceed the burst
+	now = now.Add(time.Hour)
+	assert.True(t, b.take(now, 1, 2))
+	assert.True(t, b.take(now, 1, 2))
+	assert.False(t, b.take(now, 1, 2))
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package record stores the gadget info and all packets of a gadget run in a
+// file, so that they can be replayed later on using the replay runtime.
+package record
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
+)
+
+const (
+	name = "record"
+
+	// Priority is right below the one of the filter operator: packets are
+	// recorded after they have been produced and enriched, but before they are
+	// filtered, aggregated, sorted or exported. Only operators with a higher
+	// priority need to run when replaying a recording.
+	Priority = 8999
+
+	ParamRecord = "record"
+)
+
+type recordOperator struct{}
+
+func (o *recordOperator) Name() string {
+	return name
+}
+
+func (o *recordOperator) Init(params *params.Params) error {
+	return nil
+}
+
+func (o *recordOperator) GlobalParams() api.Params {
+	return nil
+}
+
+func (o *recordOperator) InstanceParams() api.Params {
+	return api.Params{
+		{
+			Key:          ParamRecord,
+			Description:  "Record the gadget info and all events to the given file; use the replay command to replay it",
+			DefaultValue: "",
+			TypeHint:     api.TypeString,
+		},
+	}
+}
+
+func (o *recordOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	path := instanceParamValues[ParamRecord]
+	if path == "" {
+		return nil, nil
+	}
+
+	// Remote calls without an ID are attached to a client that will record the
+	// events itself; only headless instances are recorded on the server.
+	if gadgetCtx.IsRemoteCall() && gadgetCtx.ID() == "" {
+		return nil, nil
+	}
+
+	// Remember the data sources that exist at this point; operators with a
+	// higher priority could still unreference them (and register new ones),
+	// but those will run again when replaying.
+	dataSources := make([]datasource.DataSource, 0)
+	for _, ds := range gadgetCtx.GetDataSources() {
+		dataSources = append(dataSources, ds)
+	}
+	sort.Slice(dataSources, func(i, j int) bool {
+		return dataSources[i].Name() < dataSources[j].Name()
+	})
+
+	return &recordOperatorInstance{
+		path:        path,
+		dataSources: dataSources,
+	}, nil
+}
+
+func (o *recordOperator) Priority() int {
+	return Priority
+}
+
+type recordOperatorInstance struct {
+	path        string
+	dataSources []datasource.DataSource
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *recording.Writer
+}
+
+func (o *recordOperatorInstance) Name() string {
+	return name
+}
+
+func (o *recordOperatorInstance) gadgetInfo(gadgetCtx operators.GadgetContext) (*api.GadgetInfo, error) {
+	gi, err := gadgetCtx.SerializeGadgetInfo(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Params only apply to the original run
+	gi.Params = nil
+
+	gi.DataSources = make([]*api.DataSource, 0, len(o.dataSources))
+	for i, ds := range o.dataSources {
+		di := &api.DataSource{
+			Id:          uint32(i),
+			Type:        uint32(ds.Type()),
+			Name:        ds.Name(),
+			Fields:      ds.Fields(),
+			Tags:        ds.Tags(),
+			Annotations: ds.Annotations(),
+		}
+		if ds.ByteOrder() == binary.BigEndian {
+			di.Flags |= api.DataSourceFlagsBigEndian
+		}
+		gi.DataSources = append(gi.DataSources, di)
+	}
+	return gi, nil
+}
+
+func (o *recordOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	gi, err := o.gadgetInfo(gadgetCtx)
+	if err != nil {
+		return fmt.Errorf("serializing gadget info: %w", err)
+	}
+
+	f, err := os.OpenFile(o.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating recording: %w", err)
+	}
+
+	w, err := recording.NewWriter(f, gi)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("writing recording: %w", err)
+	}
+	o.file = f
+	o.writer = w
+
+	gadgetCtx.Logger().Debugf("record: recording %d data sources to %q", len(o.dataSources), o.path)
+
+	for i, ds := range o.dataSources {
+		dsID := uint32(i)
+		err := ds.SubscribePacket(func(ds datasource.DataSource, packet datasource.Packet) error {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			if o.writer == nil {
+				return nil
+			}
+			if err := o.writer.WritePacket(dsID, packet.Raw()); err != nil {
+				gadgetCtx.Logger().Warnf("record: writing packet of %q: %v", ds.Name(), err)
+			}
+			return nil
+		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (o *recordOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *recordOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return o.close()
+}
+
+func (o *recordOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return o.close()
+}
+
+func (o *recordOperatorInstance) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return nil
+	}
+	err := errors.Join(o.writer.Flush(), o.file.Close())
+	o.file = nil
+	o.writer = nil
+	return err
+}
+
+var Operator = &recordOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
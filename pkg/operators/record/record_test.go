@@ -0,0 +1,148 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
+)
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// "open" is registered first, but data sources are recorded sorted by name
+	var open, exec datasource.DataSource
+	var openComm, execComm datasource.FieldAccessor
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			open, err = gadgetCtx.RegisterDataSource(datasource.TypeArray, "open")
+			require.NoError(t, err)
+			openComm, err = open.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			exec, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "exec")
+			require.NoError(t, err)
+			execComm, err = exec.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			defer cancel()
+			for _, comm := range []string{"cat", "ls"} {
+				data, err := exec.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, execComm.PutString(data, comm))
+				require.NoError(t, exec.EmitAndRelease(data))
+			}
+
+			arr, err := open.NewPacketArray()
+			require.NoError(t, err)
+			data := arr.New()
+			require.NoError(t, openComm.PutString(data, "vim"))
+			arr.Append(data)
+			require.NoError(t, open.EmitAndRelease(arr))
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(Operator, producer))
+	require.NoError(t, gadgetCtx.Run(api.ParamValues{
+		"operator.record.record": path,
+	}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := recording.NewReader(f)
+	require.NoError(t, err)
+
+	gi := r.GadgetInfo()
+	assert.Empty(t, gi.Params)
+	require.Len(t, gi.DataSources, 2)
+	assert.Equal(t, "exec", gi.DataSources[0].Name)
+	assert.Equal(t, uint32(0), gi.DataSources[0].Id)
+	assert.Equal(t, uint32(datasource.TypeSingle), gi.DataSources[0].Type)
+	assert.Equal(t, "open", gi.DataSources[1].Name)
+	assert.Equal(t, uint32(1), gi.DataSources[1].Id)
+	assert.Equal(t, uint32(datasource.TypeArray), gi.DataSources[1].Type)
+
+	// payloadContains returns whether any payload of the elements contains s
+	payloadContains := func(elements []*api.DataElement, s string) bool {
+		for _, el := range elements {
+			for _, p := range el.Payload {
+				if bytes.Contains(p, []byte(s)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	expected := []struct {
+		dsID uint32
+		comm string
+	}{
+		{0, "cat"},
+		{0, "ls"},
+		{1, "vim"},
+	}
+	for i, e := range expected {
+		ev, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, e.dsID, ev.DataSourceID)
+		assert.Equal(t, uint32(i+1), ev.Seq)
+
+		if e.dsID == 0 {
+			packet := &api.GadgetData{}
+			require.NoError(t, proto.Unmarshal(ev.Payload, packet))
+			assert.True(t, payloadContains([]*api.DataElement{packet.Data}, e.comm))
+		} else {
+			packet := &api.GadgetDataArray{}
+			require.NoError(t, proto.Unmarshal(ev.Payload, packet))
+			assert.True(t, payloadContains(packet.DataArray, e.comm))
+		}
+	}
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.False(t, r.Truncated())
+}
+
+func TestRecordDisabled(t *testing.T) {
+	gadgetCtx := gadgetcontext.New(context.Background(), "")
+	inst, err := Operator.InstantiateDataOperator(gadgetCtx, api.ParamValues{})
+	require.NoError(t, err)
+	assert.Nil(t, inst)
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+const (
+	// maxRetryBackoff limits the exponential backoff between retries
+	maxRetryBackoff = 30 * time.Second
+
+	// closeTimeout is the time pending events are tried to be published when
+	// closing a batcher, before giving up
+	closeTimeout = 10 * time.Second
+)
+
+// publisher sends batches of messages to a destination
+type publisher interface {
+	publish(ctx context.Context, destination string, msgs []message) error
+	close() error
+}
+
+// permanentError wraps errors that won't go away by retrying, like rejected
+// requests
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// batcher collects messages for a destination and publishes them in batches
+// from a dedicated goroutine. Depending on the overflow setting, add either
+// blocks if the buffer is full, propagating backpressure to the data source,
+// or drops the message.
+type batcher struct {
+	pub          publisher
+	destination  string
+	batchSize    int
+	batchTimeout time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+	drop         bool
+	logger       logger.Logger
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	msgs      chan message
+	stopping  chan struct{}
+	done      chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	dropped   atomic.Uint64
+	published atomic.Uint64
+}
+
+func newBatcher(pub publisher, destination string, cfg *exporterConfig, logger logger.Logger) *batcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &batcher{
+		pub:          pub,
+		destination:  destination,
+		batchSize:    cfg.BatchSize,
+		batchTimeout: cfg.BatchTimeout,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		drop:         cfg.Overflow == OverflowDrop,
+		logger:       logger,
+		msgs:         make(chan message, cfg.BufferSize),
+		stopping:     make(chan struct{}),
+		done:         make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	go b.run()
+	return b
+}
+
+func (b *batcher) add(msg message) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	if b.drop {
+		select {
+		case b.msgs <- msg:
+		default:
+			b.dropped.Add(1)
+		}
+		return
+	}
+
+	select {
+	case b.msgs <- msg:
+	case <-b.stopping:
+		b.dropped.Add(1)
+	}
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	batch := make([]message, 0, b.batchSize)
+	timer := time.NewTimer(b.batchTimeout)
+	defer timer.Stop()
+
+	flush := func() {
+		if dropped := b.dropped.Swap(0); dropped > 0 {
+			b.logger.Warnf("sink: dropped %d events for %q as the buffer was full", dropped, b.destination)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		b.publish(batch)
+		batch = make([]message, 0, b.batchSize)
+	}
+
+	for {
+		select {
+		case msg, ok := <-b.msgs:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, msg)
+			if len(batch) >= b.batchSize {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+			timer.Reset(b.batchTimeout)
+		}
+	}
+}
+
+// publish sends the batch, retrying with an exponential backoff on errors
+func (b *batcher) publish(batch []message) {
+	backoff := b.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := b.pub.publish(b.ctx, b.destination, batch)
+		if err == nil {
+			b.published.Add(uint64(len(batch)))
+			return
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= b.maxRetries || b.ctx.Err() != nil {
+			b.logger.Warnf("sink: dropping %d events for %q: %v", len(batch), b.destination, err)
+			return
+		}
+		b.logger.Debugf("sink: publishing to %q failed, retrying in %s: %v", b.destination, backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-b.ctx.Done():
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// close publishes pending messages and stops the batcher. Senders blocked by a
+// full buffer are released and their messages dropped.
+func (b *batcher) close() {
+	b.closeOnce.Do(func() {
+		close(b.stopping)
+
+		b.mu.Lock()
+		b.closed = true
+		close(b.msgs)
+		b.mu.Unlock()
+
+		select {
+		case <-b.done:
+		case <-time.After(closeTimeout):
+			b.cancel()
+			<-b.done
+		}
+		b.cancel()
+	})
+}
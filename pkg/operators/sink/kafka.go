@@ -0,0 +1,72 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type kafkaPublisher struct {
+	w *kafka.Writer
+}
+
+func newKafkaPublisher(cfg *exporterConfig) (*kafkaPublisher, error) {
+	transport := &kafka.Transport{
+		DialTimeout: cfg.Timeout,
+	}
+	if cfg.TLS {
+		transport.TLS = &tls.Config{}
+	}
+
+	return &kafkaPublisher{
+		w: &kafka.Writer{
+			Addr:      kafka.TCP(cfg.Brokers...),
+			Balancer:  &kafka.Hash{},
+			Transport: transport,
+			// Batching and retries are handled by the batcher
+			BatchSize:    cfg.BatchSize,
+			BatchTimeout: time.Millisecond,
+			MaxAttempts:  1,
+			WriteTimeout: cfg.Timeout,
+			RequiredAcks: kafka.RequireAll,
+		},
+	}, nil
+}
+
+func (p *kafkaPublisher) publish(ctx context.Context, topic string, msgs []message) error {
+	kmsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		headers := make([]kafka.Header, 0, len(msg.headers))
+		for k, v := range msg.headers {
+			headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+		// Keying by data source keeps its events in order in a single partition
+		kmsgs = append(kmsgs, kafka.Message{
+			Topic:   topic,
+			Key:     []byte(msg.headers[HeaderDataSource]),
+			Value:   msg.value,
+			Headers: headers,
+		})
+	}
+	return p.w.WriteMessages(ctx, kmsgs...)
+}
+
+func (p *kafkaPublisher) close() error {
+	return p.w.Close()
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+)
+
+// Headers added to every message
+const (
+	HeaderContentType = "content-type"
+	HeaderGadget      = "ig-gadget"
+	HeaderDataSource  = "ig-datasource"
+	HeaderInstance    = "ig-instance"
+	// HeaderEventType is only set for protobuf messages and holds the type of
+	// the api.GadgetEvent
+	HeaderEventType = "ig-event-type"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// message is a single serialized event; for array data sources, it holds all
+// elements of the array
+type message struct {
+	value   []byte
+	headers map[string]string
+}
+
+func withHeaders(headers map[string]string, extra ...string) map[string]string {
+	res := make(map[string]string, len(headers)+len(extra)/2)
+	for k, v := range headers {
+		res[k] = v
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		res[extra[i]] = extra[i+1]
+	}
+	return res
+}
+
+func subscribeJSON(ds datasource.DataSource, b *batcher, headers map[string]string) error {
+	jsonFormatter, err := json.New(ds,
+		json.WithShowAll(true),
+		json.WithArray(ds.Type() == datasource.TypeArray),
+	)
+	if err != nil {
+		return fmt.Errorf("initializing JSON formatter: %w", err)
+	}
+
+	headers = withHeaders(headers, HeaderContentType, ContentTypeJSON)
+	add := func(d []byte) {
+		// d may be backed by a shared buffer of the formatter, so copy it
+		b.add(message{value: append([]byte(nil), d...), headers: headers})
+	}
+
+	switch ds.Type() {
+	case datasource.TypeSingle:
+		return ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+			add(jsonFormatter.Marshal(data))
+			return nil
+		}, Priority)
+	case datasource.TypeArray:
+		return ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+			add(jsonFormatter.MarshalArray(dataArray))
+			return nil
+		}, Priority)
+	}
+	return fmt.Errorf("unsupported data source type %d", ds.Type())
+}
+
+// subscribeProtobuf publishes messages containing a serialized api.GadgetEvent,
+// just like the ones sent by the gadget service. The first message holds the
+// api.GadgetInfo needed to decode the payloads of the following ones.
+func subscribeProtobuf(gadgetCtx operators.GadgetContext, ds datasource.DataSource, b *batcher, headers map[string]string) error {
+	gi, err := common.GadgetInfoForDataSources(gadgetCtx, []datasource.DataSource{ds})
+	if err != nil {
+		return fmt.Errorf("serializing gadget info: %w", err)
+	}
+	d, err := proto.Marshal(gi)
+	if err != nil {
+		return fmt.Errorf("marshaling gadget info: %w", err)
+	}
+
+	eventHeaders := map[uint32]map[string]string{}
+	for _, typ := range []uint32{api.EventTypeGadgetInfo, api.EventTypeGadgetPayload} {
+		eventHeaders[typ] = withHeaders(headers,
+			HeaderContentType, ContentTypeProtobuf,
+			HeaderEventType, fmt.Sprint(typ),
+		)
+	}
+
+	add := func(ev *api.GadgetEvent) error {
+		v, err := proto.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		b.add(message{value: v, headers: eventHeaders[ev.Type]})
+		return nil
+	}
+
+	if err := add(&api.GadgetEvent{Type: api.EventTypeGadgetInfo, Payload: d}); err != nil {
+		return fmt.Errorf("marshaling gadget info event: %w", err)
+	}
+
+	var seq atomic.Uint32
+	return ds.SubscribePacket(func(ds datasource.DataSource, packet datasource.Packet) error {
+		d, err := proto.Marshal(packet.Raw())
+		if err != nil {
+			gadgetCtx.Logger().Warnf("sink: marshaling packet of %q: %v", ds.Name(), err)
+			return nil
+		}
+		if err := add(&api.GadgetEvent{
+			Type:    api.EventTypeGadgetPayload,
+			Seq:     seq.Add(1),
+			Payload: d,
+		}); err != nil {
+			gadgetCtx.Logger().Warnf("sink: marshaling event of %q: %v", ds.Name(), err)
+		}
+		return nil
+	}, Priority)
+}
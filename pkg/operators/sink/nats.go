@@ -0,0 +1,65 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type natsPublisher struct {
+	conn    *nats.Conn
+	timeout time.Duration
+}
+
+func newNATSPublisher(cfg *exporterConfig) (*natsPublisher, error) {
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("inspektor-gadget"),
+		nats.Timeout(cfg.Timeout),
+		// Don't fail if the server isn't reachable yet; the batcher retries
+		// publishing until it is
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %q: %w", cfg.URL, err)
+	}
+	return &natsPublisher{conn: conn, timeout: cfg.Timeout}, nil
+}
+
+func (p *natsPublisher) publish(ctx context.Context, subject string, msgs []message) error {
+	for _, msg := range msgs {
+		nmsg := nats.NewMsg(subject)
+		nmsg.Data = msg.value
+		for k, v := range msg.headers {
+			nmsg.Header.Set(k, v)
+		}
+		if err := p.conn.PublishMsg(nmsg); err != nil {
+			return err
+		}
+	}
+
+	// Wait for the server to have processed the messages
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.conn.FlushWithContext(ctx)
+}
+
+func (p *natsPublisher) close() error {
+	return p.conn.Drain()
+}
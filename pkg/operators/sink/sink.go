@@ -0,0 +1,357 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink publishes the events of data sources to Kafka topics, NATS
+// subjects or HTTP webhooks. Exporters are configured in the
+// operator.sink.exporters section of the config file and are selected per data
+// source using the sink-exporter parameter.
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "sink"
+
+	// Priority is after the operators filtering, limiting and aggregating
+	// events, so that only the events that are left are published to the
+	// brokers and webhooks
+	Priority = 10000
+
+	ParamSinkExporter = "sink-exporter"
+
+	ExporterKafka   = "kafka"
+	ExporterNATS    = "nats"
+	ExporterWebhook = "webhook"
+
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+
+	OverflowBlock = "block"
+	OverflowDrop  = "drop"
+
+	// Placeholders that are replaced in destinations
+	DataSourcePlaceholder = "{datasource}"
+	GadgetPlaceholder     = "{gadget}"
+	InstancePlaceholder   = "{instance}"
+)
+
+var supportedExporters = []string{ExporterKafka, ExporterNATS, ExporterWebhook}
+
+type exporterConfig struct {
+	Exporter string `json:"exporter" yaml:"exporter"`
+	Format   string `json:"format" yaml:"format"`
+
+	// Kafka
+	Brokers []string `json:"brokers" yaml:"brokers"`
+	Topic   string   `json:"topic" yaml:"topic"`
+	TLS     bool     `json:"tls" yaml:"tls"`
+
+	// NATS
+	Subject string `json:"subject" yaml:"subject"`
+
+	// NATS server or webhook URL
+	URL     string            `json:"url" yaml:"url"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	Timeout time.Duration     `json:"timeout" yaml:"timeout"`
+
+	// Routes overrides the destination (topic, subject or URL) per data source
+	Routes map[string]string `json:"routes" yaml:"routes"`
+
+	BatchSize    int           `json:"batchSize" yaml:"batchSize"`
+	BatchTimeout time.Duration `json:"batchTimeout" yaml:"batchTimeout"`
+	MaxRetries   int           `json:"maxRetries" yaml:"maxRetries"`
+	RetryBackoff time.Duration `json:"retryBackoff" yaml:"retryBackoff"`
+	BufferSize   int           `json:"bufferSize" yaml:"bufferSize"`
+	Overflow     string        `json:"overflow" yaml:"overflow"`
+}
+
+func (c *exporterConfig) setDefaults() {
+	if c.Format == "" {
+		c.Format = FormatJSON
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.BatchTimeout <= 0 {
+		c.BatchTimeout = time.Second
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = 5
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = 10000
+	}
+	if c.Overflow == "" {
+		c.Overflow = OverflowBlock
+	}
+}
+
+func (c *exporterConfig) validate() error {
+	if !slices.Contains(supportedExporters, c.Exporter) {
+		return fmt.Errorf("unsupported exporter %q; expected one of %s", c.Exporter,
+			strings.Join(supportedExporters, ", "))
+	}
+	if c.Format != FormatJSON && c.Format != FormatProtobuf {
+		return fmt.Errorf("unsupported format %q; expected %s or %s", c.Format, FormatJSON, FormatProtobuf)
+	}
+	if c.Overflow != OverflowBlock && c.Overflow != OverflowDrop {
+		return fmt.Errorf("unsupported overflow %q; expected %s or %s", c.Overflow, OverflowBlock, OverflowDrop)
+	}
+	switch c.Exporter {
+	case ExporterKafka:
+		if len(c.Brokers) == 0 {
+			return errors.New("brokers must be set")
+		}
+	case ExporterNATS, ExporterWebhook:
+		if c.URL == "" {
+			return errors.New("url must be set")
+		}
+	}
+	return nil
+}
+
+// destination returns the topic, subject or URL the given data source is
+// published to
+func (c *exporterConfig) destination(dsName string) string {
+	if dst, ok := c.Routes[dsName]; ok {
+		return dst
+	}
+	switch c.Exporter {
+	case ExporterKafka:
+		return c.Topic
+	case ExporterNATS:
+		return c.Subject
+	default:
+		return c.URL
+	}
+}
+
+// exporter holds the configuration and the connection of an exporter; it is
+// shared by all gadget instances using it
+type exporter struct {
+	cfg *exporterConfig
+	pub publisher
+}
+
+// newPublisher creates the client of an exporter; it can be overridden in
+// tests
+var newPublisher = func(cfg *exporterConfig) (publisher, error) {
+	switch cfg.Exporter {
+	case ExporterKafka:
+		return newKafkaPublisher(cfg)
+	case ExporterNATS:
+		return newNATSPublisher(cfg)
+	case ExporterWebhook:
+		return newWebhookPublisher(cfg), nil
+	}
+	return nil, fmt.Errorf("unsupported exporter %q", cfg.Exporter)
+}
+
+type sinkOperator struct {
+	exporters map[string]*exporter
+}
+
+func (o *sinkOperator) Name() string {
+	return name
+}
+
+func (o *sinkOperator) Init(params *params.Params) error {
+	o.exporters = make(map[string]*exporter)
+
+	if config.Config == nil {
+		return nil
+	}
+
+	configs := make(map[string]*exporterConfig)
+	log.Debugf("loading sink exporters")
+	err := config.Config.UnmarshalKey("operator.sink.exporters", &configs)
+	if err != nil {
+		log.Warnf("failed to load operator.sink.exporters: %v", err)
+	}
+	return o.loadExporters(configs)
+}
+
+func (o *sinkOperator) loadExporters(configs map[string]*exporterConfig) error {
+	for k, cfg := range configs {
+		cfg.setDefaults()
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("sink exporter %q: %w", k, err)
+		}
+		pub, err := newPublisher(cfg)
+		if err != nil {
+			return fmt.Errorf("creating sink exporter %q: %w", k, err)
+		}
+		o.exporters[k] = &exporter{cfg: cfg, pub: pub}
+		log.Debugf("> sink exporter %q of type %q loaded", k, cfg.Exporter)
+	}
+	return nil
+}
+
+func (o *sinkOperator) GlobalParams() api.Params {
+	return api.Params{}
+}
+
+func (o *sinkOperator) InstanceParams() api.Params {
+	return api.Params{
+		&api.Param{
+			Key: ParamSinkExporter,
+			Description: "Exporter from the operator.sink.exporters config to publish events to. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','.",
+			DefaultValue: "",
+		},
+	}
+}
+
+func (o *sinkOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	if len(o.exporters) == 0 {
+		return nil, nil
+	}
+	mappings, err := apihelpers.GetStringValuesPerDataSource(instanceParamValues[ParamSinkExporter])
+	if err != nil {
+		return nil, fmt.Errorf("parsing exporter mappings: %w", err)
+	}
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+	for _, exporterName := range mappings {
+		if _, ok := o.exporters[exporterName]; !ok {
+			return nil, fmt.Errorf("sink exporter not found: %q", exporterName)
+		}
+	}
+	return &sinkOperatorInstance{
+		o:        o,
+		mappings: mappings,
+	}, nil
+}
+
+func (o *sinkOperator) Priority() int {
+	return Priority
+}
+
+type sinkOperatorInstance struct {
+	o        *sinkOperator
+	mappings map[string]string
+	batchers []*batcher
+}
+
+func (o *sinkOperatorInstance) Name() string {
+	return name
+}
+
+func (o *sinkOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	dataSources := make([]datasource.DataSource, 0)
+	for _, ds := range gadgetCtx.GetDataSources() {
+		dataSources = append(dataSources, ds)
+	}
+	sort.Slice(dataSources, func(i, j int) bool {
+		return dataSources[i].Name() < dataSources[j].Name()
+	})
+
+	for _, ds := range dataSources {
+		exporterName, ok := o.mappings[ds.Name()]
+		if !ok {
+			exporterName, ok = o.mappings[""]
+			if !ok {
+				continue
+			}
+		}
+		exp := o.o.exporters[exporterName]
+
+		destination := strings.NewReplacer(
+			DataSourcePlaceholder, ds.Name(),
+			GadgetPlaceholder, common.ImageShortName(gadgetCtx.ImageName()),
+			InstancePlaceholder, gadgetCtx.ID(),
+		).Replace(exp.cfg.destination(ds.Name()))
+		if destination == "" {
+			return fmt.Errorf("sink exporter %q: no destination for data source %q", exporterName, ds.Name())
+		}
+
+		headers := map[string]string{
+			HeaderGadget:     gadgetCtx.ImageName(),
+			HeaderDataSource: ds.Name(),
+		}
+		if gadgetCtx.ID() != "" {
+			headers[HeaderInstance] = gadgetCtx.ID()
+		}
+
+		b := newBatcher(exp.pub, destination, exp.cfg, gadgetCtx.Logger())
+		o.batchers = append(o.batchers, b)
+
+		gadgetCtx.Logger().Debugf("sink: publishing %q to %q using exporter %q", ds.Name(), destination, exporterName)
+
+		var err error
+		switch exp.cfg.Format {
+		case FormatProtobuf:
+			err = subscribeProtobuf(gadgetCtx, ds, b, headers)
+		default:
+			err = subscribeJSON(ds, b, headers)
+		}
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (o *sinkOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *sinkOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return o.close()
+}
+
+func (o *sinkOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	return o.close()
+}
+
+// close flushes all pending events
+func (o *sinkOperatorInstance) close() error {
+	for _, b := range o.batchers {
+		b.close()
+	}
+	o.batchers = nil
+	return nil
+}
+
+var Operator = &sinkOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
@@ -0,0 +1,492 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+// fakePublisher is an in-process stand-in for a broker; it records all
+// published batches and fails the first failures calls to publish
+type fakePublisher struct {
+	mu       sync.Mutex
+	batches  map[string][][]message
+	calls    int
+	failures int
+	err      error
+}
+
+func newFakePublisher() *fakePublisher {
+	return &fakePublisher{batches: make(map[string][][]message)}
+}
+
+func (p *fakePublisher) publish(ctx context.Context, destination string, msgs []message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls <= p.failures {
+		return p.err
+	}
+	p.batches[destination] = append(p.batches[destination], msgs)
+	return nil
+}
+
+func (p *fakePublisher) close() error {
+	return nil
+}
+
+func (p *fakePublisher) messages(destination string) []message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var res []message
+	for _, batch := range p.batches[destination] {
+		res = append(res, batch...)
+	}
+	return res
+}
+
+func testConfig(cfg exporterConfig) *exporterConfig {
+	cfg.setDefaults()
+	return &cfg
+}
+
+func TestConfig(t *testing.T) {
+	cfg := testConfig(exporterConfig{Exporter: ExporterKafka, Brokers: []string{"localhost:9092"}, Topic: "events"})
+	require.NoError(t, cfg.validate())
+	assert.Equal(t, FormatJSON, cfg.Format)
+	assert.Equal(t, OverflowBlock, cfg.Overflow)
+	assert.Equal(t, 5, cfg.MaxRetries)
+
+	cfg.Routes = map[string]string{"exec": "exec-events"}
+	assert.Equal(t, "exec-events", cfg.destination("exec"))
+	assert.Equal(t, "events", cfg.destination("open"))
+
+	noRetries := testConfig(exporterConfig{MaxRetries: -1})
+	assert.Equal(t, 0, noRetries.MaxRetries)
+
+	invalid := map[string]exporterConfig{
+		"unknown exporter": {Exporter: "foo"},
+		"unknown format":   {Exporter: ExporterWebhook, URL: "http://localhost", Format: "foo"},
+		"unknown overflow": {Exporter: ExporterWebhook, URL: "http://localhost", Overflow: "foo"},
+		"no brokers":       {Exporter: ExporterKafka, Topic: "events"},
+		"no nats url":      {Exporter: ExporterNATS, Subject: "events"},
+		"no webhook url":   {Exporter: ExporterWebhook},
+	}
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, testConfig(cfg).validate())
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	orig := config.Config
+	t.Cleanup(func() { config.Config = orig })
+	config.Config = config.NewWithPath("")
+	require.NoError(t, config.Config.ReadConfig(strings.NewReader(`
+operator:
+  sink:
+    exporters:
+      my-webhook:
+        exporter: webhook
+        url: http://localhost/events
+        format: protobuf
+        timeout: 5s
+        batchSize: 10
+        batchTimeout: 100ms
+        overflow: drop
+        headers:
+          Authorization: Bearer token
+        routes:
+          exec: http://localhost/exec
+`)))
+
+	op := &sinkOperator{}
+	require.NoError(t, op.Init(nil))
+	require.Contains(t, op.exporters, "my-webhook")
+
+	cfg := op.exporters["my-webhook"].cfg
+	assert.Equal(t, FormatProtobuf, cfg.Format)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, 10, cfg.BatchSize)
+	assert.Equal(t, 100*time.Millisecond, cfg.BatchTimeout)
+	assert.Equal(t, OverflowDrop, cfg.Overflow)
+	assert.Equal(t, "Bearer token", cfg.Headers["authorization"])
+	assert.Equal(t, "http://localhost/exec", cfg.destination("exec"))
+}
+
+func TestBatcher(t *testing.T) {
+	pub := newFakePublisher()
+	b := newBatcher(pub, "events", testConfig(exporterConfig{
+		BatchSize:    3,
+		BatchTimeout: time.Hour,
+	}), logger.DefaultLogger())
+
+	for i := 0; i < 7; i++ {
+		b.add(message{value: []byte{byte(i)}})
+	}
+	require.Eventually(t, func() bool {
+		return len(pub.messages("events")) == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Pending messages are flushed on close
+	b.close()
+	assert.Len(t, pub.messages("events"), 7)
+	for i, batch := range pub.batches["events"] {
+		if i < 2 {
+			assert.Len(t, batch, 3)
+		}
+	}
+
+	// Messages added after close are ignored
+	b.add(message{value: []byte{0}})
+	assert.Len(t, pub.messages("events"), 7)
+}
+
+func TestBatcherTimeout(t *testing.T) {
+	pub := newFakePublisher()
+	b := newBatcher(pub, "events", testConfig(exporterConfig{
+		BatchSize:    100,
+		BatchTimeout: 10 * time.Millisecond,
+	}), logger.DefaultLogger())
+	defer b.close()
+
+	b.add(message{value: []byte("a")})
+	require.Eventually(t, func() bool {
+		return len(pub.messages("events")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestBatcherRetries(t *testing.T) {
+	testCases := map[string]struct {
+		failures   int
+		maxRetries int
+		err        error
+		published  int
+		calls      int
+	}{
+		"retried": {
+			failures:   2,
+			maxRetries: 5,
+			err:        errors.New("unavailable"),
+			published:  1,
+			calls:      3,
+		},
+		"too many failures": {
+			failures:   10,
+			maxRetries: 2,
+			err:        errors.New("unavailable"),
+			published:  0,
+			calls:      3,
+		},
+		"permanent": {
+			failures:   10,
+			maxRetries: 5,
+			err:        &permanentError{errors.New("rejected")},
+			published:  0,
+			calls:      1,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pub := newFakePublisher()
+			pub.failures = tc.failures
+			pub.err = tc.err
+			b := newBatcher(pub, "events", testConfig(exporterConfig{
+				BatchSize:    1,
+				MaxRetries:   tc.maxRetries,
+				RetryBackoff: time.Millisecond,
+			}), logger.DefaultLogger())
+
+			b.add(message{value: []byte("a")})
+			b.close()
+
+			assert.Len(t, pub.messages("events"), tc.published)
+			assert.Equal(t, tc.calls, pub.calls)
+		})
+	}
+}
+
+// blockingPublisher blocks until unblock is closed
+type blockingPublisher struct {
+	*fakePublisher
+	unblock chan struct{}
+}
+
+func (p *blockingPublisher) publish(ctx context.Context, destination string, msgs []message) error {
+	select {
+	case <-p.unblock:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.fakePublisher.publish(ctx, destination, msgs)
+}
+
+func TestBatcherOverflow(t *testing.T) {
+	t.Run("drop", func(t *testing.T) {
+		pub := &blockingPublisher{fakePublisher: newFakePublisher(), unblock: make(chan struct{})}
+		b := newBatcher(pub, "events", testConfig(exporterConfig{
+			BatchSize:  1,
+			BufferSize: 2,
+			Overflow:   OverflowDrop,
+		}), logger.DefaultLogger())
+
+		// The first message is taken by the publishing goroutine, the next two
+		// fill the buffer and the rest are dropped without blocking
+		for i := 0; i < 10; i++ {
+			b.add(message{value: []byte{byte(i)}})
+		}
+		assert.Positive(t, b.dropped.Load())
+
+		close(pub.unblock)
+		b.close()
+		assert.Less(t, len(pub.messages("events")), 10)
+	})
+	t.Run("block", func(t *testing.T) {
+		pub := &blockingPublisher{fakePublisher: newFakePublisher(), unblock: make(chan struct{})}
+		b := newBatcher(pub, "events", testConfig(exporterConfig{
+			BatchSize:  1,
+			BufferSize: 2,
+		}), logger.DefaultLogger())
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 10; i++ {
+				b.add(message{value: []byte{byte(i)}})
+			}
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("add didn't block with a full buffer")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(pub.unblock)
+		<-done
+		b.close()
+		assert.Len(t, pub.messages("events"), 10)
+		assert.Zero(t, b.dropped.Load())
+	})
+}
+
+// publishEvents runs op together with a producer emitting two events to each
+// of the given data sources. Every event contains the name of its data source
+// and its position, so tests can check that it reached the right destination
+// in the right order.
+func publishEvents(t *testing.T, op operators.DataOperator, dsType datasource.Type, paramValues api.ParamValues, dsNames ...string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sources := make(map[datasource.DataSource][2]datasource.FieldAccessor)
+	emit := func(ds datasource.DataSource, data datasource.Data, seq uint32) {
+		fields := sources[ds]
+		require.NoError(t, fields[0].PutString(data, ds.Name()))
+		require.NoError(t, fields[1].PutUint32(data, seq))
+	}
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			for _, name := range dsNames {
+				ds, err := gadgetCtx.RegisterDataSource(dsType, name)
+				require.NoError(t, err)
+				source, err := ds.AddField("source", api.Kind_String)
+				require.NoError(t, err)
+				seq, err := ds.AddField("seq", api.Kind_Uint32)
+				require.NoError(t, err)
+				sources[ds] = [2]datasource.FieldAccessor{source, seq}
+			}
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			defer cancel()
+			for ds := range sources {
+				if dsType == datasource.TypeArray {
+					arr, err := ds.NewPacketArray()
+					require.NoError(t, err)
+					for seq := uint32(1); seq <= 2; seq++ {
+						data := arr.New()
+						emit(ds, data, seq)
+						arr.Append(data)
+					}
+					require.NoError(t, ds.EmitAndRelease(arr))
+					continue
+				}
+				for seq := uint32(1); seq <= 2; seq++ {
+					data, err := ds.NewPacketSingle()
+					require.NoError(t, err)
+					emit(ds, data, seq)
+					require.NoError(t, ds.EmitAndRelease(data))
+				}
+			}
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "",
+		gadgetcontext.WithDataOperators(op, producer),
+		gadgetcontext.WithTimeout(5*time.Second),
+	)
+	return gadgetCtx.Run(paramValues)
+}
+
+// newTestOperator returns a sink operator whose exporters publish to in-process
+// stand-ins
+func newTestOperator(t *testing.T, configs map[string]*exporterConfig) (*sinkOperator, map[string]*fakePublisher) {
+	pubs := make(map[string]*fakePublisher)
+	orig := newPublisher
+	t.Cleanup(func() { newPublisher = orig })
+	newPublisher = func(cfg *exporterConfig) (publisher, error) {
+		pub := newFakePublisher()
+		pubs[cfg.Exporter] = pub
+		return pub, nil
+	}
+
+	op := &sinkOperator{exporters: make(map[string]*exporter)}
+	require.NoError(t, op.loadExporters(configs))
+	return op, pubs
+}
+
+func TestSinkOperator(t *testing.T) {
+	op, pubs := newTestOperator(t, map[string]*exporterConfig{
+		"kafka": {
+			Exporter: ExporterKafka,
+			Brokers:  []string{"localhost:9092"},
+			Topic:    "ig-" + DataSourcePlaceholder,
+		},
+		"nats": {
+			Exporter: ExporterNATS,
+			URL:      "nats://localhost:4222",
+			Subject:  "ig.events",
+			Routes:   map[string]string{"exec": "ig.exec"},
+		},
+	})
+
+	err := publishEvents(t, op, datasource.TypeSingle, api.ParamValues{
+		"operator.sink.sink-exporter": "open:kafka,exec:nats",
+	}, "open", "exec", "other")
+	require.NoError(t, err)
+
+	msgs := pubs[ExporterKafka].messages("ig-open")
+	require.Len(t, msgs, 2)
+	assert.JSONEq(t, `{"source":"open","seq":1}`, string(msgs[0].value))
+	assert.JSONEq(t, `{"source":"open","seq":2}`, string(msgs[1].value))
+	assert.Equal(t, ContentTypeJSON, msgs[0].headers[HeaderContentType])
+	assert.Equal(t, "open", msgs[0].headers[HeaderDataSource])
+
+	msgs = pubs[ExporterNATS].messages("ig.exec")
+	require.Len(t, msgs, 2)
+	assert.JSONEq(t, `{"source":"exec","seq":1}`, string(msgs[0].value))
+	assert.Equal(t, "exec", msgs[0].headers[HeaderDataSource])
+
+	assert.Empty(t, pubs[ExporterNATS].messages("ig.events"))
+}
+
+func TestSinkOperatorArray(t *testing.T) {
+	op, pubs := newTestOperator(t, map[string]*exporterConfig{
+		"webhook": {
+			Exporter: ExporterWebhook,
+			URL:      "http://localhost/events",
+		},
+	})
+
+	err := publishEvents(t, op, datasource.TypeArray, api.ParamValues{
+		"operator.sink.sink-exporter": "webhook",
+	}, "snapshot")
+	require.NoError(t, err)
+
+	msgs := pubs[ExporterWebhook].messages("http://localhost/events")
+	require.Len(t, msgs, 1)
+	assert.JSONEq(t, `[{"source":"snapshot","seq":1},{"source":"snapshot","seq":2}]`, string(msgs[0].value))
+}
+
+func TestSinkOperatorProtobuf(t *testing.T) {
+	op, pubs := newTestOperator(t, map[string]*exporterConfig{
+		"kafka": {
+			Exporter: ExporterKafka,
+			Brokers:  []string{"localhost:9092"},
+			Topic:    "events",
+			Format:   FormatProtobuf,
+		},
+	})
+
+	err := publishEvents(t, op, datasource.TypeSingle, api.ParamValues{
+		"operator.sink.sink-exporter": "kafka",
+	}, "open")
+	require.NoError(t, err)
+
+	msgs := pubs[ExporterKafka].messages("events")
+	require.Len(t, msgs, 3)
+
+	ev := &api.GadgetEvent{}
+	require.NoError(t, proto.Unmarshal(msgs[0].value, ev))
+	require.Equal(t, api.EventTypeGadgetInfo, ev.Type)
+	gi := &api.GadgetInfo{}
+	require.NoError(t, proto.Unmarshal(ev.Payload, gi))
+	require.Len(t, gi.DataSources, 1)
+	assert.Equal(t, "open", gi.DataSources[0].Name)
+
+	for i, msg := range msgs[1:] {
+		ev := &api.GadgetEvent{}
+		require.NoError(t, proto.Unmarshal(msg.value, ev))
+		assert.Equal(t, api.EventTypeGadgetPayload, ev.Type)
+		assert.Equal(t, uint32(i+1), ev.Seq)
+		assert.Equal(t, ContentTypeProtobuf, msg.headers[HeaderContentType])
+
+		packet := &api.GadgetData{}
+		require.NoError(t, proto.Unmarshal(ev.Payload, packet))
+	}
+}
+
+func TestSinkOperatorErrors(t *testing.T) {
+	op, _ := newTestOperator(t, map[string]*exporterConfig{
+		"webhook": {
+			Exporter: ExporterWebhook,
+			URL:      "http://localhost/events",
+		},
+	})
+
+	err := publishEvents(t, op, datasource.TypeSingle, api.ParamValues{
+		"operator.sink.sink-exporter": "foo",
+	}, "open")
+	require.Error(t, err)
+
+	_, err = op.InstantiateDataOperator(nil, api.ParamValues{})
+	require.NoError(t, err)
+
+	err = (&sinkOperator{exporters: make(map[string]*exporter)}).loadExporters(map[string]*exporterConfig{
+		"invalid": {Exporter: ExporterKafka},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// webhookPublisher sends each batch as a single POST request. JSON messages are
+// sent as a JSON array, protobuf messages as a sequence of length-delimited
+// messages.
+type webhookPublisher struct {
+	client  *http.Client
+	headers map[string]string
+}
+
+func newWebhookPublisher(cfg *exporterConfig) *webhookPublisher {
+	return &webhookPublisher{
+		client:  &http.Client{Timeout: cfg.Timeout},
+		headers: cfg.Headers,
+	}
+}
+
+func webhookBody(msgs []message) []byte {
+	var body bytes.Buffer
+	if msgs[0].headers[HeaderContentType] == ContentTypeProtobuf {
+		for _, msg := range msgs {
+			body.Write(protowire.AppendVarint(nil, uint64(len(msg.value))))
+			body.Write(msg.value)
+		}
+		return body.Bytes()
+	}
+
+	body.WriteByte('[')
+	for i, msg := range msgs {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(msg.value)
+	}
+	body.WriteByte(']')
+	return body.Bytes()
+}
+
+func (p *webhookPublisher) publish(ctx context.Context, url string, msgs []message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(webhookBody(msgs)))
+	if err != nil {
+		return &permanentError{err}
+	}
+
+	// Headers of the gadget are the same for all messages of a batch, except
+	// for the event type of protobuf messages, which is part of the payload
+	for k, v := range msgs[0].headers {
+		if k == HeaderEventType {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return &permanentError{fmt.Errorf("request rejected with status %q", resp.Status)}
+}
+
+func (p *webhookPublisher) close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer srv.Close()
+
+	pub := newWebhookPublisher(testConfig(exporterConfig{
+		Exporter: ExporterWebhook,
+		URL:      srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+	}))
+	defer pub.close()
+
+	headers := map[string]string{
+		HeaderContentType: ContentTypeJSON,
+		HeaderDataSource:  "exec",
+	}
+	err := pub.publish(context.Background(), srv.URL, []message{
+		{value: []byte(`{"pid":1}`), headers: headers},
+		{value: []byte(`{"pid":2}`), headers: headers},
+	})
+	require.NoError(t, err)
+
+	var events []map[string]any
+	require.NoError(t, json.Unmarshal(body, &events))
+	assert.Equal(t, []map[string]any{{"pid": 1.0}, {"pid": 2.0}}, events)
+	assert.Equal(t, ContentTypeJSON, header.Get(HeaderContentType))
+	assert.Equal(t, "exec", header.Get(HeaderDataSource))
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+}
+
+func TestWebhookPublisherProtobuf(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	pub := newWebhookPublisher(testConfig(exporterConfig{Exporter: ExporterWebhook, URL: srv.URL}))
+	defer pub.close()
+
+	headers := map[string]string{HeaderContentType: ContentTypeProtobuf}
+	err := pub.publish(context.Background(), srv.URL, []message{
+		{value: []byte("foo"), headers: headers},
+		{value: []byte("barbaz"), headers: headers},
+	})
+	require.NoError(t, err)
+
+	var values []string
+	for len(body) > 0 {
+		v, n := protowire.ConsumeBytes(body)
+		require.Positive(t, n)
+		values = append(values, string(v))
+		body = body[n:]
+	}
+	assert.Equal(t, []string{"foo", "barbaz"}, values)
+}
+
+func TestWebhookPublisherErrors(t *testing.T) {
+	testCases := map[string]struct {
+		status    int
+		permanent bool
+	}{
+		"server error":      {status: http.StatusServiceUnavailable},
+		"too many requests": {status: http.StatusTooManyRequests},
+		"bad request":       {status: http.StatusBadRequest, permanent: true},
+		"unauthorized":      {status: http.StatusUnauthorized, permanent: true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			pub := newWebhookPublisher(testConfig(exporterConfig{Exporter: ExporterWebhook, URL: srv.URL}))
+			defer pub.close()
+
+			err := pub.publish(context.Background(), srv.URL, []message{{value: []byte("{}")}})
+			require.Error(t, err)
+			var permanent *permanentError
+			assert.Equal(t, tc.permanent, errors.As(err, &permanent))
+		})
+	}
+}
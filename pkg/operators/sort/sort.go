@@ -19,6 +19,7 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync/atomic"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
@@ -62,6 +63,7 @@ func (s *sortOperator) InstanceParams() api.Params {
 			Title: "Sort By",
 			Description: "Sort by fields. Join multiple fields with ','. Prefix a field with '-' to sort in descending order. " +
 				"If using multiple data sources, prefix fields with 'datasourcename:' and separate with ';'",
+			Tags: []string{api.TagLiveUpdate},
 		},
 	}
 }
@@ -91,9 +93,18 @@ func (s *sortOperator) Priority() int {
 	return Priority
 }
 
+// sorters holds the compare functions per data source
+type sorters map[datasource.DataSource][]func(i, j datasource.Data) bool
+
 type sortOperatorInstance struct {
-	sortBy  string
-	sorters map[datasource.DataSource][]func(i, j datasource.Data) bool
+	sortBy string
+
+	// sorters is replaced as a whole when params are updated while running
+	sorters atomic.Pointer[sorters]
+
+	// subscribed holds the data sources subscribed to; only data sources that
+	// are sorted are subscribed to, further ones when params are updated
+	subscribed map[datasource.DataSource]struct{}
 }
 
 func getCompareFunc(f datasource.FieldAccessor, negate bool) func(i, j datasource.Data) bool {
@@ -172,10 +183,10 @@ func getCompareFunc(f datasource.FieldAccessor, negate bool) func(i, j datasourc
 	}
 }
 
-func (s *sortOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
-	s.sorters = make(map[datasource.DataSource][]func(i datasource.Data, j datasource.Data) bool)
+func newSorters(gadgetCtx operators.GadgetContext, sortBy string) (sorters, error) {
+	res := make(sorters)
 	dsSorts := make(map[string][]string)
-	for _, srt := range strings.Split(s.sortBy, ";") {
+	for _, srt := range strings.Split(sortBy, ";") {
 		dsFields := strings.Split(srt, ":")
 		dsName := ""
 		fieldList := dsFields[0]
@@ -193,7 +204,7 @@ func (s *sortOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
 	dsSpecific := true
 	if _, ok := dsSorts[""]; ok {
 		if len(dsSorts) > 1 {
-			return fmt.Errorf("mixing sorting rules with and without specifying data source")
+			return nil, fmt.Errorf("mixing sorting rules with and without specifying data source")
 		}
 		dsSpecific = false
 	}
@@ -209,7 +220,7 @@ func (s *sortOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
 		}
 
 		if ds.Type() != datasource.TypeArray {
-			return fmt.Errorf("sort can only be used on array data sources")
+			return nil, fmt.Errorf("sort can only be used on array data sources")
 		}
 
 		var sortFuncs []func(i, j datasource.Data) bool
@@ -218,20 +229,20 @@ func (s *sortOperatorInstance) init(gadgetCtx operators.GadgetContext) error {
 
 			field := ds.GetField(fieldName)
 			if field == nil {
-				return fmt.Errorf("field %s not found", fieldName)
+				return nil, fmt.Errorf("field %s not found", fieldName)
 			}
 
 			cmp := getCompareFunc(field, negate)
 			if cmp == nil {
-				return fmt.Errorf("field %s cannot be used for sorting", fieldName)
+				return nil, fmt.Errorf("field %s cannot be used for sorting", fieldName)
 			}
 			sortFuncs = append(sortFuncs, cmp)
 		}
 
 		slices.Reverse(sortFuncs)
-		s.sorters[ds] = sortFuncs
+		res[ds] = sortFuncs
 	}
-	return nil
+	return res, nil
 }
 
 func (s *sortOperatorInstance) Name() string {
@@ -239,21 +250,54 @@ func (s *sortOperatorInstance) Name() string {
 }
 
 func (s *sortOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
-	err := s.init(gadgetCtx)
+	srts, err := newSorters(gadgetCtx, s.sortBy)
 	if err != nil {
 		return err
 	}
-	for ds, fns := range s.sorters {
-		ds.SubscribeArray(func(ds datasource.DataSource, data datasource.DataArray) error {
-			for _, s := range fns {
-				sort.Stable(&arrSort{DataArray: data, fn: s})
+	s.sorters.Store(&srts)
+	return s.subscribe(srts)
+}
+
+// subscribe subscribes to the data sources of srts that haven't been
+// subscribed to yet. Subscriptions are kept if the sorting of a data source is
+// removed later on.
+func (s *sortOperatorInstance) subscribe(srts sorters) error {
+	if s.subscribed == nil {
+		s.subscribed = make(map[datasource.DataSource]struct{})
+	}
+	for ds := range srts {
+		if _, ok := s.subscribed[ds]; ok {
+			continue
+		}
+		err := ds.SubscribeArray(func(ds datasource.DataSource, data datasource.DataArray) error {
+			for _, fn := range (*s.sorters.Load())[ds] {
+				sort.Stable(&arrSort{DataArray: data, fn: fn})
 			}
 			return nil
 		}, Priority)
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+		s.subscribed[ds] = struct{}{}
 	}
 	return nil
 }
 
+// UpdateParams changes the sorting of a running gadget; the previous sorting is kept if the new one is invalid
+func (s *sortOperatorInstance) UpdateParams(gadgetCtx operators.GadgetContext, paramValues api.ParamValues) error {
+	sortBy, ok := paramValues[ParamSortBy]
+	if !ok {
+		return nil
+	}
+	srts, err := newSorters(gadgetCtx, sortBy)
+	if err != nil {
+		return err
+	}
+	s.sortBy = sortBy
+	s.sorters.Store(&srts)
+	return s.subscribe(srts)
+}
+
 func (s *sortOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
 	return nil
 }
@@ -0,0 +1,99 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// conn is a connection to a syslog server or journald that is shared by all
+// gadget instances using the same exporter. It is established on the first
+// write and re-established if writing fails, so the receiver doesn't need to
+// be available when ig starts.
+type conn struct {
+	network string
+	address string
+	timeout time.Duration
+
+	mu sync.Mutex
+	c  net.Conn
+}
+
+func newConn(network, address string, timeout time.Duration) *conn {
+	return &conn{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}
+}
+
+// stream returns whether messages need to be framed as they are sent over a
+// stream-oriented connection
+func (c *conn) stream() bool {
+	return c.network == "tcp" || c.network == "tcp4" || c.network == "tcp6" || c.network == "unix"
+}
+
+func (c *conn) writeLocked(msg []byte) error {
+	if c.c == nil {
+		nc, err := net.DialTimeout(c.network, c.address, c.timeout)
+		if err != nil {
+			return fmt.Errorf("connecting to %s %q: %w", c.network, c.address, err)
+		}
+		c.c = nc
+	}
+
+	if c.stream() {
+		// Octet counting as described in RFC 6587
+		msg = append(strconv.AppendInt(nil, int64(len(msg)), 10), append([]byte{' '}, msg...)...)
+	}
+
+	c.c.SetWriteDeadline(time.Now().Add(c.timeout))
+	_, err := c.c.Write(msg)
+	if err != nil {
+		c.c.Close()
+		c.c = nil
+		return fmt.Errorf("writing to %s %q: %w", c.network, c.address, err)
+	}
+	return nil
+}
+
+// write sends msg, reconnecting once if the connection was lost
+func (c *conn) write(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wasConnected := c.c != nil
+	err := c.writeLocked(msg)
+	if err != nil && wasConnected {
+		err = c.writeLocked(msg)
+	}
+	return err
+}
+
+func (c *conn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.c == nil {
+		return nil
+	}
+	err := c.c.Close()
+	c.c = nil
+	return err
+}
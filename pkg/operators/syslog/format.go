@@ -0,0 +1,264 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var facilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"ntp":      12,
+	"security": 13,
+	"console":  14,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+var severities = map[string]int{
+	"emerg":   0,
+	"alert":   1,
+	"crit":    2,
+	"err":     3,
+	"error":   3,
+	"warning": 4,
+	"warn":    4,
+	"notice":  5,
+	"info":    6,
+	"debug":   7,
+}
+
+const (
+	maxFacility = 23
+	maxSeverity = 7
+
+	// SeverityInfo is used if no severity is set for a data source
+	SeverityInfo = 6
+)
+
+func parseLevel(s string, names map[string]int, maxLevel int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 || v > maxLevel {
+		return 0, fmt.Errorf("expected a name or a number between 0 and %d, got %q", maxLevel, s)
+	}
+	return v, nil
+}
+
+// parseFacility parses a facility given by name (e.g. "local0") or number
+func parseFacility(s string) (int, error) {
+	return parseLevel(s, facilities, maxFacility)
+}
+
+// parseSeverity parses a severity given by name (e.g. "warning") or number
+func parseSeverity(s string) (int, error) {
+	return parseLevel(s, severities, maxSeverity)
+}
+
+// clampSeverity makes sure severities taken from fields are valid
+func clampSeverity(v int64) int {
+	return int(min(max(v, 0), maxSeverity))
+}
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+// entry is a single event that's written to syslog or journald
+type entry struct {
+	timestamp time.Time
+	severity  int
+	body      string
+	// meta holds information about the gadget like its data source
+	meta []keyValue
+	// fields holds the fields of the event
+	fields []keyValue
+}
+
+// rfc5424Header is the part of RFC 5424 messages that's the same for all
+// messages of a data source
+type rfc5424Header struct {
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	msgID    string
+	// sdID is the ID of the structured data element holding the fields; no
+	// structured data is added if it's empty
+	sdID string
+}
+
+// headerValue returns s as a valid RFC 5424 header field with the given
+// maximum length: only printable US-ASCII without spaces is allowed and empty
+// values are replaced by the nil value
+func headerValue(s string, maxLen int) string {
+	if s == "" {
+		return "-"
+	}
+	b := []byte(s)
+	if len(b) > maxLen {
+		b = b[:maxLen]
+	}
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// sdParamName returns name as a valid RFC 5424 SD-NAME
+func sdParamName(name string) string {
+	b := []byte(headerValue(name, 32))
+	for i, c := range b {
+		if c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+var sdParamValueReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	`]`, `\]`,
+)
+
+func newRFC5424Header(facility int, hostname, appName, procID, msgID, sdID string) *rfc5424Header {
+	return &rfc5424Header{
+		facility: facility,
+		hostname: headerValue(hostname, 255),
+		appName:  headerValue(appName, 48),
+		procID:   headerValue(procID, 128),
+		msgID:    headerValue(msgID, 32),
+		sdID:     sdID,
+	}
+}
+
+// format returns e as RFC 5424 message
+func (h *rfc5424Header) format(e *entry) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<%d>1 %s %s %s %s %s ",
+		h.facility*8+e.severity,
+		e.timestamp.Format("2006-01-02T15:04:05.000000Z07:00"),
+		h.hostname, h.appName, h.procID, h.msgID,
+	)
+
+	if h.sdID == "" {
+		sb.WriteString("-")
+	} else {
+		sb.WriteString("[")
+		sb.WriteString(h.sdID)
+		for _, kvs := range [][]keyValue{e.meta, e.fields} {
+			for _, kv := range kvs {
+				sb.WriteString(" ")
+				sb.WriteString(sdParamName(kv.key))
+				sb.WriteString(`="`)
+				sb.WriteString(sdParamValueReplacer.Replace(kv.value))
+				sb.WriteString(`"`)
+			}
+		}
+		sb.WriteString("]")
+	}
+
+	if e.body != "" {
+		sb.WriteString(" ")
+		sb.WriteString(e.body)
+	}
+	return []byte(sb.String())
+}
+
+// journaldFieldName returns name as a valid journald field name: upper case
+// letters, digits and underscores, not starting with an underscore or digit and
+// at most 64 characters long
+func journaldFieldName(prefix, name string) string {
+	b := []byte(strings.ToUpper(prefix + name))
+	if len(b) > 64 {
+		b = b[:64]
+	}
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || b[0] == '_' || (b[0] >= '0' && b[0] <= '9') {
+		return journaldFieldName("F", string(b))
+	}
+	return string(b)
+}
+
+func appendJournaldField(b []byte, name, value string) []byte {
+	if !strings.ContainsRune(value, '\n') {
+		b = append(b, name...)
+		b = append(b, '=')
+		b = append(b, value...)
+		return append(b, '\n')
+	}
+
+	// Values containing newlines are serialized as binary with their size
+	b = append(b, name...)
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// journaldHeader holds the journald fields that are the same for all entries
+// of a data source
+type journaldHeader struct {
+	facility   int
+	identifier string
+}
+
+// format returns e serialized using the native journald protocol. Fields of
+// the event are prefixed with FieldPrefix to avoid conflicts with the fields
+// defined by journald.
+func (h *journaldHeader) format(e *entry) []byte {
+	b := make([]byte, 0, 256)
+	b = appendJournaldField(b, "MESSAGE", e.body)
+	b = appendJournaldField(b, "PRIORITY", strconv.Itoa(e.severity))
+	b = appendJournaldField(b, "SYSLOG_FACILITY", strconv.Itoa(h.facility))
+	b = appendJournaldField(b, "SYSLOG_IDENTIFIER", h.identifier)
+	for _, kvs := range [][]keyValue{e.meta, e.fields} {
+		for _, kv := range kvs {
+			b = appendJournaldField(b, journaldFieldName(FieldPrefix, kv.key), kv.value)
+		}
+	}
+	return b
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevels(t *testing.T) {
+	v, err := parseFacility("local3")
+	require.NoError(t, err)
+	assert.Equal(t, 19, v)
+	v, err = parseFacility("4")
+	require.NoError(t, err)
+	assert.Equal(t, 4, v)
+	_, err = parseFacility("24")
+	require.Error(t, err)
+	_, err = parseFacility("foo")
+	require.Error(t, err)
+
+	v, err = parseSeverity("WARNING")
+	require.NoError(t, err)
+	assert.Equal(t, 4, v)
+	v, err = parseSeverity("err")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+	_, err = parseSeverity("8")
+	require.Error(t, err)
+
+	assert.Equal(t, 0, clampSeverity(-1))
+	assert.Equal(t, 7, clampSeverity(100))
+}
+
+var testEntry = &entry{
+	timestamp: time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC),
+	severity:  4,
+	body:      "file opened",
+	meta:      []keyValue{{key: "datasource", value: "open"}},
+	fields: []keyValue{
+		{key: "comm", value: "cat"},
+		{key: "fname", value: `/tmp/"a]\b`},
+	},
+}
+
+func TestRFC5424(t *testing.T) {
+	h := newRFC5424Header(1, "my host", "trace_open", "42", "open", "")
+	assert.Equal(t, "<12>1 2025-01-02T03:04:05.000006Z my_host trace_open 42 open - file opened",
+		string(h.format(testEntry)))
+
+	h = newRFC5424Header(16, "", "", "42", "open", DefaultSDID)
+	assert.Equal(t,
+		`<132>1 2025-01-02T03:04:05.000006Z - - 42 open [ig@32473 datasource="open" comm="cat" fname="/tmp/\"a\]\\b"] file opened`,
+		string(h.format(testEntry)))
+}
+
+func TestHeaderValues(t *testing.T) {
+	assert.Equal(t, "-", headerValue("", 10))
+	assert.Equal(t, "abc", headerValue("abcdef", 3))
+	assert.Equal(t, "a_b", headerValue("a\tb", 10))
+	assert.Equal(t, "a_b_c", sdParamName(`a=b"c`))
+}
+
+func TestJournald(t *testing.T) {
+	assert.Equal(t, "IG_K8S_PODNAME", journaldFieldName(FieldPrefix, "k8s.podName"))
+	assert.Equal(t, "F_FOO", journaldFieldName("", "_foo"))
+	assert.Equal(t, "F1", journaldFieldName("", "1"))
+
+	h := &journaldHeader{facility: 3, identifier: "trace_open"}
+	assert.Equal(t, "MESSAGE=file opened\n"+
+		"PRIORITY=4\n"+
+		"SYSLOG_FACILITY=3\n"+
+		"SYSLOG_IDENTIFIER=trace_open\n"+
+		"IG_DATASOURCE=open\n"+
+		"IG_COMM=cat\n"+
+		"IG_FNAME=/tmp/\"a]\\b\n",
+		string(h.format(testEntry)))
+
+	multiline := appendJournaldField(nil, "MESSAGE", "a\nb")
+	expected := []byte("MESSAGE\n")
+	expected = binary.LittleEndian.AppendUint64(expected, 3)
+	expected = append(expected, "a\nb\n"...)
+	assert.Equal(t, expected, multiline)
+}
@@ -0,0 +1,471 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package syslog writes the events of data sources as RFC 5424 messages to a
+// syslog server or as structured entries to journald. Exporters are configured
+// in the operator.syslog.exporters section of the config file and are selected
+// per data source using the syslog-exporter parameter.
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/config"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/expr"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	apihelpers "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api-helpers"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/common"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	name = "syslog"
+
+	// Priority is after the operators filtering, deduplicating and rate
+	// limiting events, as every event left becomes an entry in the log of the
+	// host
+	Priority = 10000
+
+	ParamSyslogExporter = "syslog-exporter"
+
+	ExporterSyslog   = "syslog"
+	ExporterJournald = "journald"
+
+	// AnnotationSeverity sets a fixed severity for all events of a data source
+	AnnotationSeverity = "syslog.severity"
+	// AnnotationBody sets an expression used as message of the events of a data
+	// source
+	AnnotationBody = "syslog.body"
+	// AnnotationName renames a field or, if set to FieldNameBody or
+	// FieldNameSeverity, uses the field as message or severity
+	AnnotationName = "syslog.name"
+
+	FieldNameBody     = "body"
+	FieldNameSeverity = "severity"
+
+	// FieldPrefix is added to the names of journald fields holding the fields
+	// of events
+	FieldPrefix = "IG_"
+
+	DefaultSyslogNetwork   = "unixgram"
+	DefaultSyslogAddress   = "/dev/log"
+	DefaultJournaldAddress = "/run/systemd/journal/socket"
+	DefaultSDID            = "ig@32473"
+	DefaultBufferSize      = 1024
+)
+
+var supportedExporters = []string{ExporterSyslog, ExporterJournald}
+
+type exporterConfig struct {
+	Exporter string `json:"exporter" yaml:"exporter"`
+
+	// Network is one of udp, tcp, unix or unixgram; only used by the syslog
+	// exporter
+	Network string `json:"network" yaml:"network"`
+	// Address is host:port for udp and tcp or the path of the socket
+	Address string        `json:"address" yaml:"address"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// BufferSize is the number of messages queued while waiting to be written;
+	// further messages are dropped
+	BufferSize int `json:"bufferSize" yaml:"bufferSize"`
+
+	Facility string `json:"facility" yaml:"facility"`
+	// AppName defaults to the name of the gadget
+	AppName  string `json:"appName" yaml:"appName"`
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// StructuredData adds the fields of events as structured data with the
+	// ID StructuredDataID to syslog messages
+	StructuredData   bool   `json:"structuredData" yaml:"structuredData"`
+	StructuredDataID string `json:"structuredDataID" yaml:"structuredDataID"`
+}
+
+func (c *exporterConfig) setDefaults() {
+	switch c.Exporter {
+	case ExporterSyslog:
+		if c.Network == "" && c.Address == "" {
+			c.Network = DefaultSyslogNetwork
+			c.Address = DefaultSyslogAddress
+		} else if c.Network == "" {
+			c.Network = "udp"
+		}
+		if c.StructuredDataID == "" {
+			c.StructuredDataID = DefaultSDID
+		}
+	case ExporterJournald:
+		c.Network = "unixgram"
+		if c.Address == "" {
+			c.Address = DefaultJournaldAddress
+		}
+	}
+	if c.Facility == "" {
+		c.Facility = "user"
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = DefaultBufferSize
+	}
+	if c.Hostname == "" {
+		c.Hostname, _ = os.Hostname()
+	}
+}
+
+func (c *exporterConfig) validate() error {
+	if !slices.Contains(supportedExporters, c.Exporter) {
+		return fmt.Errorf("unsupported exporter %q; expected one of %s", c.Exporter,
+			strings.Join(supportedExporters, ", "))
+	}
+	switch c.Network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+	default:
+		return fmt.Errorf("unsupported network %q", c.Network)
+	}
+	if c.Address == "" {
+		return errors.New("address must be set")
+	}
+	if _, err := parseFacility(c.Facility); err != nil {
+		return fmt.Errorf("invalid facility: %w", err)
+	}
+	return nil
+}
+
+// exporter holds the configuration and the writer of an exporter; it is
+// shared by all gadget instances using it
+type exporter struct {
+	cfg      *exporterConfig
+	facility int
+	writer   *writer
+}
+
+type syslogOperator struct {
+	exporters map[string]*exporter
+}
+
+func (o *syslogOperator) Name() string {
+	return name
+}
+
+func (o *syslogOperator) Init(params *params.Params) error {
+	o.exporters = make(map[string]*exporter)
+
+	if config.Config == nil {
+		return nil
+	}
+
+	configs := make(map[string]*exporterConfig)
+	log.Debugf("loading syslog exporters")
+	err := config.Config.UnmarshalKey("operator.syslog.exporters", &configs)
+	if err != nil {
+		log.Warnf("failed to load operator.syslog.exporters: %v", err)
+	}
+	return o.loadExporters(configs)
+}
+
+func (o *syslogOperator) loadExporters(configs map[string]*exporterConfig) error {
+	for k, cfg := range configs {
+		cfg.setDefaults()
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("syslog exporter %q: %w", k, err)
+		}
+		facility, _ := parseFacility(cfg.Facility)
+		o.exporters[k] = &exporter{
+			cfg:      cfg,
+			facility: facility,
+			writer:   newWriter(k, newConn(cfg.Network, cfg.Address, cfg.Timeout), cfg.BufferSize),
+		}
+		log.Debugf("> syslog exporter %q of type %q with address %q loaded", k, cfg.Exporter, cfg.Address)
+	}
+	return nil
+}
+
+func (o *syslogOperator) GlobalParams() api.Params {
+	return api.Params{}
+}
+
+func (o *syslogOperator) InstanceParams() api.Params {
+	return api.Params{
+		&api.Param{
+			Key: ParamSyslogExporter,
+			Description: "Exporter from the operator.syslog.exporters config to write events to. " +
+				"If using multiple data sources, prefix the value with 'datasourcename:' and separate with ','.",
+			DefaultValue: "",
+		},
+	}
+}
+
+func (o *syslogOperator) InstantiateDataOperator(gadgetCtx operators.GadgetContext, instanceParamValues api.ParamValues) (operators.DataOperatorInstance, error) {
+	if len(o.exporters) == 0 {
+		return nil, nil
+	}
+	mappings, err := apihelpers.GetStringValuesPerDataSource(instanceParamValues[ParamSyslogExporter])
+	if err != nil {
+		return nil, fmt.Errorf("parsing exporter mappings: %w", err)
+	}
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+	for _, exporterName := range mappings {
+		if _, ok := o.exporters[exporterName]; !ok {
+			return nil, fmt.Errorf("syslog exporter not found: %q", exporterName)
+		}
+	}
+	return &syslogOperatorInstance{
+		o:        o,
+		mappings: mappings,
+	}, nil
+}
+
+func (o *syslogOperator) Priority() int {
+	return Priority
+}
+
+type syslogOperatorInstance struct {
+	o        *syslogOperator
+	mappings map[string]string
+	used     []*exporter
+}
+
+func (o *syslogOperatorInstance) Name() string {
+	return name
+}
+
+// entryFunc returns a function creating an entry for each event of the data
+// source. Fields visible by default and fields with the syslog.name annotation
+// are added to the entry. The timestamp of the entry is taken from the first
+// timestamp field of the data source, if any.
+func entryFunc(ds datasource.DataSource, meta []keyValue) (func(data datasource.Data) *entry, error) {
+	annotations := ds.Annotations()
+
+	timestamp := func(data datasource.Data) time.Time { return time.Now() }
+	if tsFields := ds.GetFieldsWithTag("type:" + ebpftypes.TimestampTypeName); len(tsFields) > 0 {
+		ts := tsFields[0]
+		timestamp = func(data datasource.Data) time.Time {
+			v, err := ts.Uint64(data)
+			if err != nil || v == 0 {
+				return time.Now()
+			}
+			return time.Unix(0, int64(v))
+		}
+	}
+
+	severity := func(data datasource.Data) int { return SeverityInfo }
+	if s, ok := annotations[AnnotationSeverity]; ok {
+		v, err := parseSeverity(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity annotation: %w", err)
+		}
+		severity = func(data datasource.Data) int { return v }
+	}
+
+	var body func(data datasource.Data) string
+	if bodyString, ok := annotations[AnnotationBody]; ok {
+		prog, err := expr.CompileStringProgram(ds, bodyString)
+		if err != nil {
+			return nil, fmt.Errorf("compiling expression %q: %w", bodyString, err)
+		}
+		body = func(data datasource.Data) string {
+			s, err := expr.Run(prog, data)
+			if err != nil {
+				return ""
+			}
+			return s.(string)
+		}
+	}
+
+	p, err := ds.Parser()
+	if err != nil {
+		return nil, fmt.Errorf("getting parser: %w", err)
+	}
+	cols, ok := p.GetColumns().(columns.ColumnMap[datasource.DataTuple])
+	if !ok {
+		return nil, fmt.Errorf("invalid columns: expected columns.ColumnMap[datasource.DataTuple], got %T", p.GetColumns())
+	}
+
+	type field struct {
+		name string
+		get  func(*datasource.DataTuple) string
+	}
+	var fields []field
+
+	for _, col := range cols.GetOrderedColumns() {
+		fieldName := col.Name
+		if f := ds.GetField(col.Name); f != nil {
+			if n, ok := f.Annotations()[AnnotationName]; ok {
+				switch n {
+				case FieldNameBody:
+					body = func(data datasource.Data) string {
+						s, _ := f.String(data)
+						return s
+					}
+					continue
+				case FieldNameSeverity:
+					v, err := datasource.AsInt64(f)
+					if err != nil {
+						return nil, fmt.Errorf("using field %q as %q: %w", f.Name(), n, err)
+					}
+					severity = func(data datasource.Data) int { return clampSeverity(v(data)) }
+					continue
+				}
+				fieldName = n
+			} else if !col.Visible {
+				continue
+			}
+		} else if !col.Visible {
+			continue
+		}
+
+		fields = append(fields, field{
+			name: fieldName,
+			get:  columns.GetFieldAsStringExt[datasource.DataTuple](col, 'f', col.Precision, col.Hex),
+		})
+	}
+
+	// Without a body set by annotations, the event is used as JSON
+	if body == nil {
+		jsonFormatter, err := json.New(ds)
+		if err != nil {
+			return nil, fmt.Errorf("initializing JSON formatter: %w", err)
+		}
+		body = func(data datasource.Data) string {
+			return string(jsonFormatter.Marshal(data))
+		}
+	}
+
+	return func(data datasource.Data) *entry {
+		tuple := datasource.NewDataTuple(ds, data)
+		e := &entry{
+			timestamp: timestamp(data),
+			severity:  severity(data),
+			body:      body(data),
+			meta:      meta,
+			fields:    make([]keyValue, 0, len(fields)),
+		}
+		for _, f := range fields {
+			e.fields = append(e.fields, keyValue{key: f.name, value: f.get(tuple)})
+		}
+		return e
+	}, nil
+}
+
+func (o *syslogOperatorInstance) PreStart(gadgetCtx operators.GadgetContext) error {
+	for _, ds := range gadgetCtx.GetDataSources() {
+		exporterName, ok := o.mappings[ds.Name()]
+		if !ok {
+			exporterName, ok = o.mappings[""]
+			if !ok {
+				continue
+			}
+		}
+		exp := o.o.exporters[exporterName]
+
+		var meta []keyValue
+		if gadgetCtx.ImageName() != "" {
+			meta = append(meta, keyValue{key: "gadget", value: gadgetCtx.ImageName()})
+		}
+		meta = append(meta, keyValue{key: "datasource", value: ds.Name()})
+		if gadgetCtx.ID() != "" {
+			meta = append(meta, keyValue{key: "instance", value: gadgetCtx.ID()})
+		}
+
+		newEntry, err := entryFunc(ds, meta)
+		if err != nil {
+			return fmt.Errorf("preparing %q for syslog: %w", ds.Name(), err)
+		}
+
+		appName := exp.cfg.AppName
+		if appName == "" {
+			appName = common.ImageShortName(gadgetCtx.ImageName())
+		}
+
+		var format func(*entry) []byte
+		switch exp.cfg.Exporter {
+		case ExporterJournald:
+			format = (&journaldHeader{facility: exp.facility, identifier: appName}).format
+		default:
+			sdID := ""
+			if exp.cfg.StructuredData {
+				sdID = exp.cfg.StructuredDataID
+			}
+			format = newRFC5424Header(exp.facility, exp.cfg.Hostname, appName,
+				strconv.Itoa(os.Getpid()), ds.Name(), sdID).format
+		}
+
+		// Messages are formatted before being queued, so data can be reused
+		// once the subscriber returns
+		write := func(data datasource.Data) {
+			exp.writer.add(format(newEntry(data)))
+		}
+		if !slices.Contains(o.used, exp) {
+			o.used = append(o.used, exp)
+		}
+
+		gadgetCtx.Logger().Debugf("syslog: writing %q using exporter %q", ds.Name(), exporterName)
+
+		switch ds.Type() {
+		case datasource.TypeSingle:
+			err = ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				write(data)
+				return nil
+			}, Priority)
+		case datasource.TypeArray:
+			err = ds.SubscribeArray(func(ds datasource.DataSource, dataArray datasource.DataArray) error {
+				for i := 0; i < dataArray.Len(); i++ {
+					write(dataArray.Get(i))
+				}
+				return nil
+			}, Priority)
+		}
+		if err != nil {
+			return fmt.Errorf("subscribing to %q: %w", ds.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (o *syslogOperatorInstance) Start(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *syslogOperatorInstance) Stop(gadgetCtx operators.GadgetContext) error {
+	return nil
+}
+
+func (o *syslogOperatorInstance) Close(gadgetCtx operators.GadgetContext) error {
+	// Give queued messages a chance to be written before ig exits
+	for _, exp := range o.used {
+		exp.writer.flush(exp.cfg.Timeout)
+	}
+	return nil
+}
+
+var Operator = &syslogOperator{}
+
+func init() {
+	operators.RegisterDataOperator(Operator)
+}
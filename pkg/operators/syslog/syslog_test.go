@@ -0,0 +1,318 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	ebpftypes "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ebpf/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+// testEntries are the events emitted by logEntries. Each one has its own
+// severity and timestamp, which must end up in the header of its entry.
+var testEntries = []struct {
+	comm      string
+	pid       uint32
+	severity  uint8
+	timestamp time.Time
+}{
+	{"cat", 1, 2 /* crit */, time.Date(2025, 1, 2, 3, 4, 5, 1000, time.UTC)},
+	{"ls", 2, 3 /* err */, time.Date(2025, 1, 2, 3, 4, 5, 2000, time.UTC)},
+}
+
+// logEntries runs op together with a producer emitting testEntries to a data
+// source called "exec" with the given annotations
+func logEntries(t *testing.T, op operators.DataOperator, annotations map[string]string, paramValues api.ParamValues) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ds datasource.DataSource
+	var comm, pid, level, ts datasource.FieldAccessor
+
+	producer := simple.New("producer",
+		simple.WithPriority(Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "exec")
+			require.NoError(t, err)
+			for k, v := range annotations {
+				ds.AddAnnotation(k, v)
+			}
+			comm, err = ds.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			pid, err = ds.AddField("pid", api.Kind_Uint32)
+			require.NoError(t, err)
+			level, err = ds.AddField("level", api.Kind_Uint8,
+				datasource.WithFlags(datasource.FieldFlagHidden),
+				datasource.WithAnnotations(map[string]string{AnnotationName: FieldNameSeverity}))
+			require.NoError(t, err)
+			ts, err = ds.AddField("timestamp_raw", api.Kind_Uint64,
+				datasource.WithFlags(datasource.FieldFlagHidden),
+				datasource.WithTags("type:"+ebpftypes.TimestampTypeName))
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			defer cancel()
+			for _, e := range testEntries {
+				data, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, comm.PutString(data, e.comm))
+				require.NoError(t, pid.PutUint32(data, e.pid))
+				require.NoError(t, level.PutUint8(data, e.severity))
+				require.NoError(t, ts.PutUint64(data, uint64(e.timestamp.UnixNano())))
+				require.NoError(t, ds.EmitAndRelease(data))
+			}
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "",
+		gadgetcontext.WithDataOperators(op, producer),
+		gadgetcontext.WithTimeout(5*time.Second),
+	)
+	return gadgetCtx.Run(paramValues)
+}
+
+func newTestOperator(t *testing.T, configs map[string]*exporterConfig) *syslogOperator {
+	op := &syslogOperator{exporters: make(map[string]*exporter)}
+	require.NoError(t, op.loadExporters(configs))
+	return op
+}
+
+// listenUnixgram creates a socket standing in for syslog or journald
+func listenUnixgram(t *testing.T) (string, *net.UnixConn) {
+	dir, err := os.MkdirTemp("", "ig-syslog")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "sock")
+	l, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	return path, l
+}
+
+func readDatagrams(t *testing.T, l *net.UnixConn, n int) []string {
+	var res []string
+	buf := make([]byte, 64*1024)
+	for i := 0; i < n; i++ {
+		l.SetReadDeadline(time.Now().Add(5 * time.Second))
+		c, err := l.Read(buf)
+		require.NoError(t, err)
+		res = append(res, string(buf[:c]))
+	}
+	return res
+}
+
+func TestSyslog(t *testing.T) {
+	path, l := listenUnixgram(t)
+
+	op := newTestOperator(t, map[string]*exporterConfig{
+		"local": {
+			Exporter:       ExporterSyslog,
+			Network:        "unixgram",
+			Address:        path,
+			Facility:       "local0",
+			AppName:        "ig",
+			Hostname:       "myhost",
+			StructuredData: true,
+		},
+	})
+
+	err := logEntries(t, op, nil, api.ParamValues{
+		"operator.syslog.syslog-exporter": "local",
+	})
+	require.NoError(t, err)
+
+	msgs := readDatagrams(t, l, 2)
+	re := regexp.MustCompile(`^<(\d+)>1 (\S+) myhost ig \d+ exec \[ig@32473 datasource="exec" comm="(\w+)" pid="(\d+)"\] (.*)$`)
+	for i, msg := range msgs {
+		e := testEntries[i]
+		m := re.FindStringSubmatch(msg)
+		require.NotNil(t, m, "unexpected message %q", msg)
+		// The severity is taken from the level field
+		assert.Equal(t, strconv.Itoa(16*8+int(e.severity)), m[1])
+		// The timestamp is taken from the event
+		assert.Equal(t, e.timestamp.Format("2006-01-02T15:04:05.000000Z07:00"), m[2])
+		assert.Equal(t, e.comm, m[3])
+		assert.Equal(t, strconv.Itoa(int(e.pid)), m[4])
+		assert.JSONEq(t, `{"comm":"`+m[3]+`","pid":`+m[4]+`}`, m[5])
+	}
+}
+
+func TestSyslogTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		// Read messages framed using octet counting
+		var msgs []string
+		r := bufio.NewReader(c)
+		for len(msgs) < 2 {
+			n, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(n))
+			msg := make([]byte, size)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				break
+			}
+			msgs = append(msgs, string(msg))
+		}
+		received <- msgs
+	}()
+
+	op := newTestOperator(t, map[string]*exporterConfig{
+		"remote": {
+			Exporter: ExporterSyslog,
+			Network:  "tcp",
+			Address:  l.Addr().String(),
+		},
+	})
+
+	err = logEntries(t, op, map[string]string{
+		AnnotationBody: `comm + " started"`,
+	}, api.ParamValues{
+		"operator.syslog.syslog-exporter": "exec:remote",
+	})
+	require.NoError(t, err)
+
+	select {
+	case msgs := <-received:
+		require.Len(t, msgs, 2)
+		assert.True(t, strings.HasSuffix(msgs[0], " exec - cat started"), msgs[0])
+		assert.True(t, strings.HasSuffix(msgs[1], " exec - ls started"), msgs[1])
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for messages")
+	}
+}
+
+func TestJournaldOutput(t *testing.T) {
+	path, l := listenUnixgram(t)
+
+	op := newTestOperator(t, map[string]*exporterConfig{
+		"journal": {
+			Exporter: ExporterJournald,
+			Address:  path,
+			AppName:  "ig",
+		},
+	})
+
+	err := logEntries(t, op, map[string]string{
+		AnnotationSeverity: "warning",
+	}, api.ParamValues{
+		"operator.syslog.syslog-exporter": "journal",
+	})
+	require.NoError(t, err)
+
+	msgs := readDatagrams(t, l, 2)
+	fields := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSuffix(msgs[1], "\n"), "\n") {
+		k, v, ok := strings.Cut(line, "=")
+		require.True(t, ok)
+		fields[k] = v
+	}
+	// The severity of the level field overrides the annotation
+	assert.Equal(t, "3", fields["PRIORITY"])
+	assert.Equal(t, "1", fields["SYSLOG_FACILITY"])
+	assert.Equal(t, "ig", fields["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "exec", fields["IG_DATASOURCE"])
+	assert.Equal(t, "ls", fields["IG_COMM"])
+	assert.Equal(t, "2", fields["IG_PID"])
+	assert.JSONEq(t, `{"comm":"ls","pid":2}`, fields["MESSAGE"])
+}
+
+func TestSyslogErrors(t *testing.T) {
+	invalid := map[string]*exporterConfig{
+		"unknown exporter": {Exporter: "foo"},
+		"unknown network":  {Exporter: ExporterSyslog, Network: "foo", Address: "localhost:514"},
+		"no address":       {Exporter: ExporterSyslog, Network: "tcp"},
+		"unknown facility": {Exporter: ExporterSyslog, Facility: "foo"},
+	}
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			op := &syslogOperator{exporters: make(map[string]*exporter)}
+			require.Error(t, op.loadExporters(map[string]*exporterConfig{"test": cfg}))
+		})
+	}
+
+	t.Run("unknown exporter mapping", func(t *testing.T) {
+		op := newTestOperator(t, map[string]*exporterConfig{
+			"local": {Exporter: ExporterSyslog},
+		})
+		err := logEntries(t, op, nil, api.ParamValues{
+			"operator.syslog.syslog-exporter": "foo",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid severity", func(t *testing.T) {
+		op := newTestOperator(t, map[string]*exporterConfig{
+			"local": {Exporter: ExporterSyslog},
+		})
+		err := logEntries(t, op, map[string]string{
+			AnnotationSeverity: "foo",
+		}, api.ParamValues{
+			"operator.syslog.syslog-exporter": "local",
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestWriterUnavailable(t *testing.T) {
+	// Nothing is listening on the socket, so every write fails
+	path := filepath.Join(t.TempDir(), "sock")
+	w := newWriter("test", newConn("unixgram", path, time.Second), 4)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		w.add([]byte("msg"))
+	}
+	w.flush(5 * time.Second)
+
+	// Adding messages must not block even if the receiver is unavailable
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Zero(t, w.pending.Load())
+	assert.Zero(t, w.written.Load())
+	assert.Equal(t, uint64(100), w.dropped.Load())
+}
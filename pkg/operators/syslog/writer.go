@@ -0,0 +1,124 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// minRetryBackoff and maxRetryBackoff limit the exponential backoff
+	// between attempts to write to an unavailable receiver
+	minRetryBackoff = 1 * time.Second
+	maxRetryBackoff = 30 * time.Second
+
+	// dropReportInterval is how often the number of dropped messages is
+	// logged
+	dropReportInterval = 10 * time.Second
+)
+
+// writer queues messages and writes them to a connection from a dedicated
+// goroutine, so a slow or unavailable receiver doesn't block the data sources.
+// Messages are dropped if the queue is full or the receiver isn't available.
+type writer struct {
+	name string
+	conn *conn
+
+	msgs chan []byte
+
+	// pending counts messages that are queued or being written
+	pending atomic.Int64
+	dropped atomic.Uint64
+	written atomic.Uint64
+}
+
+func newWriter(name string, conn *conn, bufferSize int) *writer {
+	w := &writer{
+		name: name,
+		conn: conn,
+		msgs: make(chan []byte, bufferSize),
+	}
+	go w.run()
+	return w
+}
+
+// add queues msg without blocking; it is dropped if the queue is full
+func (w *writer) add(msg []byte) {
+	w.pending.Add(1)
+	select {
+	case w.msgs <- msg:
+	default:
+		w.pending.Add(-1)
+		w.dropped.Add(1)
+	}
+}
+
+func (w *writer) run() {
+	ticker := time.NewTicker(dropReportInterval)
+	defer ticker.Stop()
+
+	var retryAt time.Time
+	backoff := minRetryBackoff
+	failing := false
+
+	for {
+		select {
+		case msg := <-w.msgs:
+			// Don't try to reconnect for every message while the receiver is
+			// unavailable
+			if time.Now().Before(retryAt) {
+				w.dropped.Add(1)
+				w.pending.Add(-1)
+				continue
+			}
+
+			err := w.conn.write(msg)
+			w.pending.Add(-1)
+			if err != nil {
+				w.dropped.Add(1)
+				if !failing {
+					log.Warnf("syslog: writing to exporter %q: %v", w.name, err)
+					failing = true
+				} else {
+					log.Debugf("syslog: writing to exporter %q failed, retrying in %s: %v", w.name, backoff, err)
+				}
+				retryAt = time.Now().Add(backoff)
+				backoff = min(backoff*2, maxRetryBackoff)
+				continue
+			}
+			w.written.Add(1)
+			if failing {
+				log.Infof("syslog: writing to exporter %q succeeded again", w.name)
+				failing = false
+			}
+			backoff = minRetryBackoff
+		case <-ticker.C:
+			if dropped := w.dropped.Swap(0); dropped > 0 {
+				log.Warnf("syslog: dropped %d events for exporter %q", dropped, w.name)
+			}
+		}
+	}
+}
+
+// flush waits until all queued messages were handled or the timeout expired
+func (w *writer) flush(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for w.pending.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recording implements the file format used to record gadget runs.
+//
+// A recording starts with a magic string followed by a sequence of
+// length-delimited api.GadgetEvent messages, just like the ones that are sent
+// by the gadget service. The first event is always of type
+// api.EventTypeGadgetInfo and contains the serialized api.GadgetInfo; all
+// following events are of type api.EventTypeGadgetPayload and contain the raw
+// GadgetData or GadgetDataArray of the data source referenced by DataSourceID.
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+// Magic is written at the beginning of every recording; the trailing digit is
+// the version of the format
+const Magic = "IGREC1\n"
+
+type Writer struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	seq uint32
+}
+
+// NewWriter writes the header and the given gadget info to w and returns a
+// Writer to add packets to the recording
+func NewWriter(w io.Writer, gi *api.GadgetInfo) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(Magic); err != nil {
+		return nil, err
+	}
+	return newWriter(bw, gi)
+}
+
+// NewStreamWriter is like NewWriter, but omits the header: the output only
+// consists of length-delimited api.GadgetEvent messages, so it can be decoded
+// by any protobuf implementation.
+func NewStreamWriter(w io.Writer, gi *api.GadgetInfo) (*Writer, error) {
+	return newWriter(bufio.NewWriter(w), gi)
+}
+
+func newWriter(bw *bufio.Writer, gi *api.GadgetInfo) (*Writer, error) {
+	d, err := proto.Marshal(gi)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling gadget info: %w", err)
+	}
+
+	rw := &Writer{w: bw}
+	err = rw.writeEvent(&api.GadgetEvent{
+		Type:    api.EventTypeGadgetInfo,
+		Payload: d,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+func (w *Writer) writeEvent(ev *api.GadgetEvent) error {
+	_, err := protodelim.MarshalTo(w.w, ev)
+	return err
+}
+
+// WritePacket adds the raw packet of the data source with the given ID to
+// the recording
+func (w *Writer) WritePacket(dsID uint32, packet proto.Message) error {
+	d, err := proto.Marshal(packet)
+	if err != nil {
+		return fmt.Errorf("marshaling packet: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	return w.writeEvent(&api.GadgetEvent{
+		Type:         api.EventTypeGadgetPayload,
+		Seq:          w.seq,
+		Payload:      d,
+		DataSourceID: dsID,
+	})
+}
+
+// Flush writes buffered packets to the underlying writer
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Flush()
+}
+
+type Reader struct {
+	r  *bufio.Reader
+	gi *api.GadgetInfo
+
+	truncated bool
+}
+
+// NewReader verifies the header of the recording and reads its gadget info
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if !bytes.Equal(magic, []byte(Magic)) {
+		return nil, errors.New("invalid header: not a gadget recording or unsupported version")
+	}
+
+	rr := &Reader{r: br}
+	ev, err := rr.readEvent()
+	if err != nil {
+		return nil, fmt.Errorf("reading gadget info: %w", err)
+	}
+	if ev.Type != api.EventTypeGadgetInfo {
+		return nil, fmt.Errorf("expected gadget info, got event of type %d", ev.Type)
+	}
+
+	rr.gi = &api.GadgetInfo{}
+	if err := proto.Unmarshal(ev.Payload, rr.gi); err != nil {
+		return nil, fmt.Errorf("unmarshaling gadget info: %w", err)
+	}
+	return rr, nil
+}
+
+func (r *Reader) readEvent() (*api.GadgetEvent, error) {
+	ev := &api.GadgetEvent{}
+	if err := protodelim.UnmarshalFrom(r.r, ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// Truncated returns whether Next reached the end of the recording in the
+// middle of a message
+func (r *Reader) Truncated() bool {
+	return r.truncated
+}
+
+// GadgetInfo returns the gadget info stored in the recording
+func (r *Reader) GadgetInfo() *api.GadgetInfo {
+	return r.gi
+}
+
+// Next returns the next packet of the recording; it returns io.EOF after the
+// last one. A recording that ends in the middle of a message, e.g. because the
+// recording process was killed, also ends with io.EOF; use Truncated to tell
+// both cases apart.
+func (r *Reader) Next() (*api.GadgetEvent, error) {
+	for {
+		ev, err := r.readEvent()
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			r.truncated = true
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ev.Type != api.EventTypeGadgetPayload {
+			// ignore unknown events to stay compatible with future versions
+			continue
+		}
+		return ev, nil
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+func TestRecording(t *testing.T) {
+	gi := &api.GadgetInfo{
+		ImageName: "trace_open",
+		DataSources: []*api.DataSource{
+			{Id: 0, Name: "open"},
+			{Id: 1, Name: "stats", Type: 1},
+		},
+	}
+
+	packets := []struct {
+		dsID   uint32
+		packet proto.Message
+	}{
+		{0, &api.GadgetData{Data: &api.DataElement{Payload: [][]byte{[]byte("first")}}}},
+		{1, &api.GadgetDataArray{DataArray: []*api.DataElement{{Payload: [][]byte{[]byte("a")}}}}},
+		{0, &api.GadgetData{Data: &api.DataElement{Payload: [][]byte{[]byte("second")}}}},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, gi)
+	require.NoError(t, err)
+	for _, p := range packets {
+		require.NoError(t, w.WritePacket(p.dsID, p.packet))
+	}
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(gi, r.GadgetInfo()))
+
+	for i, p := range packets {
+		ev, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, p.dsID, ev.DataSourceID)
+		assert.Equal(t, uint32(i+1), ev.Seq)
+
+		var got proto.Message
+		if p.dsID == 0 {
+			got = &api.GadgetData{}
+		} else {
+			got = &api.GadgetDataArray{}
+		}
+		require.NoError(t, proto.Unmarshal(ev.Payload, got))
+		assert.True(t, proto.Equal(p.packet, got))
+	}
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestInvalidRecording(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a recording")))
+	require.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader(nil))
+	require.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader([]byte(Magic)))
+	require.Error(t, err)
+}
+
+func TestTruncatedRecording(t *testing.T) {
+	gi := &api.GadgetInfo{ImageName: "trace_open"}
+	packet := &api.GadgetData{Data: &api.DataElement{Payload: [][]byte{[]byte("payload")}}}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, gi)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePacket(0, packet))
+	require.NoError(t, w.Flush())
+	complete := buf.Len()
+	require.NoError(t, w.WritePacket(0, packet))
+	require.NoError(t, w.Flush())
+
+	// Cut the second packet in the middle of its payload and right after its
+	// size prefix
+	for _, size := range []int{buf.Len() - 3, complete + 1} {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()[:size]))
+		require.NoError(t, err)
+
+		_, err = r.Next()
+		require.NoError(t, err)
+		assert.False(t, r.Truncated())
+
+		_, err = r.Next()
+		assert.ErrorIs(t, err, io.EOF)
+		assert.True(t, r.Truncated())
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	gi := &api.GadgetInfo{
+		ImageName:   "trace_exec",
+		DataSources: []*api.DataSource{{Id: 0, Name: "exec"}},
+	}
+	packet := &api.GadgetData{Data: &api.DataElement{Payload: [][]byte{[]byte("data")}}}
+
+	var buf bytes.Buffer
+	w, err := NewStreamWriter(&buf, gi)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePacket(0, packet))
+	require.NoError(t, w.Flush())
+
+	// Only length-delimited events, no header
+	events := []*api.GadgetEvent{}
+	for {
+		ev := &api.GadgetEvent{}
+		err := protodelim.UnmarshalFrom(&buf, ev)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		events = append(events, ev)
+	}
+	require.Len(t, events, 2)
+
+	assert.Equal(t, api.EventTypeGadgetInfo, events[0].Type)
+	gotInfo := &api.GadgetInfo{}
+	require.NoError(t, proto.Unmarshal(events[0].Payload, gotInfo))
+	assert.True(t, proto.Equal(gi, gotInfo))
+
+	assert.Equal(t, api.EventTypeGadgetPayload, events[1].Type)
+	gotPacket := &api.GadgetData{}
+	require.NoError(t, proto.Unmarshal(events[1].Payload, gotPacket))
+	assert.True(t, proto.Equal(packet, gotPacket))
+}
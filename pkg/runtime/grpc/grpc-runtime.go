@@ -1,4 +1,4 @@
-// Copyright 2023-2024 The Inspektor Gadget authors
+// Copyright 2023-2025 The Inspektor Gadget authors
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -34,6 +34,7 @@ import (
 
 	"github.com/inspektor-gadget/inspektor-gadget/internal/deployinfo"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/auth"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 	gadgettls "github.com/inspektor-gadget/inspektor-gadget/pkg/utils/tls"
 )
@@ -66,6 +67,7 @@ const (
 	ParamTLSCert       = "tls-cert-file"
 	ParamTLSServerCA   = "tls-server-ca-file"
 	ParamTLSServerName = "tls-server-name"
+	ParamAuthTokenFile = "auth-token-file"
 
 	// ParamGadgetServiceTCPPort is only used in combination with KubernetesProxyConnectionMethodTCP
 	ParamGadgetServiceTCPPort = "tcp-port"
@@ -233,6 +235,11 @@ func (r *Runtime) GlobalParamDescs() params.ParamDescs {
 				Description: "override TLS server name (if omitted, using target server name)",
 				TypeHint:    params.TypeString,
 			},
+			{
+				Key:         ParamAuthTokenFile,
+				Description: "File containing a token to authenticate against the remote",
+				TypeHint:    params.TypeString,
+			},
 		}...)
 		return p
 	case ConnectionModeKubernetesProxy:
@@ -390,22 +397,31 @@ func (r *Runtime) dialContext(dialCtx context.Context, target target, timeout ti
 		}
 	}
 
-	if tlsOptionsSet > 1 && tlsOptionsSet < 3 {
+	// Only setting the server CA is valid as well, to use TLS without client
+	// certificate (e.g. when authenticating using a token)
+	serverCAOnly := tlsOptionsSet == 1 && tlsCA != ""
+
+	if tlsOptionsSet > 1 && tlsOptionsSet < 3 || tlsOptionsSet == 1 && !serverCAOnly {
 		return nil, fmt.Errorf(`
 missing at least one the TLS related options:
 	* %s: %q
 	* %s: %q
 	* %s: %q
-All these options should be set at the same time to enable TLS connection`,
+All these options should be set at the same time to enable TLS connection (or only %s to connect without a client certificate)`,
 			ParamTLSKey, tlsKey,
 			ParamTLSCert, tlsCert,
-			ParamTLSServerCA, tlsCA)
+			ParamTLSServerCA, tlsCA,
+			ParamTLSServerCA)
 	}
 
-	if tlsOptionsSet == 3 {
-		cert, err := gadgettls.LoadTLSCert(tlsCert, tlsKey)
-		if err != nil {
-			return nil, fmt.Errorf("creating TLS certificate: %w", err)
+	if tlsOptionsSet == 3 || serverCAOnly {
+		var certs []tls.Certificate
+		if !serverCAOnly {
+			cert, err := gadgettls.LoadTLSCert(tlsCert, tlsKey)
+			if err != nil {
+				return nil, fmt.Errorf("creating TLS certificate: %w", err)
+			}
+			certs = append(certs, cert)
 		}
 
 		ca, err := gadgettls.LoadTLSCA(tlsCA)
@@ -420,7 +436,7 @@ All these options should be set at the same time to enable TLS connection`,
 
 		tlsConfig := &tls.Config{
 			ServerName:   purl.Hostname(),
-			Certificates: []tls.Certificate{cert},
+			Certificates: certs,
 			RootCAs:      ca,
 		}
 
@@ -437,6 +453,18 @@ All these options should be set at the same time to enable TLS connection`,
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
+	if r.connectionMode == ConnectionModeDirect {
+		if tokenFile := r.globalParams.Get(ParamAuthTokenFile).String(); tokenFile != "" {
+			// Only allow sending the token without TLS on unix sockets
+			requireTLS := !strings.HasPrefix(target.addressOrPod, "unix://")
+			creds, err := auth.LoadTokenCredentials(tokenFile, requireTLS)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, grpc.WithPerRPCCredentials(creds))
+		}
+	}
+
 	// If we're in Kubernetes connection mode, we need a custom dialer
 	if r.connectionMode == ConnectionModeKubernetesProxy {
 		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
@@ -43,6 +43,45 @@ func (r *Runtime) RemoveGadgetInstance(ctx context.Context, runtimeParams *param
 	})
 }
 
+func (r *Runtime) PauseGadgetInstance(ctx context.Context, runtimeParams *params.Params, id string) error {
+	return r.runInstanceManagerClientForTargets(ctx, runtimeParams, func(target target, client api.GadgetInstanceManagerClient) error {
+		res, err := client.PauseGadgetInstance(ctx, &api.GadgetInstanceId{Id: id})
+		if err != nil {
+			return err
+		}
+		if res.Result != 0 {
+			return errors.New(res.Message)
+		}
+		return nil
+	})
+}
+
+func (r *Runtime) ResumeGadgetInstance(ctx context.Context, runtimeParams *params.Params, id string) error {
+	return r.runInstanceManagerClientForTargets(ctx, runtimeParams, func(target target, client api.GadgetInstanceManagerClient) error {
+		res, err := client.ResumeGadgetInstance(ctx, &api.GadgetInstanceId{Id: id})
+		if err != nil {
+			return err
+		}
+		if res.Result != 0 {
+			return errors.New(res.Message)
+		}
+		return nil
+	})
+}
+
+func (r *Runtime) UpdateGadgetInstance(ctx context.Context, runtimeParams *params.Params, id string, paramValues api.ParamValues) error {
+	return r.runInstanceManagerClientForTargets(ctx, runtimeParams, func(target target, client api.GadgetInstanceManagerClient) error {
+		res, err := client.UpdateGadgetInstance(ctx, &api.UpdateGadgetInstanceRequest{Id: id, ParamValues: paramValues})
+		if err != nil {
+			return err
+		}
+		if res.Result != 0 {
+			return errors.New(res.Message)
+		}
+		return nil
+	})
+}
+
 func (r *Runtime) GetGadgetInstances(ctx context.Context, runtimeParams *params.Params) (instances []*api.GadgetInstance, err error) {
 	var mu sync.Mutex
 	err = r.runInstanceManagerClientForTargets(ctx, runtimeParams, func(target target, client api.GadgetInstanceManagerClient) error {
@@ -138,9 +177,10 @@ func (r *Runtime) createGadgetInstance(gadgetCtx runtime.GadgetContext, runtimeP
 			Name: instanceName,
 			Tags: strings.Split(runtimeParams.Get(ParamTags).AsString(), ","),
 			GadgetConfig: &api.GadgetRunRequest{
-				ImageName:   gadgetCtx.ImageName(),
-				ParamValues: paramValues,
-				Version:     api.VersionGadgetRunProtocol,
+				ImageName:            gadgetCtx.ImageName(),
+				AdditionalImageNames: gadgetCtx.ImageNames()[1:],
+				ParamValues:          paramValues,
+				Version:              api.VersionGadgetRunProtocol,
 			},
 		},
 		EventBufferLength: runtimeParams.Get(ParamEventBufferLength).AsInt32(), // default for now
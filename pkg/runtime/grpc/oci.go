@@ -44,10 +44,11 @@ func (r *Runtime) GetGadgetInfo(gadgetCtx runtime.GadgetContext, runtimeParams *
 	client := api.NewGadgetManagerClient(conn)
 
 	in := &api.GetGadgetInfoRequest{
-		ParamValues:      paramValues,
-		ImageName:        gadgetCtx.ImageName(),
-		Version:          api.VersionGadgetInfo,
-		RequestExtraInfo: gadgetCtx.ExtraInfo(),
+		ParamValues:          paramValues,
+		ImageName:            gadgetCtx.ImageName(),
+		AdditionalImageNames: gadgetCtx.ImageNames()[1:],
+		Version:              api.VersionGadgetInfo,
+		RequestExtraInfo:     gadgetCtx.ExtraInfo(),
 	}
 
 	// specify that ImageName will contain a gadget instance ID
@@ -170,12 +171,13 @@ func (r *Runtime) runGadget(gadgetCtx runtime.GadgetContext, target target, allP
 		controlRequest = &api.GadgetControlRequest{
 			Event: &api.GadgetControlRequest_RunRequest{
 				RunRequest: &api.GadgetRunRequest{
-					ImageName:   gadgetCtx.ImageName(),
-					ParamValues: allParams,
-					Args:        gadgetCtx.Args(),
-					LogLevel:    uint32(gadgetCtx.Logger().GetLevel()),
-					Timeout:     int64(gadgetCtx.Timeout()),
-					Version:     api.VersionGadgetRunProtocol,
+					ImageName:            gadgetCtx.ImageName(),
+					AdditionalImageNames: gadgetCtx.ImageNames()[1:],
+					ParamValues:          allParams,
+					Args:                 gadgetCtx.Args(),
+					LogLevel:             uint32(gadgetCtx.Logger().GetLevel()),
+					Timeout:              int64(gadgetCtx.Timeout()),
+					Version:              api.VersionGadgetRunProtocol,
 				},
 			},
 		}
@@ -0,0 +1,166 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay implements a runtime that feeds the packets of a recording
+// created by the record operator back through the local operators, without
+// running any eBPF programs.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/record"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+type Runtime struct{}
+
+func New() *Runtime {
+	return &Runtime{}
+}
+
+func (r *Runtime) Init(globalRuntimeParams *params.Params) error {
+	return nil
+}
+
+func (r *Runtime) Close() error {
+	return nil
+}
+
+func (r *Runtime) GlobalParamDescs() params.ParamDescs {
+	return nil
+}
+
+func (r *Runtime) ParamDescs() params.ParamDescs {
+	return nil
+}
+
+func (r *Runtime) SetDefaultValue(key params.ValueHint, value string) {
+	panic("not supported, yet")
+}
+
+func (r *Runtime) GetDefaultValue(key params.ValueHint) (string, bool) {
+	return "", false
+}
+
+// SkipDataOperator returns whether the given operator must not run when
+// replaying a recording; operators that already ran before the packets were
+// recorded (like enrichers) are skipped, as their fields are already part of
+// the recording.
+func SkipDataOperator(op operators.DataOperator) bool {
+	return op.Priority() <= record.Priority
+}
+
+// openRecording opens the recording referenced by the image name of the gadget
+// context
+func openRecording(gadgetCtx runtime.GadgetContext) (*os.File, *recording.Reader, error) {
+	f, err := os.Open(gadgetCtx.ImageName())
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening recording: %w", err)
+	}
+	rr, err := recording.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("reading recording %q: %w", gadgetCtx.ImageName(), err)
+	}
+	return f, rr, nil
+}
+
+func (r *Runtime) GetGadgetInfo(gadgetCtx runtime.GadgetContext, runtimeParams *params.Params, paramValues api.ParamValues) (*api.GadgetInfo, error) {
+	f, rr, err := openRecording(gadgetCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	err = gadgetCtx.LoadGadgetInfo(rr.GadgetInfo(), paramValues, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("initializing local operators: %w", err)
+	}
+
+	return gadgetCtx.SerializeGadgetInfo(gadgetCtx.ExtraInfo())
+}
+
+func (r *Runtime) RunGadget(gadgetCtx runtime.GadgetContext, runtimeParams *params.Params, paramValues api.ParamValues) error {
+	f, rr, err := openRecording(gadgetCtx)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gi := rr.GadgetInfo()
+	err = gadgetCtx.LoadGadgetInfo(gi, paramValues, true, nil)
+	if err != nil {
+		return fmt.Errorf("initializing local operators: %w", err)
+	}
+	defer gadgetCtx.StopLocalOperators()
+
+	dataSources := gadgetCtx.GetAllDataSources()
+	dsMap := make(map[uint32]datasource.DataSource)
+	for _, ds := range gi.DataSources {
+		if lds, ok := dataSources[ds.Name]; ok {
+			dsMap[ds.Id] = lds
+		}
+	}
+
+	done := gadgetCtx.Context().Done()
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		ev, err := rr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				if rr.Truncated() {
+					gadgetCtx.Logger().Warnf("replay: recording is truncated, ignoring incomplete last packet")
+				}
+				gadgetCtx.Logger().Debugf("replay: reached end of recording")
+				return nil
+			}
+			return fmt.Errorf("reading recording: %w", err)
+		}
+
+		ds, ok := dsMap[ev.DataSourceID]
+		if !ok {
+			continue
+		}
+
+		var p datasource.Packet
+		switch ds.Type() {
+		case datasource.TypeSingle:
+			p, err = ds.NewPacketSingleFromRaw(ev.Payload)
+		case datasource.TypeArray:
+			p, err = ds.NewPacketArrayFromRaw(ev.Payload)
+		default:
+			gadgetCtx.Logger().Warnf("unknown datasource type %d", ds.Type())
+			continue
+		}
+		if err != nil {
+			gadgetCtx.Logger().Debugf("error unmarshaling payload: %v", err)
+			continue
+		}
+		ds.EmitAndRelease(p)
+	}
+}
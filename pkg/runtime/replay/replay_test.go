@@ -0,0 +1,120 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/record"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+type event struct {
+	Comm string
+	Pid  uint32
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording")
+	expected := []event{{"cat", 1}, {"ls", 2}, {"sh", 3}}
+
+	// Record
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ds datasource.DataSource
+	var commF, pidF datasource.FieldAccessor
+	producer := simple.New("producer",
+		simple.WithPriority(record.Priority-1),
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			var err error
+			ds, err = gadgetCtx.RegisterDataSource(datasource.TypeSingle, "events")
+			require.NoError(t, err)
+			commF, err = ds.AddField("comm", api.Kind_String)
+			require.NoError(t, err)
+			pidF, err = ds.AddField("pid", api.Kind_Uint32)
+			require.NoError(t, err)
+			return nil
+		}),
+		simple.OnStart(func(gadgetCtx operators.GadgetContext) error {
+			for _, ev := range expected {
+				data, err := ds.NewPacketSingle()
+				require.NoError(t, err)
+				require.NoError(t, commF.PutString(data, ev.Comm))
+				require.NoError(t, pidF.PutUint32(data, ev.Pid))
+				require.NoError(t, ds.EmitAndRelease(data))
+			}
+			cancel()
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(ctx, "", gadgetcontext.WithDataOperators(record.Operator, producer))
+	require.NoError(t, gadgetCtx.Run(api.ParamValues{
+		"operator.record.record": path,
+	}))
+
+	// Replay
+	var got []event
+	verifier := simple.New("verifier",
+		simple.WithPriority(record.Priority+1),
+		simple.OnPreStart(func(gadgetCtx operators.GadgetContext) error {
+			ds := gadgetCtx.GetDataSources()["events"]
+			require.NotNil(t, ds)
+			commF := ds.GetField("comm")
+			require.NotNil(t, commF)
+			pidF := ds.GetField("pid")
+			require.NotNil(t, pidF)
+			return ds.Subscribe(func(ds datasource.DataSource, data datasource.Data) error {
+				comm, err := commF.String(data)
+				require.NoError(t, err)
+				pid, err := pidF.Uint32(data)
+				require.NoError(t, err)
+				got = append(got, event{comm, pid})
+				return nil
+			}, 0)
+		}),
+	)
+
+	r := New()
+	require.NoError(t, r.Init(nil))
+
+	infoCtx := gadgetcontext.New(context.Background(), path)
+	gi, err := r.GetGadgetInfo(infoCtx, nil, api.ParamValues{})
+	require.NoError(t, err)
+	require.Len(t, gi.DataSources, 1)
+	assert.Equal(t, "events", gi.DataSources[0].Name)
+
+	replayCtx := gadgetcontext.New(context.Background(), path, gadgetcontext.WithDataOperators(verifier))
+	require.NoError(t, r.RunGadget(replayCtx, nil, api.ParamValues{}))
+
+	assert.Equal(t, expected, got)
+}
+
+func TestReplayInvalidFile(t *testing.T) {
+	gadgetCtx := gadgetcontext.New(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	_, err := New().GetGadgetInfo(gadgetCtx, nil, api.ParamValues{})
+	require.Error(t, err)
+}
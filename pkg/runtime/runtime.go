@@ -47,6 +47,7 @@ type GadgetContext interface {
 
 	Cancel()
 	ImageName() string
+	ImageNames() []string
 	RegisterDataSource(datasource.Type, string) (datasource.DataSource, error)
 	GetDataSources() map[string]datasource.DataSource
 	GetAllDataSources() map[string]datasource.DataSource
@@ -0,0 +1,131 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !withoutebpf
+
+package tchandler
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/vishvananda/netlink"
+)
+
+// Mode defines how the dispatcher program is attached to network interfaces
+type Mode string
+
+const (
+	// ModeAuto uses TCX links if supported by the kernel and falls back to ModeLegacy otherwise
+	ModeAuto Mode = "auto"
+	// ModeTCX uses TCX links (Linux 6.6+). Several programs can be attached to the same
+	// interface without taking ownership of the clsact qdisc.
+	ModeTCX Mode = "tcx"
+	// ModeLegacy adds a filter to the clsact qdisc of the interface using netlink
+	ModeLegacy Mode = "legacy"
+)
+
+// ParseMode parses a mode; an empty string defaults to ModeAuto
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeAuto:
+		return ModeAuto, nil
+	case ModeTCX, ModeLegacy:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("invalid tc mode %q: expected %s, %s or %s", s, ModeAuto, ModeTCX, ModeLegacy)
+}
+
+// Order defines where the dispatcher program is placed relative to other programs attached to the
+// same interface. It's only used for TCX and netkit links.
+type Order string
+
+const (
+	// OrderBefore runs the program before all other programs
+	OrderBefore Order = "before"
+	// OrderAfter runs the program after all other programs
+	OrderAfter Order = "after"
+)
+
+// ParseOrder parses an order; an empty string defaults to OrderBefore
+func ParseOrder(s string) (Order, error) {
+	switch Order(s) {
+	case "", OrderBefore:
+		return OrderBefore, nil
+	case OrderAfter:
+		return OrderAfter, nil
+	}
+	return "", fmt.Errorf("invalid tc order %q: expected %s or %s", s, OrderBefore, OrderAfter)
+}
+
+func (o Order) anchor() link.Anchor {
+	if o == OrderAfter {
+		return link.Tail()
+	}
+	return link.Head()
+}
+
+// isNetkit returns whether the interface is the primary device of a netkit pair. It must be called
+// from the network namespace of the interface.
+func isNetkit(iface *net.Interface) bool {
+	l, err := netlink.LinkByIndex(iface.Index)
+	if err != nil {
+		return false
+	}
+	return l.Type() == "netkit"
+}
+
+// attachNetkit attaches the program to the given netkit device. Netkit devices don't have a
+// qdisc, programs are run when a packet is transmitted by one of the devices of the pair: packets
+// received by the primary device (ingress) are the ones transmitted by its peer.
+func attachNetkit(prog *ebpf.Program, iface *net.Interface, dir AttachmentDirection, order Order) (link.Link, error) {
+	var attachType ebpf.AttachType
+	switch dir {
+	case AttachmentDirectionIngress:
+		attachType = ebpf.AttachNetkitPeer
+	case AttachmentDirectionEgress:
+		attachType = ebpf.AttachNetkitPrimary
+	default:
+		return nil, fmt.Errorf("invalid direction")
+	}
+
+	return link.AttachNetkit(link.NetkitOptions{
+		Interface: iface.Index,
+		Program:   prog,
+		Attach:    attachType,
+		Anchor:    order.anchor(),
+	})
+}
+
+// attachTCX attaches the program to the given interface using a TCX link.
+func attachTCX(prog *ebpf.Program, iface *net.Interface, dir AttachmentDirection, order Order) (link.Link, error) {
+	var attachType ebpf.AttachType
+	switch dir {
+	case AttachmentDirectionIngress:
+		attachType = ebpf.AttachTCXIngress
+	case AttachmentDirectionEgress:
+		attachType = ebpf.AttachTCXEgress
+	default:
+		return nil, fmt.Errorf("invalid direction")
+	}
+
+	return link.AttachTCX(link.TCXOptions{
+		Interface: iface.Index,
+		Program:   prog,
+		Attach:    attachType,
+		Anchor:    order.anchor(),
+	})
+}
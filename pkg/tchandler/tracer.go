@@ -17,6 +17,8 @@
 // pkg/networktracer/tracer.go.
 // The main difference is that SchedCLS programs need to be attached to network interfaces and can
 // be attached on ingress or egress.
+// The dispatcher is attached using TCX or netkit links if supported, or by adding a filter to the
+// clsact qdisc of the interface otherwise. See Mode.
 package tchandler
 
 import (
@@ -26,6 +28,7 @@ import (
 	"sync"
 
 	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
 	"github.com/florianl/go-tc"
 	"golang.org/x/sys/unix"
 
@@ -48,8 +51,10 @@ type attachment struct {
 	// attached to.
 	dispatcher dispatcherObjects
 	// filter is the tc ebpf filter we attach to the network interface. This filter will execute
-	// the dispatcher above.
+	// the dispatcher above. Only used in legacy mode.
 	filter *tc.Object
+	// link is the TCX or netkit link executing the dispatcher above
+	link link.Link
 
 	// users keeps track of the users' pid that have called Attach(). This can happen for when
 	// there are several containers in a pod (sharing the netns, and hence the networking
@@ -61,6 +66,9 @@ func (t *Handler) closeAttachment(a *attachment) {
 	if a.filter != nil {
 		t.tcnl.Filter().Delete(a.filter)
 	}
+	if a.link != nil {
+		a.link.Close()
+	}
 	a.dispatcher.Close()
 }
 
@@ -79,13 +87,40 @@ type Handler struct {
 	tcnl *tc.Tc
 
 	direction AttachmentDirection
+	mode      Mode
+	order     Order
+
+	// tcxUnsupported is set when the kernel doesn't support TCX links in ModeAuto, so that
+	// following attachments directly use the legacy mode
+	tcxUnsupported bool
 
 	// mu protects attachments from concurrent access
 	// AttachContainer and DetachContainer can be called in parallel
 	mu sync.Mutex
 }
 
-func NewHandler(direction AttachmentDirection) (*Handler, error) {
+type Option func(*Handler)
+
+// WithMode sets how the dispatcher program is attached to network interfaces. Defaults to ModeAuto.
+func WithMode(mode Mode) Option {
+	return func(t *Handler) {
+		if mode != "" {
+			t.mode = mode
+		}
+	}
+}
+
+// WithOrder sets where the dispatcher program is placed relative to other programs when using TCX
+// or netkit links. Defaults to OrderBefore.
+func WithOrder(order Order) Option {
+	return func(t *Handler) {
+		if order != "" {
+			t.order = order
+		}
+	}
+}
+
+func NewHandler(direction AttachmentDirection, options ...Option) (*Handler, error) {
 	var err error
 	var tcnl *tc.Tc
 
@@ -107,6 +142,11 @@ func NewHandler(direction AttachmentDirection) (*Handler, error) {
 		attachments: make(map[string]*attachment),
 		tcnl:        tcnl,
 		direction:   direction,
+		mode:        ModeAuto,
+		order:       OrderBefore,
+	}
+	for _, option := range options {
+		option(t)
 	}
 	defer func() {
 		if err != nil {
@@ -162,12 +202,6 @@ func (t *Handler) newAttachment(pid uint32, iface *net.Interface, netns uint64,
 		return nil, err
 	}
 
-	// We create the clsact qdisc and leak it. We can't remove it because we'll break any other
-	// application (including other ig instances) that are using it.
-	if qdisc, err = createClsActQdisc(t.tcnl, iface); err != nil && !errors.Is(err, unix.EEXIST) {
-		return nil, fmt.Errorf("creating clsact qdisc: %w", err)
-	}
-
 	optsIngress := ebpf.CollectionOptions{
 		MapReplacements: map[string]*ebpf.Map{
 			tailCallMapName: t.dispatcherMap,
@@ -177,6 +211,34 @@ func (t *Handler) newAttachment(pid uint32, iface *net.Interface, netns uint64,
 		return nil, fmt.Errorf("loading ebpf program: %w", err)
 	}
 
+	if t.mode != ModeLegacy {
+		if isNetkit(iface) {
+			a.link, err = attachNetkit(a.dispatcher.IgNetDisp, iface, direction, t.order)
+			if err != nil {
+				return nil, fmt.Errorf("attaching ebpf program to netkit interface %s: %w", iface.Name, err)
+			}
+			return a, nil
+		}
+
+		if !t.tcxUnsupported {
+			a.link, err = attachTCX(a.dispatcher.IgNetDisp, iface, direction, t.order)
+			switch {
+			case err == nil:
+				return a, nil
+			case t.mode == ModeAuto && errors.Is(err, ebpf.ErrNotSupported):
+				t.tcxUnsupported = true
+			default:
+				return nil, fmt.Errorf("attaching ebpf program to interface %s using tcx: %w", iface.Name, err)
+			}
+		}
+	}
+
+	// We create the clsact qdisc and leak it. We can't remove it because we'll break any other
+	// application (including other ig instances) that are using it.
+	if qdisc, err = createClsActQdisc(t.tcnl, iface); err != nil && !errors.Is(err, unix.EEXIST) {
+		return nil, fmt.Errorf("creating clsact qdisc: %w", err)
+	}
+
 	a.filter, err = addTCFilter(t.tcnl, a.dispatcher.IgNetDisp, iface, direction)
 	if err != nil {
 		return nil, fmt.Errorf("attaching ebpf program to interface %s: %w", iface.Name, err)
@@ -0,0 +1,183 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tchandler
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+
+	utilstest "github.com/inspektor-gadget/inspektor-gadget/internal/test"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
+)
+
+// newHandler creates a handler and skips the test if the network namespace of pid 1, where the
+// netlink socket is created, is not accessible
+func newHandler(t *testing.T, direction AttachmentDirection, options ...Option) *Handler {
+	if _, err := os.Readlink(filepath.Join(host.HostProcFs, "1/ns/net")); err != nil {
+		t.Skipf("cannot access network namespace of pid 1: %s", err)
+	}
+	handler, err := NewHandler(direction, options...)
+	require.NoError(t, err)
+	t.Cleanup(handler.Close)
+	return handler
+}
+
+func newVeth(t *testing.T, name string) *net.Interface {
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: name},
+		PeerName:  name + "p",
+	}
+	require.NoError(t, netlink.LinkAdd(veth))
+	t.Cleanup(func() { netlink.LinkDel(veth) })
+	require.NoError(t, netlink.LinkSetUp(veth))
+
+	iface, err := net.InterfaceByName(name)
+	require.NoError(t, err)
+	return iface
+}
+
+func dispatcherID(t *testing.T, handler *Handler, iface *net.Interface) ebpf.ProgramID {
+	a, ok := handler.attachments[iface.Name]
+	require.True(t, ok)
+	info, err := a.dispatcher.IgNetDisp.Info()
+	require.NoError(t, err)
+	id, _ := info.ID()
+	return id
+}
+
+func queryPrograms(t *testing.T, iface *net.Interface, attachType ebpf.AttachType) []ebpf.ProgramID {
+	res, err := link.QueryPrograms(link.QueryOptions{
+		Target: iface.Index,
+		Attach: attachType,
+	})
+	require.NoError(t, err)
+	var ids []ebpf.ProgramID
+	for _, p := range res.Programs {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestParse(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeAuto, mode)
+	mode, err = ParseMode("legacy")
+	require.NoError(t, err)
+	assert.Equal(t, ModeLegacy, mode)
+	_, err = ParseMode("foo")
+	require.Error(t, err)
+
+	order, err := ParseOrder("")
+	require.NoError(t, err)
+	assert.Equal(t, OrderBefore, order)
+	order, err = ParseOrder("after")
+	require.NoError(t, err)
+	assert.Equal(t, OrderAfter, order)
+	_, err = ParseOrder("foo")
+	require.Error(t, err)
+}
+
+func TestAttachIfaceTCX(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	iface := newVeth(t, "igtcx0")
+	handler := newHandler(t, AttachmentDirectionIngress, WithMode(ModeTCX))
+	require.NoError(t, handler.AttachIface(iface))
+
+	id := dispatcherID(t, handler, iface)
+	assert.Equal(t, []ebpf.ProgramID{id}, queryPrograms(t, iface, ebpf.AttachTCXIngress))
+	assert.Empty(t, queryPrograms(t, iface, ebpf.AttachTCXEgress))
+	assert.Nil(t, handler.attachments[iface.Name].filter)
+
+	require.NoError(t, handler.DetachIface(iface))
+	assert.Empty(t, queryPrograms(t, iface, ebpf.AttachTCXIngress))
+}
+
+func TestAttachIfaceOrder(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	iface := newVeth(t, "igtcx0")
+
+	after := newHandler(t, AttachmentDirectionEgress, WithMode(ModeTCX), WithOrder(OrderAfter))
+	require.NoError(t, after.AttachIface(iface))
+	before := newHandler(t, AttachmentDirectionEgress, WithMode(ModeTCX), WithOrder(OrderBefore))
+	require.NoError(t, before.AttachIface(iface))
+	last := newHandler(t, AttachmentDirectionEgress, WithMode(ModeTCX), WithOrder(OrderAfter))
+	require.NoError(t, last.AttachIface(iface))
+
+	assert.Equal(t, []ebpf.ProgramID{
+		dispatcherID(t, before, iface),
+		dispatcherID(t, after, iface),
+		dispatcherID(t, last, iface),
+	}, queryPrograms(t, iface, ebpf.AttachTCXEgress))
+}
+
+func TestAttachIfaceLegacy(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	iface := newVeth(t, "igtcx0")
+	handler := newHandler(t, AttachmentDirectionIngress, WithMode(ModeLegacy))
+	require.NoError(t, handler.AttachIface(iface))
+
+	a := handler.attachments[iface.Name]
+	assert.NotNil(t, a.filter)
+	assert.Nil(t, a.link)
+	assert.Empty(t, queryPrograms(t, iface, ebpf.AttachTCXIngress))
+
+	filters, err := handler.tcnl.Filter().Get(&a.filter.Msg)
+	require.NoError(t, err)
+	found := false
+	for _, f := range filters {
+		if f.BPF != nil && f.BPF.ID != nil && ebpf.ProgramID(*f.BPF.ID) == dispatcherID(t, handler, iface) {
+			found = true
+		}
+	}
+	assert.True(t, found, "dispatcher filter not found")
+}
+
+func TestAttachIfaceNetkit(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	nk := &netlink.Netkit{
+		LinkAttrs: netlink.LinkAttrs{Name: "ignk0"},
+		Mode:      netlink.NETKIT_MODE_L3,
+		Policy:    netlink.NETKIT_POLICY_FORWARD,
+	}
+	nk.SetPeerAttrs(&netlink.LinkAttrs{Name: "ignk0p"})
+	if err := netlink.LinkAdd(nk); err != nil {
+		t.Skipf("creating netkit device: %s", err)
+	}
+	t.Cleanup(func() { netlink.LinkDel(nk) })
+
+	iface, err := net.InterfaceByName("ignk0")
+	require.NoError(t, err)
+
+	handler := newHandler(t, AttachmentDirectionIngress)
+	require.NoError(t, handler.AttachIface(iface))
+
+	// Packets received by the primary device are the ones transmitted by the peer
+	id := dispatcherID(t, handler, iface)
+	assert.Equal(t, []ebpf.ProgramID{id}, queryPrograms(t, iface, ebpf.AttachNetkitPeer))
+	assert.Empty(t, queryPrograms(t, iface, ebpf.AttachNetkitPrimary))
+}
@@ -115,6 +115,7 @@ type Container interface {
 	K8sOwnerReference() *K8sOwnerReference
 	ContainerPid() uint32
 	K8sPodLabelsAsString() string
+	K8sPodAnnotations() map[string]string
 }
 
 type BasicRuntimeMetadata struct {
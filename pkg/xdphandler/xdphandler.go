@@ -0,0 +1,328 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xdphandler handles how XDP programs are attached to containers and network interfaces.
+// The behavior is very similar to the tc handler implemented in pkg/tchandler.
+// The main differences are that XDP programs only see ingress traffic and that they are attached
+// directly using BPF links, without a dispatcher program. Only one XDP program can be attached to
+// a network interface at a time.
+package xdphandler
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/nsenter"
+)
+
+// AttachmentSide defines on which end of the veth pair of a container the program is attached
+type AttachmentSide int
+
+const (
+	// AttachmentSideHost attaches programs to the peers of the container interfaces on the
+	// host. As XDP programs only see ingress traffic, they see the packets sent by the
+	// container.
+	AttachmentSideHost AttachmentSide = iota
+	// AttachmentSideContainer attaches programs to the interfaces in the network namespace of
+	// the container. They see the packets received by the container.
+	AttachmentSideContainer
+)
+
+// ParseAttachmentSide parses "host" or "container"; an empty string defaults to "host"
+func ParseAttachmentSide(s string) (AttachmentSide, error) {
+	switch s {
+	case "", "host":
+		return AttachmentSideHost, nil
+	case "container":
+		return AttachmentSideContainer, nil
+	}
+	return 0, fmt.Errorf("invalid attachment side %q: expected host or container", s)
+}
+
+// Mode is the XDP attach mode
+type Mode string
+
+const (
+	// ModeAuto uses the native mode if the driver supports it and falls back to the generic
+	// mode otherwise
+	ModeAuto    Mode = "auto"
+	ModeGeneric Mode = "generic"
+	ModeNative  Mode = "native"
+)
+
+// ParseMode parses an attach mode; an empty string defaults to ModeAuto
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeAuto:
+		return ModeAuto, nil
+	case ModeGeneric, ModeNative:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("invalid XDP mode %q: expected %s, %s or %s", s, ModeAuto, ModeGeneric, ModeNative)
+}
+
+func (m Mode) flags() link.XDPAttachFlags {
+	switch m {
+	case ModeGeneric:
+		return link.XDPGenericMode
+	case ModeNative:
+		return link.XDPDriverMode
+	}
+	return 0
+}
+
+type attachment struct {
+	// nsPid is the pid of a process in the network namespace of the interface, 0 if it's in
+	// the current network namespace
+	nsPid   int
+	ifindex int
+	ifname  string
+
+	// link is nil until the program is set using AttachProg()
+	link link.Link
+
+	// users keeps track of the users' pid that have called Attach(). This can happen for when
+	// there are several containers in a pod (sharing the netns, and hence the networking
+	// interface). In this case we want to attach the program once.
+	users map[uint32]struct{}
+}
+
+type Handler struct {
+	prog *ebpf.Program
+	side AttachmentSide
+	mode Mode
+
+	// key: network namespace inode and network interface name
+	// value: attachment
+	attachments map[string]*attachment
+
+	// mu protects attachments from concurrent access
+	// AttachContainer and DetachContainer can be called in parallel
+	mu sync.Mutex
+}
+
+func NewHandler(side AttachmentSide, mode Mode) (*Handler, error) {
+	if _, err := ParseMode(string(mode)); err != nil {
+		return nil, err
+	}
+	return &Handler{
+		side:        side,
+		mode:        mode,
+		attachments: make(map[string]*attachment),
+	}, nil
+}
+
+func (t *Handler) attach(a *attachment) error {
+	return nsenter.NetnsEnter(a.nsPid, func() error {
+		l, err := link.AttachXDP(link.XDPOptions{
+			Program:   t.prog,
+			Interface: a.ifindex,
+			Flags:     t.mode.flags(),
+		})
+		if err != nil {
+			return fmt.Errorf("attaching XDP program to interface %s in %s mode: %w", a.ifname, t.mode, err)
+		}
+		a.link = l
+		return nil
+	})
+}
+
+func (t *Handler) closeAttachment(a *attachment) {
+	if a.link != nil {
+		a.link.Close()
+		a.link = nil
+	}
+}
+
+// AttachProg sets the program and attaches it to all interfaces registered so far
+func (t *Handler) AttachProg(prog *ebpf.Program) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prog = prog
+	for _, a := range t.attachments {
+		if a.link != nil {
+			continue
+		}
+		if err := t.attach(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addAttachment registers an interface and attaches the program to it if it's already set. It
+// must be called with mu held.
+func (t *Handler) addAttachment(key string, pid uint32, nsPid int, iface *net.Interface) error {
+	if a, ok := t.attachments[key]; ok {
+		a.users[pid] = struct{}{}
+		return nil
+	}
+
+	a := &attachment{
+		nsPid:   nsPid,
+		ifindex: iface.Index,
+		ifname:  iface.Name,
+		users:   map[uint32]struct{}{pid: {}},
+	}
+	if t.prog != nil {
+		if err := t.attach(a); err != nil {
+			return err
+		}
+	}
+	t.attachments[key] = a
+	return nil
+}
+
+// containerIfaces returns the network interfaces inside the network namespace of the given pid,
+// excluding the loopback interface
+func containerIfaces(pid int) ([]*net.Interface, error) {
+	var res []*net.Interface
+	err := nsenter.NetnsEnter(pid, func() error {
+		ifaces, err := net.Interfaces()
+		if err != nil {
+			return fmt.Errorf("getting interfaces: %w", err)
+		}
+		for _, iface := range ifaces {
+			if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+				continue
+			}
+			res = append(res, &iface)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no interface found")
+	}
+	return res, nil
+}
+
+func (t *Handler) AttachContainer(container *containercollection.Container) error {
+	// It's not clear what to do with hostNetwork containers. For now we just ignore them.
+	if container.HostNetwork {
+		return nil
+	}
+
+	pid := container.ContainerPid()
+
+	var ifaces []*net.Interface
+	var nsPid int
+	var err error
+
+	switch t.side {
+	case AttachmentSideContainer:
+		nsPid = int(pid)
+		ifaces, err = containerIfaces(nsPid)
+		if err != nil {
+			return fmt.Errorf("getting network interfaces of pid %d: %w", pid, err)
+		}
+	default:
+		// We need to perform these operations from the host network namespace, otherwise we
+		// won't find the interfaces.
+		nsPid = 1
+		ifaces, err = containerutils.GetIfacePeers(int(pid))
+		if err != nil {
+			return fmt.Errorf("getting network interfaces on the host side for pid %d: %w", pid, err)
+		}
+	}
+
+	netns, err := containerutils.GetNetNs(nsPid)
+	if err != nil {
+		return fmt.Errorf("getting network namespace of pid %d: %w", nsPid, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var errs []string
+	for _, iface := range ifaces {
+		key := fmt.Sprintf("%d/%s", netns, iface.Name)
+		if err := t.addAttachment(key, pid, nsPid, iface); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("attaching container %s: %s", container.Runtime.ContainerName, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (t *Handler) DetachContainer(container *containercollection.Container) error {
+	// It's not clear what to do with hostNetwork containers. For now we just ignore them.
+	if container.HostNetwork {
+		return nil
+	}
+
+	pid := container.ContainerPid()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found := false
+	for key, a := range t.attachments {
+		if _, ok := a.users[pid]; ok {
+			found = true
+			delete(a.users, pid)
+			if len(a.users) == 0 {
+				t.closeAttachment(a)
+				delete(t.attachments, key)
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("pid %d is not attached", pid)
+	}
+	return nil
+}
+
+// AttachIface attaches the program to the given interface in the current network namespace. See
+// AttachContainer() if you want to attach to a container.
+func (t *Handler) AttachIface(iface *net.Interface) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.addAttachment(iface.Name, 0, 0, iface)
+}
+
+func (t *Handler) DetachIface(iface *net.Interface) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if a, ok := t.attachments[iface.Name]; ok {
+		t.closeAttachment(a)
+		delete(t.attachments, iface.Name)
+		return nil
+	}
+	return fmt.Errorf("interface %s is not attached", iface.Name)
+}
+
+func (t *Handler) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, a := range t.attachments {
+		t.closeAttachment(a)
+		delete(t.attachments, key)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xdphandler
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+
+	utilstest "github.com/inspektor-gadget/inspektor-gadget/internal/test"
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+const (
+	hostVeth      = "igxdp0"
+	containerVeth = "igxdp1"
+)
+
+func newXDPProg(t *testing.T) *ebpf.Program {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:    ebpf.XDP,
+		License: "GPL",
+		Instructions: asm.Instructions{
+			asm.Mov.Imm(asm.R0, 2), // XDP_PASS
+			asm.Return(),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { prog.Close() })
+	return prog
+}
+
+type testContainer struct {
+	container *containercollection.Container
+	ns        netns.NsHandle
+}
+
+// newTestContainer creates a network namespace with a process running in it and a veth pair
+// connecting it to the current network namespace
+func newTestContainer(t *testing.T) *testContainer {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	orig, err := netns.Get()
+	require.NoError(t, err)
+	defer orig.Close()
+
+	ns, err := netns.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { ns.Close() })
+
+	// The process inherits the network namespace of the current thread
+	cmd := exec.Command("sleep", "inf")
+	err = cmd.Start()
+	require.NoError(t, netns.Set(orig))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: hostVeth},
+		PeerName:  containerVeth,
+	}
+	require.NoError(t, netlink.LinkAdd(veth))
+	t.Cleanup(func() { netlink.LinkDel(veth) })
+	require.NoError(t, netlink.LinkSetUp(veth))
+
+	peer, err := netlink.LinkByName(containerVeth)
+	require.NoError(t, err)
+	require.NoError(t, netlink.LinkSetNsFd(peer, int(ns)))
+
+	h, err := netlink.NewHandleAt(ns)
+	require.NoError(t, err)
+	defer h.Close()
+	peer, err = h.LinkByName(containerVeth)
+	require.NoError(t, err)
+	require.NoError(t, h.LinkSetUp(peer))
+
+	return &testContainer{
+		container: &containercollection.Container{
+			Runtime: containercollection.RuntimeMetadata{
+				BasicRuntimeMetadata: types.BasicRuntimeMetadata{
+					ContainerPID:  uint32(cmd.Process.Pid),
+					ContainerName: "test",
+				},
+			},
+		},
+		ns: ns,
+	}
+}
+
+func xdpInfo(t *testing.T, ns netns.NsHandle, name string) *netlink.LinkXdp {
+	h, err := netlink.NewHandleAt(ns)
+	require.NoError(t, err)
+	defer h.Close()
+	l, err := h.LinkByName(name)
+	require.NoError(t, err)
+	if l.Attrs().Xdp == nil {
+		return &netlink.LinkXdp{}
+	}
+	return l.Attrs().Xdp
+}
+
+func TestParse(t *testing.T) {
+	side, err := ParseAttachmentSide("")
+	require.NoError(t, err)
+	assert.Equal(t, AttachmentSideHost, side)
+	side, err = ParseAttachmentSide("container")
+	require.NoError(t, err)
+	assert.Equal(t, AttachmentSideContainer, side)
+	_, err = ParseAttachmentSide("foo")
+	require.Error(t, err)
+
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeAuto, mode)
+	mode, err = ParseMode("generic")
+	require.NoError(t, err)
+	assert.Equal(t, ModeGeneric, mode)
+	_, err = ParseMode("foo")
+	require.Error(t, err)
+}
+
+func TestAttachContainer(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	current, err := netns.Get()
+	require.NoError(t, err)
+	defer current.Close()
+
+	for name, tc := range map[string]struct {
+		side  AttachmentSide
+		iface string
+	}{
+		"host":      {side: AttachmentSideHost, iface: hostVeth},
+		"container": {side: AttachmentSideContainer, iface: containerVeth},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tc.side == AttachmentSideHost {
+				// The host side is reached through the network namespace of pid 1
+				if _, err := os.Readlink("/proc/1/ns/net"); err != nil {
+					t.Skipf("cannot access network namespace of pid 1: %s", err)
+				}
+			}
+
+			c := newTestContainer(t)
+			ns := current
+			if tc.side == AttachmentSideContainer {
+				ns = c.ns
+			}
+
+			handler, err := NewHandler(tc.side, ModeGeneric)
+			require.NoError(t, err)
+			defer handler.Close()
+
+			// Containers can be attached before the program is set
+			require.NoError(t, handler.AttachContainer(c.container))
+			assert.False(t, xdpInfo(t, ns, tc.iface).Attached)
+
+			prog := newXDPProg(t)
+			require.NoError(t, handler.AttachProg(prog))
+			xdp := xdpInfo(t, ns, tc.iface)
+			require.True(t, xdp.Attached)
+			info, err := prog.Info()
+			require.NoError(t, err)
+			id, _ := info.ID()
+			assert.EqualValues(t, id, xdp.ProgId)
+
+			require.NoError(t, handler.DetachContainer(c.container))
+			assert.False(t, xdpInfo(t, ns, tc.iface).Attached)
+			require.Error(t, handler.DetachContainer(c.container))
+		})
+	}
+}
+
+func TestAttachIface(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	current, err := netns.Get()
+	require.NoError(t, err)
+	defer current.Close()
+
+	newTestContainer(t)
+	iface, err := net.InterfaceByName(hostVeth)
+	require.NoError(t, err)
+
+	handler, err := NewHandler(AttachmentSideHost, ModeAuto)
+	require.NoError(t, err)
+
+	prog := newXDPProg(t)
+	require.NoError(t, handler.AttachProg(prog))
+	require.NoError(t, handler.AttachIface(iface))
+	assert.True(t, xdpInfo(t, current, hostVeth).Attached)
+
+	// Only one XDP program can be attached to an interface
+	other, err := NewHandler(AttachmentSideHost, ModeAuto)
+	require.NoError(t, err)
+	defer other.Close()
+	require.NoError(t, other.AttachProg(newXDPProg(t)))
+	require.Error(t, other.AttachIface(iface))
+
+	// Closing the handler detaches the program
+	handler.Close()
+	assert.False(t, xdpInfo(t, current, hostVeth).Attached)
+}